@@ -17,7 +17,20 @@ func ensureDBPrerequisites(db *gorm.DB) error {
 		`DO $$ BEGIN CREATE TYPE payment_status AS ENUM ('pending', 'waiting_confirmation', 'paid', 'rejected', 'expired', 'cancelled'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
 		`DO $$ BEGIN CREATE TYPE meeting_type AS ENUM ('basic', 'exam'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
 		`DO $$ BEGIN CREATE TYPE assignment_type AS ENUM ('essay', 'file'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE late_penalty_unit AS ENUM ('hour', 'day'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE annotation_type AS ENUM ('highlight', 'box', 'note'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE grade_moderation_status AS ENUM ('draft', 'submitted', 'moderated', 'released'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE certificate_status AS ENUM ('active', 'revoked', 'superseded'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
 		`DO $$ BEGIN CREATE TYPE quiz_type AS ENUM ('tf', 'mc'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE quiz_mode AS ENUM ('graded', 'practice', 'survey'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE quiz_scoring_policy AS ENUM ('highest', 'latest', 'average', 'first'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE quiz_review_level AS ENUM ('score_only', 'wrong_answers', 'full_solution'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE quiz_review_release AS ENUM ('immediate', 'after_end', 'manual'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE proctor_event_type AS ENUM ('tab_blur', 'fullscreen_exit', 'copy', 'paste', 'reconnect'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE attempt_action_type AS ENUM ('extend', 'pause', 'resume', 'reopen', 'void'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE omr_scan_status AS ENUM ('graded', 'review', 'resolved', 'rejected'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE similarity_report_status AS ENUM ('pending', 'running', 'completed', 'failed'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
+		`DO $$ BEGIN CREATE TYPE question_scoring_mode AS ENUM ('normal', 'void', 'free_credit'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
 		`DO $$ BEGIN CREATE TYPE course_type AS ENUM ('online', 'offline'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
 		`DO $$ BEGIN CREATE TYPE day_type AS ENUM ('monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday', 'sunday'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;`,
 	}
@@ -48,15 +61,31 @@ func main() {
 		&models.BatchDay{},
 		&models.BatchGroup{},
 		&models.GroupDaysBatch{},
+		&models.StudentGroup{},
+		&models.StudentGroupMember{},
 		&models.Meeting{},
 		&models.MeetingTeacher{},
 		&models.Attendance{},
 		&models.Material{},
+		&models.Rubric{},
+		&models.RubricCriterion{},
+		&models.RubricLevel{},
 		&models.Assignment{},
 		&models.AssignmentFiles{},
 		&models.AssignmentSubmission{},
+		&models.SubmissionVersion{},
+		&models.SubmissionVersionFile{},
 		&models.SubmissionFile{},
+		&models.SubmissionAnnotation{},
 		&models.AssignmentGrade{},
+		&models.AssignmentGradeCriterion{},
+		&models.AssignmentGradeAdjustment{},
+		&models.PeerReview{},
+		&models.PeerReviewCriterion{},
+		&models.SimilarityReport{},
+		&models.SimilarityDocument{},
+		&models.SimilarityPair{},
+		&models.SimilarityPassage{},
 		&models.Quiz{},
 		&models.QuizQuestion{},
 		&models.QuizOption{},
@@ -64,6 +93,19 @@ func main() {
 		&models.QuizSubmission{},
 		&models.QuizTempSubmission{},
 		&models.QuizResult{},
+		&models.QuizRegradeLog{},
+		&models.QuizRegradeChange{},
+		&models.QuizAccommodation{},
+		&models.AssignmentExtension{},
+		&models.QuizProctorEvent{},
+		&models.QuizAnswerLog{},
+		&models.QuizAttemptAction{},
+		&models.QuizPaperVersion{},
+		&models.QuizPaperQuestion{},
+		&models.QuizPaperOption{},
+		&models.QuizAnswerSheet{},
+		&models.QuizOmrScan{},
+		&models.QuizOmrScanAnswer{},
 		&models.Price{},
 		&models.Purchase{},
 		&models.Certificate{},
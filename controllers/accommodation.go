@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/services"
+	"brevet-api/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// AccommodationController handles per-student time accommodations
+type AccommodationController struct {
+	accommodationService services.IAccommodationService
+	db                   *gorm.DB
+}
+
+// NewAccommodationController creates a new instance of AccommodationController
+func NewAccommodationController(accommodationService services.IAccommodationService, db *gorm.DB) *AccommodationController {
+	return &AccommodationController{
+		accommodationService: accommodationService,
+		db:                   db,
+	}
+}
+
+// ListQuizAccommodations list accommodation quiz
+func (ctrl *AccommodationController) ListQuizAccommodations(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	accs, err := ctrl.accommodationService.ListQuizAccommodations(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch accommodations", err.Error())
+	}
+
+	var response []dto.QuizAccommodationResponse
+	if err := copier.Copy(&response, &accs); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map accommodation data", err.Error())
+	}
+	for i := range response {
+		response[i].UserName = accs[i].User.Name
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Accommodations fetched", response)
+}
+
+// UpsertQuizAccommodation create or update accommodation quiz
+func (ctrl *AccommodationController) UpsertQuizAccommodation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.UpsertQuizAccommodationRequest)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	acc, err := ctrl.accommodationService.UpsertQuizAccommodation(ctx, user, quizID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to save accommodation", err.Error())
+	}
+
+	var response dto.QuizAccommodationResponse
+	if err := copier.Copy(&response, acc); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map accommodation data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Accommodation saved", response)
+}
+
+// DeleteQuizAccommodation delete accommodation quiz
+func (ctrl *AccommodationController) DeleteQuizAccommodation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+	userID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID", err.Error())
+	}
+
+	if err := ctrl.accommodationService.DeleteQuizAccommodation(ctx, user, quizID, userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete accommodation", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Accommodation deleted", nil)
+}
+
+// ListAssignmentExtensions list perpanjangan deadline assignment
+func (ctrl *AccommodationController) ListAssignmentExtensions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	exts, err := ctrl.accommodationService.ListAssignmentExtensions(ctx, user, assignmentID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch extensions", err.Error())
+	}
+
+	var response []dto.AssignmentExtensionResponse
+	if err := copier.Copy(&response, &exts); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map extension data", err.Error())
+	}
+	for i := range response {
+		response[i].UserName = exts[i].User.Name
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Extensions fetched", response)
+}
+
+// UpsertAssignmentExtension create or update perpanjangan deadline assignment
+func (ctrl *AccommodationController) UpsertAssignmentExtension(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.UpsertAssignmentExtensionRequest)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	ext, err := ctrl.accommodationService.UpsertAssignmentExtension(ctx, user, assignmentID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to save extension", err.Error())
+	}
+
+	var response dto.AssignmentExtensionResponse
+	if err := copier.Copy(&response, ext); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map extension data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Extension saved", response)
+}
+
+// DeleteAssignmentExtension delete perpanjangan deadline assignment
+func (ctrl *AccommodationController) DeleteAssignmentExtension(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+	userID, err := uuid.Parse(c.Params("userID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID", err.Error())
+	}
+
+	if err := ctrl.accommodationService.DeleteAssignmentExtension(ctx, user, assignmentID, userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete extension", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Extension deleted", nil)
+}
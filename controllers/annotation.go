@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnnotationController handles PDF annotations on submissions
+type AnnotationController struct {
+	annotationService services.IAnnotationService
+	db                *gorm.DB
+}
+
+// NewAnnotationController creates a new instance of AnnotationController
+func NewAnnotationController(annotationService services.IAnnotationService, db *gorm.DB) *AnnotationController {
+	return &AnnotationController{
+		annotationService: annotationService,
+		db:                db,
+	}
+}
+
+func toAnnotationResponse(a *models.SubmissionAnnotation) dto.AnnotationResponse {
+	return dto.AnnotationResponse{
+		ID:               a.ID,
+		SubmissionID:     a.SubmissionID,
+		SubmissionFileID: a.SubmissionFileID,
+		FileURL:          a.FileURL,
+		Version:          a.Version,
+		AuthorID:         a.AuthorID,
+		AuthorName:       a.Author.Name,
+		Page:             a.Page,
+		Type:             a.Type,
+		X:                a.X,
+		Y:                a.Y,
+		Width:            a.Width,
+		Height:           a.Height,
+		Comment:          a.Comment,
+		Color:            a.Color,
+		CreatedAt:        a.CreatedAt,
+		UpdatedAt:        a.UpdatedAt,
+	}
+}
+
+func toAnnotationResponses(annotations []models.SubmissionAnnotation) []dto.AnnotationResponse {
+	response := make([]dto.AnnotationResponse, 0, len(annotations))
+	for i := range annotations {
+		response = append(response, toAnnotationResponse(&annotations[i]))
+	}
+	return response
+}
+
+func annotationError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Annotation or file not found", err.Error())
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, message, err.Error())
+}
+
+// GetSubmissionAnnotations semua anotasi submission
+func (ctrl *AnnotationController) GetSubmissionAnnotations(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	submissionID, err := uuid.Parse(c.Params("submissionID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid submission ID", err.Error())
+	}
+
+	annotations, err := ctrl.annotationService.GetSubmissionAnnotations(ctx, user, submissionID)
+	if err != nil {
+		return annotationError(c, err, "Failed to fetch annotations")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Annotations fetched", toAnnotationResponses(annotations))
+}
+
+// GetFileAnnotations anotasi satu file submission
+func (ctrl *AnnotationController) GetFileAnnotations(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	fileID, err := uuid.Parse(c.Params("fileID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid file ID", err.Error())
+	}
+
+	annotations, err := ctrl.annotationService.GetFileAnnotations(ctx, user, fileID)
+	if err != nil {
+		return annotationError(c, err, "Failed to fetch annotations")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Annotations fetched", toAnnotationResponses(annotations))
+}
+
+// CreateAnnotation tambah anotasi
+func (ctrl *AnnotationController) CreateAnnotation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.CreateAnnotationRequest)
+
+	fileID, err := uuid.Parse(c.Params("fileID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid file ID", err.Error())
+	}
+
+	annotation, err := ctrl.annotationService.CreateAnnotation(ctx, user, fileID, body)
+	if err != nil {
+		return annotationError(c, err, "Failed to create annotation")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Annotation created", toAnnotationResponse(annotation))
+}
+
+// UpdateAnnotation ubah anotasi
+func (ctrl *AnnotationController) UpdateAnnotation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.UpdateAnnotationRequest)
+
+	annotationID, err := uuid.Parse(c.Params("annotationID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid annotation ID", err.Error())
+	}
+
+	annotation, err := ctrl.annotationService.UpdateAnnotation(ctx, user, annotationID, body)
+	if err != nil {
+		return annotationError(c, err, "Failed to update annotation")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Annotation updated", toAnnotationResponse(annotation))
+}
+
+// DeleteAnnotation hapus anotasi
+func (ctrl *AnnotationController) DeleteAnnotation(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	annotationID, err := uuid.Parse(c.Params("annotationID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid annotation ID", err.Error())
+	}
+
+	if err := ctrl.annotationService.DeleteAnnotation(ctx, user, annotationID); err != nil {
+		return annotationError(c, err, "Failed to delete annotation")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Annotation deleted", nil)
+}
+
+// DownloadAnnotatedPDF download salinan PDF yang sudah dianotasi
+func (ctrl *AnnotationController) DownloadAnnotatedPDF(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	fileID, err := uuid.Parse(c.Params("fileID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid file ID", err.Error())
+	}
+
+	buffer, filename, err := ctrl.annotationService.GenerateAnnotatedPDF(ctx, user, fileID)
+	if err != nil {
+		return annotationError(c, err, "Failed to generate annotated PDF")
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.SendStream(buffer)
+}
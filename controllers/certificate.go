@@ -2,8 +2,10 @@ package controllers
 
 import (
 	"brevet-api/dto"
+	"brevet-api/models"
 	"brevet-api/services"
 	"brevet-api/utils"
+	"context"
 	"errors"
 	"net/url"
 
@@ -181,13 +183,7 @@ func (ctrl *CertificateController) VerifyCertificate(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to verify certificate", err.Error())
 	}
 
-	// Map ke DTO
-	var certResponse dto.CertificateResponse
-	if copyErr := copier.Copy(&certResponse, cert); copyErr != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map certificate data", copyErr.Error())
-	}
-
-	return utils.SuccessWithMeta(c, fiber.StatusOK, "Certificate is valid", certResponse, nil)
+	return ctrl.verificationResponse(c, cert)
 }
 
 // GetByNumber finds certificate by its number (public)
@@ -213,11 +209,53 @@ func (ctrl *CertificateController) GetByNumber(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get certificate", err.Error())
 	}
 
-	// mapping ke DTO
+	return ctrl.verificationResponse(c, cert)
+}
+
+// verificationResponse status keabsahan sertifikat (aktif / dicabut / diganti)
+func (ctrl *CertificateController) verificationResponse(c *fiber.Ctx, cert *models.Certificate) error {
+	verification, err := services.CertificateVerification(cert)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map certificate data", err.Error())
+	}
+	return utils.SuccessWithMeta(c, fiber.StatusOK, verification.Message, verification, nil)
+}
+
+// RevokeCertificate revokes a certificate (admin)
+func (ctrl *CertificateController) RevokeCertificate(c *fiber.Ctx) error {
+	return ctrl.certificateAction(c, ctrl.certificateService.RevokeCertificate, "Certificate revoked successfully")
+}
+
+// ReissueCertificate reissues a certificate with a new number and PDF (admin)
+func (ctrl *CertificateController) ReissueCertificate(c *fiber.Ctx) error {
+	return ctrl.certificateAction(c, ctrl.certificateService.ReissueCertificate, "Certificate reissued successfully")
+}
+
+func (ctrl *CertificateController) certificateAction(
+	c *fiber.Ctx,
+	action func(ctx context.Context, certID uuid.UUID, admin *utils.Claims, reason string) (*models.Certificate, error),
+	message string,
+) error {
+	certID, err := uuid.Parse(c.Params("certificateID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "invalid certificateID", err.Error())
+	}
+
+	body := c.Locals("body").(*dto.CertificateActionRequest)
+	userClaims := c.Locals("user").(*utils.Claims)
+
+	cert, err := action(c.UserContext(), certID, userClaims, body.Reason)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Certificate not found", "")
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update certificate", err.Error())
+	}
+
 	var certResponse dto.CertificateResponse
 	if copyErr := copier.Copy(&certResponse, cert); copyErr != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map certificate data", copyErr.Error())
 	}
 
-	return utils.SuccessWithMeta(c, fiber.StatusOK, "Certificate found", certResponse, nil)
+	return utils.SuccessWithMeta(c, fiber.StatusOK, message, certResponse, nil)
 }
@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/middlewares"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+const (
+	// liveTimeSyncInterval seberapa sering server mengirim sisa waktu ke client
+	liveTimeSyncInterval = 5 * time.Second
+	// liveMonitorRefreshInterval snapshot ulang monitor guru (menangkap presence yang kadaluarsa)
+	liveMonitorRefreshInterval = 30 * time.Second
+)
+
+// QuizLiveController WebSocket untuk attempt quiz yang sedang berjalan & monitor guru
+type QuizLiveController struct {
+	quizService services.IQuizService
+	liveHub     services.IQuizLiveHub
+}
+
+// NewQuizLiveController creates a new live quiz controller
+func NewQuizLiveController(quizService services.IQuizService, liveHub services.IQuizLiveHub) *QuizLiveController {
+	return &QuizLiveController{quizService: quizService, liveHub: liveHub}
+}
+
+// PrepareAttemptSession validasi attempt sebelum upgrade ke WebSocket
+func (ctrl *QuizLiveController) PrepareAttemptSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	attemptID, err := uuid.Parse(c.Params("attemptID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid attempt ID", err.Error())
+	}
+
+	session, err := ctrl.quizService.OpenLiveAttempt(c.UserContext(), user, attemptID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to open live session", err.Error())
+	}
+
+	c.Locals("live_session", session)
+	return c.Next()
+}
+
+// AttemptSession kanal WebSocket per attempt: sinkronisasi waktu, simpan jawaban dengan ack,
+// dan pemberitahuan submit paksa dari scheduler.
+func (ctrl *QuizLiveController) AttemptSession(conn *websocket.Conn) {
+	ctx := context.Background()
+	user := conn.Locals("user").(*utils.Claims)
+	session := conn.Locals("live_session").(*dto.QuizLiveSession)
+	client := dto.ClientInfo{IPAddress: conn.IP(), UserAgent: conn.Headers("User-Agent")}
+
+	events, unsubscribe := ctrl.liveHub.SubscribeAttempt(session.AttemptID)
+	defer unsubscribe()
+
+	ctrl.touchPresence(ctx, session)
+	ctrl.liveHub.Publish(ctx, dto.QuizLiveEvent{
+		Type: services.LiveEventConnected, QuizID: session.QuizID, AttemptID: session.AttemptID, UserID: session.UserID,
+	})
+	defer func() {
+		if err := ctrl.liveHub.ClearPresence(ctx, session.QuizID, session.AttemptID); err != nil {
+			log.Warnf("Failed to clear live presence for attempt %s: %v", session.AttemptID, err)
+		}
+		ctrl.liveHub.Publish(ctx, dto.QuizLiveEvent{
+			Type: services.LiveEventDisconnected, QuizID: session.QuizID, AttemptID: session.AttemptID, UserID: session.UserID,
+		})
+	}()
+
+	// baca pesan client di goroutine terpisah; semua penulisan tetap di loop utama.
+	// done ditutup saat handler selesai supaya pembaca tidak tertahan di incoming.
+	incoming := make(chan dto.QuizLiveClientMessage)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(incoming)
+		for {
+			var msg dto.QuizLiveClientMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			select {
+			case incoming <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(liveTimeSyncInterval)
+	defer ticker.Stop()
+
+	if err := conn.WriteJSON(liveTimeMessage(session)); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case msg, ok := <-incoming:
+			if !ok {
+				return
+			}
+			if reply := ctrl.handleClientMessage(ctx, user, session, client, msg); reply != nil {
+				if err := conn.WriteJSON(reply); err != nil {
+					return
+				}
+			}
+
+		case event := <-events:
+			switch event.Type {
+			case services.LiveEventSubmitted:
+				_ = conn.WriteJSON(event)
+				return
+			case services.LiveEventPaused:
+				pausedAt := event.At
+				session.PausedAt = &pausedAt
+				if err := conn.WriteJSON(liveTimeMessage(session)); err != nil {
+					return
+				}
+			case services.LiveEventDeadlineChanged, services.LiveEventResumed:
+				if event.Deadline != nil {
+					session.Deadline = *event.Deadline
+				}
+				if event.Type == services.LiveEventResumed {
+					session.PausedAt = nil
+				}
+				if err := conn.WriteJSON(liveTimeMessage(session)); err != nil {
+					return
+				}
+			}
+
+		case <-ticker.C:
+			ctrl.touchPresence(ctx, session)
+			if err := conn.WriteJSON(liveTimeMessage(session)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (ctrl *QuizLiveController) handleClientMessage(ctx context.Context, user *utils.Claims, session *dto.QuizLiveSession, client dto.ClientInfo, msg dto.QuizLiveClientMessage) any {
+	var err error
+	switch msg.Type {
+	case "ping":
+		return liveTimeMessage(session)
+
+	case "save_answer":
+		body := dto.SaveTempSubmissionRequest{QuestionID: msg.QuestionID, SelectedOptionID: msg.SelectedOptionID}
+		if err = middlewares.ValidateStruct(body); err == nil {
+			err = ctrl.quizService.SaveTempSubmission(ctx, user, session.AttemptID, &body, client)
+		}
+
+	case "event":
+		body := dto.ReportProctorEventRequest{Type: msg.EventType, Detail: msg.Detail, OccurredAt: msg.OccurredAt}
+		if err = middlewares.ValidateStruct(body); err == nil {
+			err = ctrl.quizService.ReportProctorEvent(ctx, user, session.AttemptID, &body, client)
+		}
+
+	default:
+		return dto.QuizLiveAckMessage{Type: "ack", RequestID: msg.RequestID, Error: "unknown message type"}
+	}
+
+	ack := dto.QuizLiveAckMessage{Type: "ack", RequestID: msg.RequestID, OK: err == nil}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+	return ack
+}
+
+func (ctrl *QuizLiveController) touchPresence(ctx context.Context, session *dto.QuizLiveSession) {
+	if err := ctrl.liveHub.TouchPresence(ctx, session.QuizID, session.AttemptID); err != nil {
+		log.Warnf("Failed to update live presence for attempt %s: %v", session.AttemptID, err)
+	}
+}
+
+// liveTimeMessage sisa waktu attempt; selama dijeda sisa waktu dibekukan di saat jeda dimulai
+func liveTimeMessage(session *dto.QuizLiveSession) dto.QuizLiveTimeMessage {
+	now := time.Now()
+	from := now
+	if session.PausedAt != nil {
+		from = *session.PausedAt
+	}
+	remaining := int64(session.Deadline.Sub(from).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+	return dto.QuizLiveTimeMessage{
+		Type: "time", ServerTime: now, Deadline: session.Deadline, RemainingSeconds: remaining, Paused: session.PausedAt != nil,
+	}
+}
+
+// GetLiveMonitor snapshot siswa yang sedang mengerjakan quiz (HTTP)
+func (ctrl *QuizLiveController) GetLiveMonitor(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	monitor, err := ctrl.quizService.GetLiveMonitor(c.UserContext(), user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch live monitor", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success", monitor)
+}
+
+// PrepareMonitorSession validasi akses guru sebelum upgrade ke WebSocket
+func (ctrl *QuizLiveController) PrepareMonitorSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	monitor, err := ctrl.quizService.GetLiveMonitor(c.UserContext(), user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to open live monitor", err.Error())
+	}
+
+	c.Locals("live_monitor", monitor)
+	return c.Next()
+}
+
+// MonitorSession kanal WebSocket monitor guru: snapshot awal lalu event per attempt
+func (ctrl *QuizLiveController) MonitorSession(conn *websocket.Conn) {
+	ctx := context.Background()
+	user := conn.Locals("user").(*utils.Claims)
+	monitor := conn.Locals("live_monitor").(*dto.QuizLiveMonitorResponse)
+
+	events, unsubscribe := ctrl.liveHub.SubscribeQuiz(monitor.QuizID)
+	defer unsubscribe()
+
+	// pesan dari guru tidak dipakai, cukup deteksi koneksi tertutup
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(liveMonitorRefreshInterval)
+	defer ticker.Stop()
+
+	if err := conn.WriteJSON(dto.QuizLiveMonitorMessage{Type: "snapshot", Data: monitor}); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-closed:
+			return
+
+		case event := <-events:
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			snapshot, err := ctrl.quizService.GetLiveMonitor(ctx, user, monitor.QuizID)
+			if err != nil {
+				log.Warnf("Failed to refresh live monitor for quiz %s: %v", monitor.QuizID, err)
+				continue
+			}
+			if err := conn.WriteJSON(dto.QuizLiveMonitorMessage{Type: "snapshot", Data: snapshot}); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// PeerReviewController handles peer review assignment
+type PeerReviewController struct {
+	peerReviewService services.IPeerReviewService
+	db                *gorm.DB
+}
+
+// NewPeerReviewController creates a new instance of PeerReviewController
+func NewPeerReviewController(peerReviewService services.IPeerReviewService, db *gorm.DB) *PeerReviewController {
+	return &PeerReviewController{
+		peerReviewService: peerReviewService,
+		db:                db,
+	}
+}
+
+func toPeerReviewCriteria(criteria []models.PeerReviewCriterion) ([]dto.GradeCriterionResponse, error) {
+	response := make([]dto.GradeCriterionResponse, 0, len(criteria))
+	if err := copier.Copy(&response, &criteria); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func toPeerReviewResponse(review *models.PeerReview) (dto.PeerReviewResponse, error) {
+	criteria, err := toPeerReviewCriteria(review.Criteria)
+	if err != nil {
+		return dto.PeerReviewResponse{}, err
+	}
+	return dto.PeerReviewResponse{
+		ID:              review.ID,
+		AssignmentID:    review.AssignmentID,
+		AssignmentTitle: review.Assignment.Title,
+		ReviewEndAt:     review.Assignment.PeerReviewEndAt,
+		Open:            review.Assignment.PeerReviewOpen(time.Now()),
+		Score:           review.Score,
+		RubricPoints:    review.RubricPoints,
+		RubricMaxPoints: review.RubricMaxPoints,
+		Comment:         review.Comment,
+		SubmittedAt:     review.SubmittedAt,
+		Criteria:        criteria,
+	}, nil
+}
+
+func toPeerReviewOverview(review *models.PeerReview) (dto.PeerReviewOverviewResponse, error) {
+	criteria, err := toPeerReviewCriteria(review.Criteria)
+	if err != nil {
+		return dto.PeerReviewOverviewResponse{}, err
+	}
+	return dto.PeerReviewOverviewResponse{
+		ID:              review.ID,
+		SubmissionID:    review.SubmissionID,
+		AuthorID:        review.Submission.UserID,
+		AuthorName:      review.Submission.User.Name,
+		ReviewerID:      review.ReviewerID,
+		ReviewerName:    review.Reviewer.Name,
+		Score:           review.Score,
+		RubricPoints:    review.RubricPoints,
+		RubricMaxPoints: review.RubricMaxPoints,
+		Comment:         review.Comment,
+		SubmittedAt:     review.SubmittedAt,
+		Excluded:        review.Excluded,
+		Criteria:        criteria,
+	}, nil
+}
+
+func peerReviewError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Peer review not found", err.Error())
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, message, err.Error())
+}
+
+// GetMyReviews daftar tugas review siswa
+func (ctrl *PeerReviewController) GetMyReviews(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	reviews, err := ctrl.peerReviewService.GetMyReviews(ctx, user)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch peer reviews", err.Error())
+	}
+
+	response := make([]dto.PeerReviewResponse, 0, len(reviews))
+	for i := range reviews {
+		item, err := toPeerReviewResponse(&reviews[i])
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map peer review", err.Error())
+		}
+		response = append(response, item)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer reviews fetched", response)
+}
+
+// GetMyReview detail tugas review: submission anonim & rubrik
+func (ctrl *PeerReviewController) GetMyReview(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	reviewID, err := uuid.Parse(c.Params("reviewID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid review ID", err.Error())
+	}
+
+	review, rubric, err := ctrl.peerReviewService.GetMyReview(ctx, user, reviewID)
+	if err != nil {
+		return peerReviewError(c, err, "Failed to fetch peer review")
+	}
+
+	item, err := toPeerReviewResponse(review)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map peer review", err.Error())
+	}
+	response := dto.PeerReviewDetailResponse{
+		PeerReviewResponse: item,
+		Submission: dto.PeerReviewSubmissionResponse{
+			EssayText: review.Submission.EssayText,
+			Note:      review.Submission.Note,
+			Files:     make([]string, 0, len(review.Submission.SubmissionFiles)),
+		},
+	}
+	for _, f := range review.Submission.SubmissionFiles {
+		response.Submission.Files = append(response.Submission.Files, f.FileURL)
+	}
+	if rubric != nil {
+		rubricResponse, err := toRubricResponse(rubric)
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map rubric", err.Error())
+		}
+		response.Rubric = &rubricResponse
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer review fetched", response)
+}
+
+// SubmitReview kirim / ubah isi review
+func (ctrl *PeerReviewController) SubmitReview(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.SubmitPeerReviewRequest)
+
+	reviewID, err := uuid.Parse(c.Params("reviewID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid review ID", err.Error())
+	}
+
+	review, err := ctrl.peerReviewService.SubmitReview(ctx, user, reviewID, body)
+	if err != nil {
+		return peerReviewError(c, err, "Failed to submit peer review")
+	}
+
+	response, err := toPeerReviewResponse(review)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map peer review", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer review submitted", response)
+}
+
+// GetReceivedReviews review yang diterima siswa atas submission-nya
+func (ctrl *PeerReviewController) GetReceivedReviews(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	submissionID, err := uuid.Parse(c.Params("submissionID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid submission ID", err.Error())
+	}
+
+	reviews, err := ctrl.peerReviewService.GetReceivedReviews(ctx, user, submissionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Submission not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch peer reviews", err.Error())
+	}
+
+	response := make([]dto.ReceivedPeerReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		criteria, err := toPeerReviewCriteria(review.Criteria)
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map peer review", err.Error())
+		}
+		response = append(response, dto.ReceivedPeerReviewResponse{
+			ID:          review.ID,
+			Score:       review.Score,
+			Comment:     review.Comment,
+			SubmittedAt: review.SubmittedAt,
+			Criteria:    criteria,
+		})
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer reviews fetched", response)
+}
+
+// DistributeReviews bagikan submission ke reviewer sekarang
+func (ctrl *PeerReviewController) DistributeReviews(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	reviews, err := ctrl.peerReviewService.DistributeReviews(ctx, user, assignmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Assignment not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to distribute peer reviews", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer reviews distributed", fiber.Map{"assigned": len(reviews)})
+}
+
+// GetAssignmentReviews pengawasan guru: semua review beserta ringkasan per submission
+func (ctrl *PeerReviewController) GetAssignmentReviews(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	assignment, reviews, err := ctrl.peerReviewService.GetAssignmentReviews(ctx, user, assignmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Assignment not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Failed to fetch peer reviews", err.Error())
+	}
+
+	response := dto.AssignmentPeerReviewsResponse{
+		DistributedAt: assignment.PeerReviewDistributedAt,
+		ReviewEndAt:   assignment.PeerReviewEndAt,
+		Weight:        assignment.PeerGradeWeight(),
+		Submissions:   []dto.PeerReviewSummaryResponse{},
+		Reviews:       make([]dto.PeerReviewOverviewResponse, 0, len(reviews)),
+	}
+	summaries := map[uuid.UUID]int{}
+	scores := map[uuid.UUID][]float64{}
+	for i := range reviews {
+		review := &reviews[i]
+		item, err := toPeerReviewOverview(review)
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map peer review", err.Error())
+		}
+		response.Reviews = append(response.Reviews, item)
+
+		idx, ok := summaries[review.SubmissionID]
+		if !ok {
+			idx = len(response.Submissions)
+			summaries[review.SubmissionID] = idx
+			response.Submissions = append(response.Submissions, dto.PeerReviewSummaryResponse{
+				SubmissionID: review.SubmissionID,
+				AuthorID:     item.AuthorID,
+				AuthorName:   item.AuthorName,
+			})
+		}
+		response.Submissions[idx].Assigned++
+		if review.SubmittedAt != nil {
+			response.Submissions[idx].Submitted++
+			if !review.Excluded && review.Score != nil {
+				scores[review.SubmissionID] = append(scores[review.SubmissionID], float64(*review.Score))
+			}
+		}
+	}
+	for i := range response.Submissions {
+		if s := scores[response.Submissions[i].SubmissionID]; len(s) > 0 {
+			score := services.PeerScore(s)
+			response.Submissions[i].PeerScore = &score
+		}
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer reviews fetched", response)
+}
+
+// SetReviewExcluded keluarkan / kembalikan review dari nilai peer
+func (ctrl *PeerReviewController) SetReviewExcluded(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.SetPeerReviewExcludedRequest)
+
+	reviewID, err := uuid.Parse(c.Params("reviewID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid review ID", err.Error())
+	}
+
+	review, err := ctrl.peerReviewService.SetReviewExcluded(ctx, user, reviewID, body.Excluded)
+	if err != nil {
+		return peerReviewError(c, err, "Failed to update peer review")
+	}
+
+	response, err := toPeerReviewOverview(review)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map peer review", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Peer review updated", response)
+}
@@ -2,8 +2,10 @@ package controllers
 
 import (
 	"brevet-api/dto"
+	"brevet-api/models"
 	"brevet-api/services"
 	"brevet-api/utils"
+	"context"
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
@@ -36,7 +38,8 @@ func (ctrl *QuizController) SaveTempSubmission(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "invalid attempt ID", err.Error())
 	}
 
-	if err := ctrl.quizService.SaveTempSubmission(ctx, user, attemptID, body); err != nil {
+	client := dto.ClientInfo{IPAddress: c.IP(), UserAgent: c.Get("User-Agent")}
+	if err := ctrl.quizService.SaveTempSubmission(ctx, user, attemptID, body, client); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "failed save temp submission", err.Error())
 	}
 	return utils.SuccessResponse(c, fiber.StatusOK, "Questions saved successfully", fiber.Map{"status": "saved"})
@@ -255,20 +258,21 @@ func (ctrl *QuizController) GetListAttempt(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
 	}
 
-	attempt, err := ctrl.quizService.GetListAttempt(ctx, quizID, user)
+	attempts, err := ctrl.quizService.GetListAttempt(ctx, quizID, user)
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch attempt", err.Error())
 	}
 
-	fmt.Println(attempt, "TAII")
-
-	if attempt == nil {
+	if len(attempts) == 0 {
 		return utils.SuccessResponse(c, fiber.StatusOK, "No attempt yet", nil)
 	}
 
-	var quizAttemptResponse []dto.QuizAttemptResponse
-	if err := copier.CopyWithOption(&quizAttemptResponse, attempt, copier.Option{IgnoreEmpty: true}); err != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map quiz attempt data", err.Error())
+	quizAttemptResponse := make([]dto.QuizAttemptResponse, len(attempts))
+	for i, a := range attempts {
+		if err := copier.CopyWithOption(&quizAttemptResponse[i], &a.Attempt, copier.Option{IgnoreEmpty: true}); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map quiz attempt data", err.Error())
+		}
+		quizAttemptResponse[i].Integrity = a.Integrity
 	}
 
 	return utils.SuccessResponse(c, fiber.StatusOK, "Success", quizAttemptResponse)
@@ -354,9 +358,256 @@ func (ctrl *QuizController) GetAttemptResult(c *fiber.Ctx) error {
 	}
 
 	var quizResultResponse dto.QuizResultResponse
-	if err := copier.Copy(&quizResultResponse, result); err != nil {
+	if err := copier.Copy(&quizResultResponse, result.Result); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map quiz data", err.Error())
 	}
+	quizResultResponse.Review = result.Review
 
 	return utils.SuccessResponse(c, fiber.StatusOK, "Success", quizResultResponse)
 }
+
+// GetQuizItemAnalysis analisis butir soal
+func (ctrl *QuizController) GetQuizItemAnalysis(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	report, err := ctrl.quizService.GetQuizItemAnalysis(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to analyze quiz", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Quiz analysis fetched", report)
+}
+
+// GenerateItemAnalysisExcel export analisis butir soal
+func (ctrl *QuizController) GenerateItemAnalysisExcel(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	f, filename, err := ctrl.quizService.GenerateItemAnalysisExcel(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate analysis excel", err.Error())
+	}
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to write excel buffer", err.Error())
+	}
+
+	c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.SendStream(buffer)
+}
+
+// RegradeQuiz hitung ulang nilai setelah koreksi kunci jawaban
+func (ctrl *QuizController) RegradeQuiz(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.RegradeQuizRequest)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	regradeLog, err := ctrl.quizService.RegradeQuiz(ctx, user, quizID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to regrade quiz", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Quiz regraded", regradeLog)
+}
+
+// GetRegradeLogs riwayat regrade quiz
+func (ctrl *QuizController) GetRegradeLogs(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	logs, err := ctrl.quizService.GetRegradeLogs(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch regrade logs", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Regrade logs fetched", logs)
+}
+
+// ReleaseQuizReview buka review quiz secara manual
+func (ctrl *QuizController) ReleaseQuizReview(c *fiber.Ctx) error {
+	return ctrl.setReviewReleased(c, true)
+}
+
+// UnreleaseQuizReview tutup kembali review quiz
+func (ctrl *QuizController) UnreleaseQuizReview(c *fiber.Ctx) error {
+	return ctrl.setReviewReleased(c, false)
+}
+
+func (ctrl *QuizController) setReviewReleased(c *fiber.Ctx, released bool) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	quiz, err := ctrl.quizService.SetReviewReleased(ctx, user, quizID, released)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update quiz review", err.Error())
+	}
+
+	var quizResponse dto.QuizResponse
+	if err := copier.Copy(&quizResponse, quiz); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map quiz data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Quiz review updated", quizResponse)
+}
+
+// UpdateQuestion update teks, gambar & pembahasan soal
+func (ctrl *QuizController) UpdateQuestion(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.UpdateQuestionRequest)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+	questionID, err := uuid.Parse(c.Params("questionID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid question ID", err.Error())
+	}
+
+	question, err := ctrl.quizService.UpdateQuestionContent(ctx, user, quizID, questionID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update question", err.Error())
+	}
+
+	var questionResponse dto.QuestionResponse
+	if err := copier.Copy(&questionResponse, question); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map question data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Question updated", questionResponse)
+}
+
+// ReportProctorEvent catat kejadian integritas dari client selama attempt
+func (ctrl *QuizController) ReportProctorEvent(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.ReportProctorEventRequest)
+
+	attemptID, err := uuid.Parse(c.Params("attemptID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid attempt ID", err.Error())
+	}
+
+	client := dto.ClientInfo{IPAddress: c.IP(), UserAgent: c.Get("User-Agent")}
+	if err := ctrl.quizService.ReportProctorEvent(ctx, user, attemptID, body, client); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to record event", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Event recorded", nil)
+}
+
+// GetAttemptTimeline timeline integritas & perubahan jawaban sebuah attempt
+func (ctrl *QuizController) GetAttemptTimeline(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	attemptID, err := uuid.Parse(c.Params("attemptID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid attempt ID", err.Error())
+	}
+
+	timeline, err := ctrl.quizService.GetAttemptTimeline(ctx, user, attemptID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch attempt timeline", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success", timeline)
+}
+
+type attemptActionFunc func(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error)
+
+// controlAttempt handler bersama untuk tindakan guru terhadap attempt
+func (ctrl *QuizController) controlAttempt(c *fiber.Ctx, action attemptActionFunc, successMessage string) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.AttemptActionRequest)
+
+	attemptID, err := uuid.Parse(c.Params("attemptID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid attempt ID", err.Error())
+	}
+
+	attempt, err := action(ctx, user, attemptID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update attempt", err.Error())
+	}
+
+	var attemptResponse dto.QuizAttemptResponse
+	if err := copier.Copy(&attemptResponse, attempt); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map attempt data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, successMessage, attemptResponse)
+}
+
+// ExtendAttempt tambah waktu attempt siswa
+func (ctrl *QuizController) ExtendAttempt(c *fiber.Ctx) error {
+	return ctrl.controlAttempt(c, ctrl.quizService.ExtendAttempt, "Attempt extended")
+}
+
+// PauseAttempt jeda attempt siswa
+func (ctrl *QuizController) PauseAttempt(c *fiber.Ctx) error {
+	return ctrl.controlAttempt(c, ctrl.quizService.PauseAttempt, "Attempt paused")
+}
+
+// ResumeAttempt lanjutkan attempt siswa yang dijeda
+func (ctrl *QuizController) ResumeAttempt(c *fiber.Ctx) error {
+	return ctrl.controlAttempt(c, ctrl.quizService.ResumeAttempt, "Attempt resumed")
+}
+
+// ReopenAttempt buka kembali attempt siswa yang sudah disubmit
+func (ctrl *QuizController) ReopenAttempt(c *fiber.Ctx) error {
+	return ctrl.controlAttempt(c, ctrl.quizService.ReopenAttempt, "Attempt reopened")
+}
+
+// VoidAttempt batalkan attempt siswa
+func (ctrl *QuizController) VoidAttempt(c *fiber.Ctx) error {
+	return ctrl.controlAttempt(c, ctrl.quizService.VoidAttempt, "Attempt voided")
+}
+
+// GetAttemptActions riwayat tindakan guru terhadap attempt
+func (ctrl *QuizController) GetAttemptActions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	attemptID, err := uuid.Parse(c.Params("attemptID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid attempt ID", err.Error())
+	}
+
+	actions, err := ctrl.quizService.GetAttemptActions(ctx, user, attemptID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch attempt actions", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success", actions)
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func toAnswerSheetResponses(sheets []models.QuizAnswerSheet) []dto.QuizAnswerSheetResponse {
+	response := make([]dto.QuizAnswerSheetResponse, 0, len(sheets))
+	for _, sheet := range sheets {
+		response = append(response, dto.QuizAnswerSheetResponse{
+			ID:          sheet.ID,
+			QuizID:      sheet.QuizID,
+			UserID:      sheet.UserID,
+			UserName:    sheet.User.Name,
+			VersionCode: sheet.Version.Code,
+			Serial:      sheet.Serial,
+			CreatedAt:   sheet.CreatedAt,
+		})
+	}
+	return response
+}
+
+func toOmrScanResponse(scan models.QuizOmrScan) dto.QuizOmrScanResponse {
+	response := dto.QuizOmrScanResponse{
+		ID:         scan.ID,
+		QuizID:     scan.QuizID,
+		SheetID:    scan.SheetID,
+		ImageURL:   scan.ImageURL,
+		Status:     scan.Status,
+		Confidence: scan.Confidence,
+		Issues:     scan.Issues,
+		AttemptID:  scan.AttemptID,
+		UploadedBy: scan.UploadedBy,
+		ReviewedBy: scan.ReviewedBy,
+		ReviewedAt: scan.ReviewedAt,
+		CreatedAt:  scan.CreatedAt,
+		Answers:    make([]dto.QuizOmrScanAnswerResponse, 0, len(scan.Answers)),
+	}
+	if scan.Sheet != nil {
+		userID := scan.Sheet.UserID
+		response.UserID = &userID
+		response.UserName = scan.Sheet.User.Name
+		response.VersionCode = scan.Sheet.Version.Code
+	}
+	for _, ans := range scan.Answers {
+		response.Answers = append(response.Answers, dto.QuizOmrScanAnswerResponse{
+			Number:     ans.Number,
+			Label:      ans.Label,
+			Confidence: ans.Confidence,
+			Ambiguous:  ans.Ambiguous,
+		})
+	}
+	return response
+}
+
+func toOmrScanResponses(scans []models.QuizOmrScan) []dto.QuizOmrScanResponse {
+	response := make([]dto.QuizOmrScanResponse, 0, len(scans))
+	for _, scan := range scans {
+		response = append(response, toOmrScanResponse(scan))
+	}
+	return response
+}
+
+// GenerateAnswerSheets buat LJK untuk semua siswa batch
+func (ctrl *QuizPaperController) GenerateAnswerSheets(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	sheets, err := ctrl.paperService.GenerateAnswerSheets(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to generate answer sheets", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Answer sheets generated", toAnswerSheetResponses(sheets))
+}
+
+// DownloadAnswerSheets download PDF LJK semua siswa
+func (ctrl *QuizPaperController) DownloadAnswerSheets(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	buffer, filename, err := ctrl.paperService.GenerateAnswerSheetsPDF(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to generate answer sheets", err.Error())
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.SendStream(buffer)
+}
+
+// UploadOmrScans upload scan LJK (PNG/JPEG/PDF, boleh banyak file di field "files")
+func (ctrl *QuizPaperController) UploadOmrScans(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid multipart form", err.Error())
+	}
+	files := form.File["files"]
+	if len(files) == 0 {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "No scan uploaded", "field files wajib diisi")
+	}
+
+	scans, err := ctrl.paperService.UploadOmrScans(ctx, user, quizID, files)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to process scans", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Scans processed", toOmrScanResponses(scans))
+}
+
+// GetOmrScans daftar scan LJK, ?status=review untuk antrian koreksi manual
+func (ctrl *QuizPaperController) GetOmrScans(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	scans, err := ctrl.paperService.GetOmrScans(ctx, user, quizID, c.Query("status"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to fetch scans", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success", toOmrScanResponses(scans))
+}
+
+// ResolveOmrScan koreksi manual scan di antrian review lalu nilai
+func (ctrl *QuizPaperController) ResolveOmrScan(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.ResolveOmrScanRequest)
+
+	scanID, err := uuid.Parse(c.Params("scanID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid scan ID", err.Error())
+	}
+
+	scan, err := ctrl.paperService.ResolveOmrScan(ctx, user, scanID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to resolve scan", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Scan resolved", toOmrScanResponse(*scan))
+}
+
+// RejectOmrScan buang scan dari antrian review
+func (ctrl *QuizPaperController) RejectOmrScan(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.RejectOmrScanRequest)
+
+	scanID, err := uuid.Parse(c.Params("scanID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid scan ID", err.Error())
+	}
+
+	scan, err := ctrl.paperService.RejectOmrScan(ctx, user, scanID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to reject scan", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Scan rejected", toOmrScanResponse(*scan))
+}
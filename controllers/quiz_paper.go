@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// QuizPaperController handles printable offline exam papers
+type QuizPaperController struct {
+	paperService services.IQuizPaperService
+	db           *gorm.DB
+}
+
+// NewQuizPaperController creates a new instance of QuizPaperController
+func NewQuizPaperController(paperService services.IQuizPaperService, db *gorm.DB) *QuizPaperController {
+	return &QuizPaperController{
+		paperService: paperService,
+		db:           db,
+	}
+}
+
+func toPaperVersionResponses(versions []models.QuizPaperVersion) ([]dto.QuizPaperVersionResponse, error) {
+	response := make([]dto.QuizPaperVersionResponse, 0, len(versions))
+	if err := copier.Copy(&response, &versions); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GeneratePaperVersions generate versi naskah cetak
+func (ctrl *QuizPaperController) GeneratePaperVersions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.GeneratePaperVersionsRequest)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	versions, err := ctrl.paperService.GeneratePaperVersions(ctx, user, quizID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to generate paper versions", err.Error())
+	}
+
+	response, err := toPaperVersionResponses(versions)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map paper versions", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Paper versions generated", response)
+}
+
+// GetPaperVersions list versi naskah cetak
+func (ctrl *QuizPaperController) GetPaperVersions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	versions, err := ctrl.paperService.GetPaperVersions(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch paper versions", err.Error())
+	}
+
+	response, err := toPaperVersionResponses(versions)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map paper versions", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Success", response)
+}
+
+// DownloadPaperBooklet download naskah soal PDF, ?version= untuk satu versi saja
+func (ctrl *QuizPaperController) DownloadPaperBooklet(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	buffer, filename, err := ctrl.paperService.GeneratePaperBookletPDF(ctx, user, quizID, c.Query("version"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to generate paper booklet", err.Error())
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.SendStream(buffer)
+}
+
+// DownloadPaperAnswerKey download kunci jawaban PDF semua versi
+func (ctrl *QuizPaperController) DownloadPaperAnswerKey(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	buffer, filename, err := ctrl.paperService.GeneratePaperAnswerKeyPDF(ctx, user, quizID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to generate answer key", err.Error())
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.SendStream(buffer)
+}
+
+// SubmitPaperAnswers input & nilai lembar jawaban naskah cetak
+func (ctrl *QuizPaperController) SubmitPaperAnswers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.SubmitPaperAnswersRequest)
+
+	quizID, err := uuid.Parse(c.Params("quizID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid quiz ID", err.Error())
+	}
+
+	result, err := ctrl.paperService.GradePaperAnswers(ctx, user, quizID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to grade paper answers", err.Error())
+	}
+
+	var response dto.QuizResultResponse
+	if err := copier.Copy(&response, result); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map quiz result", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Paper answers graded", response)
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// RubricController handles assignment grading rubrics
+type RubricController struct {
+	rubricService services.IRubricService
+	db            *gorm.DB
+}
+
+// NewRubricController creates a new instance of RubricController
+func NewRubricController(rubricService services.IRubricService, db *gorm.DB) *RubricController {
+	return &RubricController{
+		rubricService: rubricService,
+		db:            db,
+	}
+}
+
+func toRubricResponse(rubric *models.Rubric) (dto.RubricResponse, error) {
+	var response dto.RubricResponse
+	if err := copier.Copy(&response, rubric); err != nil {
+		return response, err
+	}
+	response.MaxPoints = services.RubricMaxPoints(rubric)
+	return response, nil
+}
+
+// GetRubrics list rubrik milik guru (admin: semua)
+func (ctrl *RubricController) GetRubrics(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	rubrics, err := ctrl.rubricService.GetRubrics(ctx, user)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch rubrics", err.Error())
+	}
+
+	response := make([]dto.RubricResponse, 0, len(rubrics))
+	for i := range rubrics {
+		item, err := toRubricResponse(&rubrics[i])
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map rubric data", err.Error())
+		}
+		response = append(response, item)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Rubrics fetched", response)
+}
+
+// GetRubricByID detail rubrik
+func (ctrl *RubricController) GetRubricByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	rubricID, err := uuid.Parse(c.Params("rubricID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid rubric ID", err.Error())
+	}
+
+	rubric, err := ctrl.rubricService.GetRubricByID(ctx, user, rubricID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Rubric not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Failed to fetch rubric", err.Error())
+	}
+
+	response, err := toRubricResponse(rubric)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map rubric data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Rubric fetched", response)
+}
+
+// CreateRubric buat rubrik baru
+func (ctrl *RubricController) CreateRubric(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.SaveRubricRequest)
+
+	rubric, err := ctrl.rubricService.CreateRubric(ctx, user, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to create rubric", err.Error())
+	}
+
+	response, err := toRubricResponse(rubric)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map rubric data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Rubric created", response)
+}
+
+// UpdateRubric ganti isi rubrik
+func (ctrl *RubricController) UpdateRubric(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.SaveRubricRequest)
+
+	rubricID, err := uuid.Parse(c.Params("rubricID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid rubric ID", err.Error())
+	}
+
+	rubric, err := ctrl.rubricService.UpdateRubric(ctx, user, rubricID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update rubric", err.Error())
+	}
+
+	response, err := toRubricResponse(rubric)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map rubric data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Rubric updated", response)
+}
+
+// DeleteRubric hapus rubrik
+func (ctrl *RubricController) DeleteRubric(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	rubricID, err := uuid.Parse(c.Params("rubricID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid rubric ID", err.Error())
+	}
+
+	if err := ctrl.rubricService.DeleteRubric(ctx, user, rubricID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to delete rubric", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Rubric deleted", nil)
+}
+
+// AttachRubric pasang / lepas rubrik assignment
+func (ctrl *RubricController) AttachRubric(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.AttachRubricRequest)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	assignment, err := ctrl.rubricService.AttachRubric(ctx, user, assignmentID, body)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to attach rubric", err.Error())
+	}
+
+	var response dto.AssignmentResponse
+	if err := copier.Copy(&response, assignment); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map assignment data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Assignment rubric updated", response)
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// SimilarityController handles submission similarity reports
+type SimilarityController struct {
+	similarityService services.ISimilarityService
+	db                *gorm.DB
+}
+
+// NewSimilarityController creates a new instance of SimilarityController
+func NewSimilarityController(similarityService services.ISimilarityService, db *gorm.DB) *SimilarityController {
+	return &SimilarityController{
+		similarityService: similarityService,
+		db:                db,
+	}
+}
+
+func toSimilarityReportResponse(report *models.SimilarityReport) (dto.SimilarityReportResponse, error) {
+	var response dto.SimilarityReportResponse
+	if err := copier.Copy(&response, report); err != nil {
+		return response, err
+	}
+	response.Status = string(report.Status)
+	return response, nil
+}
+
+func toSimilarityDocumentResponse(doc *models.SimilarityDocument) dto.SimilarityDocumentResponse {
+	return dto.SimilarityDocumentResponse{
+		ID:           doc.ID,
+		SubmissionID: doc.SubmissionID,
+		UserID:       doc.UserID,
+		UserName:     doc.User.Name,
+		AssignmentID: doc.AssignmentID,
+		Previous:     doc.Previous,
+		WordCount:    doc.WordCount,
+		Skipped:      doc.Skipped,
+	}
+}
+
+// toSimilarityReportDetail petakan laporan lengkap, passage diberi potongan teks dari kedua dokumen
+func toSimilarityReportDetail(report *models.SimilarityReport) (dto.SimilarityReportDetailResponse, error) {
+	var response dto.SimilarityReportDetailResponse
+	summary, err := toSimilarityReportResponse(report)
+	if err != nil {
+		return response, err
+	}
+	response.SimilarityReportResponse = summary
+
+	texts := make(map[uuid.UUID]*services.SimilarityText, len(report.Documents))
+	response.Documents = make([]dto.SimilarityDocumentResponse, 0, len(report.Documents))
+	for i := range report.Documents {
+		doc := &report.Documents[i]
+		texts[doc.ID] = &services.SimilarityText{Text: doc.Text}
+		response.Documents = append(response.Documents, toSimilarityDocumentResponse(doc))
+	}
+
+	response.Pairs = make([]dto.SimilarityPairResponse, 0, len(report.Pairs))
+	for _, pair := range report.Pairs {
+		item := dto.SimilarityPairResponse{
+			ID:          pair.ID,
+			DocumentAID: pair.DocumentAID,
+			DocumentBID: pair.DocumentBID,
+			Score:       pair.Score,
+			ScoreA:      pair.ScoreA,
+			ScoreB:      pair.ScoreB,
+			Passages:    make([]dto.SimilarityPassageResponse, 0, len(pair.Passages)),
+		}
+		textA, textB := texts[pair.DocumentAID], texts[pair.DocumentBID]
+		for _, p := range pair.Passages {
+			passage := dto.SimilarityPassageResponse{AStart: p.AStart, AEnd: p.AEnd, BStart: p.BStart, BEnd: p.BEnd}
+			if textA != nil {
+				passage.AText = textA.Excerpt(p.AStart, p.AEnd)
+			}
+			if textB != nil {
+				passage.BText = textB.Excerpt(p.BStart, p.BEnd)
+			}
+			item.Passages = append(item.Passages, passage)
+		}
+		response.Pairs = append(response.Pairs, item)
+	}
+	return response, nil
+}
+
+// RunSimilarityCheck jalankan cek kemiripan submission assignment (diproses di background)
+func (ctrl *SimilarityController) RunSimilarityCheck(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.RunSimilarityRequest)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	report, err := ctrl.similarityService.RunSimilarityCheck(ctx, user, assignmentID, body)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Assignment not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to start similarity check", err.Error())
+	}
+
+	response, err := toSimilarityReportResponse(report)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map similarity report", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusAccepted, "Similarity check started", response)
+}
+
+// GetReportsByAssignmentID riwayat laporan kemiripan assignment
+func (ctrl *SimilarityController) GetReportsByAssignmentID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	reports, err := ctrl.similarityService.GetReportsByAssignmentID(ctx, user, assignmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Assignment not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Failed to fetch similarity reports", err.Error())
+	}
+
+	response := make([]dto.SimilarityReportResponse, 0, len(reports))
+	for i := range reports {
+		item, err := toSimilarityReportResponse(&reports[i])
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map similarity report", err.Error())
+		}
+		response = append(response, item)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Similarity reports fetched", response)
+}
+
+// GetReportByID detail laporan: pasangan urut skor tertinggi beserta passage yang sama
+func (ctrl *SimilarityController) GetReportByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	reportID, err := uuid.Parse(c.Params("reportID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid report ID", err.Error())
+	}
+
+	report, err := ctrl.similarityService.GetReportByID(ctx, user, reportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Similarity report not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Failed to fetch similarity report", err.Error())
+	}
+
+	response, err := toSimilarityReportDetail(report)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map similarity report", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Similarity report fetched", response)
+}
+
+// GetReportDocument teks lengkap satu dokumen laporan, untuk highlight passage
+func (ctrl *SimilarityController) GetReportDocument(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	reportID, err := uuid.Parse(c.Params("reportID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid report ID", err.Error())
+	}
+	documentID, err := uuid.Parse(c.Params("documentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid document ID", err.Error())
+	}
+
+	report, err := ctrl.similarityService.GetReportByID(ctx, user, reportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Similarity report not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Failed to fetch similarity report", err.Error())
+	}
+
+	for i := range report.Documents {
+		if doc := &report.Documents[i]; doc.ID == documentID {
+			return utils.SuccessResponse(c, fiber.StatusOK, "Similarity document fetched", dto.SimilarityDocumentTextResponse{
+				SimilarityDocumentResponse: toSimilarityDocumentResponse(doc),
+				Text:                       doc.Text,
+			})
+		}
+	}
+	return utils.ErrorResponse(c, fiber.StatusNotFound, "Similarity document not found", nil)
+}
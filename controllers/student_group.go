@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StudentGroupController handles batch student groups
+type StudentGroupController struct {
+	groupService services.IStudentGroupService
+	db           *gorm.DB
+}
+
+// NewStudentGroupController creates a new instance of StudentGroupController
+func NewStudentGroupController(groupService services.IStudentGroupService, db *gorm.DB) *StudentGroupController {
+	return &StudentGroupController{
+		groupService: groupService,
+		db:           db,
+	}
+}
+
+func toStudentGroupResponse(group *models.StudentGroup) dto.StudentGroupResponse {
+	members := make([]dto.StudentGroupMemberResponse, 0, len(group.Members))
+	for _, m := range group.Members {
+		members = append(members, dto.StudentGroupMemberResponse{
+			UserID: m.UserID,
+			Name:   m.User.Name,
+			Email:  m.User.Email,
+		})
+	}
+	return dto.StudentGroupResponse{
+		ID:        group.ID,
+		BatchID:   group.BatchID,
+		Name:      group.Name,
+		Members:   members,
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
+
+func toStudentGroupResponses(groups []models.StudentGroup) []dto.StudentGroupResponse {
+	response := make([]dto.StudentGroupResponse, 0, len(groups))
+	for i := range groups {
+		response = append(response, toStudentGroupResponse(&groups[i]))
+	}
+	return response
+}
+
+func studentGroupError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Group not found", err.Error())
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, message, err.Error())
+}
+
+// GetGroups daftar kelompok batch
+func (ctrl *StudentGroupController) GetGroups(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	batchID, err := uuid.Parse(c.Params("batchID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid batch ID", err.Error())
+	}
+
+	groups, err := ctrl.groupService.GetGroups(ctx, user, batchID)
+	if err != nil {
+		return studentGroupError(c, err, "Failed to fetch groups")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Groups fetched", toStudentGroupResponses(groups))
+}
+
+// GetMyGroup kelompok siswa di batch
+func (ctrl *StudentGroupController) GetMyGroup(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	batchID, err := uuid.Parse(c.Params("batchID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid batch ID", err.Error())
+	}
+
+	group, err := ctrl.groupService.GetMyGroup(ctx, user, batchID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SuccessResponse(c, fiber.StatusOK, "You are not in any group", nil)
+		}
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch group", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Group fetched", toStudentGroupResponse(group))
+}
+
+// CreateGroup buat kelompok manual
+func (ctrl *StudentGroupController) CreateGroup(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.CreateStudentGroupRequest)
+
+	batchID, err := uuid.Parse(c.Params("batchID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid batch ID", err.Error())
+	}
+
+	group, err := ctrl.groupService.CreateGroup(ctx, user, batchID, body)
+	if err != nil {
+		return studentGroupError(c, err, "Failed to create group")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Group created", toStudentGroupResponse(group))
+}
+
+// GenerateGroups bagi otomatis siswa yang belum punya kelompok
+func (ctrl *StudentGroupController) GenerateGroups(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.GenerateStudentGroupsRequest)
+
+	batchID, err := uuid.Parse(c.Params("batchID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid batch ID", err.Error())
+	}
+
+	groups, err := ctrl.groupService.GenerateGroups(ctx, user, batchID, body)
+	if err != nil {
+		return studentGroupError(c, err, "Failed to generate groups")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, "Groups generated", toStudentGroupResponses(groups))
+}
+
+// UpdateGroup ubah nama / anggota kelompok
+func (ctrl *StudentGroupController) UpdateGroup(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.UpdateStudentGroupRequest)
+
+	groupID, err := uuid.Parse(c.Params("groupID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid group ID", err.Error())
+	}
+
+	group, err := ctrl.groupService.UpdateGroup(ctx, user, groupID, body)
+	if err != nil {
+		return studentGroupError(c, err, "Failed to update group")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Group updated", toStudentGroupResponse(group))
+}
+
+// DeleteGroup hapus kelompok
+func (ctrl *StudentGroupController) DeleteGroup(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	groupID, err := uuid.Parse(c.Params("groupID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid group ID", err.Error())
+	}
+
+	if err := ctrl.groupService.DeleteGroup(ctx, user, groupID); err != nil {
+		return studentGroupError(c, err, "Failed to delete group")
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Group deleted", nil)
+}
@@ -2,10 +2,14 @@ package controllers
 
 import (
 	"brevet-api/dto"
+	"brevet-api/models"
 	"brevet-api/services"
 	"brevet-api/utils"
+	"bufio"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -175,6 +179,10 @@ func (ctrl *SubmissionController) GetSubmissionGrade(c *fiber.Ctx) error {
 	}); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map submission grade data", err.Error())
 	}
+	if user.Role == string(models.RoleTypeSiswa) {
+		memberGrade := submission.MemberGrade(user.UserID)
+		gradeResponse.MemberGrade = &memberGrade
+	}
 
 	return utils.SuccessResponse(c, fiber.StatusOK, "Submission grade fetched successfully", gradeResponse)
 }
@@ -209,8 +217,86 @@ func (ctrl *SubmissionController) GradeSubmission(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, fiber.StatusOK, "Submission graded successfully", gradeResponse)
 }
 
-// GenerateGradesExcel controller
+// exportGrades unduh spreadsheet nilai dalam cakupan, ?format=xlsx (default) atau csv
+func (ctrl *SubmissionController) exportGrades(c *fiber.Ctx, kind, param string) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	id, err := uuid.Parse(c.Params(param))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid "+kind+" ID", err.Error())
+	}
+
+	format := strings.ToLower(c.Query("format", "xlsx"))
+	buffer, filename, err := ctrl.submissionService.ExportGrades(ctx, user, services.GradeSheetScope{Kind: kind, ID: id}, format)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to export grades", err.Error())
+	}
+
+	contentType := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.SendStream(buffer)
+}
+
+// importGrades import spreadsheet nilai (xlsx/csv) dalam cakupan
+func (ctrl *SubmissionController) importGrades(c *fiber.Ctx, kind, param string) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	id, err := uuid.Parse(c.Params(param))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid "+kind+" ID", err.Error())
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Missing grade file", err.Error())
+	}
+
+	result, err := ctrl.submissionService.ImportGrades(ctx, user, services.GradeSheetScope{Kind: kind, ID: id}, fileHeader)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to import grades", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Grades imported successfully", result)
+}
+
+// GenerateGradesExcel unduh nilai satu assignment
 func (ctrl *SubmissionController) GenerateGradesExcel(c *fiber.Ctx) error {
+	return ctrl.exportGrades(c, services.GradeScopeAssignment, "assignmentID")
+}
+
+// ImportGradesFromExcel import nilai satu assignment
+func (ctrl *SubmissionController) ImportGradesFromExcel(c *fiber.Ctx) error {
+	return ctrl.importGrades(c, services.GradeScopeAssignment, "assignmentID")
+}
+
+// ExportMeetingGrades unduh nilai semua assignment meeting
+func (ctrl *SubmissionController) ExportMeetingGrades(c *fiber.Ctx) error {
+	return ctrl.exportGrades(c, services.GradeScopeMeeting, "meetingID")
+}
+
+// ImportMeetingGrades import nilai beberapa assignment meeting sekaligus
+func (ctrl *SubmissionController) ImportMeetingGrades(c *fiber.Ctx) error {
+	return ctrl.importGrades(c, services.GradeScopeMeeting, "meetingID")
+}
+
+// ExportBatchGrades unduh nilai semua assignment batch
+func (ctrl *SubmissionController) ExportBatchGrades(c *fiber.Ctx) error {
+	return ctrl.exportGrades(c, services.GradeScopeBatch, "batchID")
+}
+
+// ImportBatchGrades import nilai beberapa assignment batch sekaligus
+func (ctrl *SubmissionController) ImportBatchGrades(c *fiber.Ctx) error {
+	return ctrl.importGrades(c, services.GradeScopeBatch, "batchID")
+}
+
+// DownloadSubmissionArchive ZIP semua submission assignment, dikirim secara streaming
+func (ctrl *SubmissionController) DownloadSubmissionArchive(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 	user := c.Locals("user").(*utils.Claims)
 
@@ -219,25 +305,125 @@ func (ctrl *SubmissionController) GenerateGradesExcel(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
 	}
 
-	f, filename, err := ctrl.submissionService.GenerateGradesExcel(ctx, user, assignmentID)
+	archive, err := ctrl.submissionService.GetSubmissionArchive(ctx, user, assignmentID)
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate grades excel", err.Error())
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Failed to prepare submission archive", err.Error())
 	}
 
-	// Simpan sementara di memory
-	buffer, err := f.WriteToBuffer()
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archive.Filename))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := services.WriteSubmissionArchive(w, archive); err != nil {
+			log.Println("Failed to stream submission archive:", assignmentID, err)
+		}
+	})
+	return nil
+}
+
+// GetSubmissionVersions riwayat versi submission
+func (ctrl *SubmissionController) GetSubmissionVersions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	submissionID, err := uuid.Parse(c.Params("submissionID"))
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to write excel buffer", err.Error())
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid submission ID", err.Error())
 	}
 
-	// Kirim sebagai file download
-	c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	return c.SendStream(buffer)
+	versions, err := ctrl.submissionService.GetSubmissionVersions(ctx, user, submissionID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Submission not found", err.Error())
+	}
+
+	var response []dto.SubmissionVersionResponse
+	if err := copier.Copy(&response, &versions); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map submission versions", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Submission versions fetched", response)
 }
 
-// ImportGradesFromExcel excel
-func (ctrl *SubmissionController) ImportGradesFromExcel(c *fiber.Ctx) error {
+// DiffSubmissionVersions perbedaan essay antar versi (?from=&to=)
+func (ctrl *SubmissionController) DiffSubmissionVersions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	submissionID, err := uuid.Parse(c.Params("submissionID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid submission ID", err.Error())
+	}
+	from, to := c.QueryInt("from", 0), c.QueryInt("to", 0)
+	if from < 1 || to < 1 {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Query from & to wajib diisi", nil)
+	}
+
+	diff, err := ctrl.submissionService.DiffSubmissionVersions(ctx, user, submissionID, from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Failed to diff submission versions", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Submission version diff fetched", diff)
+}
+
+// ReturnForRevision kembalikan submission ke siswa untuk direvisi
+func (ctrl *SubmissionController) ReturnForRevision(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.ReturnSubmissionRequest)
+
+	submissionID, err := uuid.Parse(c.Params("submissionID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid submission ID", err.Error())
+	}
+
+	submission, err := ctrl.submissionService.ReturnForRevision(ctx, user, submissionID, body)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Submission not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to return submission", err.Error())
+	}
+
+	var submissionResponse dto.SubmissionResponse
+	if err := copier.Copy(&submissionResponse, submission); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map submission data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Submission returned for revision", submissionResponse)
+}
+
+// ModerateGrade penilai kedua setuju / menyesuaikan nilai ujian
+func (ctrl *SubmissionController) ModerateGrade(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+	body := c.Locals("body").(*dto.ModerateGradeRequest)
+
+	submissionID, err := uuid.Parse(c.Params("submissionID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid submission ID", err.Error())
+	}
+
+	grade, err := ctrl.submissionService.ModerateSubmissionGrade(ctx, user, submissionID, body)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "Submission not found", err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to moderate grade", err.Error())
+	}
+
+	var gradeResponse dto.SubmissionGradeResponse
+	if err := copier.CopyWithOption(&gradeResponse, grade, copier.Option{
+		IgnoreEmpty: true,
+		DeepCopy:    true,
+	}); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to map submission grade data", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Grade moderated successfully", gradeResponse)
+}
+
+// SubmitGradesForModeration kirim nilai draft ujian ke penilai kedua
+func (ctrl *SubmissionController) SubmitGradesForModeration(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 	user := c.Locals("user").(*utils.Claims)
 
@@ -246,14 +432,45 @@ func (ctrl *SubmissionController) ImportGradesFromExcel(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
 	}
 
-	fileHeader, err := c.FormFile("file")
+	updated, err := ctrl.submissionService.SubmitGradesForModeration(ctx, user, assignmentID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to submit grades for moderation", err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, "Grades submitted for moderation", dto.ModerationResultResponse{Updated: updated})
+}
+
+// ReleaseGrades rilis nilai ujian yang sudah dimoderasi ke siswa
+func (ctrl *SubmissionController) ReleaseGrades(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := c.Locals("user").(*utils.Claims)
+
+	assignmentID, err := uuid.Parse(c.Params("assignmentID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid assignment ID", err.Error())
+	}
+
+	updated, err := ctrl.submissionService.ReleaseGrades(ctx, user, assignmentID)
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Missing excel file", err.Error())
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to release grades", err.Error())
 	}
 
-	if err := ctrl.submissionService.ImportGradesFromExcel(ctx, user, assignmentID, fileHeader); err != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to import grades from excel", err.Error())
+	return utils.SuccessResponse(c, fiber.StatusOK, "Grades released", dto.ModerationResultResponse{Updated: updated})
+}
+
+// GetModerationProgress progres moderasi nilai ujian per batch (admin)
+func (ctrl *SubmissionController) GetModerationProgress(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	batchID, err := uuid.Parse(c.Params("batchID"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid batch ID", err.Error())
+	}
+
+	progress, err := ctrl.submissionService.GetModerationProgress(ctx, batchID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get moderation progress", err.Error())
 	}
 
-	return utils.SuccessResponse(c, fiber.StatusOK, "Grades imported successfully", nil)
+	return utils.SuccessResponse(c, fiber.StatusOK, "Moderation progress fetched", progress)
 }
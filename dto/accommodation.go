@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpsertQuizAccommodationRequest request
+type UpsertQuizAccommodationRequest struct {
+	UserID        uuid.UUID  `json:"user_id" validate:"required"`
+	ExtraMinutes  int        `json:"extra_minutes" validate:"min=0"`
+	StartTime     *time.Time `json:"start_time" validate:"omitempty"`
+	EndTime       *time.Time `json:"end_time" validate:"omitempty"`
+	ExtraAttempts int        `json:"extra_attempts" validate:"min=0"`
+	Reason        *string    `json:"reason" validate:"omitempty"`
+}
+
+// QuizAccommodationResponse response
+type QuizAccommodationResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	QuizID        uuid.UUID  `json:"quiz_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	UserName      string     `json:"user_name"`
+	ExtraMinutes  int        `json:"extra_minutes"`
+	StartTime     *time.Time `json:"start_time"`
+	EndTime       *time.Time `json:"end_time"`
+	ExtraAttempts int        `json:"extra_attempts"`
+	Reason        *string    `json:"reason"`
+	CreatedBy     uuid.UUID  `json:"created_by"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpsertAssignmentExtensionRequest request
+type UpsertAssignmentExtensionRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	EndAt  time.Time `json:"end_at" validate:"required"`
+	Reason *string   `json:"reason" validate:"omitempty"`
+}
+
+// AssignmentExtensionResponse response
+type AssignmentExtensionResponse struct {
+	ID           uuid.UUID `json:"id"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	EndAt        time.Time `json:"end_at"`
+	Reason       *string   `json:"reason"`
+	CreatedBy    uuid.UUID `json:"created_by"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
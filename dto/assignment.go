@@ -17,8 +17,30 @@ type AssignmentResponse struct {
 	Type        models.AssignmentType `json:"type"`
 	StartAt     time.Time             `json:"start_at"`
 	EndAt       time.Time             `json:"end_at"`
-	CreatedAt   time.Time             `json:"created_at"`
-	UpdatedAt   time.Time             `json:"updated_at"`
+	RubricID    *uuid.UUID            `json:"rubric_id"`
+
+	HardDeadline       bool                   `json:"hard_deadline"`
+	LateGraceMinutes   int                    `json:"late_grace_minutes"`
+	LatePenaltyPercent float64                `json:"late_penalty_percent"`
+	LatePenaltyUnit    models.LatePenaltyUnit `json:"late_penalty_unit"`
+	LatePenaltyFloor   int                    `json:"late_penalty_floor"`
+	LateCutoffAt       *time.Time             `json:"late_cutoff_at"`
+
+	PeerReviewEnabled       bool       `json:"peer_review_enabled"`
+	PeerReviewCount         int        `json:"peer_review_count"`
+	PeerReviewEndAt         *time.Time `json:"peer_review_end_at"`
+	PeerReviewWeight        float64    `json:"peer_review_weight"`
+	PeerReviewDistributedAt *time.Time `json:"peer_review_distributed_at"`
+
+	AllowedFileTypes []string `json:"allowed_file_types"`
+	MaxFileSizeMB    int      `json:"max_file_size_mb"`
+	MinFiles         int      `json:"min_files"`
+	MaxFiles         int      `json:"max_files"`
+
+	GroupSubmission bool `json:"group_submission"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	// Meeting         models.Meeting           `json:"meeting"`
 	// Teacher         *models.User             `json:"teacher"`
@@ -46,6 +68,25 @@ type CreateAssignmentRequest struct {
 	StartAt         time.Time             `json:"start_at" validate:"required"`
 	EndAt           time.Time             `json:"end_at" validate:"required"`
 	AssignmentFiles []string              `json:"assignment_files" validate:"omitempty,min=1,dive,required"`
+
+	HardDeadline       bool                   `json:"hard_deadline"`
+	LateGraceMinutes   int                    `json:"late_grace_minutes" validate:"min=0"`
+	LatePenaltyPercent float64                `json:"late_penalty_percent" validate:"min=0,max=100"`
+	LatePenaltyUnit    models.LatePenaltyUnit `json:"late_penalty_unit" validate:"omitempty,late_penalty_unit"`
+	LatePenaltyFloor   int                    `json:"late_penalty_floor" validate:"min=0,max=100"`
+	LateCutoffAt       *time.Time             `json:"late_cutoff_at" validate:"omitempty"`
+
+	PeerReviewEnabled bool       `json:"peer_review_enabled"`
+	PeerReviewCount   int        `json:"peer_review_count" validate:"min=0,max=10"`
+	PeerReviewEndAt   *time.Time `json:"peer_review_end_at" validate:"omitempty"`
+	PeerReviewWeight  float64    `json:"peer_review_weight" validate:"min=0,max=100"`
+
+	GroupSubmission bool `json:"group_submission"` // dikerjakan per kelompok batch
+
+	AllowedFileTypes []string `json:"allowed_file_types" validate:"omitempty,dive,file_extension"` // kosong = semua ekstensi dokumen & gambar
+	MaxFileSizeMB    int      `json:"max_file_size_mb" validate:"min=0,max=100"`                   // 0 = tanpa batas khusus
+	MinFiles         int      `json:"min_files" validate:"min=0,max=20"`
+	MaxFiles         int      `json:"max_files" validate:"min=0,max=20"` // 0 = tanpa batas
 }
 
 // UpdateAssignmentRequest represents the request structure for updating an assignment
@@ -56,4 +97,25 @@ type UpdateAssignmentRequest struct {
 	StartAt         *time.Time             `json:"start_at" validate:"omitempty"`
 	EndAt           *time.Time             `json:"end_at" validate:"omitempty"`
 	AssignmentFiles []string               `json:"assignment_files" validate:"omitempty,dive,required"`
+
+	HardDeadline       *bool                   `json:"hard_deadline" validate:"omitempty"`
+	LateGraceMinutes   *int                    `json:"late_grace_minutes" validate:"omitempty,min=0"`
+	LatePenaltyPercent *float64                `json:"late_penalty_percent" validate:"omitempty,min=0,max=100"`
+	LatePenaltyUnit    *models.LatePenaltyUnit `json:"late_penalty_unit" validate:"omitempty,late_penalty_unit"`
+	LatePenaltyFloor   *int                    `json:"late_penalty_floor" validate:"omitempty,min=0,max=100"`
+	LateCutoffAt       *time.Time              `json:"late_cutoff_at" validate:"omitempty"`
+	ClearLateCutoff    bool                    `json:"clear_late_cutoff"` // hapus batas akhir telat
+
+	PeerReviewEnabled    *bool      `json:"peer_review_enabled" validate:"omitempty"`
+	PeerReviewCount      *int       `json:"peer_review_count" validate:"omitempty,min=0,max=10"`
+	PeerReviewEndAt      *time.Time `json:"peer_review_end_at" validate:"omitempty"`
+	PeerReviewWeight     *float64   `json:"peer_review_weight" validate:"omitempty,min=0,max=100"`
+	ClearPeerReviewEndAt bool       `json:"clear_peer_review_end_at"` // review tanpa batas waktu
+
+	GroupSubmission *bool `json:"group_submission" validate:"omitempty"` // tidak bisa diubah setelah ada submission
+
+	AllowedFileTypes *[]string `json:"allowed_file_types" validate:"omitempty,dive,file_extension"` // [] = hapus batasan
+	MaxFileSizeMB    *int      `json:"max_file_size_mb" validate:"omitempty,min=0,max=100"`
+	MinFiles         *int      `json:"min_files" validate:"omitempty,min=0,max=20"`
+	MaxFiles         *int      `json:"max_files" validate:"omitempty,min=0,max=20"`
 }
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"brevet-api/models"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,15 +9,38 @@ import (
 
 // CertificateResponse response
 type CertificateResponse struct {
-	ID        uuid.UUID `json:"id"`
-	BatchID   uuid.UUID `json:"batch_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	IssuedAt  time.Time `json:"issued_at"`
-	URL       string    `json:"url"`
-	QRCode    string    `json:"qr_code"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID                `json:"id"`
+	BatchID          uuid.UUID                `json:"batch_id"`
+	UserID           uuid.UUID                `json:"user_id"`
+	Number           string                   `json:"number"`
+	Status           models.CertificateStatus `json:"status"`
+	IssuedAt         time.Time                `json:"issued_at"`
+	URL              string                   `json:"url"`
+	QRCode           string                   `json:"qr_code"`
+	RevokedAt        *time.Time               `json:"revoked_at"`
+	RevocationReason *string                  `json:"revocation_reason"`
+	SupersededByID   *uuid.UUID               `json:"superseded_by_id"`
+	ReissueReason    *string                  `json:"reissue_reason"`
+	CreatedAt        time.Time                `json:"created_at"`
+	UpdatedAt        time.Time                `json:"updated_at"`
 
 	Batch *BatchResponse `json:"batch,omitempty"`
 	User  *UserResponse  `json:"user,omitempty"`
 }
+
+// CertificateActionRequest request cabut / terbit ulang sertifikat oleh admin
+type CertificateActionRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// CertificateVerificationResponse hasil verifikasi publik sertifikat
+type CertificateVerificationResponse struct {
+	Valid              bool                     `json:"valid"`
+	Status             models.CertificateStatus `json:"status"`
+	Message            string                   `json:"message"`
+	SupersededByNumber *string                  `json:"superseded_by_number,omitempty"`
+	RevokedAt          *time.Time               `json:"revoked_at,omitempty"`
+	RevocationReason   *string                  `json:"revocation_reason,omitempty"`
+
+	Certificate CertificateResponse `json:"certificate"`
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"brevet-api/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizLiveEvent event live quiz yang disebar ke semua instance lewat Redis pub/sub
+type QuizLiveEvent struct {
+	Type          string     `json:"type"` // started, answer_saved, submitted, connected, disconnected, deadline_changed, paused, resumed
+	QuizID        uuid.UUID  `json:"quiz_id"`
+	AttemptID     uuid.UUID  `json:"attempt_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	AnsweredCount *int       `json:"answered_count,omitempty"`
+	Reason        string     `json:"reason,omitempty"` // manual / auto / void untuk event submitted, reopen untuk started
+	Deadline      *time.Time `json:"deadline,omitempty"`
+	At            time.Time  `json:"at"`
+}
+
+// QuizLiveSession info attempt yang sedang dibuka lewat WebSocket
+type QuizLiveSession struct {
+	AttemptID uuid.UUID
+	QuizID    uuid.UUID
+	UserID    uuid.UUID
+	Deadline  time.Time
+	PausedAt  *time.Time // attempt sedang dijeda guru
+}
+
+// QuizLiveClientMessage pesan dari client ke server
+type QuizLiveClientMessage struct {
+	Type      string `json:"type"` // save_answer, event, ping
+	RequestID string `json:"request_id"`
+
+	QuestionID       uuid.UUID `json:"question_id"`
+	SelectedOptionID uuid.UUID `json:"selected_option_id"`
+
+	EventType  models.ProctorEventType `json:"event_type"`
+	Detail     *string                 `json:"detail"`
+	OccurredAt *time.Time              `json:"occurred_at"`
+}
+
+// QuizLiveTimeMessage sinkronisasi waktu dari server
+type QuizLiveTimeMessage struct {
+	Type             string    `json:"type"` // time
+	ServerTime       time.Time `json:"server_time"`
+	Deadline         time.Time `json:"deadline"`
+	RemainingSeconds int64     `json:"remaining_seconds"`
+	Paused           bool      `json:"paused"`
+}
+
+// QuizLiveAckMessage balasan untuk pesan client yang punya request_id
+type QuizLiveAckMessage struct {
+	Type      string `json:"type"` // ack
+	RequestID string `json:"request_id"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+// QuizLiveMonitorMessage snapshot monitor untuk guru
+type QuizLiveMonitorMessage struct {
+	Type string                   `json:"type"` // snapshot
+	Data *QuizLiveMonitorResponse `json:"data"`
+}
+
+// QuizLiveMonitorResponse daftar siswa yang sedang mengerjakan quiz
+type QuizLiveMonitorResponse struct {
+	QuizID         uuid.UUID             `json:"quiz_id"`
+	TotalQuestions int64                 `json:"total_questions"`
+	ServerTime     time.Time             `json:"server_time"`
+	Participants   []QuizLiveParticipant `json:"participants"`
+}
+
+// QuizLiveParticipant progres satu attempt aktif
+type QuizLiveParticipant struct {
+	AttemptID     uuid.UUID  `json:"attempt_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Name          string     `json:"name"`
+	StartedAt     time.Time  `json:"started_at"`
+	Deadline      time.Time  `json:"deadline"`
+	AnsweredCount int        `json:"answered_count"`
+	PausedAt      *time.Time `json:"paused_at"`
+	Connected     bool       `json:"connected"`
+	LastSeenAt    *time.Time `json:"last_seen_at"`
+}
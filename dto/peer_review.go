@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubmitPeerReviewRequest isi review. Kalau assignment memakai rubrik, Score diabaikan dan dihitung dari Criteria.
+type SubmitPeerReviewRequest struct {
+	Score    *int                     `json:"score" validate:"omitempty,min=0,max=100"`
+	Comment  *string                  `json:"comment" validate:"omitempty,max=5000"`
+	Criteria []RubricSelectionRequest `json:"criteria" validate:"omitempty,dive"`
+}
+
+// SetPeerReviewExcludedRequest guru keluarkan review dari nilai peer
+type SetPeerReviewExcludedRequest struct {
+	Excluded bool `json:"excluded"`
+}
+
+// PeerReviewResponse tugas review untuk reviewer, tanpa identitas pemilik submission
+type PeerReviewResponse struct {
+	ID              uuid.UUID                `json:"id"`
+	AssignmentID    uuid.UUID                `json:"assignment_id"`
+	AssignmentTitle string                   `json:"assignment_title"`
+	ReviewEndAt     *time.Time               `json:"review_end_at"`
+	Open            bool                     `json:"open"` // masih bisa diisi / diubah
+	Score           *int                     `json:"score"`
+	RubricPoints    *int                     `json:"rubric_points"`
+	RubricMaxPoints *int                     `json:"rubric_max_points"`
+	Comment         *string                  `json:"comment"`
+	SubmittedAt     *time.Time               `json:"submitted_at"`
+	Criteria        []GradeCriterionResponse `json:"criteria"`
+}
+
+// PeerReviewDetailResponse tugas review beserta isi submission & rubrik
+type PeerReviewDetailResponse struct {
+	PeerReviewResponse
+	Submission PeerReviewSubmissionResponse `json:"submission"`
+	Rubric     *RubricResponse              `json:"rubric"`
+}
+
+// PeerReviewSubmissionResponse isi submission yang direview (anonim)
+type PeerReviewSubmissionResponse struct {
+	EssayText *string  `json:"essay_text"`
+	Note      *string  `json:"note"`
+	Files     []string `json:"files"`
+}
+
+// ReceivedPeerReviewResponse review yang diterima siswa, tanpa identitas reviewer
+type ReceivedPeerReviewResponse struct {
+	ID          uuid.UUID                `json:"id"`
+	Score       *int                     `json:"score"`
+	Comment     *string                  `json:"comment"`
+	SubmittedAt *time.Time               `json:"submitted_at"`
+	Criteria    []GradeCriterionResponse `json:"criteria"`
+}
+
+// PeerReviewOverviewResponse satu review lengkap dengan identitas, untuk guru
+type PeerReviewOverviewResponse struct {
+	ID              uuid.UUID                `json:"id"`
+	SubmissionID    uuid.UUID                `json:"submission_id"`
+	AuthorID        uuid.UUID                `json:"author_id"`
+	AuthorName      string                   `json:"author_name"`
+	ReviewerID      uuid.UUID                `json:"reviewer_id"`
+	ReviewerName    string                   `json:"reviewer_name"`
+	Score           *int                     `json:"score"`
+	RubricPoints    *int                     `json:"rubric_points"`
+	RubricMaxPoints *int                     `json:"rubric_max_points"`
+	Comment         *string                  `json:"comment"`
+	SubmittedAt     *time.Time               `json:"submitted_at"`
+	Excluded        bool                     `json:"excluded"`
+	Criteria        []GradeCriterionResponse `json:"criteria"`
+}
+
+// PeerReviewSummaryResponse ringkasan review per submission
+type PeerReviewSummaryResponse struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+	AuthorID     uuid.UUID `json:"author_id"`
+	AuthorName   string    `json:"author_name"`
+	Assigned     int       `json:"assigned"`
+	Submitted    int       `json:"submitted"`
+	PeerScore    *float64  `json:"peer_score"` // rata-rata setelah outlier dibuang
+}
+
+// AssignmentPeerReviewsResponse pengawasan peer review satu assignment
+type AssignmentPeerReviewsResponse struct {
+	DistributedAt *time.Time                   `json:"distributed_at"`
+	ReviewEndAt   *time.Time                   `json:"review_end_at"`
+	Weight        float64                      `json:"weight"`
+	Submissions   []PeerReviewSummaryResponse  `json:"submissions"`
+	Reviews       []PeerReviewOverviewResponse `json:"reviews"`
+}
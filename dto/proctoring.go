@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"brevet-api/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientInfo info koneksi client yang diambil dari request
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// ReportProctorEventRequest request
+type ReportProctorEventRequest struct {
+	Type       models.ProctorEventType `json:"type" validate:"required,proctor_event_type"`
+	Detail     *string                 `json:"detail" validate:"omitempty,max=500"`
+	OccurredAt *time.Time              `json:"occurred_at"`
+}
+
+// AttemptIntegritySummary ringkasan integritas sebuah attempt untuk guru
+type AttemptIntegritySummary struct {
+	EventCounts        map[models.ProctorEventType]int `json:"event_counts"`
+	AnswerChanges      int                             `json:"answer_changes"`
+	AnswersAfterBlur   int                             `json:"answers_after_blur"` // jawaban diubah sesaat setelah kembali dari tab lain
+	DistinctIPs        int                             `json:"distinct_ips"`
+	DistinctUserAgents int                             `json:"distinct_user_agents"`
+	SuspicionScore     float64                         `json:"suspicion_score"` // 0 - 100
+}
+
+// AttemptTimelineEntry satu baris timeline attempt
+type AttemptTimelineEntry struct {
+	At               time.Time               `json:"at"`
+	Kind             string                  `json:"kind"` // started, event, answer, submitted
+	EventType        models.ProctorEventType `json:"event_type,omitempty"`
+	Detail           *string                 `json:"detail,omitempty"`
+	QuestionID       *uuid.UUID              `json:"question_id,omitempty"`
+	SelectedOptionID *uuid.UUID              `json:"selected_option_id,omitempty"`
+	PreviousOptionID *uuid.UUID              `json:"previous_option_id,omitempty"`
+	IPAddress        string                  `json:"ip_address,omitempty"`
+	UserAgent        string                  `json:"user_agent,omitempty"`
+}
+
+// AttemptTimelineResponse response
+type AttemptTimelineResponse struct {
+	Attempt   QuizAttemptResponse     `json:"attempt"`
+	Integrity AttemptIntegritySummary `json:"integrity"`
+	Entries   []AttemptTimelineEntry  `json:"entries"`
+}
+
+// QuizAttemptSummary attempt beserta ringkasan integritas (nil untuk siswa)
+type QuizAttemptSummary struct {
+	Attempt   models.QuizAttempt
+	Integrity *AttemptIntegritySummary
+}
@@ -12,11 +12,19 @@ type ImportQuizzesRequest struct {
 	Title          string          `json:"title" validate:"required"`
 	Description    *string         `json:"description" validate:"omitempty"`
 	Type           models.QuizType `json:"quiz_type" validate:"required,quiz_type"`
+	Mode           models.QuizMode `json:"mode" validate:"omitempty,quiz_mode"`
 	DurationMinute int             `json:"duration_minute" validate:"required,min=1"`
-	MaxAttempts    int             `json:"max_attempts" validate:"required"`
+	MaxAttempts    int             `json:"max_attempts" validate:"required_unless=Mode practice,min=0"`
 	IsOpen         bool            `json:"is_open" validate:"required"`
 	StartTime      time.Time       `json:"start_time"`
 	EndTime        time.Time       `json:"end_time"`
+
+	ScoringPolicy         models.QuizScoringPolicy `json:"scoring_policy" validate:"omitempty,quiz_scoring_policy"`
+	CooldownMinutes       int                      `json:"cooldown_minutes" validate:"min=0"`
+	AttemptPenaltyPercent float64                  `json:"attempt_penalty_percent" validate:"min=0,max=100"`
+
+	ReviewLevel   models.QuizReviewLevel   `json:"review_level" validate:"omitempty,quiz_review_level"`
+	ReviewRelease models.QuizReviewRelease `json:"review_release" validate:"omitempty,quiz_review_release"`
 }
 
 // SaveTempSubmissionRequest request
@@ -30,11 +38,35 @@ type UpdateQuizRequest struct {
 	Title          *string          `json:"title,omitempty"`
 	Description    *string          `json:"description,omitempty"`
 	Type           *models.QuizType `json:"type,omitempty"`
+	Mode           *models.QuizMode `json:"mode,omitempty" validate:"omitempty,quiz_mode"`
 	IsOpen         *bool            `json:"is_open,omitempty"`
 	StartTime      *time.Time       `json:"start_time,omitempty"`
 	EndTime        *time.Time       `json:"end_time,omitempty"`
 	DurationMinute *int             `json:"duration_minute,omitempty"`
 	MaxAttempts    *int             `json:"max_attempts,omitempty"`
+
+	ScoringPolicy         *models.QuizScoringPolicy `json:"scoring_policy,omitempty" validate:"omitempty,quiz_scoring_policy"`
+	CooldownMinutes       *int                      `json:"cooldown_minutes,omitempty" validate:"omitempty,min=0"`
+	AttemptPenaltyPercent *float64                  `json:"attempt_penalty_percent,omitempty" validate:"omitempty,min=0,max=100"`
+
+	ReviewLevel   *models.QuizReviewLevel   `json:"review_level,omitempty" validate:"omitempty,quiz_review_level"`
+	ReviewRelease *models.QuizReviewRelease `json:"review_release,omitempty" validate:"omitempty,quiz_review_release"`
+}
+
+// UpdateQuestionRequest request
+// Field nil tidak diubah; string kosong menghapus pembahasan / gambar.
+type UpdateQuestionRequest struct {
+	Question    *string                      `json:"question" validate:"omitempty,max=20000"`
+	ImageURL    *string                      `json:"image_url" validate:"omitempty,max=500"`
+	Explanation *string                      `json:"explanation" validate:"omitempty,max=20000"`
+	Options     []UpdateOptionContentRequest `json:"options" validate:"omitempty,dive"`
+}
+
+// UpdateOptionContentRequest request ubah teks / gambar satu opsi
+type UpdateOptionContentRequest struct {
+	ID         uuid.UUID `json:"id" validate:"required"`
+	OptionText *string   `json:"option_text" validate:"omitempty,max=5000"`
+	ImageURL   *string   `json:"image_url" validate:"omitempty,max=500"`
 }
 
 // QuizResponse response
@@ -44,6 +76,7 @@ type QuizResponse struct {
 	Title          string          `json:"title"`
 	Description    *string         `json:"description"`
 	Type           models.QuizType `json:"type"`
+	Mode           models.QuizMode `json:"mode"`
 	IsOpen         bool            `json:"is_open"`
 	StartTime      time.Time       `json:"start_time"`
 	EndTime        time.Time       `json:"end_time"`
@@ -51,6 +84,14 @@ type QuizResponse struct {
 
 	MaxAttempts int `json:"max_attempts"`
 
+	ScoringPolicy         models.QuizScoringPolicy `json:"scoring_policy"`
+	CooldownMinutes       int                      `json:"cooldown_minutes"`
+	AttemptPenaltyPercent float64                  `json:"attempt_penalty_percent"`
+
+	ReviewLevel      models.QuizReviewLevel   `json:"review_level"`
+	ReviewRelease    models.QuizReviewRelease `json:"review_release"`
+	ReviewReleasedAt *time.Time               `json:"review_released_at"`
+
 	Questions []QuestionResponse `json:"questions,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
@@ -71,13 +112,18 @@ type QuizResultResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 
 	Attempt QuizAttemptWithQuizMetadataResponse `json:"attempt"`
+	Review  *QuizAttemptReview                  `json:"review,omitempty"`
 }
 
 // QuestionResponse response
 type QuestionResponse struct {
-	ID       uuid.UUID `json:"id"`
-	QuizID   uuid.UUID `json:"quiz_id"`
-	Question string    `json:"question"`
+	ID          uuid.UUID `json:"id"`
+	QuizID      uuid.UUID `json:"quiz_id"`
+	Question    string    `json:"question"`
+	ImageURL    *string   `json:"image_url"`
+	Explanation *string   `json:"explanation"`
+
+	ScoringMode models.QuestionScoringMode `json:"scoring_mode"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -92,6 +138,7 @@ type QuizOptionResponse struct {
 	ID         uuid.UUID `json:"id"`
 	QuestionID uuid.UUID `json:"question_id"`
 	OptionText string    `json:"option_text"`
+	ImageURL   *string   `json:"image_url"`
 	IsCorrect  bool      `json:"is_correct"`
 
 	CreatedAt time.Time `json:"created_at"`
@@ -117,8 +164,17 @@ type QuizAttemptResponse struct {
 	StartedAt time.Time  `json:"started_at"`
 	EndedAt   *time.Time `json:"ended_at"`
 
+	ExtraMinutes  int        `json:"extra_minutes"`
+	PausedAt      *time.Time `json:"paused_at"`
+	PausedSeconds int        `json:"paused_seconds"`
+	VoidedAt      *time.Time `json:"voided_at"`
+
+	PaperVersionID *uuid.UUID `json:"paper_version_id"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	Integrity *AttemptIntegritySummary `json:"integrity,omitempty"`
 }
 
 // QuizAttemptWithQuizMetadataResponse response
@@ -140,6 +196,7 @@ type QuizOptionForUserResponse struct {
 	ID         uuid.UUID `json:"id"`
 	QuestionID uuid.UUID `json:"question_id"`
 	OptionText string    `json:"option_text"`
+	ImageURL   *string   `json:"image_url"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -150,6 +207,7 @@ type QuestionForUserResponse struct {
 	ID       uuid.UUID `json:"id"`
 	QuizID   uuid.UUID `json:"quiz_id"`
 	Question string    `json:"question"`
+	ImageURL *string   `json:"image_url"`
 
 	Options  []QuizOptionForUserResponse  `json:"options,omitempty"`
 	TempSubs []QuizTempSubmissionResponse `json:"temp_subs,omitempty"`
@@ -165,6 +223,7 @@ type QuizForUserResponse struct {
 	Title          string          `json:"title"`
 	Description    *string         `json:"description"`
 	Type           models.QuizType `json:"type"`
+	Mode           models.QuizMode `json:"mode"`
 	IsOpen         bool            `json:"is_open"`
 	StartTime      time.Time       `json:"start_time"`
 	EndTime        time.Time       `json:"end_time"`
@@ -172,6 +231,14 @@ type QuizForUserResponse struct {
 
 	MaxAttempts int `json:"max_attempts"`
 
+	ScoringPolicy         models.QuizScoringPolicy `json:"scoring_policy"`
+	CooldownMinutes       int                      `json:"cooldown_minutes"`
+	AttemptPenaltyPercent float64                  `json:"attempt_penalty_percent"`
+
+	ReviewLevel      models.QuizReviewLevel   `json:"review_level"`
+	ReviewRelease    models.QuizReviewRelease `json:"review_release"`
+	ReviewReleasedAt *time.Time               `json:"review_released_at"`
+
 	Questions []QuestionForUserResponse `json:"questions,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
@@ -183,6 +250,8 @@ type QuizAttemptFullResponse struct {
 	Attempt         *QuizAttemptResponse          `json:"attempt"`
 	Quiz            *QuizForUserResponse          `json:"quiz"`
 	TempSubmissions []*QuizTempSubmissionResponse `json:"temp_submissions,omitempty"`
+	Deadline        time.Time                     `json:"deadline"`
+	Review          *QuizAttemptReview            `json:"review,omitempty"`
 }
 
 // QuizAttemptFull response
@@ -190,4 +259,124 @@ type QuizAttemptFull struct {
 	Attempt         *models.QuizAttempt         `json:"attempt"`
 	Quiz            *models.Quiz                `json:"quiz"`
 	TempSubmissions []models.QuizTempSubmission `json:"temp_submissions,omitempty"`
+	Deadline        time.Time                   `json:"deadline"`
+	Review          *QuizAttemptReview          `json:"review,omitempty"`
+}
+
+// QuizAttemptResult result attempt beserta review yang boleh dilihat user
+type QuizAttemptResult struct {
+	Result *models.QuizResult
+	Review *QuizAttemptReview
+}
+
+// QuizAttemptReview review attempt sesuai aturan rilis quiz
+type QuizAttemptReview struct {
+	Level     models.QuizReviewLevel `json:"level"`
+	Released  bool                   `json:"released"`
+	ReleaseAt *time.Time             `json:"release_at"` // nil = menunggu dibuka manual oleh guru
+	Questions []QuestionReview       `json:"questions,omitempty"`
+}
+
+// QuestionReview detail jawaban per soal, field diisi sesuai level review
+type QuestionReview struct {
+	QuestionID       uuid.UUID                   `json:"question_id"`
+	Question         string                      `json:"question"`
+	ImageURL         *string                     `json:"image_url"`
+	ScoringMode      models.QuestionScoringMode  `json:"scoring_mode"`
+	SelectedOptionID *uuid.UUID                  `json:"selected_option_id"`
+	IsCorrect        bool                        `json:"is_correct"`
+	CorrectOptionIDs []uuid.UUID                 `json:"correct_option_ids,omitempty"`
+	Explanation      *string                     `json:"explanation,omitempty"`
+	Options          []QuizOptionForUserResponse `json:"options"`
+}
+
+// QuizItemAnalysisResponse laporan analisis butir soal untuk satu quiz
+type QuizItemAnalysisResponse struct {
+	QuizID        uuid.UUID          `json:"quiz_id"`
+	Title         string             `json:"title"`
+	TotalAttempts int                `json:"total_attempts"`
+	GroupSize     int                `json:"group_size"` // jumlah peserta di kelompok atas/bawah (27%)
+	MeanScore     float64            `json:"mean_score"`
+	StdDevScore   float64            `json:"std_dev_score"`
+	Items         []QuizItemAnalysis `json:"items"`
+}
+
+// QuizItemAnalysis statistik per soal
+type QuizItemAnalysis struct {
+	QuestionID     uuid.UUID            `json:"question_id"`
+	Question       string               `json:"question"`
+	CorrectCount   int                  `json:"correct_count"`
+	OmittedCount   int                  `json:"omitted_count"`
+	Difficulty     float64              `json:"difficulty"`     // p-value, proporsi peserta yang menjawab benar
+	Discrimination float64              `json:"discrimination"` // p kelompok atas - p kelompok bawah
+	PointBiserial  float64              `json:"point_biserial"`
+	Flags          []string             `json:"flags"`
+	Options        []QuizOptionAnalysis `json:"options"`
+}
+
+// QuizOptionAnalysis sebaran jawaban per opsi (termasuk distractor)
+type QuizOptionAnalysis struct {
+	OptionID   uuid.UUID `json:"option_id"`
+	OptionText string    `json:"option_text"`
+	IsCorrect  bool      `json:"is_correct"`
+	Count      int       `json:"count"`
+	Proportion float64   `json:"proportion"`
+	UpperCount int       `json:"upper_count"`
+	LowerCount int       `json:"lower_count"`
+}
+
+// RegradeQuizRequest request regrade quiz. Kalau QuestionID kosong, seluruh quiz dihitung ulang
+// dengan kunci yang berlaku sekarang.
+type RegradeQuizRequest struct {
+	QuestionID       *uuid.UUID                  `json:"question_id" validate:"omitempty"`
+	CorrectOptionIDs []uuid.UUID                 `json:"correct_option_ids" validate:"omitempty"`
+	ScoringMode      *models.QuestionScoringMode `json:"scoring_mode" validate:"omitempty,question_scoring_mode"`
+	Reason           *string                     `json:"reason" validate:"omitempty"`
+}
+
+// QuizRegradeLogResponse response
+type QuizRegradeLogResponse struct {
+	ID         uuid.UUID                  `json:"id"`
+	QuizID     uuid.UUID                  `json:"quiz_id"`
+	QuestionID *uuid.UUID                 `json:"question_id"`
+	ActorID    uuid.UUID                  `json:"actor_id"`
+	ActorName  string                     `json:"actor_name"`
+	Mode       models.QuestionScoringMode `json:"mode"`
+	Reason     *string                    `json:"reason"`
+
+	AffectedAttempts int `json:"affected_attempts"`
+	ChangedAttempts  int `json:"changed_attempts"`
+
+	Items []QuizRegradeChangeResponse `json:"items"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QuizRegradeChangeResponse response
+type QuizRegradeChangeResponse struct {
+	AttemptID       uuid.UUID `json:"attempt_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	UserName        string    `json:"user_name"`
+	OldScorePercent float64   `json:"old_score_percent"`
+	NewScorePercent float64   `json:"new_score_percent"`
+	OldCorrect      int       `json:"old_correct"`
+	NewCorrect      int       `json:"new_correct"`
+}
+
+// AttemptActionRequest request tindakan guru terhadap attempt. Minutes dipakai untuk extend & reopen.
+type AttemptActionRequest struct {
+	Reason  string `json:"reason" validate:"required,max=500"`
+	Minutes int    `json:"minutes" validate:"omitempty,min=0,max=600"`
+}
+
+// QuizAttemptActionResponse response
+type QuizAttemptActionResponse struct {
+	ID        uuid.UUID                `json:"id"`
+	AttemptID uuid.UUID                `json:"attempt_id"`
+	ActorID   uuid.UUID                `json:"actor_id"`
+	ActorName string                   `json:"actor_name"`
+	Action    models.AttemptActionType `json:"action"`
+	Reason    string                   `json:"reason"`
+	Minutes   *int                     `json:"minutes"`
+	CreatedAt time.Time                `json:"created_at"`
 }
@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"brevet-api/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GeneratePaperVersionsRequest request generate versi naskah cetak (A, B, C, ...)
+type GeneratePaperVersionsRequest struct {
+	Versions int `json:"versions" validate:"required,min=1,max=10"`
+}
+
+// PaperAnswerRequest jawaban satu nomor soal di naskah cetak, Label kosong = tidak dijawab
+type PaperAnswerRequest struct {
+	Number int    `json:"number" validate:"required,min=1"`
+	Label  string `json:"label" validate:"omitempty,len=1,alpha"`
+}
+
+// SubmitPaperAnswersRequest input lembar jawaban siswa untuk versi naskah tertentu
+type SubmitPaperAnswersRequest struct {
+	UserID      uuid.UUID            `json:"user_id" validate:"required"`
+	VersionCode string               `json:"version_code" validate:"required,max=2"`
+	Answers     []PaperAnswerRequest `json:"answers" validate:"required,min=1,dive"`
+}
+
+// QuizPaperVersionResponse response
+type QuizPaperVersionResponse struct {
+	ID        uuid.UUID                   `json:"id"`
+	QuizID    uuid.UUID                   `json:"quiz_id"`
+	Code      string                      `json:"code"`
+	CreatedAt time.Time                   `json:"created_at"`
+	Questions []QuizPaperQuestionResponse `json:"questions"`
+}
+
+// QuizPaperQuestionResponse pemetaan nomor soal naskah ke soal quiz
+type QuizPaperQuestionResponse struct {
+	Number     int                       `json:"number"`
+	QuestionID uuid.UUID                 `json:"question_id"`
+	Options    []QuizPaperOptionResponse `json:"options"`
+}
+
+// QuizPaperOptionResponse pemetaan huruf opsi naskah ke opsi quiz
+type QuizPaperOptionResponse struct {
+	Label    string    `json:"label"`
+	OptionID uuid.UUID `json:"option_id"`
+}
+
+// ResolveOmrScanRequest koreksi manual scan LJK. UserID wajib kalau kode lembar tidak terbaca.
+type ResolveOmrScanRequest struct {
+	UserID  *uuid.UUID           `json:"user_id" validate:"omitempty"`
+	Answers []PaperAnswerRequest `json:"answers" validate:"required,min=1,dive"`
+}
+
+// RejectOmrScanRequest request buang scan LJK dari antrian review
+type RejectOmrScanRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// QuizAnswerSheetResponse response
+type QuizAnswerSheetResponse struct {
+	ID          uuid.UUID `json:"id"`
+	QuizID      uuid.UUID `json:"quiz_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	UserName    string    `json:"user_name"`
+	VersionCode string    `json:"version_code"`
+	Serial      int64     `json:"serial"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// QuizOmrScanResponse response
+type QuizOmrScanResponse struct {
+	ID          uuid.UUID            `json:"id"`
+	QuizID      uuid.UUID            `json:"quiz_id"`
+	SheetID     *uuid.UUID           `json:"sheet_id"`
+	UserID      *uuid.UUID           `json:"user_id"`
+	UserName    string               `json:"user_name"`
+	VersionCode string               `json:"version_code"`
+	ImageURL    string               `json:"image_url"`
+	Status      models.OmrScanStatus `json:"status"`
+	Confidence  float64              `json:"confidence"`
+	Issues      *string              `json:"issues"`
+	AttemptID   *uuid.UUID           `json:"attempt_id"`
+	UploadedBy  uuid.UUID            `json:"uploaded_by"`
+	ReviewedBy  *uuid.UUID           `json:"reviewed_by"`
+	ReviewedAt  *time.Time           `json:"reviewed_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+
+	Answers []QuizOmrScanAnswerResponse `json:"answers"`
+}
+
+// QuizOmrScanAnswerResponse bacaan satu nomor
+type QuizOmrScanAnswerResponse struct {
+	Number     int     `json:"number"`
+	Label      string  `json:"label"`
+	Confidence float64 `json:"confidence"`
+	Ambiguous  bool    `json:"ambiguous"`
+}
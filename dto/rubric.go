@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RubricLevelRequest satu level capaian di kriteria
+type RubricLevelRequest struct {
+	Title       string  `json:"title" validate:"required,max=255"`
+	Description *string `json:"description" validate:"omitempty"`
+	Points      int     `json:"points" validate:"min=0,max=1000"`
+}
+
+// RubricCriterionRequest satu kriteria beserta level-levelnya
+type RubricCriterionRequest struct {
+	Title       string               `json:"title" validate:"required,max=255"`
+	Description *string              `json:"description" validate:"omitempty"`
+	Levels      []RubricLevelRequest `json:"levels" validate:"required,min=2,dive"`
+}
+
+// SaveRubricRequest request create / replace rubrik
+type SaveRubricRequest struct {
+	Title       string                   `json:"title" validate:"required,max=255"`
+	Description *string                  `json:"description" validate:"omitempty"`
+	Criteria    []RubricCriterionRequest `json:"criteria" validate:"required,min=1,dive"`
+}
+
+// AttachRubricRequest pasang / lepas rubrik dari assignment, RubricID null = lepas
+type AttachRubricRequest struct {
+	RubricID *uuid.UUID `json:"rubric_id" validate:"omitempty"`
+}
+
+// RubricSelectionRequest level yang dipilih guru untuk satu kriteria
+type RubricSelectionRequest struct {
+	CriterionID uuid.UUID `json:"criterion_id" validate:"required"`
+	LevelID     uuid.UUID `json:"level_id" validate:"required"`
+	Comment     *string   `json:"comment" validate:"omitempty,max=1000"`
+}
+
+// RubricResponse response
+type RubricResponse struct {
+	ID          uuid.UUID                 `json:"id"`
+	Title       string                    `json:"title"`
+	Description *string                   `json:"description"`
+	MaxPoints   int                       `json:"max_points"`
+	CreatedBy   uuid.UUID                 `json:"created_by"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	Criteria    []RubricCriterionResponse `json:"criteria"`
+}
+
+// RubricCriterionResponse response
+type RubricCriterionResponse struct {
+	ID          uuid.UUID             `json:"id"`
+	Title       string                `json:"title"`
+	Description *string               `json:"description"`
+	Order       int                   `json:"order"`
+	Levels      []RubricLevelResponse `json:"levels"`
+}
+
+// RubricLevelResponse response
+type RubricLevelResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description *string   `json:"description"`
+	Points      int       `json:"points"`
+	Order       int       `json:"order"`
+}
+
+// GradeCriterionResponse rincian nilai per kriteria rubrik
+type GradeCriterionResponse struct {
+	CriterionID    uuid.UUID `json:"criterion_id"`
+	LevelID        uuid.UUID `json:"level_id"`
+	CriterionTitle string    `json:"criterion_title"`
+	LevelTitle     string    `json:"level_title"`
+	Points         int       `json:"points"`
+	MaxPoints      int       `json:"max_points"`
+	Comment        *string   `json:"comment"`
+	Order          int       `json:"order"`
+}
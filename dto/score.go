@@ -43,7 +43,19 @@ type QuizScore struct {
 
 	MaxAttempts int `json:"max_attempts"`
 
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Score     float64   `json:"score"`
+	ScoringPolicy         models.QuizScoringPolicy `json:"scoring_policy"`
+	AttemptPenaltyPercent float64                  `json:"attempt_penalty_percent"`
+
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Score        float64   `json:"score"`
+	AttemptCount int       `json:"attempt_count"`
+}
+
+// QuizAttemptScore skor satu attempt selesai, dipakai untuk menghitung nilai akhir sesuai policy
+type QuizAttemptScore struct {
+	QuizID       uuid.UUID `json:"quiz_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	StartedAt    time.Time `json:"started_at"`
+	ScorePercent float64   `json:"score_percent"`
 }
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunSimilarityRequest request cek kemiripan submission assignment
+type RunSimilarityRequest struct {
+	IncludePreviousBatches bool     `json:"include_previous_batches"`
+	MinScore               *float64 `json:"min_score" validate:"omitempty,min=0,max=1"`
+}
+
+// SimilarityReportResponse ringkasan laporan
+type SimilarityReportResponse struct {
+	ID                     uuid.UUID  `json:"id"`
+	AssignmentID           uuid.UUID  `json:"assignment_id"`
+	Status                 string     `json:"status"`
+	IncludePreviousBatches bool       `json:"include_previous_batches"`
+	MinScore               float64    `json:"min_score"`
+	Automatic              bool       `json:"automatic"`
+	Error                  *string    `json:"error"`
+	RequestedBy            *uuid.UUID `json:"requested_by"`
+	StartedAt              *time.Time `json:"started_at"`
+	FinishedAt             *time.Time `json:"finished_at"`
+	CreatedAt              time.Time  `json:"created_at"`
+}
+
+// SimilarityReportDetailResponse laporan lengkap dengan dokumen & pasangan
+type SimilarityReportDetailResponse struct {
+	SimilarityReportResponse
+	Documents []SimilarityDocumentResponse `json:"documents"`
+	Pairs     []SimilarityPairResponse     `json:"pairs"`
+}
+
+// SimilarityDocumentResponse dokumen yang ikut dibandingkan (tanpa teks lengkap)
+type SimilarityDocumentResponse struct {
+	ID           uuid.UUID `json:"id"`
+	SubmissionID uuid.UUID `json:"submission_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	Previous     bool      `json:"previous"`
+	WordCount    int       `json:"word_count"`
+	Skipped      *string   `json:"skipped"`
+}
+
+// SimilarityPairResponse skor kemiripan dua dokumen
+type SimilarityPairResponse struct {
+	ID          uuid.UUID                   `json:"id"`
+	DocumentAID uuid.UUID                   `json:"document_a_id"`
+	DocumentBID uuid.UUID                   `json:"document_b_id"`
+	Score       float64                     `json:"score"`
+	ScoreA      float64                     `json:"score_a"`
+	ScoreB      float64                     `json:"score_b"`
+	Passages    []SimilarityPassageResponse `json:"passages"`
+}
+
+// SimilarityPassageResponse potongan teks yang sama, offset rune di teks dokumen masing-masing
+type SimilarityPassageResponse struct {
+	AStart int    `json:"a_start"`
+	AEnd   int    `json:"a_end"`
+	AText  string `json:"a_text"`
+	BStart int    `json:"b_start"`
+	BEnd   int    `json:"b_end"`
+	BText  string `json:"b_text"`
+}
+
+// SimilarityDocumentTextResponse teks lengkap dokumen untuk highlight di sisi client
+type SimilarityDocumentTextResponse struct {
+	SimilarityDocumentResponse
+	Text string `json:"text"`
+}
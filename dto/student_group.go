@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateStudentGroupRequest buat kelompok manual
+type CreateStudentGroupRequest struct {
+	Name      string      `json:"name" validate:"required,max=100"`
+	MemberIDs []uuid.UUID `json:"member_ids" validate:"omitempty,dive,required"`
+}
+
+// UpdateStudentGroupRequest ubah kelompok, member_ids diisi = ganti seluruh anggota
+type UpdateStudentGroupRequest struct {
+	Name      *string     `json:"name" validate:"omitempty,min=1,max=100"`
+	MemberIDs []uuid.UUID `json:"member_ids" validate:"omitempty,dive,required"`
+}
+
+// GenerateStudentGroupsRequest bagi otomatis siswa yang belum punya kelompok
+type GenerateStudentGroupsRequest struct {
+	GroupSize int `json:"group_size" validate:"omitempty,min=2,max=10"` // kosong = 4
+}
+
+// StudentGroupMemberResponse anggota kelompok
+type StudentGroupMemberResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Email  string    `json:"email"`
+}
+
+// StudentGroupResponse kelompok siswa
+type StudentGroupResponse struct {
+	ID        uuid.UUID                    `json:"id"`
+	BatchID   uuid.UUID                    `json:"batch_id"`
+	Name      string                       `json:"name"`
+	Members   []StudentGroupMemberResponse `json:"members"`
+	CreatedAt time.Time                    `json:"created_at"`
+	UpdatedAt time.Time                    `json:"updated_at"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"brevet-api/models"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +12,19 @@ type SubmissionResponse struct {
 	ID           uuid.UUID `json:"id"`
 	AssignmentID uuid.UUID `json:"assignment_id"`
 
-	UserID uuid.UUID `json:"user_id"`
+	UserID  uuid.UUID  `json:"user_id"`
+	GroupID *uuid.UUID `json:"group_id"` // assignment kelompok
 
 	Note      *string `json:"note"`
 	EssayText *string `json:"essay_text"`
 
-	IsLate bool `json:"is_late"`
+	IsLate      bool `json:"is_late"`
+	LateMinutes int  `json:"late_minutes"`
+	Version     int  `json:"version"`
+
+	RevisionRequested bool       `json:"revision_requested"`
+	RevisionNote      *string    `json:"revision_note"`
+	RevisionDueAt     *time.Time `json:"revision_due_at"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -37,16 +45,44 @@ type SubmissionResponse struct {
 
 // SubmissionGradeResponse represents the response structure for submission grade
 type SubmissionGradeResponse struct {
-	ID                     uuid.UUID `json:"id"`
-	AssignmentSubmissionID uuid.UUID `json:"assignment_submission_id"` // Foreign key ke assignment_submissions.id
-	Grade                  int       `json:"grade"`
-	Feedback               string    `json:"feedback"`
-	GradedBy               uuid.UUID `json:"graded_by"`
-	CreatedAt              time.Time `json:"created_at"`
-	UpdatedAt              time.Time `json:"updated_at"`
+	ID                     uuid.UUID  `json:"id"`
+	AssignmentSubmissionID uuid.UUID  `json:"assignment_submission_id"` // Foreign key ke assignment_submissions.id
+	Grade                  int        `json:"grade"`
+	Feedback               string     `json:"feedback"`
+	GradedBy               uuid.UUID  `json:"graded_by"`
+	RubricID               *uuid.UUID `json:"rubric_id"`
+	RubricPoints           *int       `json:"rubric_points"`
+	RubricMaxPoints        *int       `json:"rubric_max_points"`
+	LatePenaltyPercent     float64    `json:"late_penalty_percent"`
+	PenalizedGrade         *int       `json:"penalized_grade"`
+	EffectiveGrade         int        `json:"effective_grade"` // nilai yang masuk ke nilai akhir
+	Version                *int       `json:"version"`         // versi submission yang dinilai
+	PeerScore              *float64   `json:"peer_score"`      // rata-rata nilai peer review
+	PeerWeight             float64    `json:"peer_weight"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+
+	// Moderasi nilai ujian
+	ModerationStatus  models.GradeModerationStatus `json:"moderation_status"`
+	ModeratorID       *uuid.UUID                   `json:"moderator_id"`
+	ModeratedAt       *time.Time                   `json:"moderated_at"`
+	ModerationComment *string                      `json:"moderation_comment"`
+	OriginalGrade     *int                         `json:"original_grade"` // nilai penilai pertama kalau disesuaikan
+	ModerationFlagged bool                         `json:"moderation_flagged"`
+	ReleasedAt        *time.Time                   `json:"released_at"`
 
 	// AssignmentSubmission AssignmentSubmission `gorm:"foreignKey:AssignmentSubmissionID"`
-	GradedByUser UserResponse `json:"graded_by_user"`
+	GradedByUser UserResponse              `json:"graded_by_user"`
+	Criteria     []GradeCriterionResponse  `json:"criteria"`
+	Adjustments  []GradeAdjustmentResponse `json:"adjustments"`  // assignment kelompok, siswa hanya melihat miliknya
+	MemberGrade  *int                      `json:"member_grade"` // nilai akhir siswa yang melihat (termasuk penyesuaian)
+}
+
+// GradeAdjustmentResponse penyesuaian nilai individu anggota kelompok
+type GradeAdjustmentResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Points int       `json:"points"`
+	Reason *string   `json:"reason"`
 }
 
 // SubmissionFileDTO submission files
@@ -68,8 +104,93 @@ type UpdateSubmissionRequest struct {
 	SubmissionFiles *[]SubmissionFileDTO `json:"submission_files,omitempty" validate:"omitempty,dive"`
 }
 
-// GradeSubmissionRequest for grade submission request
+// GradeSubmissionRequest for grade submission request.
+// Kalau assignment memakai rubrik, Grade diabaikan dan dihitung dari Criteria.
 type GradeSubmissionRequest struct {
-	Grade    int    `json:"grade" validate:"required,min=0,max=100"`
-	Feedback string `json:"feedback"`
+	Grade    int                      `json:"grade" validate:"required_without=Criteria,min=0,max=100"`
+	Feedback string                   `json:"feedback"`
+	Criteria []RubricSelectionRequest `json:"criteria" validate:"omitempty,dive"`
+	Version  *int                     `json:"version" validate:"omitempty,min=1"` // kosong = versi terbaru
+
+	// Penyesuaian nilai individu, hanya untuk submission kelompok
+	Adjustments []GradeAdjustmentRequest `json:"adjustments" validate:"omitempty,dive"`
+
+	// Nilai ujian: true = langsung dikirim ke penilai kedua, false = simpan sebagai draft
+	Submit bool `json:"submit"`
+}
+
+// ModerateGradeRequest penilai kedua setuju (grade kosong) atau menyesuaikan nilai ujian
+type ModerateGradeRequest struct {
+	Grade   *int    `json:"grade" validate:"omitempty,min=0,max=100"`
+	Comment *string `json:"comment" validate:"omitempty,max=2000"`
+}
+
+// GradeImportResponse hasil import spreadsheet nilai
+type GradeImportResponse struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // nilai ujian yang sudah dimoderasi / dirilis tidak ditimpa
+}
+
+// ModerationResultResponse jumlah nilai yang berubah status
+type ModerationResultResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// ModerationProgress progres moderasi satu assignment ujian
+type ModerationProgress struct {
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	MeetingID    uuid.UUID `json:"meeting_id"`
+	Title        string    `json:"title"`
+	Submissions  int64     `json:"submissions"`
+	Ungraded     int64     `json:"ungraded"`
+	Draft        int64     `json:"draft"`
+	Submitted    int64     `json:"submitted"`
+	Moderated    int64     `json:"moderated"`
+	Released     int64     `json:"released"`
+	Flagged      int64     `json:"flagged"`
+}
+
+// GradeAdjustmentRequest tambah/kurang nilai satu anggota kelompok
+type GradeAdjustmentRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Points int       `json:"points" validate:"min=-100,max=100"`
+	Reason *string   `json:"reason" validate:"omitempty,max=500"`
+}
+
+// ReturnSubmissionRequest kembalikan submission ke siswa untuk direvisi
+type ReturnSubmissionRequest struct {
+	Note  *string    `json:"note" validate:"omitempty,max=2000"`
+	DueAt *time.Time `json:"due_at" validate:"omitempty"` // batas revisi, kosong = tanpa batas
+}
+
+// SubmissionVersionResponse satu versi submission
+type SubmissionVersionResponse struct {
+	ID          uuid.UUID                       `json:"id"`
+	Version     int                             `json:"version"`
+	Note        *string                         `json:"note"`
+	EssayText   *string                         `json:"essay_text"`
+	IsLate      bool                            `json:"is_late"`
+	LateMinutes int                             `json:"late_minutes"`
+	IsRevision  bool                            `json:"is_revision"`
+	CreatedAt   time.Time                       `json:"created_at"`
+	Files       []SubmissionVersionFileResponse `json:"files"`
+}
+
+// SubmissionVersionFileResponse file milik satu versi
+type SubmissionVersionFileResponse struct {
+	ID      uuid.UUID `json:"id"`
+	FileURL string    `json:"file_url"`
+}
+
+// TextDiffOp potongan hasil diff teks: equal, insert (hanya di versi baru) atau delete (hanya di versi lama)
+type TextDiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// SubmissionVersionDiffResponse perbedaan essay dua versi
+type SubmissionVersionDiffResponse struct {
+	From int          `json:"from"`
+	To   int          `json:"to"`
+	Ops  []TextDiffOp `json:"ops"`
 }
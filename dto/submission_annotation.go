@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"brevet-api/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAnnotationRequest anotasi baru di file PDF submission. Posisi & ukuran relatif halaman (0-1).
+type CreateAnnotationRequest struct {
+	Page    int                   `json:"page" validate:"required,min=1"`
+	Type    models.AnnotationType `json:"type" validate:"required,annotation_type"`
+	X       float64               `json:"x" validate:"min=0,max=1"`
+	Y       float64               `json:"y" validate:"min=0,max=1"`
+	Width   float64               `json:"width" validate:"min=0,max=1"`
+	Height  float64               `json:"height" validate:"min=0,max=1"`
+	Comment *string               `json:"comment" validate:"omitempty,max=2000"`
+	Color   *string               `json:"color" validate:"omitempty,hexcolor,len=7"`
+}
+
+// UpdateAnnotationRequest ubah anotasi, field kosong tidak diubah
+type UpdateAnnotationRequest struct {
+	Page    *int                   `json:"page" validate:"omitempty,min=1"`
+	Type    *models.AnnotationType `json:"type" validate:"omitempty,annotation_type"`
+	X       *float64               `json:"x" validate:"omitempty,min=0,max=1"`
+	Y       *float64               `json:"y" validate:"omitempty,min=0,max=1"`
+	Width   *float64               `json:"width" validate:"omitempty,min=0,max=1"`
+	Height  *float64               `json:"height" validate:"omitempty,min=0,max=1"`
+	Comment *string                `json:"comment" validate:"omitempty,max=2000"`
+	Color   *string                `json:"color" validate:"omitempty,hexcolor,len=7"`
+}
+
+// AnnotationResponse anotasi file submission
+type AnnotationResponse struct {
+	ID               uuid.UUID             `json:"id"`
+	SubmissionID     uuid.UUID             `json:"submission_id"`
+	SubmissionFileID *uuid.UUID            `json:"submission_file_id"`
+	FileURL          string                `json:"file_url"`
+	Version          int                   `json:"version"`
+	AuthorID         uuid.UUID             `json:"author_id"`
+	AuthorName       string                `json:"author_name"`
+	Page             int                   `json:"page"`
+	Type             models.AnnotationType `json:"type"`
+	X                float64               `json:"x"`
+	Y                float64               `json:"y"`
+	Width            float64               `json:"width"`
+	Height           float64               `json:"height"`
+	Comment          *string               `json:"comment"`
+	Color            *string               `json:"color"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+}
@@ -5,8 +5,14 @@ import (
 	"brevet-api/middlewares"
 	"brevet-api/routes"
 	"brevet-api/scheduler"
+	"brevet-api/services"
 	"brevet-api/utils"
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -55,6 +61,8 @@ func main() {
 	// Cleanup expired sessions every hour
 	scheduler.StartCleanupScheduler(db)
 	scheduler.InitQuizScheduler(db)
+	scheduler.StartSimilarityScheduler(db)
+	scheduler.StartPeerReviewScheduler(db)
 
 	// Purpose route for testing
 	app.Get("/hello", func(c *fiber.Ctx) error {
@@ -71,7 +79,22 @@ func main() {
 
 	// Start
 	port := config.GetEnv("APP_PORT", "3000")
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Graceful shutdown: tunggu request & cek kemiripan yang sedang berjalan
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("Shutting down server...")
+	if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+		log.Println("Failed to shutdown server:", err)
+	}
+	if !services.StopSimilarityJobs(10 * time.Second) {
+		log.Println("Similarity reports still running at shutdown, they will be marked failed as stale")
 	}
 }
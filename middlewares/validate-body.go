@@ -36,3 +36,8 @@ func ValidateBody[T any]() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// ValidateStruct validasi struct di luar body HTTP (misal pesan WebSocket)
+func ValidateStruct(body any) error {
+	return validate.Struct(body)
+}
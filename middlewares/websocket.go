@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"brevet-api/utils"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireWebSocketUpgrade tolak request yang bukan upgrade WebSocket.
+// Browser tidak bisa mengirim header Authorization saat membuka WebSocket,
+// jadi token boleh dikirim lewat query ?token= dan diteruskan ke RequireAuth.
+func RequireWebSocketUpgrade() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return utils.ErrorResponse(c, fiber.StatusUpgradeRequired, "WebSocket upgrade required", nil)
+		}
+
+		if c.Get("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request().Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		return c.Next()
+	}
+}
@@ -9,6 +9,24 @@ type IEmailService struct {
 	mock.Mock
 }
 
+// SendEmail provides a mock function with given fields: to, subject, body
+func (_m *IEmailService) SendEmail(to string, subject string, body string) error {
+	ret := _m.Called(to, subject, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(to, subject, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendVerificationEmail provides a mock function with given fields: email, code, token
 func (_m *IEmailService) SendVerificationEmail(email string, code string, token string) error {
 	ret := _m.Called(email, code, token)
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssignmentExtension perpanjangan deadline assignment untuk siswa tertentu
+type AssignmentExtension struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AssignmentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_assignment_extension_user"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_assignment_extension_user"`
+	EndAt        time.Time `gorm:"type:timestamptz;not null"`
+	Reason       *string   `gorm:"type:text"`
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Assignment Assignment `gorm:"foreignKey:AssignmentID;constraint:OnDelete:CASCADE"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,15 +9,55 @@ import (
 
 // AssignmentGrade represents the assignment_grades table
 type AssignmentGrade struct {
-	ID                     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	AssignmentSubmissionID uuid.UUID `gorm:"type:uuid;not null"` // Foreign key ke assignment_submissions.id
-	Grade                  int       `gorm:"not null"`
-	Feedback               string    `gorm:"type:text"`
-	GradedBy               uuid.UUID `gorm:"type:uuid;not null"` // Foreign key ke users.id
+	ID                     uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AssignmentSubmissionID uuid.UUID  `gorm:"type:uuid;not null"` // Foreign key ke assignment_submissions.id
+	Grade                  int        `gorm:"not null"`           // nilai mentah dari guru / rubrik
+	Feedback               string     `gorm:"type:text"`
+	GradedBy               uuid.UUID  `gorm:"type:uuid;not null"` // Foreign key ke users.id
+	RubricID               *uuid.UUID `gorm:"type:uuid"`          // nil = dinilai tanpa rubrik
+	RubricPoints           *int
+	RubricMaxPoints        *int
+	LatePenaltyPercent     float64  `gorm:"not null;default:0"`
+	PenalizedGrade         *int     // nilai setelah potongan telat, nil = data lama (sama dengan Grade)
+	Version                *int     // versi submission yang dinilai
+	PeerScore              *float64 // rata-rata nilai peer review setelah outlier dibuang
+	PeerWeight             float64  `gorm:"not null;default:0"` // persen nilai peer di nilai akhir
+	// Moderasi nilai ujian, nilai tugas biasa langsung released
+	ModerationStatus  GradeModerationStatus `gorm:"type:grade_moderation_status;not null;default:'released'"`
+	ModeratorID       *uuid.UUID            `gorm:"type:uuid"`
+	ModeratedAt       *time.Time
+	ModerationComment *string `gorm:"type:text"`
+	OriginalGrade     *int    // nilai penilai pertama sebelum disesuaikan moderator
+	ModerationFlagged bool    `gorm:"not null;default:false"` // selisih besar, rilis harus oleh admin
+	ReleasedAt        *time.Time
 	// GradedAt               time.Time `gorm:"type:timestamp;not null"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	AssignmentSubmission AssignmentSubmission `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	GradedByUser         User                 `gorm:"foreignKey:GradedBy"`
+	AssignmentSubmission AssignmentSubmission        `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	GradedByUser         User                        `gorm:"foreignKey:GradedBy"`
+	Moderator            *User                       `gorm:"foreignKey:ModeratorID"`
+	Criteria             []AssignmentGradeCriterion  `gorm:"foreignKey:GradeID;constraint:OnDelete:CASCADE"`
+	Adjustments          []AssignmentGradeAdjustment `gorm:"foreignKey:GradeID;constraint:OnDelete:CASCADE"`
+}
+
+// BlendedGrade nilai guru dicampur nilai peer sesuai bobot, sebelum potongan telat
+func (g AssignmentGrade) BlendedGrade() int {
+	if g.PeerScore == nil || g.PeerWeight <= 0 {
+		return g.Grade
+	}
+	return int(math.Round(float64(g.Grade)*(100-g.PeerWeight)/100 + *g.PeerScore*g.PeerWeight/100))
+}
+
+// EffectiveGrade nilai yang dihitung ke nilai akhir siswa
+func (g AssignmentGrade) EffectiveGrade() int {
+	if g.PenalizedGrade != nil {
+		return *g.PenalizedGrade
+	}
+	return g.Grade
+}
+
+// IsReleased nilai sudah boleh dilihat siswa, data lama tanpa status dianggap released
+func (g AssignmentGrade) IsReleased() bool {
+	return g.ModerationStatus == "" || g.ModerationStatus == GradeReleased
 }
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"math"
+	"time"
+)
+
+// LatePenaltyUnit satuan waktu potongan nilai telat
+type LatePenaltyUnit string
+
+const (
+	// LatePenaltyPerHour potongan dihitung per jam (dibulatkan ke atas)
+	LatePenaltyPerHour LatePenaltyUnit = "hour"
+	// LatePenaltyPerDay potongan dihitung per hari (dibulatkan ke atas)
+	LatePenaltyPerDay LatePenaltyUnit = "day"
+)
+
+// Scan implements the Scanner interface
+func (u *LatePenaltyUnit) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*u = LatePenaltyUnit(string(v))
+		return nil
+	case string:
+		*u = LatePenaltyUnit(v)
+		return nil
+	}
+	return errors.New("failed to scan LatePenaltyUnit: invalid type")
+}
+
+// Value implements the Valuer interface
+func (u LatePenaltyUnit) Value() (driver.Value, error) {
+	return string(u), nil
+}
+
+// Minutes panjang satu satuan dalam menit
+func (u LatePenaltyUnit) Minutes() int {
+	if u == LatePenaltyPerHour {
+		return 60
+	}
+	return 24 * 60
+}
+
+var (
+	// ErrHardDeadline assignment tidak menerima pengumpulan setelah deadline
+	ErrHardDeadline = errors.New("deadline sudah lewat, assignment ini tidak menerima pengumpulan telat")
+	// ErrLateCutoff batas akhir pengumpulan telat sudah lewat
+	ErrLateCutoff = errors.New("batas akhir pengumpulan telat sudah lewat")
+)
+
+// LateMinutes berapa menit submittedAt melewati deadline (EndAt atau perpanjangan siswa).
+// Error kalau assignment memakai hard deadline atau sudah lewat LateCutoffAt.
+func (a *Assignment) LateMinutes(submittedAt, deadline time.Time) (int, error) {
+	if !submittedAt.After(deadline) {
+		return 0, nil
+	}
+	if a.HardDeadline {
+		return 0, ErrHardDeadline
+	}
+	if a.LateCutoffAt != nil && submittedAt.After(*a.LateCutoffAt) {
+		return 0, ErrLateCutoff
+	}
+	return int(math.Ceil(submittedAt.Sub(deadline).Minutes())), nil
+}
+
+// LatePenalty persen potongan untuk keterlambatan lateMinutes, setelah dikurangi masa tenggang. Maksimal 100.
+func (a *Assignment) LatePenalty(lateMinutes int) float64 {
+	late := lateMinutes - a.LateGraceMinutes
+	if late <= 0 || a.LatePenaltyPercent <= 0 {
+		return 0
+	}
+	unit := a.LatePenaltyUnit.Minutes()
+	units := (late + unit - 1) / unit
+	return math.Min(float64(units)*a.LatePenaltyPercent, 100)
+}
+
+// ApplyLatePenalty nilai setelah potongan persen. Nilai tidak turun di bawah LatePenaltyFloor,
+// kecuali nilai mentahnya memang sudah lebih rendah.
+func (a *Assignment) ApplyLatePenalty(grade int, penaltyPercent float64) int {
+	if penaltyPercent <= 0 {
+		return grade
+	}
+	penalized := int(math.Round(float64(grade) * (100 - penaltyPercent) / 100))
+	if floor := min(grade, a.LatePenaltyFloor); penalized < floor {
+		return floor
+	}
+	return penalized
+}
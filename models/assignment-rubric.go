@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rubric template penilaian (kriteria × level) milik guru, bisa dipasang ke banyak assignment
+type Rubric struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Title       string    `gorm:"type:varchar(255);not null"`
+	Description *string   `gorm:"type:text"`
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Criteria []RubricCriterion `gorm:"foreignKey:RubricID;constraint:OnDelete:CASCADE"`
+}
+
+// RubricCriterion satu kriteria rubrik, mis. "Ketepatan perhitungan"
+type RubricCriterion struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	RubricID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Title       string    `gorm:"type:varchar(255);not null"`
+	Description *string   `gorm:"type:text"`
+	Order       int       `gorm:"not null;default:0"`
+
+	Levels []RubricLevel `gorm:"foreignKey:CriterionID;constraint:OnDelete:CASCADE"`
+}
+
+// RubricLevel tingkat capaian di satu kriteria beserta poinnya
+type RubricLevel struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CriterionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Title       string    `gorm:"type:varchar(255);not null"`
+	Description *string   `gorm:"type:text"`
+	Points      int       `gorm:"not null"`
+	Order       int       `gorm:"not null;default:0"`
+}
+
+// MaxPoints poin tertinggi yang bisa didapat di kriteria ini
+func (c RubricCriterion) MaxPoints() int {
+	max := 0
+	for _, l := range c.Levels {
+		if l.Points > max {
+			max = l.Points
+		}
+	}
+	return max
+}
+
+// AssignmentGradeCriterion pilihan level per kriteria saat menilai. Judul & poin disalin
+// supaya rincian nilai tetap utuh walau rubriknya diedit belakangan.
+type AssignmentGradeCriterion struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	GradeID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	CriterionID    uuid.UUID `gorm:"type:uuid;not null"`
+	LevelID        uuid.UUID `gorm:"type:uuid;not null"`
+	CriterionTitle string    `gorm:"type:varchar(255);not null"`
+	LevelTitle     string    `gorm:"type:varchar(255);not null"`
+	Points         int       `gorm:"not null"`
+	MaxPoints      int       `gorm:"not null"`
+	Comment        *string   `gorm:"type:text"`
+	Order          int       `gorm:"not null;default:0"`
+}
@@ -9,20 +9,30 @@ import (
 // AssignmentSubmission represents the assignment_submissions table
 type AssignmentSubmission struct {
 	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	AssignmentID uuid.UUID `gorm:"type:uuid;not null"`
+	AssignmentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_submission_assignment_group"`
 
-	UserID uuid.UUID `gorm:"type:uuid;not null"`
+	UserID  uuid.UUID  `gorm:"type:uuid;not null"`                                    // yang mengumpulkan
+	GroupID *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_submission_assignment_group"` // diisi untuk assignment kelompok
 
 	Note      *string `gorm:"type:text"`
 	EssayText *string `gorm:"type:text"`
 	// SubmittedAt time.Time `gorm:"type:timestamp"`
-	IsLate bool `gorm:"not null"`
+	IsLate      bool `gorm:"not null"`
+	LateMinutes int  `gorm:"not null;default:0"` // keterlambatan dari deadline siswa, dasar potongan nilai
+	Version     int  `gorm:"not null;default:1"` // nomor versi terbaru
+
+	// Dikembalikan guru untuk revisi: siswa boleh kumpul ulang walau deadline sudah lewat
+	RevisionRequested bool       `gorm:"not null;default:false"`
+	RevisionNote      *string    `gorm:"type:text"`
+	RevisionDueAt     *time.Time `gorm:"type:timestamptz"` // nil = tanpa batas
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Assignment      Assignment       `gorm:"foreignKey:AssignmentID;references:ID"` // Relasi ke Assignment
-	User            User             `gorm:"foreignKey:UserID;references:ID"`       // Relasi ke User
-	SubmissionFiles []SubmissionFile `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	AssignmentGrade *AssignmentGrade `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Assignment      Assignment          `gorm:"foreignKey:AssignmentID;references:ID"` // Relasi ke Assignment
+	User            User                `gorm:"foreignKey:UserID;references:ID"`       // Relasi ke User
+	Group           *StudentGroup       `gorm:"foreignKey:GroupID;constraint:OnDelete:SET NULL;"`
+	SubmissionFiles []SubmissionFile    `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	AssignmentGrade *AssignmentGrade    `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Versions        []SubmissionVersion `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnDelete:CASCADE;"`
 }
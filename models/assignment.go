@@ -16,12 +16,38 @@ type Assignment struct {
 	Type        AssignmentType `gorm:"type:assignment_type;not null"`
 	StartAt     time.Time      `gorm:"type:timestamptz"`
 	EndAt       time.Time      `gorm:"type:timestamptz"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	RubricID    *uuid.UUID     `gorm:"type:uuid;index"`
+
+	// Aturan pengumpulan telat
+	HardDeadline       bool            `gorm:"not null;default:false"` // tolak submission setelah deadline
+	LateGraceMinutes   int             `gorm:"not null;default:0"`     // telat dalam masa tenggang tidak dipotong
+	LatePenaltyPercent float64         `gorm:"not null;default:0"`     // potongan per LatePenaltyUnit
+	LatePenaltyUnit    LatePenaltyUnit `gorm:"type:late_penalty_unit;not null;default:'day'"`
+	LatePenaltyFloor   int             `gorm:"not null;default:0"` // nilai minimal setelah potongan
+	LateCutoffAt       *time.Time      `gorm:"type:timestamptz"`   // setelah ini submission telat ditolak
+
+	// Aturan file submission, kosong/0 = pakai batasan umum
+	AllowedFileTypes []string `gorm:"type:jsonb;serializer:json"` // ekstensi, mis. [".pdf", ".docx"]
+	MaxFileSizeMB    int      `gorm:"not null;default:0"`
+	MinFiles         int      `gorm:"not null;default:0"`
+	MaxFiles         int      `gorm:"not null;default:0"`
+
+	GroupSubmission bool `gorm:"not null;default:false"` // satu anggota kelompok mengumpulkan untuk kelompoknya
+
+	// Peer review: setelah deadline tiap submission dinilai anonim oleh PeerReviewCount siswa lain
+	PeerReviewEnabled       bool       `gorm:"not null;default:false"`
+	PeerReviewCount         int        `gorm:"not null;default:0"`
+	PeerReviewEndAt         *time.Time `gorm:"type:timestamptz"`   // batas mengisi review, nil = tanpa batas
+	PeerReviewWeight        float64    `gorm:"not null;default:0"` // persen nilai akhir dari nilai peer
+	PeerReviewDistributedAt *time.Time `gorm:"type:timestamptz"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
 
 	Meeting         Meeting           `gorm:"foreignKey:MeetingID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Teacher         *User             `gorm:"foreignKey:TeacherID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	AssignmentFiles []AssignmentFiles `gorm:"foreignKey:AssignmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Rubric          *Rubric           `gorm:"foreignKey:RubricID;constraint:OnDelete:SET NULL;"`
 
 	AssignmentSubmissions []AssignmentSubmission `gorm:"foreignKey:AssignmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
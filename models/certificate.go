@@ -1,22 +1,65 @@
 package models
 
 import (
+	"database/sql/driver"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// CertificateStatus status keabsahan sertifikat
+type CertificateStatus string
+
+const (
+	// CertificateActive sertifikat berlaku
+	CertificateActive CertificateStatus = "active"
+	// CertificateRevoked sertifikat dicabut admin
+	CertificateRevoked CertificateStatus = "revoked"
+	// CertificateSuperseded sertifikat diganti hasil terbit ulang
+	CertificateSuperseded CertificateStatus = "superseded"
+)
+
+// Scan implements the Scanner interface
+func (s *CertificateStatus) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*s = CertificateStatus(string(v))
+		return nil
+	case string:
+		*s = CertificateStatus(v)
+		return nil
+	}
+	return errors.New("failed to scan CertificateStatus: invalid type")
+}
+
+// Value implements the Valuer interface
+func (s CertificateStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
+
 // Certificate for table certificates
 type Certificate struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	BatchID   uuid.UUID `gorm:"type:uuid;not null"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null"`
-	Number    string    `gorm:"type:varchar(50);uniqueIndex;not null"` // nomor sertifikat resmi
-	URL       string    `gorm:"type:text;not null"`
-	QRCode    string    `gorm:"type:text;not null"`
+	ID        uuid.UUID         `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	BatchID   uuid.UUID         `gorm:"type:uuid;not null"`
+	UserID    uuid.UUID         `gorm:"type:uuid;not null"`
+	Number    string            `gorm:"type:varchar(50);uniqueIndex;not null"` // nomor sertifikat resmi
+	URL       string            `gorm:"type:text;not null"`
+	QRCode    string            `gorm:"type:text;not null"`
+	Status    CertificateStatus `gorm:"type:certificate_status;not null;default:'active'"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Batch *Batch `gorm:"foreignKey:BatchID;references:ID"`
-	User  *User  `gorm:"foreignKey:UserID;references:ID"`
+	// Pencabutan oleh admin
+	RevokedAt        *time.Time
+	RevokedBy        *uuid.UUID `gorm:"type:uuid"`
+	RevocationReason *string    `gorm:"type:text"`
+
+	// Terbit ulang: sertifikat lama menunjuk ke penggantinya
+	SupersededByID *uuid.UUID `gorm:"type:uuid;index"`
+	ReissueReason  *string    `gorm:"type:text"`
+
+	Batch        *Batch       `gorm:"foreignKey:BatchID;references:ID"`
+	User         *User        `gorm:"foreignKey:UserID;references:ID"`
+	SupersededBy *Certificate `gorm:"foreignKey:SupersededByID;references:ID"`
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// GradeModerationStatus tahap moderasi nilai ujian oleh penilai kedua
+type GradeModerationStatus string
+
+const (
+	// GradeDraft nilai masih dikerjakan penilai pertama
+	GradeDraft GradeModerationStatus = "draft"
+	// GradeSubmitted nilai dikirim, menunggu penilai kedua
+	GradeSubmitted GradeModerationStatus = "submitted"
+	// GradeModerated penilai kedua sudah setuju / menyesuaikan nilai
+	GradeModerated GradeModerationStatus = "moderated"
+	// GradeReleased nilai sudah bisa dilihat siswa
+	GradeReleased GradeModerationStatus = "released"
+)
+
+// ModerationDiscrepancyThreshold selisih nilai penilai pertama & kedua yang ditandai untuk dicek admin
+const ModerationDiscrepancyThreshold = 10
+
+// Scan implements the Scanner interface
+func (s *GradeModerationStatus) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*s = GradeModerationStatus(string(v))
+		return nil
+	case string:
+		*s = GradeModerationStatus(v)
+		return nil
+	}
+	return errors.New("failed to scan GradeModerationStatus: invalid type")
+}
+
+// Value implements the Valuer interface
+func (s GradeModerationStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PeerReview satu penugasan review anonim: Reviewer menilai submission siswa lain
+type PeerReview struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AssignmentID uuid.UUID `gorm:"type:uuid;not null;index"`
+	SubmissionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_peer_review_reviewer"`
+	ReviewerID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_peer_review_reviewer;index"`
+
+	Score           *int // 0-100, dari rubrik kalau assignment memakai rubrik
+	RubricPoints    *int
+	RubricMaxPoints *int
+	Comment         *string    `gorm:"type:text"`
+	SubmittedAt     *time.Time `gorm:"type:timestamptz"`
+	Excluded        bool       `gorm:"not null;default:false"` // dikeluarkan guru, tidak dihitung ke nilai
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Assignment Assignment            `gorm:"foreignKey:AssignmentID;constraint:OnDelete:CASCADE"`
+	Submission AssignmentSubmission  `gorm:"foreignKey:SubmissionID;constraint:OnDelete:CASCADE"`
+	Reviewer   User                  `gorm:"foreignKey:ReviewerID;constraint:OnDelete:CASCADE"`
+	Criteria   []PeerReviewCriterion `gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE"`
+}
+
+// PeerReviewCriterion pilihan level rubrik dari reviewer, judul & poin disalin seperti AssignmentGradeCriterion
+type PeerReviewCriterion struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ReviewID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	CriterionID    uuid.UUID `gorm:"type:uuid;not null"`
+	LevelID        uuid.UUID `gorm:"type:uuid;not null"`
+	CriterionTitle string    `gorm:"type:varchar(255);not null"`
+	LevelTitle     string    `gorm:"type:varchar(255);not null"`
+	Points         int       `gorm:"not null"`
+	MaxPoints      int       `gorm:"not null"`
+	Comment        *string   `gorm:"type:text"`
+	Order          int       `gorm:"not null;default:0"`
+}
+
+// PeerGradeWeight bobot (persen) nilai peer di nilai akhir, 0 kalau peer review tidak aktif
+func (a *Assignment) PeerGradeWeight() float64 {
+	if !a.PeerReviewEnabled {
+		return 0
+	}
+	return a.PeerReviewWeight
+}
+
+// PeerReviewOpen review masih bisa diisi pada waktu now
+func (a *Assignment) PeerReviewOpen(now time.Time) bool {
+	if !a.PeerReviewEnabled || a.PeerReviewDistributedAt == nil {
+		return false
+	}
+	return a.PeerReviewEndAt == nil || !now.After(*a.PeerReviewEndAt)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizAccommodation override waktu & attempt quiz untuk siswa tertentu
+type QuizAccommodation struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	QuizID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_quiz_accommodation_user"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_quiz_accommodation_user"`
+
+	ExtraMinutes  int        `gorm:"not null;default:0"` // tambahan durasi pengerjaan
+	StartTime     *time.Time // jendela alternatif, nil = ikut quiz
+	EndTime       *time.Time
+	ExtraAttempts int     `gorm:"not null;default:0"` // tambahan di luar MaxAttempts
+	Reason        *string `gorm:"type:text"`
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Quiz Quiz `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE"`
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
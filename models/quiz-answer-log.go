@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizAnswerLog riwayat setiap perubahan jawaban sementara (autosave) dalam attempt
+type QuizAnswerLog struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AttemptID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	QuestionID       uuid.UUID  `gorm:"type:uuid;not null"`
+	SelectedOptionID uuid.UUID  `gorm:"type:uuid;not null"`
+	PreviousOptionID *uuid.UUID `gorm:"type:uuid"` // nil = jawaban pertama untuk soal ini
+	IPAddress        string     `gorm:"type:varchar(45)"`
+	UserAgent        string     `gorm:"type:text"`
+
+	CreatedAt time.Time
+
+	Attempt QuizAttempt `gorm:"foreignKey:AttemptID;constraint:OnDelete:CASCADE"`
+}
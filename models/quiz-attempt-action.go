@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttemptActionType jenis tindakan guru/admin terhadap sebuah attempt
+type AttemptActionType string
+
+const (
+	// AttemptActionExtend tambah waktu attempt yang sedang berjalan
+	AttemptActionExtend AttemptActionType = "extend"
+	// AttemptActionPause jeda attempt, waktu jeda tidak dihitung ke durasi
+	AttemptActionPause AttemptActionType = "pause"
+	// AttemptActionResume lanjutkan attempt yang dijeda
+	AttemptActionResume AttemptActionType = "resume"
+	// AttemptActionReopen buka kembali attempt yang sudah disubmit
+	AttemptActionReopen AttemptActionType = "reopen"
+	// AttemptActionVoid batalkan attempt, tidak dihitung ke MaxAttempts & nilai
+	AttemptActionVoid AttemptActionType = "void"
+)
+
+// Scan implements the Scanner interface
+func (t *AttemptActionType) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*t = AttemptActionType(string(v))
+		return nil
+	case string:
+		*t = AttemptActionType(v)
+		return nil
+	}
+	return errors.New("failed to scan AttemptActionType: invalid type")
+}
+
+// Value implements the Valuer interface
+func (t AttemptActionType) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// QuizAttemptAction audit tindakan guru/admin terhadap attempt siswa
+type QuizAttemptAction struct {
+	ID        uuid.UUID         `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AttemptID uuid.UUID         `gorm:"type:uuid;not null;index"`
+	ActorID   uuid.UUID         `gorm:"type:uuid;not null"`
+	Action    AttemptActionType `gorm:"type:attempt_action_type;not null"`
+	Reason    string            `gorm:"type:text;not null"`
+	Minutes   *int              // menit tambahan untuk extend / reopen
+
+	CreatedAt time.Time
+
+	Attempt QuizAttempt `gorm:"foreignKey:AttemptID;constraint:OnDelete:CASCADE"`
+	Actor   User        `gorm:"foreignKey:ActorID"`
+}
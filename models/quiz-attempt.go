@@ -14,11 +14,20 @@ type QuizAttempt struct {
 	StartedAt time.Time  `gorm:"not null"`     // Waktu user mulai quiz
 	EndedAt   *time.Time `gorm:"default:null"` // Waktu selesai, diisi saat submit
 
+	ExtraMinutes  int        `gorm:"not null;default:0"` // Perpanjangan waktu dari guru untuk attempt ini
+	PausedAt      *time.Time `gorm:"default:null"`       // Diisi selama attempt dijeda guru
+	PausedSeconds int        `gorm:"not null;default:0"` // Total waktu jeda, tidak dihitung ke durasi
+	VoidedAt      *time.Time `gorm:"default:null"`       // Attempt dibatalkan, tidak dihitung ke MaxAttempts & nilai
+
+	PaperVersionID *uuid.UUID `gorm:"type:uuid;index"` // Diisi untuk jawaban naskah cetak (ujian offline)
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
 	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	Quiz Quiz `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE"`
 
+	PaperVersion *QuizPaperVersion `gorm:"foreignKey:PaperVersionID;constraint:OnDelete:SET NULL"`
+
 	Submissions []QuizSubmission `gorm:"foreignKey:AttemptID"`
 }
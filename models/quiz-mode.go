@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// QuizMode fungsi quiz dalam batch
+type QuizMode string
+
+const (
+	// QuizModeGraded quiz biasa, dihitung ke nilai, progress & sertifikat
+	QuizModeGraded QuizMode = "graded"
+	// QuizModePractice latihan tanpa batas attempt, pembahasan langsung terbuka
+	QuizModePractice QuizMode = "practice"
+	// QuizModeSurvey kuesioner tanpa kunci jawaban
+	QuizModeSurvey QuizMode = "survey"
+)
+
+// CountsTowardGrade hanya quiz graded yang masuk nilai, progress, sertifikat & prasyarat meeting
+func (m QuizMode) CountsTowardGrade() bool {
+	return m == "" || m == QuizModeGraded
+}
+
+// Scan implements the Scanner interface
+func (m *QuizMode) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*m = QuizMode(string(v))
+		return nil
+	case string:
+		*m = QuizMode(v)
+		return nil
+	}
+	return errors.New("failed to scan QuizMode: invalid type")
+}
+
+// Value implements the Valuer interface
+func (m QuizMode) Value() (driver.Value, error) {
+	return string(m), nil
+}
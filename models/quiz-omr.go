@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OmrScanStatus status hasil scan lembar jawaban (LJK)
+type OmrScanStatus string
+
+const (
+	// OmrScanGraded terbaca dengan yakin dan langsung dinilai
+	OmrScanGraded OmrScanStatus = "graded"
+	// OmrScanReview keyakinan rendah, menunggu koreksi manual
+	OmrScanReview OmrScanStatus = "review"
+	// OmrScanResolved sudah dikoreksi manual lalu dinilai
+	OmrScanResolved OmrScanStatus = "resolved"
+	// OmrScanRejected dibuang saat review (scan rusak / duplikat)
+	OmrScanRejected OmrScanStatus = "rejected"
+)
+
+// Scan implements the Scanner interface
+func (s *OmrScanStatus) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*s = OmrScanStatus(string(v))
+		return nil
+	case string:
+		*s = OmrScanStatus(v)
+		return nil
+	}
+	return errors.New("failed to scan OmrScanStatus: invalid type")
+}
+
+// Value implements the Valuer interface
+func (s OmrScanStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
+
+// QuizAnswerSheet LJK milik satu siswa untuk quiz tertentu; Serial dicetak sebagai kode biner di lembar
+type QuizAnswerSheet struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	QuizID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_quiz_answer_sheet_user"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_quiz_answer_sheet_user"`
+	VersionID uuid.UUID `gorm:"type:uuid;not null"`
+	Serial    int64     `gorm:"not null;uniqueIndex"`
+
+	CreatedAt time.Time
+
+	Quiz    Quiz             `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE"`
+	User    User             `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Version QuizPaperVersion `gorm:"foreignKey:VersionID;constraint:OnDelete:CASCADE"`
+}
+
+// QuizOmrScan satu halaman hasil scan LJK beserta status pembacaannya
+type QuizOmrScan struct {
+	ID         uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	QuizID     uuid.UUID     `gorm:"type:uuid;not null;index"`
+	SheetID    *uuid.UUID    `gorm:"type:uuid;index"` // nil = kode lembar tidak terbaca
+	ImageURL   string        `gorm:"type:varchar;not null"`
+	Status     OmrScanStatus `gorm:"type:omr_scan_status;not null"`
+	Confidence float64       `gorm:"not null;default:0"` // 0-1, keyakinan terendah dari semua tanda yang dibaca
+	Issues     *string       `gorm:"type:text"`
+	AttemptID  *uuid.UUID    `gorm:"type:uuid"` // attempt hasil penilaian
+
+	UploadedBy uuid.UUID  `gorm:"type:uuid;not null"`
+	ReviewedBy *uuid.UUID `gorm:"type:uuid"`
+	ReviewedAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Quiz    Quiz                `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE"`
+	Sheet   *QuizAnswerSheet    `gorm:"foreignKey:SheetID;constraint:OnDelete:SET NULL"`
+	Attempt *QuizAttempt        `gorm:"foreignKey:AttemptID;constraint:OnDelete:SET NULL"`
+	Answers []QuizOmrScanAnswer `gorm:"foreignKey:ScanID;constraint:OnDelete:CASCADE"`
+}
+
+// QuizOmrScanAnswer bacaan satu nomor pada scan LJK
+type QuizOmrScanAnswer struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ScanID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	Number     int       `gorm:"not null"`
+	Label      string    `gorm:"type:varchar(1);not null;default:''"` // kosong = tidak dijawab / tidak jelas
+	Confidence float64   `gorm:"not null;default:0"`
+	Ambiguous  bool      `gorm:"not null;default:false"` // lebih dari satu bulatan / bulatan setengah terisi
+}
@@ -10,7 +10,8 @@ import (
 type QuizOption struct {
 	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
 	QuestionID uuid.UUID `gorm:"type:uuid;not null;index"`
-	OptionText string    `gorm:"type:text;not null"`
+	OptionText string    `gorm:"type:text;not null"` // markdown terbatas, boleh kosong kalau ada gambar
+	ImageURL   *string   `gorm:"type:varchar"`       // gambar opsi hasil upload
 	IsCorrect  bool      `gorm:"not null"`
 
 	CreatedAt time.Time
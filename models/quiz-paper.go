@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizPaperVersion satu versi naskah cetak quiz (A, B, C, ...) untuk ujian offline
+type QuizPaperVersion struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	QuizID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_quiz_paper_version_code"`
+	Code      string    `gorm:"type:varchar(2);not null;uniqueIndex:idx_quiz_paper_version_code"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+
+	CreatedAt time.Time
+
+	Quiz      Quiz                `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE"`
+	Questions []QuizPaperQuestion `gorm:"foreignKey:VersionID;constraint:OnDelete:CASCADE"`
+}
+
+// QuizPaperQuestion nomor soal pada naskah versi tertentu
+type QuizPaperQuestion struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	VersionID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	Number     int       `gorm:"not null"` // nomor soal di naskah, mulai dari 1
+	QuestionID uuid.UUID `gorm:"type:uuid;not null"`
+
+	Question QuizQuestion      `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	Options  []QuizPaperOption `gorm:"foreignKey:PaperQuestionID;constraint:OnDelete:CASCADE"`
+}
+
+// QuizPaperOption huruf pilihan (A, B, C, ...) untuk sebuah opsi di naskah versi tertentu
+type QuizPaperOption struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	PaperQuestionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Label           string    `gorm:"type:varchar(1);not null"`
+	OptionID        uuid.UUID `gorm:"type:uuid;not null"`
+
+	Option QuizOption `gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProctorEventType jenis kejadian integritas yang dilaporkan client selama attempt
+type ProctorEventType string
+
+const (
+	// ProctorTabBlur siswa pindah tab / window kehilangan fokus
+	ProctorTabBlur ProctorEventType = "tab_blur"
+	// ProctorFullscreenExit siswa keluar dari mode fullscreen
+	ProctorFullscreenExit ProctorEventType = "fullscreen_exit"
+	// ProctorCopy siswa menyalin teks
+	ProctorCopy ProctorEventType = "copy"
+	// ProctorPaste siswa menempel teks
+	ProctorPaste ProctorEventType = "paste"
+	// ProctorReconnect koneksi client tersambung ulang
+	ProctorReconnect ProctorEventType = "reconnect"
+)
+
+// Scan implements the Scanner interface
+func (t *ProctorEventType) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*t = ProctorEventType(string(v))
+		return nil
+	case string:
+		*t = ProctorEventType(v)
+		return nil
+	}
+	return errors.New("failed to scan ProctorEventType: invalid type")
+}
+
+// Value implements the Valuer interface
+func (t ProctorEventType) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// QuizProctorEvent satu kejadian integritas selama attempt berlangsung
+type QuizProctorEvent struct {
+	ID        uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AttemptID uuid.UUID        `gorm:"type:uuid;not null;index"`
+	Type      ProctorEventType `gorm:"type:proctor_event_type;not null"`
+	Detail    *string          `gorm:"type:text"`
+	IPAddress string           `gorm:"type:varchar(45)"` // IPv4/IPv6
+	UserAgent string           `gorm:"type:text"`
+
+	OccurredAt time.Time `gorm:"not null"` // waktu kejadian menurut client (dibatasi waktu server)
+	CreatedAt  time.Time
+
+	Attempt QuizAttempt `gorm:"foreignKey:AttemptID;constraint:OnDelete:CASCADE"`
+}
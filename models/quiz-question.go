@@ -8,9 +8,13 @@ import (
 
 // QuizQuestion adalah soal individual di dalam satu Quiz
 type QuizQuestion struct {
-	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	QuizID   uuid.UUID `gorm:"type:uuid;not null;index"`
-	Question string    `gorm:"type:text;not null"`
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	QuizID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	Question    string    `gorm:"type:text;not null"` // markdown terbatas (tabel & rumus LaTeX)
+	ImageURL    *string   `gorm:"type:varchar"`       // gambar soal hasil upload
+	Explanation *string   `gorm:"type:text"`          // pembahasan, hanya tampil saat review full_solution dibuka
+
+	ScoringMode QuestionScoringMode `gorm:"type:question_scoring_mode;not null;default:'normal'"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizRegradeLog mencatat setiap regrade quiz (koreksi kunci, void, free credit)
+type QuizRegradeLog struct {
+	ID         uuid.UUID           `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	QuizID     uuid.UUID           `gorm:"type:uuid;not null;index"`
+	QuestionID *uuid.UUID          `gorm:"type:uuid"` // nil = regrade seluruh quiz
+	ActorID    uuid.UUID           `gorm:"type:uuid;not null"`
+	Mode       QuestionScoringMode `gorm:"type:question_scoring_mode"`
+	Reason     *string             `gorm:"type:text"`
+
+	AffectedAttempts int `gorm:"not null;default:0"`
+	ChangedAttempts  int `gorm:"not null;default:0"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Quiz  Quiz                `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE"`
+	Actor User                `gorm:"foreignKey:ActorID"`
+	Items []QuizRegradeChange `gorm:"foreignKey:RegradeLogID"`
+}
+
+// QuizRegradeChange perubahan skor satu attempt akibat regrade
+type QuizRegradeChange struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	RegradeLogID uuid.UUID `gorm:"type:uuid;not null;index"`
+	AttemptID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null"`
+
+	OldScorePercent float64 `gorm:"not null"`
+	NewScorePercent float64 `gorm:"not null"`
+	OldCorrect      int     `gorm:"not null"`
+	NewCorrect      int     `gorm:"not null"`
+
+	// PendingNotice email perubahan nilai belum dikirim, menunggu review quiz dibuka untuk siswa
+	PendingNotice bool `gorm:"not null;default:false;index"`
+
+	CreatedAt time.Time
+
+	RegradeLog QuizRegradeLog `gorm:"foreignKey:RegradeLogID;constraint:OnDelete:CASCADE"`
+	User       User           `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
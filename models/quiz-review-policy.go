@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// QuizReviewLevel seberapa banyak detail hasil quiz yang boleh dilihat siswa
+type QuizReviewLevel string
+
+const (
+	// ReviewScoreOnly hanya nilai
+	ReviewScoreOnly QuizReviewLevel = "score_only"
+	// ReviewWrongAnswers nilai + tanda soal yang dijawab salah
+	ReviewWrongAnswers QuizReviewLevel = "wrong_answers"
+	// ReviewFullSolution nilai + kunci jawaban + pembahasan
+	ReviewFullSolution QuizReviewLevel = "full_solution"
+)
+
+// Scan implements the Scanner interface
+func (l *QuizReviewLevel) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*l = QuizReviewLevel(string(v))
+		return nil
+	case string:
+		*l = QuizReviewLevel(v)
+		return nil
+	}
+	return errors.New("failed to scan QuizReviewLevel: invalid type")
+}
+
+// Value implements the Valuer interface
+func (l QuizReviewLevel) Value() (driver.Value, error) {
+	return string(l), nil
+}
+
+// QuizReviewRelease kapan hasil quiz dibuka untuk siswa
+type QuizReviewRelease string
+
+const (
+	// ReleaseImmediate langsung setelah attempt selesai
+	ReleaseImmediate QuizReviewRelease = "immediate"
+	// ReleaseAfterEnd setelah EndTime quiz (termasuk jendela accommodation)
+	ReleaseAfterEnd QuizReviewRelease = "after_end"
+	// ReleaseManual dibuka manual oleh guru
+	ReleaseManual QuizReviewRelease = "manual"
+)
+
+// Scan implements the Scanner interface
+func (r *QuizReviewRelease) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*r = QuizReviewRelease(string(v))
+		return nil
+	case string:
+		*r = QuizReviewRelease(v)
+		return nil
+	}
+	return errors.New("failed to scan QuizReviewRelease: invalid type")
+}
+
+// Value implements the Valuer interface
+func (r QuizReviewRelease) Value() (driver.Value, error) {
+	return string(r), nil
+}
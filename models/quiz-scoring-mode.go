@@ -0,0 +1,36 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// QuestionScoringMode cara penilaian sebuah soal
+type QuestionScoringMode string
+
+const (
+	// ScoringNormal dinilai sesuai kunci jawaban
+	ScoringNormal QuestionScoringMode = "normal"
+	// ScoringVoid soal dibatalkan, tidak dihitung di total
+	ScoringVoid QuestionScoringMode = "void"
+	// ScoringFreeCredit semua jawaban dianggap benar
+	ScoringFreeCredit QuestionScoringMode = "free_credit"
+)
+
+// Scan implements the Scanner interface
+func (m *QuestionScoringMode) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*m = QuestionScoringMode(string(v))
+		return nil
+	case string:
+		*m = QuestionScoringMode(v)
+		return nil
+	}
+	return errors.New("failed to scan QuestionScoringMode: invalid type")
+}
+
+// Value implements the Valuer interface
+func (m QuestionScoringMode) Value() (driver.Value, error) {
+	return string(m), nil
+}
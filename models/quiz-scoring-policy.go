@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// QuizScoringPolicy aturan attempt mana yang dihitung sebagai nilai akhir quiz
+type QuizScoringPolicy string
+
+const (
+	// ScoringPolicyHighest nilai tertinggi
+	ScoringPolicyHighest QuizScoringPolicy = "highest"
+	// ScoringPolicyLatest nilai attempt terakhir
+	ScoringPolicyLatest QuizScoringPolicy = "latest"
+	// ScoringPolicyAverage rata-rata semua attempt
+	ScoringPolicyAverage QuizScoringPolicy = "average"
+	// ScoringPolicyFirst nilai attempt pertama
+	ScoringPolicyFirst QuizScoringPolicy = "first"
+)
+
+// Scan implements the Scanner interface
+func (p *QuizScoringPolicy) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*p = QuizScoringPolicy(string(v))
+		return nil
+	case string:
+		*p = QuizScoringPolicy(v)
+		return nil
+	}
+	return errors.New("failed to scan QuizScoringPolicy: invalid type")
+}
+
+// Value implements the Valuer interface
+func (p QuizScoringPolicy) Value() (driver.Value, error) {
+	return string(p), nil
+}
+
+// Aggregate hitung nilai akhir dari skor tiap attempt (urut dari attempt pertama).
+// Attempt ke-n dikurangi (n-1) * penaltyPercent poin, minimal 0.
+func (p QuizScoringPolicy) Aggregate(scores []float64, penaltyPercent float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+
+	adjusted := make([]float64, len(scores))
+	for i, score := range scores {
+		adjusted[i] = score - float64(i)*penaltyPercent
+		if adjusted[i] < 0 {
+			adjusted[i] = 0
+		}
+	}
+
+	switch p {
+	case ScoringPolicyLatest:
+		return adjusted[len(adjusted)-1]
+	case ScoringPolicyFirst:
+		return adjusted[0]
+	case ScoringPolicyAverage:
+		var sum float64
+		for _, v := range adjusted {
+			sum += v
+		}
+		return sum / float64(len(adjusted))
+	default:
+		best := adjusted[0]
+		for _, v := range adjusted[1:] {
+			if v > best {
+				best = v
+			}
+		}
+		return best
+	}
+}
@@ -13,6 +13,7 @@ type Quiz struct {
 	Title          string    `gorm:"type:text;not null"`
 	Description    *string   `gorm:"type:text"`
 	Type           QuizType  `gorm:"type:quiz_type;not null"`
+	Mode           QuizMode  `gorm:"type:quiz_mode;not null;default:'graded'"`
 	IsOpen         bool      `gorm:"not null;default:false"`
 	StartTime      time.Time
 	EndTime        time.Time
@@ -20,6 +21,14 @@ type Quiz struct {
 
 	MaxAttempts int `gorm:"not null;default:1"` // 1 = hanya sekali, >1 = multi-attempt
 
+	ScoringPolicy         QuizScoringPolicy `gorm:"type:quiz_scoring_policy;not null;default:'highest'"`
+	CooldownMinutes       int               `gorm:"not null;default:0"` // jeda minimal antar attempt
+	AttemptPenaltyPercent float64           `gorm:"not null;default:0"` // potongan nilai per attempt ulang
+
+	ReviewLevel      QuizReviewLevel   `gorm:"type:quiz_review_level;not null;default:'score_only'"`
+	ReviewRelease    QuizReviewRelease `gorm:"type:quiz_review_release;not null;default:'immediate'"`
+	ReviewReleasedAt *time.Time        // diisi saat guru membuka hasil secara manual
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
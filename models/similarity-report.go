@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SimilarityReportStatus status proses cek kemiripan
+type SimilarityReportStatus string
+
+const (
+	// SimilarityPending menunggu diproses
+	SimilarityPending SimilarityReportStatus = "pending"
+	// SimilarityRunning sedang membandingkan submission
+	SimilarityRunning SimilarityReportStatus = "running"
+	// SimilarityCompleted laporan siap dibaca
+	SimilarityCompleted SimilarityReportStatus = "completed"
+	// SimilarityFailed proses gagal, lihat Error
+	SimilarityFailed SimilarityReportStatus = "failed"
+)
+
+// Scan implements the Scanner interface
+func (s *SimilarityReportStatus) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*s = SimilarityReportStatus(string(v))
+		return nil
+	case string:
+		*s = SimilarityReportStatus(v)
+		return nil
+	}
+	return errors.New("failed to scan SimilarityReportStatus: invalid type")
+}
+
+// Value implements the Valuer interface
+func (s SimilarityReportStatus) Value() (driver.Value, error) {
+	return string(s), nil
+}
+
+// SimilarityReport satu kali cek kemiripan antar submission sebuah assignment
+type SimilarityReport struct {
+	ID                     uuid.UUID              `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AssignmentID           uuid.UUID              `gorm:"type:uuid;not null;index"`
+	Status                 SimilarityReportStatus `gorm:"type:similarity_report_status;not null"`
+	IncludePreviousBatches bool                   `gorm:"not null;default:false"`
+	MinScore               float64                `gorm:"not null"`               // pasangan di bawah skor ini tidak disimpan
+	Automatic              bool                   `gorm:"not null;default:false"` // dijalankan scheduler setelah deadline
+	Error                  *string                `gorm:"type:text"`
+
+	RequestedBy *uuid.UUID `gorm:"type:uuid"` // nil = scheduler
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Assignment Assignment           `gorm:"foreignKey:AssignmentID;constraint:OnDelete:CASCADE"`
+	Documents  []SimilarityDocument `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE"`
+	Pairs      []SimilarityPair     `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE"`
+}
+
+// SimilarityDocument teks submission yang dibandingkan, disimpan supaya offset passage tetap valid
+type SimilarityDocument struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ReportID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	SubmissionID uuid.UUID `gorm:"type:uuid;not null"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null"`
+	AssignmentID uuid.UUID `gorm:"type:uuid;not null"` // beda dengan report kalau dari batch sebelumnya
+	Previous     bool      `gorm:"not null;default:false"`
+	Text         string    `gorm:"type:text;not null"`
+	WordCount    int       `gorm:"not null"`
+	Skipped      *string   `gorm:"type:text"` // alasan tidak dibandingkan, mis. file tidak bisa dibaca
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// SimilarityPair skor kemiripan dua dokumen
+type SimilarityPair struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ReportID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	DocumentAID uuid.UUID `gorm:"type:uuid;not null"`
+	DocumentBID uuid.UUID `gorm:"type:uuid;not null"`
+	Score       float64   `gorm:"not null"`
+	ScoreA      float64   `gorm:"not null"` // porsi dokumen A yang ada di B
+	ScoreB      float64   `gorm:"not null"`
+
+	DocumentA SimilarityDocument  `gorm:"foreignKey:DocumentAID;constraint:OnDelete:CASCADE"`
+	DocumentB SimilarityDocument  `gorm:"foreignKey:DocumentBID;constraint:OnDelete:CASCADE"`
+	Passages  []SimilarityPassage `gorm:"foreignKey:PairID;constraint:OnDelete:CASCADE"`
+}
+
+// SimilarityPassage potongan teks yang sama, offset dalam rune pada Text dokumen
+type SimilarityPassage struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	PairID uuid.UUID `gorm:"type:uuid;not null;index"`
+	AStart int       `gorm:"not null"`
+	AEnd   int       `gorm:"not null"`
+	BStart int       `gorm:"not null"`
+	BEnd   int       `gorm:"not null"`
+}
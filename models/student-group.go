@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StudentGroup kelompok siswa dalam satu batch, dipakai untuk assignment kelompok
+type StudentGroup struct {
+	ID      uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	BatchID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name    string    `gorm:"type:varchar(100);not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Batch   Batch                `gorm:"foreignKey:BatchID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Members []StudentGroupMember `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+}
+
+// StudentGroupMember anggota kelompok. Satu siswa hanya boleh ada di satu kelompok per batch.
+type StudentGroupMember struct {
+	ID      uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	GroupID uuid.UUID `gorm:"type:uuid;not null;index"`
+	BatchID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_group_member_batch"`
+	UserID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_group_member_batch"`
+
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// AssignmentGradeAdjustment penyesuaian nilai individu anggota kelompok (bisa minus)
+type AssignmentGradeAdjustment struct {
+	ID      uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	GradeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_grade_adjustment_user"`
+	UserID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_grade_adjustment_user"`
+	Points  int       `gorm:"not null"`
+	Reason  *string   `gorm:"type:text"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// MemberGrade nilai akhir satu anggota: nilai efektif ditambah penyesuaian individunya, dibatasi 0-100
+func (g AssignmentGrade) MemberGrade(userID uuid.UUID) int {
+	grade := g.EffectiveGrade()
+	for _, adj := range g.Adjustments {
+		if adj.UserID == userID {
+			grade += adj.Points
+			break
+		}
+	}
+	return min(100, max(0, grade))
+}
+
+// IsOwnedBy submission milik user: yang mengumpulkan atau anggota kelompoknya (Group.Members harus di-preload)
+func (s *AssignmentSubmission) IsOwnedBy(userID uuid.UUID) bool {
+	if s.UserID == userID {
+		return true
+	}
+	if s.Group == nil {
+		return false
+	}
+	for _, m := range s.Group.Members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
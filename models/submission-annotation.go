@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnnotationType jenis coretan guru di PDF submission
+type AnnotationType string
+
+const (
+	// AnnotationHighlight area diberi stabilo
+	AnnotationHighlight AnnotationType = "highlight"
+	// AnnotationBox area diberi kotak
+	AnnotationBox AnnotationType = "box"
+	// AnnotationNote penanda komentar di satu titik
+	AnnotationNote AnnotationType = "note"
+)
+
+// Scan implements the Scanner interface
+func (t *AnnotationType) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		*t = AnnotationType(string(v))
+		return nil
+	case string:
+		*t = AnnotationType(v)
+		return nil
+	}
+	return errors.New("failed to scan AnnotationType: invalid type")
+}
+
+// Value implements the Valuer interface
+func (t AnnotationType) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// SubmissionAnnotation anotasi guru pada satu halaman file PDF submission.
+// Posisi relatif terhadap ukuran halaman (0-1) dengan titik awal di kiri atas.
+// File yang diganti saat kumpul ulang melepas anotasinya (SubmissionFileID jadi null), riwayat tetap ada.
+type SubmissionAnnotation struct {
+	ID               uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SubmissionID     uuid.UUID      `gorm:"type:uuid;not null;index"`
+	SubmissionFileID *uuid.UUID     `gorm:"type:uuid;index"`
+	FileURL          string         `gorm:"type:varchar(255);not null"`
+	Version          int            `gorm:"not null;default:1"` // versi submission yang dianotasi
+	AuthorID         uuid.UUID      `gorm:"type:uuid;not null"`
+	Page             int            `gorm:"not null"`
+	Type             AnnotationType `gorm:"type:annotation_type;not null"`
+	X                float64        `gorm:"not null"`
+	Y                float64        `gorm:"not null"`
+	Width            float64        `gorm:"not null;default:0"`
+	Height           float64        `gorm:"not null;default:0"`
+	Comment          *string        `gorm:"type:text"`
+	Color            *string        `gorm:"type:varchar(7)"` // #RRGGBB, kosong = warna bawaan jenisnya
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Submission     AssignmentSubmission `gorm:"foreignKey:SubmissionID;constraint:OnDelete:CASCADE"`
+	SubmissionFile *SubmissionFile      `gorm:"foreignKey:SubmissionFileID;constraint:OnDelete:SET NULL"`
+	Author         User                 `gorm:"foreignKey:AuthorID"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubmissionVersion snapshot isi submission setiap kali dikumpulkan. Tidak pernah diubah setelah dibuat.
+type SubmissionVersion struct {
+	ID                     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AssignmentSubmissionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_submission_version"`
+	Version                int       `gorm:"not null;uniqueIndex:idx_submission_version"`
+	Note                   *string   `gorm:"type:text"`
+	EssayText              *string   `gorm:"type:text"`
+	IsLate                 bool      `gorm:"not null"`
+	LateMinutes            int       `gorm:"not null;default:0"`
+	IsRevision             bool      `gorm:"not null;default:false"` // dikumpulkan setelah dikembalikan guru
+	CreatedAt              time.Time
+
+	AssignmentSubmission *AssignmentSubmission   `gorm:"foreignKey:AssignmentSubmissionID;constraint:OnDelete:CASCADE"`
+	Files                []SubmissionVersionFile `gorm:"foreignKey:VersionID;constraint:OnDelete:CASCADE"`
+}
+
+// SubmissionVersionFile file yang terlampir di satu versi submission
+type SubmissionVersionFile struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	VersionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	FileURL   string    `gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IAccommodationRepository interface
+type IAccommodationRepository interface {
+	WithTx(tx *gorm.DB) IAccommodationRepository
+	GetQuizAccommodation(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAccommodation, error)
+	GetQuizAccommodationsByQuizIDs(ctx context.Context, quizIDs []uuid.UUID) ([]models.QuizAccommodation, error)
+	ListQuizAccommodations(ctx context.Context, quizID uuid.UUID) ([]models.QuizAccommodation, error)
+	UpsertQuizAccommodation(ctx context.Context, acc *models.QuizAccommodation) error
+	DeleteQuizAccommodation(ctx context.Context, quizID, userID uuid.UUID) error
+	GetAssignmentExtension(ctx context.Context, assignmentID, userID uuid.UUID) (*models.AssignmentExtension, error)
+	ListAssignmentExtensions(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentExtension, error)
+	UpsertAssignmentExtension(ctx context.Context, ext *models.AssignmentExtension) error
+	DeleteAssignmentExtension(ctx context.Context, assignmentID, userID uuid.UUID) error
+}
+
+// AccommodationRepository menyimpan override waktu per siswa untuk quiz & assignment
+type AccommodationRepository struct {
+	db *gorm.DB
+}
+
+// NewAccommodationRepository creates a new accommodation repository
+func NewAccommodationRepository(db *gorm.DB) IAccommodationRepository {
+	return &AccommodationRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *AccommodationRepository) WithTx(tx *gorm.DB) IAccommodationRepository {
+	return &AccommodationRepository{db: tx}
+}
+
+// GetQuizAccommodation ambil accommodation quiz milik user, nil kalau tidak ada
+func (r *AccommodationRepository) GetQuizAccommodation(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAccommodation, error) {
+	var acc models.QuizAccommodation
+	err := r.db.WithContext(ctx).
+		Where("quiz_id = ? AND user_id = ?", quizID, userID).
+		First(&acc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+// GetQuizAccommodationsByQuizIDs ambil semua accommodation untuk beberapa quiz sekaligus
+func (r *AccommodationRepository) GetQuizAccommodationsByQuizIDs(ctx context.Context, quizIDs []uuid.UUID) ([]models.QuizAccommodation, error) {
+	var accs []models.QuizAccommodation
+	if len(quizIDs) == 0 {
+		return accs, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("quiz_id IN ?", quizIDs).
+		Find(&accs).Error
+	return accs, err
+}
+
+// ListQuizAccommodations list accommodation sebuah quiz
+func (r *AccommodationRepository) ListQuizAccommodations(ctx context.Context, quizID uuid.UUID) ([]models.QuizAccommodation, error) {
+	var accs []models.QuizAccommodation
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("quiz_id = ?", quizID).
+		Order("created_at ASC").
+		Find(&accs).Error
+	return accs, err
+}
+
+// UpsertQuizAccommodation insert atau update accommodation (unik per quiz & user)
+func (r *AccommodationRepository) UpsertQuizAccommodation(ctx context.Context, acc *models.QuizAccommodation) error {
+	acc.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "quiz_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"extra_minutes", "start_time", "end_time", "extra_attempts", "reason", "created_by", "updated_at"}),
+		}).
+		Create(acc).Error
+}
+
+// DeleteQuizAccommodation hapus accommodation quiz
+func (r *AccommodationRepository) DeleteQuizAccommodation(ctx context.Context, quizID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("quiz_id = ? AND user_id = ?", quizID, userID).
+		Delete(&models.QuizAccommodation{}).Error
+}
+
+// GetAssignmentExtension ambil perpanjangan deadline assignment milik user, nil kalau tidak ada
+func (r *AccommodationRepository) GetAssignmentExtension(ctx context.Context, assignmentID, userID uuid.UUID) (*models.AssignmentExtension, error) {
+	var ext models.AssignmentExtension
+	err := r.db.WithContext(ctx).
+		Where("assignment_id = ? AND user_id = ?", assignmentID, userID).
+		First(&ext).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ext, nil
+}
+
+// ListAssignmentExtensions list perpanjangan deadline sebuah assignment
+func (r *AccommodationRepository) ListAssignmentExtensions(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentExtension, error) {
+	var exts []models.AssignmentExtension
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("assignment_id = ?", assignmentID).
+		Order("created_at ASC").
+		Find(&exts).Error
+	return exts, err
+}
+
+// UpsertAssignmentExtension insert atau update perpanjangan deadline (unik per assignment & user)
+func (r *AccommodationRepository) UpsertAssignmentExtension(ctx context.Context, ext *models.AssignmentExtension) error {
+	ext.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "assignment_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"end_at", "reason", "created_by", "updated_at"}),
+		}).
+		Create(ext).Error
+}
+
+// DeleteAssignmentExtension hapus perpanjangan deadline
+func (r *AccommodationRepository) DeleteAssignmentExtension(ctx context.Context, assignmentID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("assignment_id = ? AND user_id = ?", assignmentID, userID).
+		Delete(&models.AssignmentExtension{}).Error
+}
@@ -26,7 +26,10 @@ type IAssignmentRepository interface {
 	GetBatchIDByAssignmentID(ctx context.Context, assignmentID uuid.UUID) (uuid.UUID, error)
 	GetBatchByAssignmentID(ctx context.Context, assignmentID uuid.UUID) (models.Batch, error)
 	CountByBatchID(ctx context.Context, batchID uuid.UUID) (int64, error)
+	HasSubmissions(ctx context.Context, assignmentID uuid.UUID) (bool, error)
 	GetByMeetingID(ctx context.Context, meetingID uuid.UUID) (*models.Assignment, error)
+	GetAllByMeetingID(ctx context.Context, meetingID uuid.UUID) ([]models.Assignment, error)
+	GetAllByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.Assignment, error)
 }
 
 // AssignmentRepository provides methods for managing assignments
@@ -104,7 +107,7 @@ func (r *AssignmentRepository) GetAllUpcomingAssignments(ctx context.Context, us
 		Joins("JOIN batches ON batches.id = meetings.batch_id").
 		Joins("JOIN purchases ON purchases.batch_id = batches.id").
 		// join submissions untuk filter sudah dikerjakan
-		Joins("LEFT JOIN assignment_submissions ON assignment_submissions.assignment_id = assignments.id AND assignment_submissions.user_id = ?", userID).
+		Joins("LEFT JOIN assignment_submissions ON assignment_submissions.assignment_id = assignments.id AND "+SubmissionOwnedBy("assignment_submissions"), userID, userID).
 		Where("purchases.user_id = ? AND purchases.payment_status = ?", userID, models.Paid).
 		// belum dikerjakan
 		Where("assignment_submissions.id IS NULL")
@@ -183,6 +186,27 @@ func (r *AssignmentRepository) GetByMeetingID(ctx context.Context, meetingID uui
 	return &assignment, nil
 }
 
+// GetAllByMeetingID semua assignment meeting, urut waktu mulai
+func (r *AssignmentRepository) GetAllByMeetingID(ctx context.Context, meetingID uuid.UUID) ([]models.Assignment, error) {
+	var assignments []models.Assignment
+	err := r.db.WithContext(ctx).
+		Where("meeting_id = ?", meetingID).
+		Order("start_at ASC, created_at ASC").
+		Find(&assignments).Error
+	return assignments, err
+}
+
+// GetAllByBatchID semua assignment di batch, urut meeting lalu waktu mulai
+func (r *AssignmentRepository) GetAllByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.Assignment, error) {
+	var assignments []models.Assignment
+	err := r.db.WithContext(ctx).
+		Joins("JOIN meetings ON meetings.id = assignments.meeting_id").
+		Where("meetings.batch_id = ?", batchID).
+		Order("meetings.start_at ASC, assignments.start_at ASC, assignments.created_at ASC").
+		Find(&assignments).Error
+	return assignments, err
+}
+
 // Create creates a new assignment
 func (r *AssignmentRepository) Create(ctx context.Context, assignment *models.Assignment) error {
 	return r.db.WithContext(ctx).Create(assignment).Error
@@ -234,7 +258,7 @@ func (r *AssignmentRepository) FindByIDWithUserData(ctx context.Context, id uuid
 				return db.Where("user_id = ?", userID)
 			}).
 			Preload("AssignmentSubmissions.SubmissionFiles").
-			Preload("AssignmentSubmissions.AssignmentGrade")
+			Preload("AssignmentSubmissions.AssignmentGrade", preloadReleasedGrade)
 	} else {
 		query = query.
 			Preload("AssignmentSubmissions").
@@ -292,3 +316,12 @@ func (r *AssignmentRepository) CountByBatchID(ctx context.Context, batchID uuid.
 		Count(&count).Error
 	return count, err
 }
+
+// HasSubmissions assignment sudah punya submission
+func (r *AssignmentRepository) HasSubmissions(ctx context.Context, assignmentID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.AssignmentSubmission{}).
+		Where("assignment_id = ?", assignmentID).
+		Count(&count).Error
+	return count > 0, err
+}
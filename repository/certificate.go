@@ -2,11 +2,13 @@ package repository
 
 import (
 	"brevet-api/models"
+	"brevet-api/utils"
 	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ICertificateRepository interface
@@ -15,10 +17,11 @@ type ICertificateRepository interface {
 	GetByBatchUser(ctx context.Context, batchID, userID uuid.UUID) (*models.Certificate, error)
 	Create(ctx context.Context, cert *models.Certificate) error
 	Update(ctx context.Context, cert *models.Certificate) error
-	GetLastSequenceByBatch(ctx context.Context, batchID uuid.UUID) (int, error)
+	GetNumbersByBatch(ctx context.Context, batchID uuid.UUID) ([]string, error)
 	GetByIDAndBatch(ctx context.Context, certID, batchID uuid.UUID) (*models.Certificate, error)
 	GetByID(ctx context.Context, certID uuid.UUID) (*models.Certificate, error)
 	GetByNumber(ctx context.Context, number string) (*models.Certificate, error)
+	Supersede(ctx context.Context, old, replacement *models.Certificate) error
 }
 
 // CertificateRepository is a struct that represents a certificate repository
@@ -43,11 +46,11 @@ func (r *CertificateRepository) GetByBatch(ctx context.Context, batchID uuid.UUI
 	return certs, nil
 }
 
-// GetByBatchUser retrieves a certificate by batch ID and user ID
+// GetByBatchUser retrieves the current certificate (not superseded) by batch ID and user ID
 func (r *CertificateRepository) GetByBatchUser(ctx context.Context, batchID, userID uuid.UUID) (*models.Certificate, error) {
 	var cert models.Certificate
 	err := r.db.WithContext(ctx).
-		Where("batch_id = ? AND user_id = ?", batchID, userID).
+		Where("batch_id = ? AND user_id = ? AND status <> ?", batchID, userID, models.CertificateSuperseded).
 		First(&cert).Error
 	if err != nil {
 		return nil, err
@@ -62,31 +65,20 @@ func (r *CertificateRepository) Create(ctx context.Context, cert *models.Certifi
 
 // Update updates an existing certificate in the database
 func (r *CertificateRepository) Update(ctx context.Context, cert *models.Certificate) error {
-	return r.db.WithContext(ctx).Save(cert).Error
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(cert).Error
 }
 
-// GetLastSequenceByBatch get last
-func (r *CertificateRepository) GetLastSequenceByBatch(ctx context.Context, batchID uuid.UUID) (int, error) {
-	var lastNumber string
+// GetNumbersByBatch semua nomor sertifikat di batch (termasuk yang dicabut / diganti)
+func (r *CertificateRepository) GetNumbersByBatch(ctx context.Context, batchID uuid.UUID) ([]string, error) {
+	var numbers []string
 	err := r.db.WithContext(ctx).
 		Model(&models.Certificate{}).
 		Where("batch_id = ?", batchID).
-		Order("created_at DESC").
-		Pluck("number", &lastNumber).Error
+		Pluck("number", &numbers).Error
 	if err != nil {
-		return 0, err
-	}
-	if lastNumber == "" {
-		return 0, nil
-	}
-
-	// parsing nomor terakhir → ambil bagian urutannya
-	var seq int
-	_, err = fmt.Sscanf(lastNumber, "20100112-%*d %d", &seq)
-	if err != nil {
-		return 0, nil
+		return nil, err
 	}
-	return seq, nil
+	return numbers, nil
 }
 
 // GetByIDAndBatch get by id and batch id
@@ -109,6 +101,7 @@ func (r *CertificateRepository) GetByID(ctx context.Context, certID uuid.UUID) (
 	err := r.db.WithContext(ctx).
 		Preload("Batch").
 		Preload("User").
+		Preload("SupersededBy").
 		Where("id = ?", certID).
 		First(&cert).Error
 	if err != nil {
@@ -123,6 +116,7 @@ func (r *CertificateRepository) GetByNumber(ctx context.Context, number string)
 	err := r.db.WithContext(ctx).
 		Preload("Batch").
 		Preload("User").
+		Preload("SupersededBy").
 		Where("number = ?", number).
 		First(&cert).Error
 	if err != nil {
@@ -130,3 +124,26 @@ func (r *CertificateRepository) GetByNumber(ctx context.Context, number string)
 	}
 	return &cert, nil
 }
+
+// Supersede simpan sertifikat pengganti dan tandai sertifikat lama superseded dalam satu transaksi
+func (r *CertificateRepository) Supersede(ctx context.Context, old, replacement *models.Certificate) error {
+	return utils.WithTransaction(r.db.WithContext(ctx), func(tx *gorm.DB) error {
+		if err := tx.Create(replacement).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.Certificate{}).
+			Where("id = ? AND status = ?", old.ID, models.CertificateActive).
+			Updates(map[string]any{
+				"status":           models.CertificateSuperseded,
+				"superseded_by_id": replacement.ID,
+				"reissue_reason":   old.ReissueReason,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("sertifikat %s sudah tidak aktif", old.Number)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IPeerReviewRepository interface
+type IPeerReviewRepository interface {
+	WithTx(tx *gorm.DB) IPeerReviewRepository
+	CreateReviews(ctx context.Context, reviews []models.PeerReview) error
+	MarkDistributed(ctx context.Context, assignmentID uuid.UUID, at time.Time) error
+	GetByID(ctx context.Context, reviewID uuid.UUID) (*models.PeerReview, error)
+	GetByReviewerID(ctx context.Context, reviewerID uuid.UUID) ([]models.PeerReview, error)
+	GetByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.PeerReview, error)
+	GetSubmittedBySubmissionID(ctx context.Context, submissionID uuid.UUID) ([]models.PeerReview, error)
+	Update(ctx context.Context, review *models.PeerReview) error
+	ReplaceCriteria(ctx context.Context, reviewID uuid.UUID, criteria []models.PeerReviewCriterion) error
+	GetSubmissionsByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error)
+	GetPaidStudentIDsByBatchID(ctx context.Context, batchID uuid.UUID) ([]uuid.UUID, error)
+	GetAssignmentsDueForDistribution(ctx context.Context, now time.Time, limit int) ([]models.Assignment, error)
+}
+
+// PeerReviewRepository menyimpan penugasan & hasil peer review
+type PeerReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewPeerReviewRepository creates a new peer review repository
+func NewPeerReviewRepository(db *gorm.DB) IPeerReviewRepository {
+	return &PeerReviewRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *PeerReviewRepository) WithTx(tx *gorm.DB) IPeerReviewRepository {
+	return &PeerReviewRepository{db: tx}
+}
+
+// CreateReviews simpan penugasan reviewer hasil distribusi
+func (r *PeerReviewRepository) CreateReviews(ctx context.Context, reviews []models.PeerReview) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(&reviews).Error
+}
+
+// MarkDistributed tandai assignment sudah dibagikan ke reviewer
+func (r *PeerReviewRepository) MarkDistributed(ctx context.Context, assignmentID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Assignment{}).
+		Where("id = ?", assignmentID).
+		Update("peer_review_distributed_at", at).Error
+}
+
+// GetByID review beserta assignment, submission yang dinilai & rincian rubrik.
+// Identitas hanya untuk guru, jangan dipetakan ke response reviewer.
+func (r *PeerReviewRepository) GetByID(ctx context.Context, reviewID uuid.UUID) (*models.PeerReview, error) {
+	var review models.PeerReview
+	err := r.db.WithContext(ctx).
+		Preload("Assignment").
+		Preload("Reviewer").
+		Preload("Submission.User").
+		Preload("Submission.SubmissionFiles").
+		Preload("Criteria", orderGradeCriteria).
+		First(&review, "id = ?", reviewID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByReviewerID tugas review milik siswa, yang belum dikirim dulu
+func (r *PeerReviewRepository) GetByReviewerID(ctx context.Context, reviewerID uuid.UUID) ([]models.PeerReview, error) {
+	var reviews []models.PeerReview
+	err := r.db.WithContext(ctx).
+		Preload("Assignment").
+		Preload("Criteria", orderGradeCriteria).
+		Where("reviewer_id = ?", reviewerID).
+		Order("submitted_at IS NOT NULL, created_at DESC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// GetByAssignmentID semua review assignment lengkap dengan reviewer & pemilik submission, untuk guru
+func (r *PeerReviewRepository) GetByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.PeerReview, error) {
+	var reviews []models.PeerReview
+	err := r.db.WithContext(ctx).
+		Preload("Reviewer").
+		Preload("Submission.User").
+		Preload("Criteria", orderGradeCriteria).
+		Where("assignment_id = ?", assignmentID).
+		Order("submission_id, created_at").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// GetSubmittedBySubmissionID review yang sudah dikirim & tidak dikeluarkan guru
+func (r *PeerReviewRepository) GetSubmittedBySubmissionID(ctx context.Context, submissionID uuid.UUID) ([]models.PeerReview, error) {
+	var reviews []models.PeerReview
+	err := r.db.WithContext(ctx).
+		Preload("Criteria", orderGradeCriteria).
+		Where("submission_id = ? AND submitted_at IS NOT NULL AND excluded = false", submissionID).
+		Order("submitted_at ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// Update simpan isi review tanpa menyentuh relasi
+func (r *PeerReviewRepository) Update(ctx context.Context, review *models.PeerReview) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(review).Error
+}
+
+// ReplaceCriteria ganti rincian rubrik review
+func (r *PeerReviewRepository) ReplaceCriteria(ctx context.Context, reviewID uuid.UUID, criteria []models.PeerReviewCriterion) error {
+	if err := r.db.WithContext(ctx).
+		Where("review_id = ?", reviewID).
+		Delete(&models.PeerReviewCriterion{}).Error; err != nil {
+		return err
+	}
+	if len(criteria) == 0 {
+		return nil
+	}
+	for i := range criteria {
+		criteria[i].ReviewID = reviewID
+	}
+	return r.db.WithContext(ctx).Create(&criteria).Error
+}
+
+// GetSubmissionsByAssignmentID submission yang akan dibagikan ke reviewer
+func (r *PeerReviewRepository) GetSubmissionsByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error) {
+	var submissions []models.AssignmentSubmission
+	err := r.db.WithContext(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Order("created_at ASC").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// GetPaidStudentIDsByBatchID siswa lunas di batch, calon reviewer
+func (r *PeerReviewRepository) GetPaidStudentIDsByBatchID(ctx context.Context, batchID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Joins("JOIN purchases ON purchases.user_id = users.id").
+		Where("purchases.batch_id = ? AND purchases.payment_status = ?", batchID, models.Paid).
+		Where("users.role_type = ?", models.RoleTypeSiswa).
+		Distinct().
+		Order("users.id").
+		Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+// GetAssignmentsDueForDistribution assignment peer review yang deadline-nya (termasuk perpanjangan) sudah lewat
+// tapi belum dibagikan ke reviewer
+func (r *PeerReviewRepository) GetAssignmentsDueForDistribution(ctx context.Context, now time.Time, limit int) ([]models.Assignment, error) {
+	var assignments []models.Assignment
+	err := r.db.WithContext(ctx).
+		Where("assignments.peer_review_enabled = true AND assignments.peer_review_distributed_at IS NULL").
+		Where("assignments.end_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM assignment_extensions e WHERE e.assignment_id = assignments.id AND e.end_at > ?)", now).
+		Order("assignments.end_at ASC").
+		Limit(limit).
+		Find(&assignments).Error
+	return assignments, err
+}
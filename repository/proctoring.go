@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IProctoringRepository interface
+type IProctoringRepository interface {
+	WithTx(tx *gorm.DB) IProctoringRepository
+	CreateEvent(ctx context.Context, event *models.QuizProctorEvent) error
+	CreateAnswerLog(ctx context.Context, answerLog *models.QuizAnswerLog) error
+	GetEventsByAttemptIDs(ctx context.Context, attemptIDs []uuid.UUID) ([]models.QuizProctorEvent, error)
+	GetAnswerLogsByAttemptIDs(ctx context.Context, attemptIDs []uuid.UUID) ([]models.QuizAnswerLog, error)
+}
+
+// ProctoringRepository menyimpan log integritas & riwayat jawaban attempt quiz
+type ProctoringRepository struct {
+	db *gorm.DB
+}
+
+// NewProctoringRepository creates a new proctoring repository
+func NewProctoringRepository(db *gorm.DB) IProctoringRepository {
+	return &ProctoringRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *ProctoringRepository) WithTx(tx *gorm.DB) IProctoringRepository {
+	return &ProctoringRepository{db: tx}
+}
+
+// CreateEvent simpan kejadian integritas
+func (r *ProctoringRepository) CreateEvent(ctx context.Context, event *models.QuizProctorEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// CreateAnswerLog simpan riwayat perubahan jawaban
+func (r *ProctoringRepository) CreateAnswerLog(ctx context.Context, answerLog *models.QuizAnswerLog) error {
+	return r.db.WithContext(ctx).Create(answerLog).Error
+}
+
+// GetEventsByAttemptIDs ambil kejadian integritas beberapa attempt sekaligus, urut waktu
+func (r *ProctoringRepository) GetEventsByAttemptIDs(ctx context.Context, attemptIDs []uuid.UUID) ([]models.QuizProctorEvent, error) {
+	var events []models.QuizProctorEvent
+	if len(attemptIDs) == 0 {
+		return events, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("attempt_id IN ?", attemptIDs).
+		Order("occurred_at ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// GetAnswerLogsByAttemptIDs ambil riwayat jawaban beberapa attempt sekaligus, urut waktu
+func (r *ProctoringRepository) GetAnswerLogsByAttemptIDs(ctx context.Context, attemptIDs []uuid.UUID) ([]models.QuizAnswerLog, error) {
+	var logs []models.QuizAnswerLog
+	if len(attemptIDs) == 0 {
+		return logs, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("attempt_id IN ?", attemptIDs).
+		Order("created_at ASC").
+		Find(&logs).Error
+	return logs, err
+}
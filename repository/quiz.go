@@ -11,8 +11,12 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrQuizAttemptNotFound attempt quiz tidak ada (mis. sudah dihapus)
+var ErrQuizAttemptNotFound = errors.New("quiz attempt not found")
+
 // IQuizRepository interface
 type IQuizRepository interface {
 	WithTx(tx *gorm.DB) IQuizRepository
@@ -36,14 +40,38 @@ type IQuizRepository interface {
 	GetAttemptByID(ctx context.Context, attemptID uuid.UUID) (*models.QuizAttempt, error)
 	GetOptionByID(ctx context.Context, optionID, questionID uuid.UUID) (*models.QuizOption, error)
 	GetAttemptsByQuizAndUser(ctx context.Context, quizID, userID uuid.UUID) ([]models.QuizAttempt, error)
+	GetAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAttempt, error)
 	UpdateQuiz(ctx context.Context, quiz *models.Quiz) error
 	DeleteQuiz(ctx context.Context, quizID uuid.UUID) error
 	GetQuizResultByAttemptID(ctx context.Context, attemptID uuid.UUID) (*models.QuizResult, error)
 	CountByBatchID(ctx context.Context, batchID uuid.UUID) (int64, error)
 	CountCompletedByBatchUser(ctx context.Context, batchID, userID uuid.UUID) (int64, error)
-	GetQuizzesWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID) ([]dto.QuizScore, error)
+	GetQuizzesWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID, releasedOnly bool) ([]dto.QuizScore, error)
+	GetAttemptScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID, releasedOnly bool) ([]dto.QuizAttemptScore, error)
 	GetAllByMeetingID(ctx context.Context, meetingID uuid.UUID) ([]models.Quiz, error)
 	GetQuizSubmissionByQuizAndUser(ctx context.Context, quizID, userID uuid.UUID) ([]models.QuizSubmission, error)
+	GetFinishedAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAttempt, error)
+	GetQuizAttemptByIDForUpdate(ctx context.Context, attemptID uuid.UUID) (*models.QuizAttempt, error)
+	GetActiveAttempts(ctx context.Context) ([]models.QuizAttempt, error)
+	GetActiveAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAttempt, error)
+	CountTempSubmissionsByAttemptIDs(ctx context.Context, attemptIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	UpdateOptionsCorrectness(ctx context.Context, questionID uuid.UUID, correctOptionIDs []uuid.UUID) error
+	UpdateQuestionScoringMode(ctx context.Context, questionID uuid.UUID, mode models.QuestionScoringMode) error
+	UpdateSubmissionScore(ctx context.Context, submissionID uuid.UUID, score int) error
+	GetResultsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizResult, error)
+	UpdateQuizResult(ctx context.Context, result *models.QuizResult) error
+	CreateRegradeLog(ctx context.Context, regradeLog *models.QuizRegradeLog) error
+	GetRegradeLogsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizRegradeLog, error)
+	GetReleasedPendingRegradeChanges(ctx context.Context, now time.Time) ([]models.QuizRegradeChange, error)
+	ClearRegradeNotices(ctx context.Context, changeIDs []uuid.UUID) error
+	GetSubmissionsByAttemptID(ctx context.Context, attemptID uuid.UUID) ([]models.QuizSubmission, error)
+	GetQuestionWithOptions(ctx context.Context, questionID, quizID uuid.UUID) (*models.QuizQuestion, error)
+	UpdateQuestionContent(ctx context.Context, question *models.QuizQuestion) error
+	UpdateOptionContent(ctx context.Context, option *models.QuizOption) error
+	UpdateReviewReleasedAt(ctx context.Context, quizID uuid.UUID, releasedAt *time.Time) error
+	DeleteAttemptGrading(ctx context.Context, attemptID uuid.UUID) error
+	CreateAttemptAction(ctx context.Context, action *models.QuizAttemptAction) error
+	GetAttemptActionsByAttemptID(ctx context.Context, attemptID uuid.UUID) ([]models.QuizAttemptAction, error)
 }
 
 // QuizRepository is a struct that represents a quiz repository
@@ -155,8 +183,8 @@ func (r *QuizRepository) GetAllUpcomingQuizzes(ctx context.Context, userID uuid.
 		Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
 		Joins("JOIN batches ON batches.id = meetings.batch_id").
 		Joins("JOIN purchases ON purchases.batch_id = batches.id").
-		// cek attempt
-		Joins("LEFT JOIN quiz_attempts ON quiz_attempts.quiz_id = quizzes.id AND quiz_attempts.user_id = ?", userID).
+		// cek attempt (attempt yang dibatalkan guru diabaikan)
+		Joins("LEFT JOIN quiz_attempts ON quiz_attempts.quiz_id = quizzes.id AND quiz_attempts.user_id = ? AND quiz_attempts.voided_at IS NULL", userID).
 		Where("purchases.user_id = ? AND purchases.payment_status = ?", userID, models.Paid).
 		// belum pernah attempt atau attempt belum selesai
 		Where("quiz_attempts.id IS NULL OR quiz_attempts.ended_at IS NULL").
@@ -185,30 +213,76 @@ func (r *QuizRepository) GetAllUpcomingQuizzes(ctx context.Context, userID uuid.
 	return quizzes, total, err
 }
 
-// GetQuizzesWithScoresByBatchUser retrieves quiz scores of a user in a batch
-func (r *QuizRepository) GetQuizzesWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID) ([]dto.QuizScore, error) {
+// QuizReviewReleased kondisi hasil quiz (alias tabel) sudah dibuka untuk siswa, sama dengan aturan ReviewReleaseTime:
+// immediate, manual setelah dirilis guru, after_end setelah EndTime terakhir termasuk accommodation.
+// Butuh dua argumen waktu sekarang.
+func QuizReviewReleased(alias string) string {
+	return fmt.Sprintf(`(%[1]s.mode = '%[2]s' OR %[1]s.review_release = '%[3]s'
+		OR (%[1]s.review_release = '%[4]s' AND %[1]s.review_released_at IS NOT NULL AND %[1]s.review_released_at <= ?)
+		OR (%[1]s.review_release = '%[5]s' AND GREATEST(%[1]s.end_time, COALESCE((SELECT MAX(acc.end_time) FROM quiz_accommodations acc WHERE acc.quiz_id = %[1]s.id), %[1]s.end_time)) <= ?))`,
+		alias, models.QuizModePractice, models.ReleaseImmediate, models.ReleaseManual, models.ReleaseAfterEnd)
+}
+
+// GetQuizzesWithScoresByBatchUser retrieves quiz scores of a user in a batch.
+// Nilai akhir dihitung dari semua attempt sesuai scoring policy masing-masing quiz.
+// releasedOnly untuk tampilan siswa: quiz yang hasilnya belum dibuka bernilai 0 seperti di GetAttemptResult.
+func (r *QuizRepository) GetQuizzesWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID, releasedOnly bool) ([]dto.QuizScore, error) {
 	var results []dto.QuizScore
 
 	err := r.db.WithContext(ctx).
 		Table("quizzes").
-		Select(`
-			quizzes.*,
-			COALESCE(MAX(qr.score_percent), 0) AS score
-		`).
+		Select("quizzes.*").
 		Joins("JOIN meetings m ON m.id = quizzes.meeting_id").
-		Joins("LEFT JOIN quiz_attempts qa ON qa.quiz_id = quizzes.id AND qa.user_id = ?", userID).
-		Joins("LEFT JOIN quiz_results qr ON qr.attempt_id = qa.id").
-		Where("m.batch_id = ?", batchID).
-		Group("quizzes.id").
+		Where("m.batch_id = ? AND quizzes.mode = ?", batchID, models.QuizModeGraded).
 		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
 
+	attemptScores, err := r.GetAttemptScoresByBatchUser(ctx, batchID, userID, releasedOnly)
 	if err != nil {
 		return nil, err
 	}
 
+	scoresByQuiz := make(map[uuid.UUID][]float64)
+	for _, a := range attemptScores {
+		scoresByQuiz[a.QuizID] = append(scoresByQuiz[a.QuizID], a.ScorePercent)
+	}
+
+	for i := range results {
+		scores := scoresByQuiz[results[i].ID]
+		results[i].AttemptCount = len(scores)
+		results[i].Score = results[i].ScoringPolicy.Aggregate(scores, results[i].AttemptPenaltyPercent)
+	}
+
 	return results, nil
 }
 
+// GetAttemptScoresByBatchUser ambil skor semua attempt selesai milik user di batch, urut dari attempt pertama.
+// releasedOnly membatasi ke quiz yang hasilnya sudah dibuka untuk siswa.
+func (r *QuizRepository) GetAttemptScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID, releasedOnly bool) ([]dto.QuizAttemptScore, error) {
+	var rows []dto.QuizAttemptScore
+
+	db := r.db.WithContext(ctx).
+		Table("quiz_attempts qa").
+		Select("qa.quiz_id, qa.user_id, qa.started_at, qr.score_percent").
+		Joins("JOIN quiz_results qr ON qr.attempt_id = qa.id").
+		Joins("JOIN quizzes q ON q.id = qa.quiz_id").
+		Joins("JOIN meetings m ON m.id = q.meeting_id").
+		Where("m.batch_id = ? AND qa.user_id = ? AND qa.ended_at IS NOT NULL AND qa.voided_at IS NULL", batchID, userID).
+		Where("q.mode = ?", models.QuizModeGraded)
+	if releasedOnly {
+		now := time.Now()
+		db = db.Where(QuizReviewReleased("q"), now, now)
+	}
+
+	if err := db.Order("qa.started_at ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
 // CountQuestionsByQuizID menghitung jumlah soal di quiz tertentu
 func (r *QuizRepository) CountQuestionsByQuizID(ctx context.Context, quizID uuid.UUID) (int64, error) {
 	var count int64
@@ -326,6 +400,18 @@ func (r *QuizRepository) GetAttemptsByQuizAndUser(ctx context.Context, quizID, u
 	return attempts, nil
 }
 
+// GetAttemptsByQuizID semua attempt sebuah quiz, urut waktu mulai
+func (r *QuizRepository) GetAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAttempt, error) {
+	var attempts []models.QuizAttempt
+	if err := r.db.WithContext(ctx).
+		Where("quiz_id = ?", quizID).
+		Order("started_at ASC").
+		Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
 // SaveQuizSubmission save
 func (r *QuizRepository) SaveQuizSubmission(ctx context.Context, sub *models.QuizSubmission) error {
 	return r.db.WithContext(ctx).Create(sub).Error
@@ -355,7 +441,7 @@ func (r *QuizRepository) GetQuizAttemptByID(ctx context.Context, attemptID uuid.
 		Where("id = ?", attemptID).
 		First(&attempt).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("quiz attempt not found")
+			return nil, ErrQuizAttemptNotFound
 		}
 		return nil, err
 	}
@@ -421,7 +507,7 @@ func (r *QuizRepository) CountByBatchID(ctx context.Context, batchID uuid.UUID)
 	var count int64
 	err := r.db.WithContext(ctx).Model(&models.Quiz{}).
 		Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
-		Where("meetings.batch_id = ?", batchID).
+		Where("meetings.batch_id = ? AND quizzes.mode = ?", batchID, models.QuizModeGraded).
 		Count(&count).Error
 	return count, err
 }
@@ -433,8 +519,271 @@ func (r *QuizRepository) CountCompletedByBatchUser(ctx context.Context, batchID,
 		Model(&models.QuizAttempt{}).
 		Joins("JOIN quizzes ON quizzes.id = quiz_attempts.quiz_id").
 		Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
-		Where("meetings.batch_id = ? AND quiz_attempts.user_id = ? AND quiz_attempts.ended_at IS NOT NULL", batchID, userID).
+		Where("meetings.batch_id = ? AND quiz_attempts.user_id = ? AND quiz_attempts.ended_at IS NOT NULL AND quiz_attempts.voided_at IS NULL", batchID, userID).
+		Where("quizzes.mode = ?", models.QuizModeGraded).
 		Distinct("quiz_attempts.quiz_id").
 		Count(&count).Error
 	return count, err
 }
+
+// GetFinishedAttemptsByQuizID ambil semua attempt yang sudah selesai beserta submission-nya
+func (r *QuizRepository) GetFinishedAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAttempt, error) {
+	var attempts []models.QuizAttempt
+	err := r.db.WithContext(ctx).
+		Preload("Submissions").
+		Preload("User").
+		Where("quiz_id = ? AND ended_at IS NOT NULL AND voided_at IS NULL", quizID).
+		Order("started_at ASC").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// GetQuizAttemptByIDForUpdate ambil attempt dengan row lock, dipakai saat submit supaya tidak dobel
+func (r *QuizRepository) GetQuizAttemptByIDForUpdate(ctx context.Context, attemptID uuid.UUID) (*models.QuizAttempt, error) {
+	var attempt models.QuizAttempt
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", attemptID).
+		First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuizAttemptNotFound
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// GetActiveAttempts ambil semua attempt yang belum selesai beserta quiz-nya
+func (r *QuizRepository) GetActiveAttempts(ctx context.Context) ([]models.QuizAttempt, error) {
+	var attempts []models.QuizAttempt
+	if err := r.db.WithContext(ctx).
+		Preload("Quiz").
+		Where("ended_at IS NULL").
+		Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// GetActiveAttemptsByQuizID attempt yang sedang berjalan di satu quiz, beserta user
+func (r *QuizRepository) GetActiveAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAttempt, error) {
+	var attempts []models.QuizAttempt
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("quiz_id = ? AND ended_at IS NULL", quizID).
+		Order("started_at ASC").
+		Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// CountTempSubmissionsByAttemptIDs jumlah soal yang sudah dijawab per attempt
+func (r *QuizRepository) CountTempSubmissionsByAttemptIDs(ctx context.Context, attemptIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(attemptIDs))
+	if len(attemptIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		AttemptID uuid.UUID
+		Total     int
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.QuizTempSubmission{}).
+		Select("attempt_id, COUNT(*) AS total").
+		Where("attempt_id IN ?", attemptIDs).
+		Group("attempt_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.AttemptID] = row.Total
+	}
+	return counts, nil
+}
+
+// UpdateOptionsCorrectness set ulang kunci jawaban sebuah soal
+func (r *QuizRepository) UpdateOptionsCorrectness(ctx context.Context, questionID uuid.UUID, correctOptionIDs []uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Model(&models.QuizOption{}).
+		Where("question_id = ?", questionID).
+		Update("is_correct", false).Error; err != nil {
+		return err
+	}
+	if len(correctOptionIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.QuizOption{}).
+		Where("question_id = ? AND id IN ?", questionID, correctOptionIDs).
+		Update("is_correct", true).Error
+}
+
+// UpdateQuestionScoringMode ubah mode penilaian soal
+func (r *QuizRepository) UpdateQuestionScoringMode(ctx context.Context, questionID uuid.UUID, mode models.QuestionScoringMode) error {
+	return r.db.WithContext(ctx).
+		Model(&models.QuizQuestion{}).
+		Where("id = ?", questionID).
+		Update("scoring_mode", mode).Error
+}
+
+// GetQuestionWithOptions ambil soal beserta opsinya
+func (r *QuizRepository) GetQuestionWithOptions(ctx context.Context, questionID, quizID uuid.UUID) (*models.QuizQuestion, error) {
+	var q models.QuizQuestion
+	err := r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("id = ? AND quiz_id = ?", questionID, quizID).
+		First(&q).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("question not found in this quiz")
+		}
+		return nil, err
+	}
+	return &q, nil
+}
+
+// UpdateQuestionContent update teks, gambar & pembahasan soal
+func (r *QuizRepository) UpdateQuestionContent(ctx context.Context, question *models.QuizQuestion) error {
+	return r.db.WithContext(ctx).
+		Model(&models.QuizQuestion{}).
+		Where("id = ?", question.ID).
+		Select("question", "image_url", "explanation").
+		Updates(question).Error
+}
+
+// UpdateOptionContent update teks & gambar opsi
+func (r *QuizRepository) UpdateOptionContent(ctx context.Context, option *models.QuizOption) error {
+	return r.db.WithContext(ctx).
+		Model(&models.QuizOption{}).
+		Where("id = ?", option.ID).
+		Select("option_text", "image_url").
+		Updates(option).Error
+}
+
+// UpdateReviewReleasedAt set/hapus waktu rilis manual review quiz
+func (r *QuizRepository) UpdateReviewReleasedAt(ctx context.Context, quizID uuid.UUID, releasedAt *time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Quiz{}).
+		Where("id = ?", quizID).
+		Update("review_released_at", releasedAt).Error
+}
+
+// GetSubmissionsByAttemptID ambil jawaban final sebuah attempt
+func (r *QuizRepository) GetSubmissionsByAttemptID(ctx context.Context, attemptID uuid.UUID) ([]models.QuizSubmission, error) {
+	var subs []models.QuizSubmission
+	err := r.db.WithContext(ctx).
+		Where("attempt_id = ?", attemptID).
+		Find(&subs).Error
+	return subs, err
+}
+
+// UpdateSubmissionScore update skor final submission
+func (r *QuizRepository) UpdateSubmissionScore(ctx context.Context, submissionID uuid.UUID, score int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.QuizSubmission{}).
+		Where("id = ?", submissionID).
+		Update("score", score).Error
+}
+
+// GetResultsByQuizID ambil semua result dari attempt sebuah quiz
+func (r *QuizRepository) GetResultsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizResult, error) {
+	var results []models.QuizResult
+	err := r.db.WithContext(ctx).
+		Joins("JOIN quiz_attempts qa ON qa.id = quiz_results.attempt_id").
+		Where("qa.quiz_id = ?", quizID).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UpdateQuizResult update result
+func (r *QuizRepository) UpdateQuizResult(ctx context.Context, result *models.QuizResult) error {
+	return r.db.WithContext(ctx).Save(result).Error
+}
+
+// CreateRegradeLog simpan log regrade beserta detail perubahan skor
+func (r *QuizRepository) CreateRegradeLog(ctx context.Context, regradeLog *models.QuizRegradeLog) error {
+	return r.db.WithContext(ctx).Create(regradeLog).Error
+}
+
+// GetRegradeLogsByQuizID ambil riwayat regrade sebuah quiz
+func (r *QuizRepository) GetRegradeLogsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizRegradeLog, error) {
+	var logs []models.QuizRegradeLog
+	err := r.db.WithContext(ctx).
+		Preload("Actor").
+		Preload("Items.User").
+		Where("quiz_id = ?", quizID).
+		Order("created_at DESC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// GetReleasedPendingRegradeChanges ambil perubahan nilai yang emailnya tertunda dan review quiz-nya sudah dibuka.
+// Baris dikunci (SKIP LOCKED) supaya pengiriman paralel tidak mengambil perubahan yang sama.
+func (r *QuizRepository) GetReleasedPendingRegradeChanges(ctx context.Context, now time.Time) ([]models.QuizRegradeChange, error) {
+	var changes []models.QuizRegradeChange
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "quiz_regrade_changes"}, Options: "SKIP LOCKED"}).
+		Joins("JOIN quiz_regrade_logs rl ON rl.id = quiz_regrade_changes.regrade_log_id").
+		Joins("JOIN quizzes q ON q.id = rl.quiz_id").
+		Where("quiz_regrade_changes.pending_notice = ?", true).
+		Where(QuizReviewReleased("q"), now, now).
+		Preload("User").
+		Preload("RegradeLog.Quiz").
+		Order("quiz_regrade_changes.created_at ASC").
+		Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// ClearRegradeNotices tandai email perubahan nilai sudah dikirim
+func (r *QuizRepository) ClearRegradeNotices(ctx context.Context, changeIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&models.QuizRegradeChange{}).
+		Where("id IN ?", changeIDs).
+		Update("pending_notice", false).Error
+}
+
+// DeleteAttemptGrading hapus submission final & result sebuah attempt (dipakai saat attempt dibuka kembali)
+func (r *QuizRepository) DeleteAttemptGrading(ctx context.Context, attemptID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("attempt_id = ?", attemptID).
+		Delete(&models.QuizSubmission{}).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Where("attempt_id = ?", attemptID).
+		Delete(&models.QuizResult{}).Error
+}
+
+// CreateAttemptAction simpan audit tindakan guru terhadap attempt
+func (r *QuizRepository) CreateAttemptAction(ctx context.Context, action *models.QuizAttemptAction) error {
+	return r.db.WithContext(ctx).Create(action).Error
+}
+
+// GetAttemptActionsByAttemptID ambil riwayat tindakan guru terhadap sebuah attempt
+func (r *QuizRepository) GetAttemptActionsByAttemptID(ctx context.Context, attemptID uuid.UUID) ([]models.QuizAttemptAction, error) {
+	var actions []models.QuizAttemptAction
+	err := r.db.WithContext(ctx).
+		Preload("Actor").
+		Where("attempt_id = ?", attemptID).
+		Order("created_at ASC").
+		Find(&actions).Error
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
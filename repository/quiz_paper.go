@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IQuizPaperRepository interface
+type IQuizPaperRepository interface {
+	WithTx(tx *gorm.DB) IQuizPaperRepository
+	GetVersionsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizPaperVersion, error)
+	GetVersionByCode(ctx context.Context, quizID uuid.UUID, code string) (*models.QuizPaperVersion, error)
+	CreateVersion(ctx context.Context, version *models.QuizPaperVersion) error
+	DeleteVersionsByQuizID(ctx context.Context, quizID uuid.UUID) error
+	CountPaperAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) (int64, error)
+	GetPaperAttemptByQuizAndUser(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAttempt, error)
+	GetPaidStudentsByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.User, error)
+	GetSheetsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAnswerSheet, error)
+	GetSheetBySerial(ctx context.Context, quizID uuid.UUID, serial int64) (*models.QuizAnswerSheet, error)
+	GetSheetByQuizAndUser(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAnswerSheet, error)
+	IsSheetSerialUsed(ctx context.Context, serial int64) (bool, error)
+	CountSheetsByQuizID(ctx context.Context, quizID uuid.UUID) (int64, error)
+	CreateSheet(ctx context.Context, sheet *models.QuizAnswerSheet) error
+	CreateScan(ctx context.Context, scan *models.QuizOmrScan) error
+	UpdateScan(ctx context.Context, scan *models.QuizOmrScan) error
+	GetScanByID(ctx context.Context, scanID uuid.UUID) (*models.QuizOmrScan, error)
+	GetScansByQuizID(ctx context.Context, quizID uuid.UUID, status string) ([]models.QuizOmrScan, error)
+}
+
+// QuizPaperRepository menyimpan versi naskah cetak quiz beserta pemetaan nomor soal & huruf opsi
+type QuizPaperRepository struct {
+	db *gorm.DB
+}
+
+// NewQuizPaperRepository creates a new quiz paper repository
+func NewQuizPaperRepository(db *gorm.DB) IQuizPaperRepository {
+	return &QuizPaperRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *QuizPaperRepository) WithTx(tx *gorm.DB) IQuizPaperRepository {
+	return &QuizPaperRepository{db: tx}
+}
+
+// GetVersionsByQuizID ambil semua versi naskah lengkap dengan soal & opsi, urut per kode versi
+func (r *QuizPaperRepository) GetVersionsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizPaperVersion, error) {
+	var versions []models.QuizPaperVersion
+	err := r.db.WithContext(ctx).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("number ASC") }).
+		Preload("Questions.Question.Options").
+		Preload("Questions.Options", func(db *gorm.DB) *gorm.DB { return db.Order("label ASC") }).
+		Preload("Questions.Options.Option").
+		Where("quiz_id = ?", quizID).
+		Order("code ASC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// GetVersionByCode ambil satu versi naskah berdasarkan kodenya
+func (r *QuizPaperRepository) GetVersionByCode(ctx context.Context, quizID uuid.UUID, code string) (*models.QuizPaperVersion, error) {
+	var version models.QuizPaperVersion
+	err := r.db.WithContext(ctx).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("number ASC") }).
+		Preload("Questions.Question.Options").
+		Preload("Questions.Options", func(db *gorm.DB) *gorm.DB { return db.Order("label ASC") }).
+		Where("quiz_id = ? AND code = ?", quizID, code).
+		First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// CreateVersion simpan versi naskah beserta soal & opsinya
+func (r *QuizPaperRepository) CreateVersion(ctx context.Context, version *models.QuizPaperVersion) error {
+	return r.db.WithContext(ctx).Create(version).Error
+}
+
+// DeleteVersionsByQuizID hapus semua versi naskah quiz (soal & opsi ikut terhapus lewat cascade)
+func (r *QuizPaperRepository) DeleteVersionsByQuizID(ctx context.Context, quizID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("quiz_id = ?", quizID).
+		Delete(&models.QuizPaperVersion{}).Error
+}
+
+// CountPaperAttemptsByQuizID jumlah attempt dari jawaban naskah cetak yang belum dibatalkan
+func (r *QuizPaperRepository) CountPaperAttemptsByQuizID(ctx context.Context, quizID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.QuizAttempt{}).
+		Where("quiz_id = ? AND paper_version_id IS NOT NULL AND voided_at IS NULL", quizID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetPaperAttemptByQuizAndUser attempt naskah cetak milik siswa untuk quiz ini (kalau sudah pernah diinput)
+func (r *QuizPaperRepository) GetPaperAttemptByQuizAndUser(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAttempt, error) {
+	var attempt models.QuizAttempt
+	err := r.db.WithContext(ctx).
+		Where("quiz_id = ? AND user_id = ? AND paper_version_id IS NOT NULL AND voided_at IS NULL", quizID, userID).
+		Order("created_at DESC").
+		First(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// GetPaidStudentsByBatchID siswa yang sudah lunas di batch, urut nama
+func (r *QuizPaperRepository) GetPaidStudentsByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.User, error) {
+	var students []models.User
+	err := r.db.WithContext(ctx).
+		Preload("Profile").
+		Joins("JOIN purchases ON purchases.user_id = users.id").
+		Where("purchases.batch_id = ? AND purchases.payment_status = ?", batchID, models.Paid).
+		Where("users.role_type = ?", models.RoleTypeSiswa).
+		Group("users.id").
+		Order("users.name ASC").
+		Find(&students).Error
+	return students, err
+}
+
+// GetSheetsByQuizID semua LJK quiz beserta siswa & versi naskahnya, urut nama siswa
+func (r *QuizPaperRepository) GetSheetsByQuizID(ctx context.Context, quizID uuid.UUID) ([]models.QuizAnswerSheet, error) {
+	var sheets []models.QuizAnswerSheet
+	err := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Preload("Version").
+		Joins("JOIN users ON users.id = quiz_answer_sheets.user_id").
+		Where("quiz_answer_sheets.quiz_id = ?", quizID).
+		Order("users.name ASC").
+		Find(&sheets).Error
+	return sheets, err
+}
+
+// GetSheetBySerial cari LJK dari kode yang terbaca di scan
+func (r *QuizPaperRepository) GetSheetBySerial(ctx context.Context, quizID uuid.UUID, serial int64) (*models.QuizAnswerSheet, error) {
+	var sheet models.QuizAnswerSheet
+	err := r.db.WithContext(ctx).
+		Preload("Version").
+		Where("quiz_id = ? AND serial = ?", quizID, serial).
+		First(&sheet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sheet, nil
+}
+
+// GetSheetByQuizAndUser LJK milik siswa untuk quiz ini
+func (r *QuizPaperRepository) GetSheetByQuizAndUser(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAnswerSheet, error) {
+	var sheet models.QuizAnswerSheet
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Version").
+		Where("quiz_id = ? AND user_id = ?", quizID, userID).
+		First(&sheet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sheet, nil
+}
+
+// IsSheetSerialUsed serial LJK unik untuk semua quiz
+func (r *QuizPaperRepository) IsSheetSerialUsed(ctx context.Context, serial int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.QuizAnswerSheet{}).
+		Where("serial = ?", serial).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountSheetsByQuizID jumlah LJK yang sudah dibuat untuk quiz
+func (r *QuizPaperRepository) CountSheetsByQuizID(ctx context.Context, quizID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.QuizAnswerSheet{}).
+		Where("quiz_id = ?", quizID).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateSheet simpan LJK baru
+func (r *QuizPaperRepository) CreateSheet(ctx context.Context, sheet *models.QuizAnswerSheet) error {
+	return r.db.WithContext(ctx).Create(sheet).Error
+}
+
+// CreateScan simpan hasil scan beserta bacaan tiap nomor
+func (r *QuizPaperRepository) CreateScan(ctx context.Context, scan *models.QuizOmrScan) error {
+	return r.db.WithContext(ctx).Create(scan).Error
+}
+
+// UpdateScan update status scan tanpa menyentuh bacaan aslinya
+func (r *QuizPaperRepository) UpdateScan(ctx context.Context, scan *models.QuizOmrScan) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(scan).Error
+}
+
+// GetScanByID ambil scan lengkap dengan bacaan, LJK & siswa
+func (r *QuizPaperRepository) GetScanByID(ctx context.Context, scanID uuid.UUID) (*models.QuizOmrScan, error) {
+	var scan models.QuizOmrScan
+	err := r.db.WithContext(ctx).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB { return db.Order("number ASC") }).
+		Preload("Sheet.User").
+		Preload("Sheet.Version").
+		First(&scan, "id = ?", scanID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
+
+// GetScansByQuizID daftar scan quiz, status kosong = semua status
+func (r *QuizPaperRepository) GetScansByQuizID(ctx context.Context, quizID uuid.UUID, status string) ([]models.QuizOmrScan, error) {
+	var scans []models.QuizOmrScan
+	db := r.db.WithContext(ctx).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB { return db.Order("number ASC") }).
+		Preload("Sheet.User").
+		Preload("Sheet.Version").
+		Where("quiz_id = ?", quizID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Order("created_at ASC").Find(&scans).Error
+	return scans, err
+}
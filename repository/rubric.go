@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IRubricRepository interface
+type IRubricRepository interface {
+	WithTx(tx *gorm.DB) IRubricRepository
+	GetRubrics(ctx context.Context, createdBy *uuid.UUID) ([]models.Rubric, error)
+	GetRubricByID(ctx context.Context, rubricID uuid.UUID) (*models.Rubric, error)
+	CreateRubric(ctx context.Context, rubric *models.Rubric) error
+	UpdateRubric(ctx context.Context, rubric *models.Rubric) error
+	DeleteCriteriaByRubricID(ctx context.Context, rubricID uuid.UUID) error
+	CreateCriteria(ctx context.Context, criteria []models.RubricCriterion) error
+	DeleteRubric(ctx context.Context, rubricID uuid.UUID) error
+}
+
+// RubricRepository menyimpan template rubrik penilaian assignment
+type RubricRepository struct {
+	db *gorm.DB
+}
+
+// NewRubricRepository creates a new rubric repository
+func NewRubricRepository(db *gorm.DB) IRubricRepository {
+	return &RubricRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *RubricRepository) WithTx(tx *gorm.DB) IRubricRepository {
+	return &RubricRepository{db: tx}
+}
+
+func preloadRubricCriteria(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Criteria", func(db *gorm.DB) *gorm.DB { return db.Order(`"order" ASC`) }).
+		Preload("Criteria.Levels", func(db *gorm.DB) *gorm.DB { return db.Order(`"order" ASC`) })
+}
+
+// GetRubrics daftar rubrik, createdBy nil = semua (admin)
+func (r *RubricRepository) GetRubrics(ctx context.Context, createdBy *uuid.UUID) ([]models.Rubric, error) {
+	var rubrics []models.Rubric
+	db := preloadRubricCriteria(r.db.WithContext(ctx))
+	if createdBy != nil {
+		db = db.Where("created_by = ?", *createdBy)
+	}
+	err := db.Order("title ASC").Find(&rubrics).Error
+	return rubrics, err
+}
+
+// GetRubricByID ambil rubrik lengkap dengan kriteria & level, urut sesuai input
+func (r *RubricRepository) GetRubricByID(ctx context.Context, rubricID uuid.UUID) (*models.Rubric, error) {
+	var rubric models.Rubric
+	err := preloadRubricCriteria(r.db.WithContext(ctx)).
+		First(&rubric, "id = ?", rubricID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rubric, nil
+}
+
+// CreateRubric simpan rubrik beserta kriteria & levelnya
+func (r *RubricRepository) CreateRubric(ctx context.Context, rubric *models.Rubric) error {
+	return r.db.WithContext(ctx).Create(rubric).Error
+}
+
+// UpdateRubric update judul & deskripsi rubrik saja
+func (r *RubricRepository) UpdateRubric(ctx context.Context, rubric *models.Rubric) error {
+	return r.db.WithContext(ctx).Omit("Criteria").Save(rubric).Error
+}
+
+// DeleteCriteriaByRubricID hapus semua kriteria rubrik (level ikut terhapus lewat cascade)
+func (r *RubricRepository) DeleteCriteriaByRubricID(ctx context.Context, rubricID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("rubric_id = ?", rubricID).
+		Delete(&models.RubricCriterion{}).Error
+}
+
+// CreateCriteria simpan kriteria baru beserta levelnya
+func (r *RubricRepository) CreateCriteria(ctx context.Context, criteria []models.RubricCriterion) error {
+	return r.db.WithContext(ctx).Create(&criteria).Error
+}
+
+// DeleteRubric hapus rubrik, assignment yang memakainya otomatis lepas (SET NULL)
+func (r *RubricRepository) DeleteRubric(ctx context.Context, rubricID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", rubricID).
+		Delete(&models.Rubric{}).Error
+}
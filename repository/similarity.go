@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ISimilarityRepository interface
+type ISimilarityRepository interface {
+	WithTx(tx *gorm.DB) ISimilarityRepository
+	CreateReport(ctx context.Context, report *models.SimilarityReport) error
+	UpdateReport(ctx context.Context, report *models.SimilarityReport) error
+	GetReportByID(ctx context.Context, reportID uuid.UUID) (*models.SimilarityReport, error)
+	GetReportsByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.SimilarityReport, error)
+	HasActiveReport(ctx context.Context, assignmentID uuid.UUID) (bool, error)
+	FailStaleReports(ctx context.Context, before time.Time, reason string) (int64, error)
+	CreateDocuments(ctx context.Context, documents []models.SimilarityDocument) error
+	CreatePairs(ctx context.Context, pairs []models.SimilarityPair) error
+	GetSubmissionsByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error)
+	GetPreviousBatchSubmissions(ctx context.Context, assignment *models.Assignment, batch *models.Batch) ([]models.AssignmentSubmission, error)
+	GetAssignmentsDueForReport(ctx context.Context, now time.Time, limit int) ([]models.Assignment, error)
+}
+
+// SimilarityRepository menyimpan laporan cek kemiripan submission
+type SimilarityRepository struct {
+	db *gorm.DB
+}
+
+// NewSimilarityRepository creates a new similarity repository
+func NewSimilarityRepository(db *gorm.DB) ISimilarityRepository {
+	return &SimilarityRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *SimilarityRepository) WithTx(tx *gorm.DB) ISimilarityRepository {
+	return &SimilarityRepository{db: tx}
+}
+
+// CreateReport simpan laporan baru
+func (r *SimilarityRepository) CreateReport(ctx context.Context, report *models.SimilarityReport) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(report).Error
+}
+
+// UpdateReport update status laporan tanpa menyentuh dokumen & pasangan
+func (r *SimilarityRepository) UpdateReport(ctx context.Context, report *models.SimilarityReport) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(report).Error
+}
+
+// GetReportByID ambil laporan lengkap: dokumen, pasangan urut skor tertinggi & passage
+func (r *SimilarityRepository) GetReportByID(ctx context.Context, reportID uuid.UUID) (*models.SimilarityReport, error) {
+	var report models.SimilarityReport
+	err := r.db.WithContext(ctx).
+		Preload("Documents.User").
+		Preload("Pairs", func(db *gorm.DB) *gorm.DB { return db.Order("score DESC") }).
+		Preload("Pairs.Passages", func(db *gorm.DB) *gorm.DB { return db.Order("a_start ASC") }).
+		First(&report, "id = ?", reportID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetReportsByAssignmentID riwayat laporan assignment, terbaru dulu (tanpa isi)
+func (r *SimilarityRepository) GetReportsByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.SimilarityReport, error) {
+	var reports []models.SimilarityReport
+	err := r.db.WithContext(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Order("created_at DESC").
+		Find(&reports).Error
+	return reports, err
+}
+
+// HasActiveReport masih ada laporan pending / running untuk assignment ini
+func (r *SimilarityRepository) HasActiveReport(ctx context.Context, assignmentID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.SimilarityReport{}).
+		Where("assignment_id = ? AND status IN ?", assignmentID, []models.SimilarityReportStatus{models.SimilarityPending, models.SimilarityRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FailStaleReports tandai failed laporan pending / running yang mulai (atau dibuat) sebelum batas waktu,
+// mis. prosesnya terputus karena server mati
+func (r *SimilarityRepository) FailStaleReports(ctx context.Context, before time.Time, reason string) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.SimilarityReport{}).
+		Where("status IN ? AND COALESCE(started_at, created_at) < ?", []models.SimilarityReportStatus{models.SimilarityPending, models.SimilarityRunning}, before).
+		Updates(map[string]any{
+			"status":      models.SimilarityFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+// CreateDocuments simpan teks dokumen yang dibandingkan
+func (r *SimilarityRepository) CreateDocuments(ctx context.Context, documents []models.SimilarityDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).CreateInBatches(&documents, 100).Error
+}
+
+// CreatePairs simpan pasangan beserta passage-nya
+func (r *SimilarityRepository) CreatePairs(ctx context.Context, pairs []models.SimilarityPair) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit("DocumentA", "DocumentB").CreateInBatches(&pairs, 100).Error
+}
+
+// GetSubmissionsByAssignmentID submission assignment beserta file-nya
+func (r *SimilarityRepository) GetSubmissionsByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error) {
+	var submissions []models.AssignmentSubmission
+	err := r.db.WithContext(ctx).
+		Preload("SubmissionFiles").
+		Where("assignment_id = ?", assignmentID).
+		Order("created_at ASC").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// GetPreviousBatchSubmissions submission assignment berjudul sama di batch sebelumnya dari course yang sama
+func (r *SimilarityRepository) GetPreviousBatchSubmissions(ctx context.Context, assignment *models.Assignment, batch *models.Batch) ([]models.AssignmentSubmission, error) {
+	var submissions []models.AssignmentSubmission
+	err := r.db.WithContext(ctx).
+		Preload("SubmissionFiles").
+		Joins("JOIN assignments a ON a.id = assignment_submissions.assignment_id").
+		Joins("JOIN meetings m ON m.id = a.meeting_id").
+		Joins("JOIN batches b ON b.id = m.batch_id").
+		Where("b.course_id = ? AND b.id <> ? AND b.start_at < ?", batch.CourseID, batch.ID, batch.StartAt).
+		Where("LOWER(TRIM(a.title)) = LOWER(TRIM(?)) AND a.type = ?", assignment.Title, assignment.Type).
+		Order("assignment_submissions.created_at ASC").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// GetAssignmentsDueForReport assignment yang deadline-nya (termasuk perpanjangan) sudah lewat
+// dan belum pernah dicek otomatis
+func (r *SimilarityRepository) GetAssignmentsDueForReport(ctx context.Context, now time.Time, limit int) ([]models.Assignment, error) {
+	var assignments []models.Assignment
+	err := r.db.WithContext(ctx).
+		Where("assignments.end_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM assignment_extensions e WHERE e.assignment_id = assignments.id AND e.end_at > ?)", now).
+		Where("NOT EXISTS (SELECT 1 FROM similarity_reports sr WHERE sr.assignment_id = assignments.id AND sr.automatic = true)").
+		Where("EXISTS (SELECT 1 FROM assignment_submissions s WHERE s.assignment_id = assignments.id)").
+		Order("assignments.end_at ASC").
+		Limit(limit).
+		Find(&assignments).Error
+	return assignments, err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubmissionOwnedBy kondisi submission (alias tabel) milik user: dikumpulkan sendiri atau oleh kelompoknya.
+// Placeholder user_id dipakai dua kali.
+func SubmissionOwnedBy(alias string) string {
+	return fmt.Sprintf("(%[1]s.user_id = ? OR %[1]s.group_id IN (SELECT gm.group_id FROM student_group_members gm WHERE gm.user_id = ?))", alias)
+}
+
+// MemberGradeSelect nilai akhir anggota dari grade (alias g) + penyesuaian individu (alias adj), dibatasi 0-100
+func MemberGradeSelect(g, adj string) string {
+	return fmt.Sprintf("LEAST(100, GREATEST(0, COALESCE(%[1]s.penalized_grade, %[1]s.grade) + COALESCE(%[2]s.points, 0)))", g, adj)
+}
+
+// IStudentGroupRepository interface
+type IStudentGroupRepository interface {
+	WithTx(tx *gorm.DB) IStudentGroupRepository
+	GetByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.StudentGroup, error)
+	GetByID(ctx context.Context, groupID uuid.UUID) (*models.StudentGroup, error)
+	GetByBatchAndUser(ctx context.Context, batchID, userID uuid.UUID) (*models.StudentGroup, error)
+	Create(ctx context.Context, group *models.StudentGroup) error
+	Update(ctx context.Context, group *models.StudentGroup) error
+	Delete(ctx context.Context, groupID uuid.UUID) error
+	ReplaceMembers(ctx context.Context, group *models.StudentGroup, userIDs []uuid.UUID) error
+	HasSubmissions(ctx context.Context, groupID uuid.UUID) (bool, error)
+	CountPaidStudents(ctx context.Context, batchID uuid.UUID, userIDs []uuid.UUID) (int64, error)
+	GetUngroupedStudentIDs(ctx context.Context, batchID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// StudentGroupRepository menyimpan kelompok siswa per batch
+type StudentGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewStudentGroupRepository creates a new student group repository
+func NewStudentGroupRepository(db *gorm.DB) IStudentGroupRepository {
+	return &StudentGroupRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *StudentGroupRepository) WithTx(tx *gorm.DB) IStudentGroupRepository {
+	return &StudentGroupRepository{db: tx}
+}
+
+func preloadGroupMembers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Members.User").Preload("Members", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") })
+}
+
+// GetByBatchID semua kelompok batch beserta anggotanya
+func (r *StudentGroupRepository) GetByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.StudentGroup, error) {
+	var groups []models.StudentGroup
+	err := preloadGroupMembers(r.db.WithContext(ctx)).
+		Where("batch_id = ?", batchID).
+		Order("name ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+// GetByID kelompok beserta anggotanya
+func (r *StudentGroupRepository) GetByID(ctx context.Context, groupID uuid.UUID) (*models.StudentGroup, error) {
+	var group models.StudentGroup
+	if err := preloadGroupMembers(r.db.WithContext(ctx)).First(&group, "id = ?", groupID).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetByBatchAndUser kelompok siswa di batch
+func (r *StudentGroupRepository) GetByBatchAndUser(ctx context.Context, batchID, userID uuid.UUID) (*models.StudentGroup, error) {
+	var group models.StudentGroup
+	err := preloadGroupMembers(r.db.WithContext(ctx)).
+		Where("id = (SELECT group_id FROM student_group_members WHERE batch_id = ? AND user_id = ?)", batchID, userID).
+		First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// Create simpan kelompok baru tanpa anggota
+func (r *StudentGroupRepository) Create(ctx context.Context, group *models.StudentGroup) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(group).Error
+}
+
+// Update simpan nama kelompok
+func (r *StudentGroupRepository) Update(ctx context.Context, group *models.StudentGroup) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(group).Error
+}
+
+// Delete hapus kelompok beserta anggotanya
+func (r *StudentGroupRepository) Delete(ctx context.Context, groupID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.StudentGroup{}, "id = ?", groupID).Error
+}
+
+// ReplaceMembers ganti seluruh anggota kelompok
+func (r *StudentGroupRepository) ReplaceMembers(ctx context.Context, group *models.StudentGroup, userIDs []uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("group_id = ?", group.ID).
+		Delete(&models.StudentGroupMember{}).Error; err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]models.StudentGroupMember, 0, len(userIDs))
+	for _, id := range userIDs {
+		members = append(members, models.StudentGroupMember{GroupID: group.ID, BatchID: group.BatchID, UserID: id})
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(&members).Error
+}
+
+// HasSubmissions kelompok sudah pernah mengumpulkan assignment
+func (r *StudentGroupRepository) HasSubmissions(ctx context.Context, groupID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.AssignmentSubmission{}).
+		Where("group_id = ?", groupID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountPaidStudents berapa dari userIDs yang siswa lunas di batch
+func (r *StudentGroupRepository) CountPaidStudents(ctx context.Context, batchID uuid.UUID, userIDs []uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("users.id IN ? AND users.role_type = ?", userIDs, models.RoleTypeSiswa).
+		Where("EXISTS (SELECT 1 FROM purchases p WHERE p.user_id = users.id AND p.batch_id = ? AND p.payment_status = ?)", batchID, models.Paid).
+		Count(&count).Error
+	return count, err
+}
+
+// GetUngroupedStudentIDs siswa lunas di batch yang belum punya kelompok
+func (r *StudentGroupRepository) GetUngroupedStudentIDs(ctx context.Context, batchID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("users.role_type = ?", models.RoleTypeSiswa).
+		Where("EXISTS (SELECT 1 FROM purchases p WHERE p.user_id = users.id AND p.batch_id = ? AND p.payment_status = ?)", batchID, models.Paid).
+		Where("NOT EXISTS (SELECT 1 FROM student_group_members gm WHERE gm.user_id = users.id AND gm.batch_id = ?)", batchID).
+		Order("users.name ASC").
+		Pluck("users.id", &ids).Error
+	return ids, err
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ISubmisssionRepository interface
@@ -31,8 +32,34 @@ type ISubmisssionRepository interface {
 	GetByIDUser(ctx context.Context, submissionID, userID uuid.UUID) (*models.AssignmentSubmission, error)
 	GetGradeBySubmissionID(ctx context.Context, submissionID uuid.UUID) (*models.AssignmentGrade, error)
 	UpsertGrade(ctx context.Context, grade models.AssignmentGrade) (models.AssignmentGrade, error)
+	ReplaceGradeCriteria(ctx context.Context, gradeID uuid.UUID, criteria []models.AssignmentGradeCriterion) error
+	ReplaceGradeAdjustments(ctx context.Context, gradeID uuid.UUID, adjustments []models.AssignmentGradeAdjustment) error
+	FindUserGroupID(ctx context.Context, assignmentID, userID uuid.UUID) (*uuid.UUID, error)
 	CountCompletedByBatchUser(ctx context.Context, batchID, userID uuid.UUID) (int64, error)
-	GetAssignmentsWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID) ([]dto.AssignmentScore, error)
+	GetAssignmentsWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID, releasedOnly bool) ([]dto.AssignmentScore, error)
+	GetUserDeadline(ctx context.Context, assignmentID, userID uuid.UUID) (time.Time, error)
+	UpdateGradeFinal(ctx context.Context, grade *models.AssignmentGrade) error
+	GetPeerScores(ctx context.Context, submissionID uuid.UUID) ([]float64, error)
+	CreateVersion(ctx context.Context, version *models.SubmissionVersion) error
+	GetVersions(ctx context.Context, submissionID uuid.UUID) ([]models.SubmissionVersion, error)
+	GetVersion(ctx context.Context, submissionID uuid.UUID, version int) (*models.SubmissionVersion, error)
+	SetRevisionRequest(ctx context.Context, submissionID uuid.UUID, note *string, dueAt *time.Time) error
+	GetArchiveByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error)
+	UpdateGradeModeration(ctx context.Context, grade *models.AssignmentGrade) error
+	SubmitDraftGrades(ctx context.Context, assignmentID uuid.UUID) (int64, error)
+	ReleaseModeratedGrades(ctx context.Context, assignmentID uuid.UUID, includeFlagged bool, releasedAt time.Time) (int64, error)
+	GetModerationProgressByBatch(ctx context.Context, batchID uuid.UUID) ([]dto.ModerationProgress, error)
+	GetStudentsByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.User, error)
+}
+
+// GradeReleased kondisi grade (alias tabel) sudah dirilis ke siswa
+func GradeReleased(alias string) string {
+	return fmt.Sprintf("%s.moderation_status = '%s'", alias, models.GradeReleased)
+}
+
+// preloadReleasedGrade preload grade yang sudah dirilis saja, untuk tampilan siswa
+func preloadReleasedGrade(db *gorm.DB) *gorm.DB {
+	return db.Where(GradeReleased("assignment_grades"))
 }
 
 // SubmissionRepository provides methods for managing submissions
@@ -68,9 +95,11 @@ func (r *SubmissionRepository) GetAllByAssignment(ctx context.Context, assignmen
 		Where("assignment_id = ?", assignmentID).
 		Model(&models.AssignmentSubmission{})
 
-	// Filter user_id kalau dikasih
+	// Filter user_id kalau dikasih, submission kelompok ikut terlihat oleh semua anggota.
+	// Siswa hanya melihat nilai yang sudah dirilis.
 	if userID != nil {
-		db = db.Where("user_id = ?", *userID)
+		db = db.Where(SubmissionOwnedBy("assignment_submissions"), *userID, *userID).
+			Preload("AssignmentGrade", preloadReleasedGrade)
 	}
 
 	db = utils.ApplyFiltersWithJoins(db, "assignment_submissions", opts.Filters, validSortFields, map[string]string{}, map[string]bool{})
@@ -93,7 +122,8 @@ func (r *SubmissionRepository) GetAllByAssignment(ctx context.Context, assignmen
 func (r *SubmissionRepository) GetByIDAssignmentUser(ctx context.Context, submissionID, assignmentID, userID uuid.UUID) (models.AssignmentSubmission, error) {
 	var submission models.AssignmentSubmission
 	err := r.db.WithContext(ctx).Preload("SubmissionFiles").Preload("Assignment").Preload("User").Preload("AssignmentGrade").Preload("AssignmentGrade.GradedByUser").
-		Where("id = ? AND assignment_id = ? AND user_id = ?", submissionID, assignmentID, userID).
+		Where("id = ? AND assignment_id = ?", submissionID, assignmentID).
+		Where(SubmissionOwnedBy("assignment_submissions"), userID, userID).
 		First(&submission).Error
 
 	return submission, err
@@ -103,7 +133,8 @@ func (r *SubmissionRepository) GetByIDAssignmentUser(ctx context.Context, submis
 func (r *SubmissionRepository) FindByAssignmentAndUserID(ctx context.Context, assignmentID uuid.UUID, userID uuid.UUID) (*models.AssignmentSubmission, error) {
 	var submission models.AssignmentSubmission
 	err := r.db.WithContext(ctx).
-		Where("assignment_id = ? AND user_id = ?", assignmentID, userID).
+		Where("assignment_id = ?", assignmentID).
+		Where(SubmissionOwnedBy("assignment_submissions"), userID, userID).
 		First(&submission).Error
 	if err != nil {
 		return nil, err
@@ -111,16 +142,23 @@ func (r *SubmissionRepository) FindByAssignmentAndUserID(ctx context.Context, as
 	return &submission, nil
 }
 
-// GetAssignmentsWithScoresByBatchUser get scores by batch id and user id
-func (r *SubmissionRepository) GetAssignmentsWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID) ([]dto.AssignmentScore, error) {
+// GetAssignmentsWithScoresByBatchUser get scores by batch id and user id.
+// releasedOnly untuk tampilan siswa: nilai yang belum dirilis dihitung 0.
+func (r *SubmissionRepository) GetAssignmentsWithScoresByBatchUser(ctx context.Context, batchID, userID uuid.UUID, releasedOnly bool) ([]dto.AssignmentScore, error) {
 	var results []dto.AssignmentScore
 
+	gradeJoin := "LEFT JOIN assignment_grades ag ON ag.assignment_submission_id = s.id"
+	if releasedOnly {
+		gradeJoin += " AND " + GradeReleased("ag")
+	}
+
 	err := r.db.WithContext(ctx).
 		Model(&models.Assignment{}).
-		Select("assignments.*, COALESCE(MAX(ag.grade), 0) as score").
+		Select("assignments.*, COALESCE(MAX("+MemberGradeSelect("ag", "adj")+"), 0) as score").
 		Joins("JOIN meetings m ON m.id = assignments.meeting_id").
-		Joins("LEFT JOIN assignment_submissions s ON s.assignment_id = assignments.id AND s.user_id = ?", userID).
-		Joins("LEFT JOIN assignment_grades ag ON ag.assignment_submission_id = s.id").
+		Joins("LEFT JOIN assignment_submissions s ON s.assignment_id = assignments.id AND "+SubmissionOwnedBy("s"), userID, userID).
+		Joins(gradeJoin).
+		Joins("LEFT JOIN assignment_grade_adjustments adj ON adj.grade_id = ag.id AND adj.user_id = ?", userID).
 		Where("m.batch_id = ?", batchID).
 		Group("assignments.id").
 		Scan(&results).Error
@@ -134,20 +172,53 @@ func (r *SubmissionRepository) GetAssignmentsWithScoresByBatchUser(ctx context.C
 
 // Create is for create assignment_submissions
 func (r *SubmissionRepository) Create(ctx context.Context, submission *models.AssignmentSubmission) error {
-	// Ambil end_at dari assignment
+	return r.db.WithContext(ctx).Create(submission).Error
+}
+
+// GetUserDeadline deadline assignment untuk siswa, perpanjangan per siswa menggantikan end_at assignment
+func (r *SubmissionRepository) GetUserDeadline(ctx context.Context, assignmentID, userID uuid.UUID) (time.Time, error) {
 	var assignment models.Assignment
 	if err := r.db.WithContext(ctx).
 		Select("end_at").
-		Where("id = ?", submission.AssignmentID).
+		Where("id = ?", assignmentID).
 		First(&assignment).Error; err != nil {
-		return err
+		return time.Time{}, err
 	}
 
-	// Hitung telat atau tidak
-	submission.IsLate = time.Now().After(assignment.EndAt)
+	var ext models.AssignmentExtension
+	err := r.db.WithContext(ctx).
+		Select("end_at").
+		Where("assignment_id = ? AND user_id = ?", assignmentID, userID).
+		First(&ext).Error
+	if err == nil {
+		return ext.EndAt, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, err
+	}
+	return assignment.EndAt, nil
+}
 
-	// Simpan submission
-	return r.db.WithContext(ctx).Create(submission).Error
+// UpdateGradeFinal simpan ulang nilai peer & potongan telat tanpa mengubah nilai mentah
+func (r *SubmissionRepository) UpdateGradeFinal(ctx context.Context, grade *models.AssignmentGrade) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AssignmentGrade{}).
+		Where("id = ?", grade.ID).
+		Updates(map[string]any{
+			"peer_score":           grade.PeerScore,
+			"peer_weight":          grade.PeerWeight,
+			"late_penalty_percent": grade.LatePenaltyPercent,
+			"penalized_grade":      grade.PenalizedGrade,
+		}).Error
+}
+
+// GetPeerScores nilai peer review yang sudah dikirim & tidak dikeluarkan guru
+func (r *SubmissionRepository) GetPeerScores(ctx context.Context, submissionID uuid.UUID) ([]float64, error) {
+	var scores []float64
+	err := r.db.WithContext(ctx).
+		Model(&models.PeerReview{}).
+		Where("submission_id = ? AND submitted_at IS NOT NULL AND excluded = false AND score IS NOT NULL", submissionID).
+		Pluck("score", &scores).Error
+	return scores, err
 }
 
 // CreateSubmissionFiles for create submission_files
@@ -158,14 +229,17 @@ func (r *SubmissionRepository) CreateSubmissionFiles(ctx context.Context, files
 // GetByAssignmentUser is get submission by assignment id and user id
 func (r *SubmissionRepository) GetByAssignmentUser(ctx context.Context, assignmentID, userID uuid.UUID) (models.AssignmentSubmission, error) {
 	var submission models.AssignmentSubmission
-	err := r.db.WithContext(ctx).Where("assignment_id = ? AND user_id = ?", assignmentID, userID).First(&submission).Error
+	err := r.db.WithContext(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Where(SubmissionOwnedBy("assignment_submissions"), userID, userID).
+		First(&submission).Error
 	return submission, err
 }
 
 // FindByID get submission by id with preload submissionFiles
 func (r *SubmissionRepository) FindByID(ctx context.Context, id uuid.UUID) (models.AssignmentSubmission, error) {
 	var submission models.AssignmentSubmission
-	err := r.db.WithContext(ctx).Preload("SubmissionFiles").Preload("Assignment").Preload("User").Preload("AssignmentGrade").Preload("AssignmentGrade.GradedByUser").Where("id = ?", id).First(&submission).Error
+	err := r.db.WithContext(ctx).Preload("SubmissionFiles").Preload("Assignment").Preload("User").Preload("AssignmentGrade").Preload("AssignmentGrade.GradedByUser").Preload("AssignmentGrade.Criteria", orderGradeCriteria).Preload("AssignmentGrade.Adjustments").Preload("Group.Members.User").Where("id = ?", id).First(&submission).Error
 	return submission, err
 }
 
@@ -192,12 +266,15 @@ func (r *SubmissionRepository) DeleteByID(ctx context.Context, id uuid.UUID) err
 		Error
 }
 
-// GetByIDUser get submission by id and user id
+// GetByIDUser get submission by id and user id, nilai yang belum dirilis tidak ikut
 func (r *SubmissionRepository) GetByIDUser(ctx context.Context, submissionID, userID uuid.UUID) (*models.AssignmentSubmission, error) {
 	var submission models.AssignmentSubmission
 	err := r.db.WithContext(ctx).
-		Preload("SubmissionFiles").Preload("Assignment").Preload("User").Preload("AssignmentGrade").Preload("AssignmentGrade.GradedByUser").
-		Where("id = ? AND user_id = ?", submissionID, userID).
+		Preload("SubmissionFiles").Preload("Assignment").Preload("User").Preload("AssignmentGrade", preloadReleasedGrade).Preload("AssignmentGrade.GradedByUser").
+		Preload("AssignmentGrade.Criteria", orderGradeCriteria).
+		Preload("AssignmentGrade.Adjustments").
+		Where("id = ?", submissionID).
+		Where(SubmissionOwnedBy("assignment_submissions"), userID, userID).
 		First(&submission).Error
 	if err != nil {
 		return nil, err
@@ -208,7 +285,7 @@ func (r *SubmissionRepository) GetByIDUser(ctx context.Context, submissionID, us
 // GetGradeBySubmissionID repo get grade by submission id
 func (r *SubmissionRepository) GetGradeBySubmissionID(ctx context.Context, submissionID uuid.UUID) (*models.AssignmentGrade, error) {
 	var grade models.AssignmentGrade
-	err := r.db.WithContext(ctx).Preload("GradedByUser").
+	err := r.db.WithContext(ctx).Preload("GradedByUser").Preload("Criteria", orderGradeCriteria).Preload("Adjustments").
 		Where("assignment_submission_id = ?", submissionID).
 		First(&grade).Error
 
@@ -223,8 +300,9 @@ func (r *SubmissionRepository) GetGradeBySubmissionID(ctx context.Context, submi
 func (r *SubmissionRepository) GetGradesByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error) {
 	var submissions []models.AssignmentSubmission
 	err := r.db.WithContext(ctx).
-		Preload("User").
+		Preload("User.Profile").
 		Preload("AssignmentGrade").
+		Preload("AssignmentGrade.Criteria", orderGradeCriteria).
 		Where("assignment_id = ?", assignmentID).
 		Find(&submissions).Error
 	if err != nil {
@@ -251,6 +329,22 @@ func (r *SubmissionRepository) UpsertGrade(ctx context.Context, grade models.Ass
 	existing.Grade = grade.Grade
 	existing.Feedback = grade.Feedback
 	existing.GradedBy = grade.GradedBy
+	existing.RubricID = grade.RubricID
+	existing.RubricPoints = grade.RubricPoints
+	existing.RubricMaxPoints = grade.RubricMaxPoints
+	existing.LatePenaltyPercent = grade.LatePenaltyPercent
+	existing.PenalizedGrade = grade.PenalizedGrade
+	existing.Version = grade.Version
+	existing.PeerScore = grade.PeerScore
+	existing.PeerWeight = grade.PeerWeight
+	// nilai ulang memulai moderasi dari awal
+	existing.ModerationStatus = grade.ModerationStatus
+	existing.ModeratorID = grade.ModeratorID
+	existing.ModeratedAt = grade.ModeratedAt
+	existing.ModerationComment = grade.ModerationComment
+	existing.OriginalGrade = grade.OriginalGrade
+	existing.ModerationFlagged = grade.ModerationFlagged
+	existing.ReleasedAt = grade.ReleasedAt
 
 	if err := r.db.WithContext(ctx).Save(&existing).Error; err != nil {
 		return models.AssignmentGrade{}, err
@@ -258,6 +352,58 @@ func (r *SubmissionRepository) UpsertGrade(ctx context.Context, grade models.Ass
 	return existing, nil
 }
 
+// ReplaceGradeCriteria ganti rincian nilai rubrik, criteria kosong = nilai tanpa rubrik
+func (r *SubmissionRepository) ReplaceGradeCriteria(ctx context.Context, gradeID uuid.UUID, criteria []models.AssignmentGradeCriterion) error {
+	if err := r.db.WithContext(ctx).
+		Where("grade_id = ?", gradeID).
+		Delete(&models.AssignmentGradeCriterion{}).Error; err != nil {
+		return err
+	}
+	if len(criteria) == 0 {
+		return nil
+	}
+	for i := range criteria {
+		criteria[i].GradeID = gradeID
+	}
+	return r.db.WithContext(ctx).Create(&criteria).Error
+}
+
+// ReplaceGradeAdjustments ganti seluruh penyesuaian nilai individu pada grade
+func (r *SubmissionRepository) ReplaceGradeAdjustments(ctx context.Context, gradeID uuid.UUID, adjustments []models.AssignmentGradeAdjustment) error {
+	if err := r.db.WithContext(ctx).
+		Where("grade_id = ?", gradeID).
+		Delete(&models.AssignmentGradeAdjustment{}).Error; err != nil {
+		return err
+	}
+	if len(adjustments) == 0 {
+		return nil
+	}
+	for i := range adjustments {
+		adjustments[i].GradeID = gradeID
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(&adjustments).Error
+}
+
+// FindUserGroupID kelompok user di batch tempat assignment berada, nil kalau belum punya kelompok
+func (r *SubmissionRepository) FindUserGroupID(ctx context.Context, assignmentID, userID uuid.UUID) (*uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("student_group_members gm").
+		Joins("JOIN meetings m ON m.batch_id = gm.batch_id").
+		Joins("JOIN assignments a ON a.meeting_id = m.id").
+		Where("a.id = ? AND gm.user_id = ?", assignmentID, userID).
+		Limit(1).
+		Pluck("gm.group_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return &ids[0], nil
+}
+
+func orderGradeCriteria(db *gorm.DB) *gorm.DB {
+	return db.Order(`"order" ASC`)
+}
+
 // CountCompletedByBatchUser for count completed submission by batch id and user id
 func (r *SubmissionRepository) CountCompletedByBatchUser(ctx context.Context, batchID, userID uuid.UUID) (int64, error) {
 	var count int64
@@ -265,8 +411,145 @@ func (r *SubmissionRepository) CountCompletedByBatchUser(ctx context.Context, ba
 		Model(&models.AssignmentSubmission{}).
 		Joins("JOIN assignments ON assignments.id = assignment_submissions.assignment_id").
 		Joins("JOIN meetings ON meetings.id = assignments.meeting_id").
-		Where("meetings.batch_id = ? AND assignment_submissions.user_id = ?", batchID, userID).
+		Where("meetings.batch_id = ?", batchID).
+		Where(SubmissionOwnedBy("assignment_submissions"), userID, userID).
 		Distinct("assignment_submissions.assignment_id").
 		Count(&count).Error
 	return count, err
 }
+
+// CreateVersion simpan snapshot versi submission beserta file-nya
+func (r *SubmissionRepository) CreateVersion(ctx context.Context, version *models.SubmissionVersion) error {
+	return r.db.WithContext(ctx).Omit("AssignmentSubmission").Create(version).Error
+}
+
+// GetVersions riwayat versi submission, dari yang paling lama
+func (r *SubmissionRepository) GetVersions(ctx context.Context, submissionID uuid.UUID) ([]models.SubmissionVersion, error) {
+	var versions []models.SubmissionVersion
+	err := r.db.WithContext(ctx).
+		Preload("Files").
+		Where("assignment_submission_id = ?", submissionID).
+		Order("version ASC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// GetVersion satu versi submission berdasarkan nomornya
+func (r *SubmissionRepository) GetVersion(ctx context.Context, submissionID uuid.UUID, version int) (*models.SubmissionVersion, error) {
+	var v models.SubmissionVersion
+	err := r.db.WithContext(ctx).
+		Preload("Files").
+		Where("assignment_submission_id = ? AND version = ?", submissionID, version).
+		First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SetRevisionRequest tandai submission dikembalikan untuk revisi
+func (r *SubmissionRepository) SetRevisionRequest(ctx context.Context, submissionID uuid.UUID, note *string, dueAt *time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AssignmentSubmission{}).
+		Where("id = ?", submissionID).
+		Updates(map[string]any{"revision_requested": true, "revision_note": note, "revision_due_at": dueAt}).Error
+}
+
+// GetArchiveByAssignmentID submission assignment beserta profil siswa & file, untuk unduhan ZIP
+func (r *SubmissionRepository) GetArchiveByAssignmentID(ctx context.Context, assignmentID uuid.UUID) ([]models.AssignmentSubmission, error) {
+	var submissions []models.AssignmentSubmission
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("User.Profile").
+		Preload("SubmissionFiles").
+		Where("assignment_id = ?", assignmentID).
+		Find(&submissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// UpdateGradeModeration simpan hasil moderasi penilai kedua
+func (r *SubmissionRepository) UpdateGradeModeration(ctx context.Context, grade *models.AssignmentGrade) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AssignmentGrade{}).
+		Where("id = ?", grade.ID).
+		Updates(map[string]any{
+			"grade":                grade.Grade,
+			"late_penalty_percent": grade.LatePenaltyPercent,
+			"penalized_grade":      grade.PenalizedGrade,
+			"moderation_status":    grade.ModerationStatus,
+			"moderator_id":         grade.ModeratorID,
+			"moderated_at":         grade.ModeratedAt,
+			"moderation_comment":   grade.ModerationComment,
+			"original_grade":       grade.OriginalGrade,
+			"moderation_flagged":   grade.ModerationFlagged,
+		}).Error
+}
+
+// gradesOfAssignment grade milik submission di satu assignment
+func (r *SubmissionRepository) gradesOfAssignment(ctx context.Context, assignmentID uuid.UUID) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&models.AssignmentGrade{}).
+		Where("assignment_submission_id IN (?)", r.db.Model(&models.AssignmentSubmission{}).Select("id").Where("assignment_id = ?", assignmentID))
+}
+
+// SubmitDraftGrades kirim semua nilai draft assignment ke penilai kedua
+func (r *SubmissionRepository) SubmitDraftGrades(ctx context.Context, assignmentID uuid.UUID) (int64, error) {
+	res := r.gradesOfAssignment(ctx, assignmentID).
+		Where("moderation_status = ?", models.GradeDraft).
+		Update("moderation_status", models.GradeSubmitted)
+	return res.RowsAffected, res.Error
+}
+
+// ReleaseModeratedGrades rilis nilai yang sudah dimoderasi, nilai yang ditandai hanya kalau includeFlagged
+func (r *SubmissionRepository) ReleaseModeratedGrades(ctx context.Context, assignmentID uuid.UUID, includeFlagged bool, releasedAt time.Time) (int64, error) {
+	db := r.gradesOfAssignment(ctx, assignmentID).
+		Where("moderation_status = ?", models.GradeModerated)
+	if !includeFlagged {
+		db = db.Where("moderation_flagged = false")
+	}
+	res := db.Updates(map[string]any{
+		"moderation_status": models.GradeReleased,
+		"released_at":       releasedAt,
+	})
+	return res.RowsAffected, res.Error
+}
+
+// GetModerationProgressByBatch jumlah submission per status moderasi untuk tiap assignment ujian di batch
+func (r *SubmissionRepository) GetModerationProgressByBatch(ctx context.Context, batchID uuid.UUID) ([]dto.ModerationProgress, error) {
+	var results []dto.ModerationProgress
+	err := r.db.WithContext(ctx).
+		Model(&models.Assignment{}).
+		Select(`assignments.id AS assignment_id, assignments.meeting_id, assignments.title,
+			COUNT(s.id) AS submissions,
+			COUNT(s.id) FILTER (WHERE ag.id IS NULL) AS ungraded,
+			COUNT(ag.id) FILTER (WHERE ag.moderation_status = ?) AS draft,
+			COUNT(ag.id) FILTER (WHERE ag.moderation_status = ?) AS submitted,
+			COUNT(ag.id) FILTER (WHERE ag.moderation_status = ?) AS moderated,
+			COUNT(ag.id) FILTER (WHERE ag.moderation_status = ?) AS released,
+			COUNT(ag.id) FILTER (WHERE ag.moderation_flagged AND ag.moderation_status <> ?) AS flagged`,
+			models.GradeDraft, models.GradeSubmitted, models.GradeModerated, models.GradeReleased, models.GradeReleased).
+		Joins("JOIN meetings m ON m.id = assignments.meeting_id").
+		Joins("LEFT JOIN assignment_submissions s ON s.assignment_id = assignments.id").
+		Joins("LEFT JOIN assignment_grades ag ON ag.assignment_submission_id = s.id").
+		Where("m.batch_id = ? AND m.type = ?", batchID, models.ExamMeeting).
+		Group("assignments.id").
+		Order("assignments.start_at ASC").
+		Scan(&results).Error
+	return results, err
+}
+
+// GetStudentsByBatchID siswa lunas di batch beserta profil (NIM), untuk mencocokkan import nilai
+func (r *SubmissionRepository) GetStudentsByBatchID(ctx context.Context, batchID uuid.UUID) ([]models.User, error) {
+	var students []models.User
+	err := r.db.WithContext(ctx).
+		Preload("Profile").
+		Joins("JOIN purchases ON purchases.user_id = users.id").
+		Where("purchases.batch_id = ? AND purchases.payment_status = ?", batchID, models.Paid).
+		Where("users.role_type = ?", models.RoleTypeSiswa).
+		Group("users.id").
+		Find(&students).Error
+	return students, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"brevet-api/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IAnnotationRepository interface
+type IAnnotationRepository interface {
+	WithTx(tx *gorm.DB) IAnnotationRepository
+	GetFileByID(ctx context.Context, fileID uuid.UUID) (*models.SubmissionFile, error)
+	GetByFileID(ctx context.Context, fileID uuid.UUID) ([]models.SubmissionAnnotation, error)
+	GetBySubmissionID(ctx context.Context, submissionID uuid.UUID) ([]models.SubmissionAnnotation, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SubmissionAnnotation, error)
+	Create(ctx context.Context, annotation *models.SubmissionAnnotation) error
+	Update(ctx context.Context, annotation *models.SubmissionAnnotation) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// AnnotationRepository menyimpan anotasi PDF submission
+type AnnotationRepository struct {
+	db *gorm.DB
+}
+
+// NewAnnotationRepository creates a new annotation repository
+func NewAnnotationRepository(db *gorm.DB) IAnnotationRepository {
+	return &AnnotationRepository{db: db}
+}
+
+// WithTx running with transaction
+func (r *AnnotationRepository) WithTx(tx *gorm.DB) IAnnotationRepository {
+	return &AnnotationRepository{db: tx}
+}
+
+func orderAnnotations(db *gorm.DB) *gorm.DB {
+	return db.Order("page ASC").Order("y ASC").Order("x ASC")
+}
+
+// GetFileByID file submission
+func (r *AnnotationRepository) GetFileByID(ctx context.Context, fileID uuid.UUID) (*models.SubmissionFile, error) {
+	var file models.SubmissionFile
+	if err := r.db.WithContext(ctx).First(&file, "id = ?", fileID).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetByFileID anotasi satu file, urut halaman lalu posisi
+func (r *AnnotationRepository) GetByFileID(ctx context.Context, fileID uuid.UUID) ([]models.SubmissionAnnotation, error) {
+	var annotations []models.SubmissionAnnotation
+	err := orderAnnotations(r.db.WithContext(ctx).Preload("Author")).
+		Where("submission_file_id = ?", fileID).
+		Find(&annotations).Error
+	return annotations, err
+}
+
+// GetBySubmissionID semua anotasi submission termasuk milik file versi lama
+func (r *AnnotationRepository) GetBySubmissionID(ctx context.Context, submissionID uuid.UUID) ([]models.SubmissionAnnotation, error) {
+	var annotations []models.SubmissionAnnotation
+	err := orderAnnotations(r.db.WithContext(ctx).Preload("Author").Order("version DESC").Order("file_url ASC")).
+		Where("submission_id = ?", submissionID).
+		Find(&annotations).Error
+	return annotations, err
+}
+
+// GetByID satu anotasi
+func (r *AnnotationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SubmissionAnnotation, error) {
+	var annotation models.SubmissionAnnotation
+	if err := r.db.WithContext(ctx).Preload("Author").First(&annotation, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &annotation, nil
+}
+
+// Create simpan anotasi baru
+func (r *AnnotationRepository) Create(ctx context.Context, annotation *models.SubmissionAnnotation) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(annotation).Error
+}
+
+// Update simpan perubahan anotasi
+func (r *AnnotationRepository) Update(ctx context.Context, annotation *models.SubmissionAnnotation) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(annotation).Error
+}
+
+// Delete hapus anotasi
+func (r *AnnotationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.SubmissionAnnotation{}, "id = ?", id).Error
+}
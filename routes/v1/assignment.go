@@ -22,7 +22,7 @@ func RegisterAssignmentRoutes(r fiber.Router, db *gorm.DB) {
 
 	meetingRepo := repository.NewMeetingRepository(db)
 	assignmentRepository := repository.NewAssignmentRepository(db)
-	assignmentService := services.NewAssignmentService(assignmentRepository, meetingRepo, purchaseRepo, fileService, db)
+	assignmentService := services.NewAssignmentService(assignmentRepository, meetingRepo, purchaseRepo, repository.NewSubmissionRepository(db), fileService, db)
 
 	assignmentController := controllers.NewAssignmentController(assignmentService, db)
 
@@ -40,6 +40,7 @@ func RegisterAssignmentRoutes(r fiber.Router, db *gorm.DB) {
 	// 				Submissions
 	// ==================================
 	submissionRepository := repository.NewSubmissionRepository(db)
+	rubricRepository := repository.NewRubricRepository(db)
 	attendanceRepository := repository.NewAttendanceRepository(db)
 	quizRepository := repository.NewQuizRepository(db)
 	meetingRepository := repository.NewMeetingRepository(db)
@@ -49,14 +50,56 @@ func RegisterAssignmentRoutes(r fiber.Router, db *gorm.DB) {
 	}
 	batchRepository := repository.NewBatchRepository(db)
 	purchaseService := services.NewPurchaseService(purchaseRepo, userRepo, batchRepository, emailService, db)
-	submissionService := services.NewSubmissionService(submissionRepository, assignmentRepository, meetingRepository, attendanceRepository, quizRepository, purchaseService, fileService, db)
+	submissionService := services.NewSubmissionService(submissionRepository, assignmentRepository, meetingRepository, attendanceRepository, quizRepository, rubricRepository, purchaseService, fileService, db)
 	submissionController := controllers.NewSubmissionController(submissionService, db)
+
+	accommodationRepo := repository.NewAccommodationRepository(db)
+	accommodationService := services.NewAccommodationService(accommodationRepo, quizRepository, assignmentRepository, meetingRepository, batchRepository, userRepo, purchaseService, db)
+	accommodationController := controllers.NewAccommodationController(accommodationService, db)
+	r.Get("/:assignmentID/extensions", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), accommodationController.ListAssignmentExtensions)
+	r.Put("/:assignmentID/extensions", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.UpsertAssignmentExtensionRequest](),
+		accommodationController.UpsertAssignmentExtension)
+	r.Delete("/:assignmentID/extensions/:userID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), accommodationController.DeleteAssignmentExtension)
+
 	r.Get("/:assignmentID/submissions", middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa", "guru", "admin"}), submissionController.GetAllSubmissionByAssignmentID)
 
 	r.Post("/:assignmentID/submissions", middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa"}), middlewares.ValidateBody[dto.CreateSubmissionRequest](),
 		submissionController.CreateSubmission)
+	r.Get("/:assignmentID/submissions/archive", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), submissionController.DownloadSubmissionArchive)
+
+	rubricService := services.NewRubricService(rubricRepository, assignmentRepository, meetingRepository, db)
+	rubricController := controllers.NewRubricController(rubricService, db)
+	r.Put("/:assignmentID/rubric", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.AttachRubricRequest](),
+		rubricController.AttachRubric)
+
+	similarityService := services.NewSimilarityService(repository.NewSimilarityRepository(db), assignmentRepository, meetingRepository, batchRepository, db)
+	similarityController := controllers.NewSimilarityController(similarityService, db)
+	r.Get("/similarity/:reportID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), similarityController.GetReportByID)
+	r.Get("/similarity/:reportID/documents/:documentID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), similarityController.GetReportDocument)
+	r.Post("/:assignmentID/similarity", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.RunSimilarityRequest](),
+		similarityController.RunSimilarityCheck)
+	r.Get("/:assignmentID/similarity", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), similarityController.GetReportsByAssignmentID)
+
+	peerReviewService := services.NewPeerReviewService(repository.NewPeerReviewRepository(db), assignmentRepository, submissionRepository, meetingRepository, rubricRepository, db)
+	peerReviewController := controllers.NewPeerReviewController(peerReviewService, db)
+	r.Patch("/peer-reviews/:reviewID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.SetPeerReviewExcludedRequest](),
+		peerReviewController.SetReviewExcluded)
+	r.Get("/:assignmentID/peer-reviews", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), peerReviewController.GetAssignmentReviews)
+	r.Post("/:assignmentID/peer-reviews/distribute", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), peerReviewController.DistributeReviews)
 
 	r.Get("/:assignmentID/grades/excel", middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"guru", "admin"}), submissionController.GenerateGradesExcel,
@@ -64,5 +107,9 @@ func RegisterAssignmentRoutes(r fiber.Router, db *gorm.DB) {
 	r.Put("/:assignmentID/grades/import", middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"guru", "admin"}), submissionController.ImportGradesFromExcel,
 	)
+	r.Post("/:assignmentID/grades/submit", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), submissionController.SubmitGradesForModeration)
+	r.Post("/:assignmentID/grades/release", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), submissionController.ReleaseGrades)
 
 }
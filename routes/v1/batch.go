@@ -119,4 +119,35 @@ func RegisterBatchRoute(r fiber.Router, db *gorm.DB) {
 		middlewares.RequireRole([]string{"admin", "guru"}),
 		certificateController.GetBatchCertificates)
 
+	// ==================================
+	// 				Student Groups
+	// ==================================
+	studentGroupService := services.NewStudentGroupService(repository.NewStudentGroupRepository(db), batchRepository, meetingRepository, db)
+	studentGroupController := controllers.NewStudentGroupController(studentGroupService, db)
+	r.Get("/:batchID/groups", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}), studentGroupController.GetGroups)
+	r.Post("/:batchID/groups", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.CreateStudentGroupRequest](), studentGroupController.CreateGroup)
+	r.Post("/:batchID/groups/generate", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.GenerateStudentGroupsRequest](), studentGroupController.GenerateGroups)
+	r.Patch("/groups/:groupID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.UpdateStudentGroupRequest](), studentGroupController.UpdateGroup)
+	r.Delete("/groups/:groupID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}), studentGroupController.DeleteGroup)
+
+	// ==================================
+	// 		Penilaian & Moderasi Nilai
+	// ==================================
+	submissionService := services.NewSubmissionService(submissionRepository, assignmentRepository, meetingRepository, attendanceRepository, quizRepository, repository.NewRubricRepository(db), purchaseService, fileService, db)
+	submissionController := controllers.NewSubmissionController(submissionService, db)
+	r.Get("/:batchID/moderation", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin"}), submissionController.GetModerationProgress)
+	r.Get("/:batchID/grades/export", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}), submissionController.ExportBatchGrades)
+	r.Put("/:batchID/grades/import", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}), submissionController.ImportBatchGrades)
+
 }
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"brevet-api/controllers"
+	"brevet-api/dto"
 	"brevet-api/middlewares"
 	"brevet-api/repository"
 	"brevet-api/services"
@@ -46,4 +47,16 @@ func RegisterCertificateRoutes(r fiber.Router, db *gorm.DB) {
 	// routes
 	r.Get("/:certificateID/verify", certificateController.VerifyCertificate)
 
+	// Admin: cabut / terbit ulang sertifikat
+	r.Post("/:certificateID/revoke",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin"}),
+		middlewares.ValidateBody[dto.CertificateActionRequest](),
+		certificateController.RevokeCertificate)
+	r.Post("/:certificateID/reissue",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin"}),
+		middlewares.ValidateBody[dto.CertificateActionRequest](),
+		certificateController.ReissueCertificate)
+
 }
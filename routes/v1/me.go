@@ -54,11 +54,13 @@ func RegisterMeRoutes(r fiber.Router, db *gorm.DB) {
 	purchaseService := services.NewPurchaseService(purchaseRepo, userRepository, batchRepository, emailService, db)
 	purchaseController := controllers.NewPurchaseController(purchaseService, db)
 
-	assignmentService := services.NewAssignmentService(assignmentRepository, meetingRepository, purchaseRepo, fileService, db)
+	assignmentService := services.NewAssignmentService(assignmentRepository, meetingRepository, purchaseRepo, repository.NewSubmissionRepository(db), fileService, db)
 
 	assignmentController := controllers.NewAssignmentController(assignmentService, db)
 
-	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepository, attendanceRepository, assignmentRepository, submissionRepository, purchaseService, fileService, db)
+	accommodationRepo := repository.NewAccommodationRepository(db)
+	proctoringRepo := repository.NewProctoringRepository(db)
+	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepository, attendanceRepository, assignmentRepository, submissionRepository, accommodationRepo, proctoringRepo, purchaseService, fileService, emailService, db)
 	quizController := controllers.NewQuizController(quizService, db)
 
 	certificateRepository := repository.NewCertificateRepository(db)
@@ -106,11 +108,34 @@ func RegisterMeRoutes(r fiber.Router, db *gorm.DB) {
 		middlewares.RequireRole([]string{"siswa"}),
 		scoreController.GetScores)
 
+	studentGroupService := services.NewStudentGroupService(repository.NewStudentGroupRepository(db), batchRepository, meetingRepository, db)
+	studentGroupController := controllers.NewStudentGroupController(studentGroupService, db)
+	r.Get("/batches/:batchID/group",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}),
+		studentGroupController.GetMyGroup)
+
 	r.Get("/assignments/upcoming",
 		middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa"}),
 		assignmentController.GetAllUpcomingAssignments)
 
+	peerReviewService := services.NewPeerReviewService(repository.NewPeerReviewRepository(db), assignmentRepository, submissionRepository, meetingRepository, repository.NewRubricRepository(db), db)
+	peerReviewController := controllers.NewPeerReviewController(peerReviewService, db)
+	r.Get("/peer-reviews",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}),
+		peerReviewController.GetMyReviews)
+	r.Get("/peer-reviews/:reviewID",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}),
+		peerReviewController.GetMyReview)
+	r.Put("/peer-reviews/:reviewID",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}),
+		middlewares.ValidateBody[dto.SubmitPeerReviewRequest](),
+		peerReviewController.SubmitReview)
+
 	r.Get("/quizzes/upcoming",
 		middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa"}),
@@ -34,7 +34,7 @@ func RegisterMeetingRoutes(r fiber.Router, db *gorm.DB) {
 	meetingController := controllers.NewMeetingController(meetingService, db)
 
 	assignmentRepository := repository.NewAssignmentRepository(db)
-	assignmentService := services.NewAssignmentService(assignmentRepository, meetingRepo, purchaseRepo, fileService, db)
+	assignmentService := services.NewAssignmentService(assignmentRepository, meetingRepo, purchaseRepo, repository.NewSubmissionRepository(db), fileService, db)
 	assignmentController := controllers.NewAssignmentController(assignmentService, db)
 
 	materialRepository := repository.NewMaterialRepository(db)
@@ -42,7 +42,9 @@ func RegisterMeetingRoutes(r fiber.Router, db *gorm.DB) {
 	materialController := controllers.NewMaterialController(materialService, db)
 
 	quizRepository := repository.NewQuizRepository(db)
-	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepo, attendanceRepo, assignmentRepo, submissionRepo, purchaseService, fileService, db)
+	accommodationRepo := repository.NewAccommodationRepository(db)
+	proctoringRepo := repository.NewProctoringRepository(db)
+	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepo, attendanceRepo, assignmentRepo, submissionRepo, accommodationRepo, proctoringRepo, purchaseService, fileService, emailService, db)
 	quizController := controllers.NewQuizController(quizService, db)
 
 	r.Get("/", middlewares.RequireAuth(),
@@ -86,6 +88,14 @@ func RegisterMeetingRoutes(r fiber.Router, db *gorm.DB) {
 		middlewares.RequireRole([]string{"admin", "guru"}),
 		middlewares.ValidateBody[dto.CreateAssignmentRequest](), assignmentController.CreateAssignment)
 
+	rubricRepository := repository.NewRubricRepository(db)
+	submissionService := services.NewSubmissionService(submissionRepo, assignmentRepository, meetingRepo, attendanceRepo, repository.NewQuizRepository(db), rubricRepository, purchaseService, fileService, db)
+	submissionController := controllers.NewSubmissionController(submissionService, db)
+	r.Get("/:meetingID/grades/export", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}), submissionController.ExportMeetingGrades)
+	r.Put("/:meetingID/grades/import", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}), submissionController.ImportMeetingGrades)
+
 	// ==================================
 	// 				Material
 	// ==================================
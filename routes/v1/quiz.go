@@ -7,6 +7,7 @@ import (
 	"brevet-api/repository"
 	"brevet-api/services"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -31,8 +32,30 @@ func RegisterQuizRoutes(r fiber.Router, db *gorm.DB) {
 	purchaseService := services.NewPurchaseService(purchaseRepo, userRepository, batchRepository, emailService, db)
 
 	quizRepository := repository.NewQuizRepository(db)
-	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepo, attendanceRepo, assignmentRepo, submissionRepo, purchaseService, fileService, db)
+	accommodationRepo := repository.NewAccommodationRepository(db)
+	proctoringRepo := repository.NewProctoringRepository(db)
+	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepo, attendanceRepo, assignmentRepo, submissionRepo, accommodationRepo, proctoringRepo, purchaseService, fileService, emailService, db)
 	quizController := controllers.NewQuizController(quizService, db)
+	liveController := controllers.NewQuizLiveController(quizService, services.DefaultQuizLiveHub())
+
+	accommodationService := services.NewAccommodationService(accommodationRepo, quizRepository, assignmentRepo, meetingRepo, batchRepository, userRepository, purchaseService, db)
+	accommodationController := controllers.NewAccommodationController(accommodationService, db)
+
+	paperService := services.NewQuizPaperService(repository.NewQuizPaperRepository(db), quizRepository, batchRepository, meetingRepo, purchaseService, fileService, db)
+	paperController := controllers.NewQuizPaperController(paperService, db)
+
+	r.Post("/omr/scans/:scanID/resolve",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.ResolveOmrScanRequest](),
+		paperController.ResolveOmrScan,
+	)
+	r.Post("/omr/scans/:scanID/reject",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.RejectOmrScanRequest](),
+		paperController.RejectOmrScan,
+	)
 
 	r.Post("/attempts/:attemptID/temp-submissions",
 		middlewares.RequireAuth(),
@@ -53,6 +76,68 @@ func RegisterQuizRoutes(r fiber.Router, db *gorm.DB) {
 		quizController.GetAttemptDetail,
 	)
 
+	r.Post("/attempts/:attemptID/events",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}),
+		middlewares.ValidateBody[dto.ReportProctorEventRequest](),
+		quizController.ReportProctorEvent,
+	)
+
+	r.Get("/attempts/:attemptID/timeline",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		quizController.GetAttemptTimeline,
+	)
+
+	r.Post("/attempts/:attemptID/extend",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		middlewares.ValidateBody[dto.AttemptActionRequest](),
+		quizController.ExtendAttempt,
+	)
+
+	r.Post("/attempts/:attemptID/pause",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		middlewares.ValidateBody[dto.AttemptActionRequest](),
+		quizController.PauseAttempt,
+	)
+
+	r.Post("/attempts/:attemptID/resume",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		middlewares.ValidateBody[dto.AttemptActionRequest](),
+		quizController.ResumeAttempt,
+	)
+
+	r.Post("/attempts/:attemptID/reopen",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		middlewares.ValidateBody[dto.AttemptActionRequest](),
+		quizController.ReopenAttempt,
+	)
+
+	r.Post("/attempts/:attemptID/void",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		middlewares.ValidateBody[dto.AttemptActionRequest](),
+		quizController.VoidAttempt,
+	)
+
+	r.Get("/attempts/:attemptID/actions",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}),
+		quizController.GetAttemptActions,
+	)
+
+	r.Get("/attempts/:attemptID/live",
+		middlewares.RequireWebSocketUpgrade(),
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}),
+		liveController.PrepareAttemptSession,
+		websocket.New(liveController.AttemptSession),
+	)
+
 	r.Get("/attempts/:attemptID/result",
 		middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa", "guru", "admin"}),
@@ -65,6 +150,81 @@ func RegisterQuizRoutes(r fiber.Router, db *gorm.DB) {
 	r.Get("/:quizID/questions", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
 		quizController.GetQuizWithQuestions)
 
+	r.Get("/:quizID/analysis", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		quizController.GetQuizItemAnalysis)
+	r.Get("/:quizID/analysis/excel", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		quizController.GenerateItemAnalysisExcel)
+
+	r.Post("/:quizID/regrade",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.RegradeQuizRequest](),
+		quizController.RegradeQuiz,
+	)
+	r.Get("/:quizID/regrade-logs", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		quizController.GetRegradeLogs)
+
+	r.Post("/:quizID/review/release", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		quizController.ReleaseQuizReview)
+	r.Delete("/:quizID/review/release", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		quizController.UnreleaseQuizReview)
+
+	r.Patch("/:quizID/questions/:questionID",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.UpdateQuestionRequest](),
+		quizController.UpdateQuestion,
+	)
+
+	r.Get("/:quizID/live", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		liveController.GetLiveMonitor)
+	r.Get("/:quizID/live/ws",
+		middlewares.RequireWebSocketUpgrade(),
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		liveController.PrepareMonitorSession,
+		websocket.New(liveController.MonitorSession),
+	)
+
+	r.Get("/:quizID/accommodations", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		accommodationController.ListQuizAccommodations)
+	r.Put("/:quizID/accommodations",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.UpsertQuizAccommodationRequest](),
+		accommodationController.UpsertQuizAccommodation,
+	)
+	r.Delete("/:quizID/accommodations/:userID", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		accommodationController.DeleteQuizAccommodation)
+
+	r.Post("/:quizID/papers",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.GeneratePaperVersionsRequest](),
+		paperController.GeneratePaperVersions,
+	)
+	r.Get("/:quizID/papers", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.GetPaperVersions)
+	r.Get("/:quizID/papers/booklet", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.DownloadPaperBooklet)
+	r.Get("/:quizID/papers/answer-key", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.DownloadPaperAnswerKey)
+	r.Post("/:quizID/papers/answers",
+		middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"admin", "guru"}),
+		middlewares.ValidateBody[dto.SubmitPaperAnswersRequest](),
+		paperController.SubmitPaperAnswers,
+	)
+
+	r.Post("/:quizID/omr/sheets", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.GenerateAnswerSheets)
+	r.Get("/:quizID/omr/sheets/pdf", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.DownloadAnswerSheets)
+	r.Post("/:quizID/omr/scans", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.UploadOmrScans)
+	r.Get("/:quizID/omr/scans", middlewares.RequireAuth(), middlewares.RequireRole([]string{"admin", "guru"}),
+		paperController.GetOmrScans)
+
 	r.Get("/:quizID/attempts/active",
 		middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa"}),
@@ -72,7 +232,7 @@ func RegisterQuizRoutes(r fiber.Router, db *gorm.DB) {
 	)
 	r.Get("/:quizID/attempts",
 		middlewares.RequireAuth(),
-		middlewares.RequireRole([]string{"siswa"}),
+		middlewares.RequireRole([]string{"siswa", "guru", "admin"}),
 		quizController.GetListAttempt,
 	)
 
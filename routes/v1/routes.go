@@ -59,6 +59,10 @@ func RegisterV1Routes(r fiber.Router, db *gorm.DB) {
 	submissionGroup := r.Group("/submissions")
 	RegisterSubmissionRoutes(submissionGroup, db)
 
+	// /v1/rubrics
+	rubricGroup := r.Group("/rubrics")
+	RegisterRubricRoutes(rubricGroup, db)
+
 	// /v1/quizzes
 	quizGroup := r.Group("/quizzes")
 	RegisterQuizRoutes(quizGroup, db)
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"brevet-api/controllers"
+	"brevet-api/dto"
+	"brevet-api/middlewares"
+	"brevet-api/repository"
+	"brevet-api/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterRubricRoutes registers assignment rubric routes
+func RegisterRubricRoutes(r fiber.Router, db *gorm.DB) {
+	rubricService := services.NewRubricService(repository.NewRubricRepository(db), repository.NewAssignmentRepository(db),
+		repository.NewMeetingRepository(db), db)
+	rubricController := controllers.NewRubricController(rubricService, db)
+
+	r.Get("/", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), rubricController.GetRubrics)
+	r.Post("/", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.SaveRubricRequest](),
+		rubricController.CreateRubric)
+	r.Get("/:rubricID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), rubricController.GetRubricByID)
+	r.Put("/:rubricID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.SaveRubricRequest](),
+		rubricController.UpdateRubric)
+	r.Delete("/:rubricID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), rubricController.DeleteRubric)
+}
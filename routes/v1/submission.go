@@ -16,6 +16,7 @@ func RegisterSubmissionRoutes(r fiber.Router, db *gorm.DB) {
 	purchaseRepo := repository.NewPurchaseRepository(db)
 	userRepo := repository.NewUserRepository(db)
 	submissionRepository := repository.NewSubmissionRepository(db)
+	rubricRepository := repository.NewRubricRepository(db)
 	assignmentRepository := repository.NewAssignmentRepository(db)
 	attendanceRepository := repository.NewAttendanceRepository(db)
 	quizRepository := repository.NewQuizRepository(db)
@@ -27,7 +28,7 @@ func RegisterSubmissionRoutes(r fiber.Router, db *gorm.DB) {
 	}
 	batchRepository := repository.NewBatchRepository(db)
 	purchaseService := services.NewPurchaseService(purchaseRepo, userRepo, batchRepository, emailService, db)
-	submissionService := services.NewSubmissionService(submissionRepository, assignmentRepository, meetingRepository, attendanceRepository, quizRepository, purchaseService, fileService, db)
+	submissionService := services.NewSubmissionService(submissionRepository, assignmentRepository, meetingRepository, attendanceRepository, quizRepository, rubricRepository, purchaseService, fileService, db)
 	submissionController := controllers.NewSubmissionController(submissionService, db)
 	r.Get("/:submissionID", middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"siswa", "guru"}), submissionController.GetDetailSubmission)
@@ -43,5 +44,38 @@ func RegisterSubmissionRoutes(r fiber.Router, db *gorm.DB) {
 	r.Put("/:submissionID/grade", middlewares.RequireAuth(),
 		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.GradeSubmissionRequest](),
 		submissionController.GradeSubmission)
+	r.Put("/:submissionID/grade/moderation", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.ModerateGradeRequest](),
+		submissionController.ModerateGrade)
+
+	r.Get("/:submissionID/versions", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa", "guru", "admin"}), submissionController.GetSubmissionVersions)
+	r.Get("/:submissionID/versions/diff", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa", "guru", "admin"}), submissionController.DiffSubmissionVersions)
+	peerReviewService := services.NewPeerReviewService(repository.NewPeerReviewRepository(db), assignmentRepository, submissionRepository, meetingRepository, rubricRepository, db)
+	peerReviewController := controllers.NewPeerReviewController(peerReviewService, db)
+	r.Get("/:submissionID/peer-reviews", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa"}), peerReviewController.GetReceivedReviews)
+
+	r.Post("/:submissionID/return", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.ReturnSubmissionRequest](),
+		submissionController.ReturnForRevision)
+
+	annotationService := services.NewAnnotationService(repository.NewAnnotationRepository(db), submissionRepository, assignmentRepository, meetingRepository, purchaseService, db)
+	annotationController := controllers.NewAnnotationController(annotationService, db)
+	r.Get("/:submissionID/annotations", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa", "guru", "admin"}), annotationController.GetSubmissionAnnotations)
+	r.Get("/files/:fileID/annotations", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa", "guru", "admin"}), annotationController.GetFileAnnotations)
+	r.Post("/files/:fileID/annotations", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.CreateAnnotationRequest](),
+		annotationController.CreateAnnotation)
+	r.Get("/files/:fileID/annotated", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"siswa", "guru", "admin"}), annotationController.DownloadAnnotatedPDF)
+	r.Patch("/annotations/:annotationID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), middlewares.ValidateBody[dto.UpdateAnnotationRequest](),
+		annotationController.UpdateAnnotation)
+	r.Delete("/annotations/:annotationID", middlewares.RequireAuth(),
+		middlewares.RequireRole([]string{"guru", "admin"}), annotationController.DeleteAnnotation)
 
 }
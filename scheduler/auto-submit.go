@@ -1,16 +1,28 @@
 package scheduler
 
 import (
-	"brevet-api/models"
+	"brevet-api/config"
 	"brevet-api/repository"
 	"brevet-api/services"
+	"brevet-api/utils"
 	"context"
-	"fmt"
+	"errors"
+	"log"
+	"os"
+	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	autoSubmitLeaseKey = "quiz:auto_submit:lease"
+	autoSubmitLeaseTTL = 15 * time.Second
+	autoSubmitBatch    = 100
+	autoSubmitRetry    = 30 * time.Second
+)
+
 // InitQuizScheduler inisialisasi dependency quiz + jalanin scheduler
 func InitQuizScheduler(db *gorm.DB) {
 	emailService, err := services.NewEmailServiceFromEnv()
@@ -32,43 +44,121 @@ func InitQuizScheduler(db *gorm.DB) {
 	)
 
 	quizRepository := repository.NewQuizRepository(db)
-	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepo, attendanceRepo, assignmentRepo, submissionRepo, purchaseService, fileService, db)
+	accommodationRepo := repository.NewAccommodationRepository(db)
+	proctoringRepo := repository.NewProctoringRepository(db)
+	quizService := services.NewQuizService(quizRepository, batchRepository, meetingRepo, attendanceRepo, assignmentRepo, submissionRepo, accommodationRepo, proctoringRepo, purchaseService, fileService, emailService, db)
 
-	go startAutoSubmitScheduler(db, quizService)
+	go startAutoSubmitWorker(quizService, services.NewQuizDeadlineQueue())
+	go startRegradeNoticeWorker(quizService)
 }
 
-func startAutoSubmitScheduler(db *gorm.DB, quizService services.IQuizService) {
-	ticker := time.NewTicker(1 * time.Minute)
-	go func() {
-		for range ticker.C {
-			now := time.Now()
-			var attempts []models.QuizAttempt
-			if err := db.Where("ended_at IS NULL").Find(&attempts).Error; err != nil {
-				fmt.Println("Failed to fetch attempts:", err)
-				continue
+// startAutoSubmitWorker memproses antrian deadline attempt.
+// Hanya instance yang memegang lease di Redis yang memproses, jadi aman dijalankan di banyak replika.
+func startAutoSubmitWorker(quizService services.IQuizService, queue services.IQuizDeadlineQueue) {
+	pollSeconds := envInt("QUIZ_AUTO_SUBMIT_POLL_SECONDS", 1)
+	reconcileMinutes := envInt("QUIZ_DEADLINE_RECONCILE_MINUTES", 10)
+
+	owner, err := os.Hostname()
+	if err != nil {
+		owner = "instance"
+	}
+	owner = owner + ":" + uuid.NewString()
+
+	log.Printf("Starting quiz auto-submit worker, poll: %ds, reconcile: %dm", pollSeconds, reconcileMinutes)
+
+	ticker := time.NewTicker(time.Duration(pollSeconds) * time.Second)
+	defer ticker.Stop()
+
+	isLeader := false
+	var lastReconcile time.Time
+
+	for range ticker.C {
+		ctx := context.Background()
+		if config.RedisClient == nil {
+			continue
+		}
+
+		// 1. Ambil atau perpanjang lease
+		if isLeader {
+			isLeader, err = utils.RenewLock(ctx, config.RedisClient, autoSubmitLeaseKey, owner, autoSubmitLeaseTTL)
+		} else {
+			isLeader, err = utils.AcquireLock(ctx, config.RedisClient, autoSubmitLeaseKey, owner, autoSubmitLeaseTTL)
+			if isLeader {
+				lastReconcile = time.Time{} // leader baru selalu reconcile dulu
 			}
+		}
+		if err != nil {
+			log.Println("Failed to acquire auto-submit lease:", err)
+			isLeader = false
+			continue
+		}
+		if !isLeader {
+			continue
+		}
 
-			for _, attempt := range attempts {
-				var quiz models.Quiz
-				if err := db.First(&quiz, "id = ?", attempt.QuizID).Error; err != nil {
-					continue
-				}
+		// 2. Isi ulang antrian dari DB secara berkala (mis. setelah Redis restart)
+		if time.Since(lastReconcile) >= time.Duration(reconcileMinutes)*time.Minute {
+			n, err := quizService.ReconcileAttemptDeadlines(ctx)
+			if err != nil {
+				log.Println("Failed to reconcile attempt deadlines:", err)
+			} else {
+				lastReconcile = time.Now()
+				log.Printf("Reconciled %d active attempt deadline(s)", n)
+			}
+		}
 
-				// Hitung endTime attempt
-				endTime := attempt.StartedAt.Add(time.Duration(quiz.DurationMinute) * time.Minute)
-				if !quiz.EndTime.IsZero() && quiz.EndTime.Before(endTime) {
-					endTime = quiz.EndTime
-				}
+		// 3. Proses attempt yang sudah jatuh tempo, lease diperpanjang tiap batch
+		isLeader = ProcessDueAttempts(ctx, quizService, queue, func(ctx context.Context) (bool, error) {
+			return utils.RenewLock(ctx, config.RedisClient, autoSubmitLeaseKey, owner, autoSubmitLeaseTTL)
+		})
+	}
+}
 
-				if now.After(endTime) {
-					// Auto-submit pakai service biar logikanya sama dengan manual submit
-					if err := quizService.AutoSubmitQuiz(context.Background(), attempt.ID); err != nil {
-						fmt.Println("Failed to auto-submit attempt:", attempt.ID, err)
-					} else {
-						fmt.Println("Auto-submitted attempt:", attempt.ID)
-					}
+// ProcessDueAttempts auto-submit semua attempt yang jatuh tempo per batch.
+// renew dipanggil sebelum batch berikutnya; kalau lease hilang proses berhenti dan hasilnya false.
+func ProcessDueAttempts(ctx context.Context, quizService services.IQuizService, queue services.IQuizDeadlineQueue, renew func(ctx context.Context) (bool, error)) bool {
+	for {
+		ids, err := queue.PopDue(ctx, time.Now(), autoSubmitBatch)
+		if err != nil {
+			log.Println("Failed to pop due attempts:", err)
+			return true
+		}
+
+		for _, id := range ids {
+			// Auto-submit pakai service biar logikanya sama dengan manual submit
+			if err := quizService.HandleAttemptDeadline(ctx, id); err != nil {
+				log.Println("Failed to auto-submit attempt:", id, err)
+				if errors.Is(err, services.ErrAttemptNotFound) {
+					continue
+				}
+				if err := queue.Schedule(ctx, id, time.Now().Add(autoSubmitRetry)); err != nil {
+					log.Println("Failed to requeue attempt:", id, err)
 				}
 			}
 		}
-	}()
+
+		if len(ids) < autoSubmitBatch {
+			return true
+		}
+
+		// Backlog panjang bisa melewati TTL lease, jadi perpanjang dulu sebelum lanjut
+		ok, err := renew(ctx)
+		if err != nil {
+			log.Println("Failed to renew auto-submit lease:", err)
+			return false
+		}
+		if !ok {
+			log.Println("Lost auto-submit lease, stop processing due attempts")
+			return false
+		}
+	}
+}
+
+func envInt(key string, fallback int) int {
+	v, err := strconv.Atoi(config.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || v <= 0 {
+		log.Printf("Invalid %s, fallback to %d", key, fallback)
+		return fallback
+	}
+	return v
 }
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"brevet-api/config"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	peerReviewLeaseKey = "assignment:peer-review:lease"
+	peerReviewBatch    = 20
+)
+
+// StartPeerReviewScheduler bagikan submission ke reviewer begitu deadline assignment peer review lewat
+func StartPeerReviewScheduler(db *gorm.DB) {
+	peerReviewService := services.NewPeerReviewService(
+		repository.NewPeerReviewRepository(db), repository.NewAssignmentRepository(db),
+		repository.NewSubmissionRepository(db), repository.NewMeetingRepository(db),
+		repository.NewRubricRepository(db), db,
+	)
+
+	minutes := envInt("PEER_REVIEW_SCAN_INTERVAL_MINUTES", 5)
+	interval := time.Duration(minutes) * time.Minute
+
+	owner, err := os.Hostname()
+	if err != nil {
+		owner = "instance"
+	}
+	owner = owner + ":" + uuid.NewString()
+
+	log.Printf("Starting peer review scheduler, interval: %dm", minutes)
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			ctx := context.Background()
+			if config.RedisClient == nil {
+				continue
+			}
+
+			ok, err := utils.AcquireLock(ctx, config.RedisClient, peerReviewLeaseKey, owner, interval)
+			if err != nil {
+				log.Println("Failed to acquire peer review lease:", err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			n, err := peerReviewService.RunDueDistributions(ctx, peerReviewBatch)
+			if err != nil {
+				log.Println("Failed to distribute peer reviews:", err)
+			} else if n > 0 {
+				log.Printf("Distributed peer reviews for %d assignment(s)", n)
+			}
+
+			if err := utils.ReleaseLock(ctx, config.RedisClient, peerReviewLeaseKey, owner); err != nil {
+				log.Println("Failed to release peer review lease:", err)
+			}
+		}
+	}()
+}
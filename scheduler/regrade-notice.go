@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"brevet-api/config"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const regradeNoticeLeaseKey = "quiz:regrade_notice:lease"
+
+// startRegradeNoticeWorker kirim email perubahan nilai regrade yang tertunda begitu review quiz terbuka.
+// Rilis manual langsung memicu pengiriman, worker ini menangkap quiz after_end yang terbuka karena waktu.
+func startRegradeNoticeWorker(quizService services.IQuizService) {
+	minutes := envInt("QUIZ_REGRADE_NOTICE_INTERVAL_MINUTES", 5)
+	interval := time.Duration(minutes) * time.Minute
+
+	owner, err := os.Hostname()
+	if err != nil {
+		owner = "instance"
+	}
+	owner = owner + ":" + uuid.NewString()
+
+	log.Printf("Starting regrade notice worker, interval: %dm", minutes)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		if config.RedisClient == nil {
+			continue
+		}
+
+		ok, err := utils.AcquireLock(ctx, config.RedisClient, regradeNoticeLeaseKey, owner, interval)
+		if err != nil {
+			log.Println("Failed to acquire regrade notice lease:", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		n, err := quizService.NotifyPendingRegrades(ctx)
+		if err != nil {
+			log.Println("Failed to send pending regrade notifications:", err)
+		} else if n > 0 {
+			log.Printf("Sent %d pending regrade notification(s)", n)
+		}
+
+		if err := utils.ReleaseLock(ctx, config.RedisClient, regradeNoticeLeaseKey, owner); err != nil {
+			log.Println("Failed to release regrade notice lease:", err)
+		}
+	}
+}
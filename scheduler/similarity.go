@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"brevet-api/config"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	similarityLeaseKey = "assignment:similarity:lease"
+	similarityBatch    = 10
+)
+
+// StartSimilarityScheduler cek kemiripan otomatis untuk assignment yang deadline-nya sudah lewat.
+// Lease Redis dipegang selama satu putaran supaya replika lain tidak memproses assignment yang sama.
+func StartSimilarityScheduler(db *gorm.DB) {
+	similarityService := services.NewSimilarityService(
+		repository.NewSimilarityRepository(db), repository.NewAssignmentRepository(db),
+		repository.NewMeetingRepository(db), repository.NewBatchRepository(db), db,
+	)
+
+	minutes := envInt("SIMILARITY_SCAN_INTERVAL_MINUTES", 15)
+	interval := time.Duration(minutes) * time.Minute
+
+	owner, err := os.Hostname()
+	if err != nil {
+		owner = "instance"
+	}
+	owner = owner + ":" + uuid.NewString()
+
+	log.Printf("Starting similarity scheduler, interval: %dm", minutes)
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			ctx := context.Background()
+			if config.RedisClient == nil {
+				continue
+			}
+
+			ok, err := utils.AcquireLock(ctx, config.RedisClient, similarityLeaseKey, owner, interval)
+			if err != nil {
+				log.Println("Failed to acquire similarity lease:", err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			if n, err := similarityService.RecoverStaleReports(ctx); err != nil {
+				log.Println("Failed to recover stale similarity reports:", err)
+			} else if n > 0 {
+				log.Printf("Marked %d stale similarity report(s) as failed", n)
+			}
+
+			n, err := similarityService.RunDueReports(ctx, similarityBatch)
+			if err != nil {
+				log.Println("Failed to run similarity reports:", err)
+			} else if n > 0 {
+				log.Printf("Generated %d automatic similarity report(s)", n)
+			}
+
+			if err := utils.ReleaseLock(ctx, config.RedisClient, similarityLeaseKey, owner); err != nil {
+				log.Println("Failed to release similarity lease:", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IAccommodationService interface
+type IAccommodationService interface {
+	ListQuizAccommodations(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]models.QuizAccommodation, error)
+	UpsertQuizAccommodation(ctx context.Context, user *utils.Claims, quizID uuid.UUID, body *dto.UpsertQuizAccommodationRequest) (*models.QuizAccommodation, error)
+	DeleteQuizAccommodation(ctx context.Context, user *utils.Claims, quizID, userID uuid.UUID) error
+	ListAssignmentExtensions(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) ([]models.AssignmentExtension, error)
+	UpsertAssignmentExtension(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, body *dto.UpsertAssignmentExtensionRequest) (*models.AssignmentExtension, error)
+	DeleteAssignmentExtension(ctx context.Context, user *utils.Claims, assignmentID, userID uuid.UUID) error
+}
+
+// AccommodationService mengelola override waktu per siswa (extra time, jendela alternatif, perpanjangan deadline)
+type AccommodationService struct {
+	accommodationRepo repository.IAccommodationRepository
+	quizRepo          repository.IQuizRepository
+	assignmentRepo    repository.IAssignmentRepository
+	meetingRepo       repository.IMeetingRepository
+	batchRepo         repository.IBatchRepository
+	userRepo          repository.IUserRepository
+	purchaseService   IPurchaseService
+	deadlineQueue     IQuizDeadlineQueue
+	liveHub           IQuizLiveHub
+	db                *gorm.DB
+}
+
+// NewAccommodationService creates a new instance of AccommodationService
+func NewAccommodationService(accommodationRepo repository.IAccommodationRepository, quizRepo repository.IQuizRepository,
+	assignmentRepo repository.IAssignmentRepository, meetingRepo repository.IMeetingRepository, batchRepo repository.IBatchRepository,
+	userRepo repository.IUserRepository, purchaseService IPurchaseService, db *gorm.DB) IAccommodationService {
+	return &AccommodationService{accommodationRepo: accommodationRepo, quizRepo: quizRepo, assignmentRepo: assignmentRepo,
+		meetingRepo: meetingRepo, batchRepo: batchRepo, userRepo: userRepo, purchaseService: purchaseService, deadlineQueue: NewQuizDeadlineQueue(), liveHub: DefaultQuizLiveHub(), db: db}
+}
+
+// checkTeacherAccess admin selalu boleh, guru hanya untuk meeting yang dia ajar
+func (s *AccommodationService) checkTeacherAccess(ctx context.Context, user *utils.Claims, meetingID uuid.UUID) error {
+	if user.Role == string(models.RoleTypeAdmin) {
+		return nil
+	}
+	if user.Role == string(models.RoleTypeGuru) {
+		ok, err := s.meetingRepo.IsMeetingTaughtByUser(ctx, meetingID, user.UserID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("forbidden: not teacher of this meeting")
+}
+
+// ensureStudent pastikan target accommodation adalah siswa yang sudah membeli batch meeting tersebut.
+// Accommodation ikut menentukan kapan hasil quiz after_end dibuka untuk seluruh kelas.
+func (s *AccommodationService) ensureStudent(ctx context.Context, userID, meetingID uuid.UUID) error {
+	target, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if target.RoleType != models.RoleTypeSiswa {
+		return fmt.Errorf("accommodation hanya untuk siswa")
+	}
+
+	batch, err := s.batchRepo.GetBatchByMeetingID(ctx, meetingID)
+	if err != nil {
+		return err
+	}
+	paid, err := s.purchaseService.HasPaid(ctx, userID, batch.ID)
+	if err != nil {
+		return err
+	}
+	if !paid {
+		return fmt.Errorf("siswa bukan peserta batch ini")
+	}
+	return nil
+}
+
+// ListQuizAccommodations list accommodation sebuah quiz
+func (s *AccommodationService) ListQuizAccommodations(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]models.QuizAccommodation, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTeacherAccess(ctx, user, quiz.MeetingID); err != nil {
+		return nil, err
+	}
+	return s.accommodationRepo.ListQuizAccommodations(ctx, quizID)
+}
+
+// UpsertQuizAccommodation buat atau ubah accommodation quiz untuk satu siswa
+func (s *AccommodationService) UpsertQuizAccommodation(ctx context.Context, user *utils.Claims, quizID uuid.UUID, body *dto.UpsertQuizAccommodationRequest) (*models.QuizAccommodation, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTeacherAccess(ctx, user, quiz.MeetingID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureStudent(ctx, body.UserID, quiz.MeetingID); err != nil {
+		return nil, err
+	}
+
+	start, end := QuizWindowFor(quiz, &models.QuizAccommodation{StartTime: body.StartTime, EndTime: body.EndTime})
+	if !end.IsZero() && !start.Before(end) {
+		return nil, fmt.Errorf("start_time harus sebelum end_time")
+	}
+
+	acc := &models.QuizAccommodation{
+		ID:            uuid.New(),
+		QuizID:        quizID,
+		UserID:        body.UserID,
+		ExtraMinutes:  body.ExtraMinutes,
+		StartTime:     body.StartTime,
+		EndTime:       body.EndTime,
+		ExtraAttempts: body.ExtraAttempts,
+		Reason:        body.Reason,
+		CreatedBy:     user.UserID,
+	}
+
+	if err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		return s.accommodationRepo.WithTx(tx).UpsertQuizAccommodation(ctx, acc)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.rescheduleActiveAttempt(ctx, quiz, body.UserID)
+
+	return s.accommodationRepo.GetQuizAccommodation(ctx, quizID, body.UserID)
+}
+
+// DeleteQuizAccommodation hapus accommodation quiz satu siswa
+func (s *AccommodationService) DeleteQuizAccommodation(ctx context.Context, user *utils.Claims, quizID, userID uuid.UUID) error {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTeacherAccess(ctx, user, quiz.MeetingID); err != nil {
+		return err
+	}
+
+	if err := s.accommodationRepo.DeleteQuizAccommodation(ctx, quizID, userID); err != nil {
+		return err
+	}
+
+	s.rescheduleActiveAttempt(ctx, quiz, userID)
+	return nil
+}
+
+// rescheduleActiveAttempt perbarui deadline auto-submit kalau siswa sedang mengerjakan
+func (s *AccommodationService) rescheduleActiveAttempt(ctx context.Context, quiz *models.Quiz, userID uuid.UUID) {
+	attempt, err := s.quizRepo.GetActiveAttemptByQuizAndUser(ctx, quiz.ID, userID)
+	if err != nil || attempt == nil {
+		return
+	}
+
+	acc, err := s.accommodationRepo.GetQuizAccommodation(ctx, quiz.ID, userID)
+	if err != nil {
+		return
+	}
+
+	deadline := ComputeAttemptDeadline(attempt, quiz, acc)
+	if err := s.deadlineQueue.Schedule(ctx, attempt.ID, deadline); err != nil {
+		log.Errorf("Failed to reschedule attempt %s: %v", attempt.ID, err)
+	}
+	s.liveHub.Publish(ctx, dto.QuizLiveEvent{
+		Type: LiveEventDeadlineChanged, QuizID: quiz.ID, AttemptID: attempt.ID, UserID: userID, Deadline: &deadline,
+	})
+}
+
+// ListAssignmentExtensions list perpanjangan deadline sebuah assignment
+func (s *AccommodationService) ListAssignmentExtensions(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) ([]models.AssignmentExtension, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTeacherAccess(ctx, user, assignment.MeetingID); err != nil {
+		return nil, err
+	}
+	return s.accommodationRepo.ListAssignmentExtensions(ctx, assignmentID)
+}
+
+// UpsertAssignmentExtension buat atau ubah perpanjangan deadline assignment untuk satu siswa
+func (s *AccommodationService) UpsertAssignmentExtension(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, body *dto.UpsertAssignmentExtensionRequest) (*models.AssignmentExtension, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTeacherAccess(ctx, user, assignment.MeetingID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureStudent(ctx, body.UserID, assignment.MeetingID); err != nil {
+		return nil, err
+	}
+	if !body.EndAt.After(assignment.EndAt) {
+		return nil, fmt.Errorf("end_at harus setelah deadline assignment (%s)", assignment.EndAt.Format(time.RFC3339))
+	}
+
+	ext := &models.AssignmentExtension{
+		ID:           uuid.New(),
+		AssignmentID: assignmentID,
+		UserID:       body.UserID,
+		EndAt:        body.EndAt,
+		Reason:       body.Reason,
+		CreatedBy:    user.UserID,
+	}
+
+	if err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		return s.accommodationRepo.WithTx(tx).UpsertAssignmentExtension(ctx, ext)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.accommodationRepo.GetAssignmentExtension(ctx, assignmentID, body.UserID)
+}
+
+// DeleteAssignmentExtension hapus perpanjangan deadline satu siswa
+func (s *AccommodationService) DeleteAssignmentExtension(ctx context.Context, user *utils.Claims, assignmentID, userID uuid.UUID) error {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTeacherAccess(ctx, user, assignment.MeetingID); err != nil {
+		return err
+	}
+	return s.accommodationRepo.DeleteAssignmentExtension(ctx, assignmentID, userID)
+}
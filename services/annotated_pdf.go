@@ -0,0 +1,195 @@
+package services
+
+import (
+	"brevet-api/models"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/phpdave11/gofpdf/contrib/gofpdi"
+)
+
+// ValidateAnnotation cek bentuk anotasi: area harus punya ukuran dan muat di halaman, note wajib berkomentar
+func ValidateAnnotation(a *models.SubmissionAnnotation) error {
+	switch a.Type {
+	case models.AnnotationHighlight, models.AnnotationBox:
+		if a.Width <= 0 || a.Height <= 0 {
+			return fmt.Errorf("width dan height wajib diisi untuk anotasi %s", a.Type)
+		}
+	case models.AnnotationNote:
+		if a.Comment == nil || strings.TrimSpace(*a.Comment) == "" {
+			return fmt.Errorf("comment wajib diisi untuk anotasi note")
+		}
+		a.Width, a.Height = 0, 0
+	default:
+		return fmt.Errorf("jenis anotasi %q tidak dikenal", a.Type)
+	}
+
+	const eps = 1e-9
+	if a.X+a.Width > 1+eps || a.Y+a.Height > 1+eps {
+		return fmt.Errorf("anotasi melewati batas halaman")
+	}
+	return nil
+}
+
+// warna bawaan tiap jenis anotasi
+var annotationColors = map[models.AnnotationType][3]int{
+	models.AnnotationHighlight: {255, 235, 59},
+	models.AnnotationBox:       {229, 57, 53},
+	models.AnnotationNote:      {229, 57, 53},
+}
+
+func annotationColor(a *models.SubmissionAnnotation) (int, int, int) {
+	if a.Color != nil && len(*a.Color) == 7 {
+		if v, err := strconv.ParseUint((*a.Color)[1:], 16, 32); err == nil {
+			return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+		}
+	}
+	c := annotationColors[a.Type]
+	return c[0], c[1], c[2]
+}
+
+// WriteAnnotatedPDF salin tiap halaman PDF asli lalu gambar anotasi di atasnya (flatten).
+// Anotasi yang berkomentar diberi nomor, komentarnya didaftar di halaman terakhir.
+func WriteAnnotatedPDF(w io.Writer, srcPath string, annotations []models.SubmissionAnnotation) (err error) {
+	// gofpdi panic kalau PDF sumber rusak / terenkripsi
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gagal membaca PDF submission: %v", r)
+		}
+	}()
+
+	sorted := make([]models.SubmissionAnnotation, len(annotations))
+	copy(sorted, annotations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Page != sorted[j].Page {
+			return sorted[i].Page < sorted[j].Page
+		}
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+
+	// nomor urut untuk anotasi berkomentar
+	numbers := make(map[int]int)
+	n := 0
+	for i, a := range sorted {
+		if a.Comment != nil && strings.TrimSpace(*a.Comment) != "" {
+			n++
+			numbers[i] = n
+		}
+	}
+
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	font := paperFont(pdf)
+	tr := func(s string) string { return s }
+	if font == "Arial" {
+		tr = pdf.UnicodeTranslatorFromDescriptor("")
+	}
+
+	importer := gofpdi.NewImporter()
+	first := importer.ImportPage(pdf, srcPath, 1, "/MediaBox")
+	sizes := importer.GetPageSizes()
+
+	for page := 1; page <= len(sizes); page++ {
+		tpl := first
+		if page > 1 {
+			tpl = importer.ImportPage(pdf, srcPath, page, "/MediaBox")
+		}
+		box := sizes[page]["/MediaBox"]
+		pw, ph := box["w"], box["h"]
+		pdf.AddPageFormat("P", gofpdf.SizeType{Wd: pw, Ht: ph})
+		importer.UseImportedTemplate(pdf, tpl, 0, 0, pw, ph)
+
+		for i := range sorted {
+			if sorted[i].Page == page {
+				drawAnnotation(pdf, font, &sorted[i], numbers[i], pw, ph)
+			}
+		}
+	}
+
+	if n > 0 {
+		writeAnnotationNotes(pdf, font, tr, sorted, numbers, len(sizes))
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func drawAnnotation(pdf *gofpdf.Fpdf, font string, a *models.SubmissionAnnotation, number int, pw, ph float64) {
+	r, g, b := annotationColor(a)
+	x, y := a.X*pw, a.Y*ph
+	w, h := a.Width*pw, a.Height*ph
+
+	switch a.Type {
+	case models.AnnotationHighlight:
+		pdf.SetAlpha(0.35, "Multiply")
+		pdf.SetFillColor(r, g, b)
+		pdf.Rect(x, y, w, h, "F")
+		pdf.SetAlpha(1, "Normal")
+	case models.AnnotationBox:
+		pdf.SetDrawColor(r, g, b)
+		pdf.SetLineWidth(1.5)
+		pdf.Rect(x, y, w, h, "D")
+	case models.AnnotationNote:
+		pdf.SetFillColor(r, g, b)
+		pdf.Circle(x, y, 7, "F")
+	}
+
+	if number == 0 {
+		return
+	}
+	label := strconv.Itoa(number)
+	pdf.SetFont(font, "B", 8)
+	pdf.SetTextColor(255, 255, 255)
+	lw := pdf.GetStringWidth(label)
+	if a.Type == models.AnnotationNote {
+		pdf.Text(x-lw/2, y+3, label)
+		return
+	}
+
+	// badge nomor di pojok kiri atas area, di dalam area kalau mepet tepi atas
+	// stabilo terlalu terang untuk teks putih, badge-nya pakai warna note
+	by := math.Max(y-11, 0)
+	if a.Type == models.AnnotationHighlight {
+		c := annotationColors[models.AnnotationNote]
+		r, g, b = c[0], c[1], c[2]
+	}
+	pdf.SetFillColor(r, g, b)
+	pdf.Rect(x, by, lw+6, 11, "F")
+	pdf.Text(x+3, by+8, label)
+}
+
+// writeAnnotationNotes halaman daftar komentar sesuai nomor di halaman
+func writeAnnotationNotes(pdf *gofpdf.Fpdf, font string, tr func(string) string, sorted []models.SubmissionAnnotation, numbers map[int]int, pages int) {
+	pdf.SetMargins(40, 40, 40)
+	pdf.SetAutoPageBreak(true, 40)
+	pdf.AddPageFormat("P", gofpdf.SizeType{Wd: 595.28, Ht: 841.89})
+	pdf.SetTextColor(0, 0, 0)
+
+	pdf.SetFont(font, "B", 14)
+	pdf.CellFormat(0, 20, tr("Catatan Koreksi"), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	pdf.SetFont(font, "", 10)
+	for i, a := range sorted {
+		number, ok := numbers[i]
+		if !ok {
+			continue
+		}
+		page := fmt.Sprintf("Hal. %d", a.Page)
+		if a.Page > pages {
+			page += " (tidak ada)"
+		}
+		pdf.MultiCell(0, 14, tr(fmt.Sprintf("%d. [%s] %s", number, page, strings.TrimSpace(*a.Comment))), "", "L", false)
+		pdf.Ln(4)
+	}
+}
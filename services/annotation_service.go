@@ -0,0 +1,214 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// IAnnotationService interface
+type IAnnotationService interface {
+	GetSubmissionAnnotations(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) ([]models.SubmissionAnnotation, error)
+	GetFileAnnotations(ctx context.Context, user *utils.Claims, fileID uuid.UUID) ([]models.SubmissionAnnotation, error)
+	CreateAnnotation(ctx context.Context, user *utils.Claims, fileID uuid.UUID, req *dto.CreateAnnotationRequest) (*models.SubmissionAnnotation, error)
+	UpdateAnnotation(ctx context.Context, user *utils.Claims, annotationID uuid.UUID, req *dto.UpdateAnnotationRequest) (*models.SubmissionAnnotation, error)
+	DeleteAnnotation(ctx context.Context, user *utils.Claims, annotationID uuid.UUID) error
+	GenerateAnnotatedPDF(ctx context.Context, user *utils.Claims, fileID uuid.UUID) (*bytes.Buffer, string, error)
+}
+
+// AnnotationService anotasi guru pada PDF submission
+type AnnotationService struct {
+	annotationRepo  repository.IAnnotationRepository
+	submissionRepo  repository.ISubmisssionRepository
+	assignmentRepo  repository.IAssignmentRepository
+	meetingRepo     repository.IMeetingRepository
+	purchaseService IPurchaseService
+	db              *gorm.DB
+}
+
+// NewAnnotationService creates a new instance of AnnotationService
+func NewAnnotationService(annotationRepo repository.IAnnotationRepository, submissionRepo repository.ISubmisssionRepository,
+	assignmentRepo repository.IAssignmentRepository, meetingRepo repository.IMeetingRepository, purchaseService IPurchaseService, db *gorm.DB) IAnnotationService {
+	return &AnnotationService{
+		annotationRepo:  annotationRepo,
+		submissionRepo:  submissionRepo,
+		assignmentRepo:  assignmentRepo,
+		meetingRepo:     meetingRepo,
+		purchaseService: purchaseService,
+		db:              db,
+	}
+}
+
+// loadSubmission submission beserta cek akses. Guru pengajar batch & admin boleh menulis,
+// siswa pemilik hanya boleh membaca setelah submission dinilai.
+func (s *AnnotationService) loadSubmission(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, write bool) (*models.AssignmentSubmission, error) {
+	submission, err := s.submissionRepo.FindByID(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := s.assignmentRepo.GetBatchByAssignmentID(ctx, submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch user.Role {
+	case string(models.RoleTypeAdmin):
+		return &submission, nil
+	case string(models.RoleTypeGuru):
+		ok, err := s.meetingRepo.IsBatchOwnedByUser(ctx, user.UserID, batch.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("forbidden: not teacher of this assignment")
+		}
+		return &submission, nil
+	case string(models.RoleTypeSiswa):
+		if write {
+			return nil, fmt.Errorf("forbidden: only teachers can annotate submissions")
+		}
+		paid, err := s.purchaseService.HasPaid(ctx, user.UserID, batch.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !paid || !submission.IsOwnedBy(user.UserID) {
+			return nil, fmt.Errorf("forbidden: not your submission")
+		}
+		if submission.AssignmentGrade == nil || !submission.AssignmentGrade.IsReleased() {
+			return nil, fmt.Errorf("anotasi bisa dilihat setelah submission dinilai")
+		}
+		return &submission, nil
+	}
+	return nil, fmt.Errorf("forbidden")
+}
+
+// loadFile file PDF submission beserta cek akses
+func (s *AnnotationService) loadFile(ctx context.Context, user *utils.Claims, fileID uuid.UUID, write bool) (*models.SubmissionFile, *models.AssignmentSubmission, error) {
+	file, err := s.annotationRepo.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	submission, err := s.loadSubmission(ctx, user, file.AssignmentSubmissionID, write)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.ToLower(filepath.Ext(file.FileURL)) != ".pdf" {
+		return nil, nil, fmt.Errorf("anotasi hanya untuk file PDF")
+	}
+	return file, submission, nil
+}
+
+// GetSubmissionAnnotations semua anotasi submission, termasuk file versi sebelumnya
+func (s *AnnotationService) GetSubmissionAnnotations(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) ([]models.SubmissionAnnotation, error) {
+	if _, err := s.loadSubmission(ctx, user, submissionID, false); err != nil {
+		return nil, err
+	}
+	return s.annotationRepo.GetBySubmissionID(ctx, submissionID)
+}
+
+// GetFileAnnotations anotasi satu file
+func (s *AnnotationService) GetFileAnnotations(ctx context.Context, user *utils.Claims, fileID uuid.UUID) ([]models.SubmissionAnnotation, error) {
+	if _, _, err := s.loadFile(ctx, user, fileID, false); err != nil {
+		return nil, err
+	}
+	return s.annotationRepo.GetByFileID(ctx, fileID)
+}
+
+// CreateAnnotation tambah anotasi di file
+func (s *AnnotationService) CreateAnnotation(ctx context.Context, user *utils.Claims, fileID uuid.UUID, req *dto.CreateAnnotationRequest) (*models.SubmissionAnnotation, error) {
+	file, submission, err := s.loadFile(ctx, user, fileID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	annotation := models.SubmissionAnnotation{
+		SubmissionID:     submission.ID,
+		SubmissionFileID: &file.ID,
+		FileURL:          file.FileURL,
+		Version:          submission.Version,
+		AuthorID:         user.UserID,
+		Page:             req.Page,
+		Type:             req.Type,
+		X:                req.X,
+		Y:                req.Y,
+		Width:            req.Width,
+		Height:           req.Height,
+		Comment:          req.Comment,
+		Color:            req.Color,
+	}
+	if err := ValidateAnnotation(&annotation); err != nil {
+		return nil, err
+	}
+	if err := s.annotationRepo.Create(ctx, &annotation); err != nil {
+		return nil, err
+	}
+	return s.annotationRepo.GetByID(ctx, annotation.ID)
+}
+
+// UpdateAnnotation ubah anotasi
+func (s *AnnotationService) UpdateAnnotation(ctx context.Context, user *utils.Claims, annotationID uuid.UUID, req *dto.UpdateAnnotationRequest) (*models.SubmissionAnnotation, error) {
+	annotation, err := s.annotationRepo.GetByID(ctx, annotationID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.loadSubmission(ctx, user, annotation.SubmissionID, true); err != nil {
+		return nil, err
+	}
+
+	if err := copier.CopyWithOption(annotation, req, copier.Option{IgnoreEmpty: true}); err != nil {
+		return nil, err
+	}
+	if err := ValidateAnnotation(annotation); err != nil {
+		return nil, err
+	}
+	if err := s.annotationRepo.Update(ctx, annotation); err != nil {
+		return nil, err
+	}
+	return s.annotationRepo.GetByID(ctx, annotationID)
+}
+
+// DeleteAnnotation hapus anotasi
+func (s *AnnotationService) DeleteAnnotation(ctx context.Context, user *utils.Claims, annotationID uuid.UUID) error {
+	annotation, err := s.annotationRepo.GetByID(ctx, annotationID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.loadSubmission(ctx, user, annotation.SubmissionID, true); err != nil {
+		return err
+	}
+	return s.annotationRepo.Delete(ctx, annotationID)
+}
+
+// GenerateAnnotatedPDF salinan PDF submission dengan anotasi yang sudah di-flatten
+func (s *AnnotationService) GenerateAnnotatedPDF(ctx context.Context, user *utils.Claims, fileID uuid.UUID) (*bytes.Buffer, string, error) {
+	file, _, err := s.loadFile(ctx, user, fileID, false)
+	if err != nil {
+		return nil, "", err
+	}
+	path := utils.LocalUploadPath(file.FileURL)
+	if path == "" {
+		return nil, "", fmt.Errorf("file submission tidak ditemukan di server")
+	}
+
+	annotations, err := s.annotationRepo.GetByFileID(ctx, fileID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAnnotatedPDF(&buf, path, annotations); err != nil {
+		return nil, "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(file.FileURL), filepath.Ext(file.FileURL))
+	return &buf, fmt.Sprintf("annotated-%s.pdf", name), nil
+}
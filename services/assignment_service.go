@@ -32,14 +32,52 @@ type AssignmentService struct {
 	assignmentRepo repository.IAssignmentRepository
 	meetingRepo    repository.IMeetingRepository
 	purchaseRepo   repository.IPurchaseRepository
+	submissionRepo repository.ISubmisssionRepository
 	fileService    IFileService
 	db             *gorm.DB
 }
 
 // NewAssignmentService creates a new instance of AssignmentService
 func NewAssignmentService(assignmentRepository repository.IAssignmentRepository, meetingRepository repository.IMeetingRepository,
-	purchaseRepo repository.IPurchaseRepository, fileService IFileService, db *gorm.DB) IAssignmentService {
-	return &AssignmentService{assignmentRepo: assignmentRepository, meetingRepo: meetingRepository, purchaseRepo: purchaseRepo, fileService: fileService, db: db}
+	purchaseRepo repository.IPurchaseRepository, submissionRepo repository.ISubmisssionRepository, fileService IFileService, db *gorm.DB) IAssignmentService {
+	return &AssignmentService{assignmentRepo: assignmentRepository, meetingRepo: meetingRepository, purchaseRepo: purchaseRepo,
+		submissionRepo: submissionRepo, fileService: fileService, db: db}
+}
+
+// validateLatePolicy cek konsistensi aturan telat assignment
+func validateLatePolicy(assignment *models.Assignment) error {
+	if assignment.LatePenaltyUnit == "" {
+		assignment.LatePenaltyUnit = models.LatePenaltyPerDay
+	}
+	if assignment.LateCutoffAt != nil && !assignment.LateCutoffAt.After(assignment.EndAt) {
+		return fmt.Errorf("late_cutoff_at harus setelah end_at")
+	}
+	return nil
+}
+
+// validatePeerReview cek pengaturan peer review assignment
+func validatePeerReview(assignment *models.Assignment) error {
+	if !assignment.PeerReviewEnabled {
+		return nil
+	}
+	if assignment.GroupSubmission {
+		return fmt.Errorf("peer review belum bisa dipakai untuk assignment kelompok")
+	}
+	if assignment.PeerReviewCount < 1 {
+		return fmt.Errorf("peer_review_count minimal 1")
+	}
+	if assignment.PeerReviewEndAt != nil && !assignment.PeerReviewEndAt.After(assignment.EndAt) {
+		return fmt.Errorf("peer_review_end_at harus setelah end_at")
+	}
+	return nil
+}
+
+// validateFileRules cek batasan file submission
+func validateFileRules(assignment *models.Assignment) error {
+	if assignment.MaxFiles > 0 && assignment.MinFiles > assignment.MaxFiles {
+		return fmt.Errorf("min_files tidak boleh lebih dari max_files")
+	}
+	return nil
 }
 
 // GetAllFilteredAssignments retrieves all assignments with pagination and filtering options
@@ -157,6 +195,34 @@ func (s *AssignmentService) CreateAssignment(ctx context.Context, user *utils.Cl
 			Title:       body.Title,
 			Description: utils.SafeNil(body.Description),
 			Type:        models.AssignmentType(body.Type),
+
+			HardDeadline:       body.HardDeadline,
+			LateGraceMinutes:   body.LateGraceMinutes,
+			LatePenaltyPercent: body.LatePenaltyPercent,
+			LatePenaltyUnit:    body.LatePenaltyUnit,
+			LatePenaltyFloor:   body.LatePenaltyFloor,
+			LateCutoffAt:       body.LateCutoffAt,
+
+			PeerReviewEnabled: body.PeerReviewEnabled,
+			PeerReviewCount:   body.PeerReviewCount,
+			PeerReviewEndAt:   body.PeerReviewEndAt,
+			PeerReviewWeight:  body.PeerReviewWeight,
+
+			GroupSubmission: body.GroupSubmission,
+
+			AllowedFileTypes: body.AllowedFileTypes,
+			MaxFileSizeMB:    body.MaxFileSizeMB,
+			MinFiles:         body.MinFiles,
+			MaxFiles:         body.MaxFiles,
+		}
+		if err := validateLatePolicy(assignmentPtr); err != nil {
+			return err
+		}
+		if err := validatePeerReview(assignmentPtr); err != nil {
+			return err
+		}
+		if err := validateFileRules(assignmentPtr); err != nil {
+			return err
 		}
 
 		if err := s.assignmentRepo.WithTx(tx).Create(ctx, assignmentPtr); err != nil {
@@ -217,6 +283,17 @@ func (s *AssignmentService) UpdateAssignment(ctx context.Context, user *utils.Cl
 			}
 		}
 
+		// Mode kelompok tidak bisa diganti setelah ada yang mengumpulkan
+		if body.GroupSubmission != nil && *body.GroupSubmission != assignment.GroupSubmission {
+			submitted, err := s.assignmentRepo.WithTx(tx).HasSubmissions(ctx, assignment.ID)
+			if err != nil {
+				return err
+			}
+			if submitted {
+				return fmt.Errorf("group_submission tidak bisa diubah karena sudah ada submission")
+			}
+		}
+
 		// Copy field yang tidak nil saja
 		if err := copier.CopyWithOption(&assignment, body, copier.Option{
 			IgnoreEmpty: true,
@@ -224,11 +301,34 @@ func (s *AssignmentService) UpdateAssignment(ctx context.Context, user *utils.Cl
 		}); err != nil {
 			return err
 		}
+		if body.ClearLateCutoff {
+			assignment.LateCutoffAt = nil
+		}
+		if body.ClearPeerReviewEndAt {
+			assignment.PeerReviewEndAt = nil
+		}
+		if err := validateLatePolicy(assignment); err != nil {
+			return err
+		}
+		if err := validatePeerReview(assignment); err != nil {
+			return err
+		}
+		if err := validateFileRules(assignment); err != nil {
+			return err
+		}
 
 		if err := s.assignmentRepo.WithTx(tx).Update(ctx, assignment); err != nil {
 			return err
 		}
 
+		// Aturan potongan / bobot peer berubah: hitung ulang nilai yang sudah ada
+		if body.LateGraceMinutes != nil || body.LatePenaltyPercent != nil || body.LatePenaltyUnit != nil || body.LatePenaltyFloor != nil ||
+			body.PeerReviewEnabled != nil || body.PeerReviewWeight != nil {
+			if err := s.recalculateGrades(ctx, tx, assignment); err != nil {
+				return err
+			}
+		}
+
 		// Optional: replace files (delete old, insert new)
 		if body.AssignmentFiles != nil {
 			// Hapus semua file lama
@@ -315,3 +415,25 @@ func (s *AssignmentService) DeleteAssignment(ctx context.Context, user *utils.Cl
 	}
 	return nil
 }
+
+// recalculateGrades terapkan ulang nilai peer & potongan telat ke nilai yang sudah diberikan
+func (s *AssignmentService) recalculateGrades(ctx context.Context, tx *gorm.DB, assignment *models.Assignment) error {
+	submissions, err := s.submissionRepo.WithTx(tx).GetGradesByAssignmentID(ctx, assignment.ID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range submissions {
+		if sub.AssignmentGrade == nil {
+			continue
+		}
+		grade := sub.AssignmentGrade
+		if err := blendPeerScore(ctx, s.submissionRepo.WithTx(tx), grade, assignment); err != nil {
+			return err
+		}
+		applyLatePenalty(grade, assignment, sub.LateMinutes)
+		if err := s.submissionRepo.WithTx(tx).UpdateGradeFinal(ctx, grade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
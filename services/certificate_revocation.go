@@ -0,0 +1,137 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+// MarkCertificateRevoked cabut sertifikat aktif, alasan & admin pencabut wajib tercatat
+func MarkCertificateRevoked(cert *models.Certificate, actorID uuid.UUID, reason string, now time.Time) error {
+	if cert.Status != models.CertificateActive {
+		return fmt.Errorf("sertifikat berstatus %s, hanya sertifikat aktif yang bisa dicabut", cert.Status)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("alasan pencabutan wajib diisi")
+	}
+	cert.Status = models.CertificateRevoked
+	cert.RevokedAt = &now
+	cert.RevokedBy = &actorID
+	cert.RevocationReason = &reason
+	return nil
+}
+
+// CertificateVerification status keabsahan sertifikat untuk endpoint verifikasi publik
+func CertificateVerification(cert *models.Certificate) (*dto.CertificateVerificationResponse, error) {
+	res := &dto.CertificateVerificationResponse{Status: cert.Status}
+	if err := copier.Copy(&res.Certificate, cert); err != nil {
+		return nil, err
+	}
+
+	switch cert.Status {
+	case models.CertificateRevoked:
+		res.Message = "Sertifikat telah dicabut"
+		res.RevokedAt = cert.RevokedAt
+		res.RevocationReason = cert.RevocationReason
+	case models.CertificateSuperseded:
+		res.Message = "Sertifikat telah diganti"
+		if cert.SupersededBy != nil {
+			res.SupersededByNumber = &cert.SupersededBy.Number
+			res.Message = fmt.Sprintf("Sertifikat telah diganti oleh %s", cert.SupersededBy.Number)
+		}
+	default:
+		res.Valid = true
+		res.Message = "Sertifikat valid"
+	}
+	return res, nil
+}
+
+// RevokeCertificate admin mencabut sertifikat, nomornya tetap terverifikasi sebagai dicabut
+func (s *CertificateService) RevokeCertificate(ctx context.Context, certID uuid.UUID, admin *utils.Claims, reason string) (*models.Certificate, error) {
+	cert, err := s.certRepo.GetByID(ctx, certID)
+	if err != nil {
+		return nil, err
+	}
+	if err := MarkCertificateRevoked(cert, admin.UserID, reason, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.certRepo.Update(ctx, cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// ReissueCertificate terbitkan ulang sertifikat aktif (nama salah ketik / data batch berubah)
+// dengan nomor & PDF baru. Sertifikat lama diverifikasi sebagai superseded.
+func (s *CertificateService) ReissueCertificate(ctx context.Context, certID uuid.UUID, admin *utils.Claims, reason string) (*models.Certificate, error) {
+	old, err := s.certRepo.GetByID(ctx, certID)
+	if err != nil {
+		return nil, err
+	}
+	if old.Status != models.CertificateActive {
+		return nil, fmt.Errorf("sertifikat berstatus %s, hanya sertifikat aktif yang bisa diterbitkan ulang", old.Status)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("alasan terbit ulang wajib diisi")
+	}
+
+	// data terbaru: nama siswa & batch diambil ulang
+	user, err := s.userRepo.FindByID(ctx, old.UserID)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := s.batchRepo.GetBatchWithCourse(ctx, old.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, fmt.Errorf("batch tidak ditemukan")
+	}
+	listOfMeeting, err := s.meetingRepo.GetMeetingNamesByBatchID(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	certNumber, err := s.generateCertificateNumber(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ID dibuat di awal supaya QR sudah menunjuk ke sertifikat baru
+	replacement := &models.Certificate{
+		ID:      uuid.New(),
+		BatchID: old.BatchID,
+		UserID:  old.UserID,
+		Number:  certNumber,
+		Status:  models.CertificateActive,
+	}
+
+	qrPath, err := s.generateQRCode(replacement.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(qrPath)
+
+	pdfURL, err := s.generatePDF(user.Name, batch, certNumber, listOfMeeting, qrPath)
+	if err != nil {
+		return nil, err
+	}
+	replacement.URL = pdfURL
+	replacement.QRCode = fmt.Sprintf("/certificates/%s", replacement.ID)
+
+	old.ReissueReason = &reason
+	if err := s.certRepo.Supersede(ctx, old, replacement); err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +34,8 @@ type ICertificateService interface {
 	GetCertificateDetail(ctx context.Context, certID uuid.UUID, claims *utils.Claims) (*models.Certificate, error)
 	VerifyCertificate(ctx context.Context, certID uuid.UUID) (*models.Certificate, error)
 	GetByNumber(ctx context.Context, number string) (*models.Certificate, error)
+	RevokeCertificate(ctx context.Context, certID uuid.UUID, admin *utils.Claims, reason string) (*models.Certificate, error)
+	ReissueCertificate(ctx context.Context, certID uuid.UUID, admin *utils.Claims, reason string) (*models.Certificate, error)
 }
 
 // CertificateService provides methods for managing courses
@@ -377,26 +380,38 @@ func (s *CertificateService) generateQRCode(certID uuid.UUID) (string, error) {
 	return filePath, nil
 }
 
-func (s *CertificateService) generateCertificateNumber(ctx context.Context, batchID uuid.UUID) (string, error) {
-	// prefix bisa statis, misalnya kode lembaga
-	prefix := "20100112"
+// certificateNumberPrefix kode lembaga di depan nomor sertifikat
+const certificateNumberPrefix = "20100112"
+
+// NextCertificateNumber nomor berikutnya di batch: urutan terbesar (angka setelah spasi terakhir) + 1.
+// Nomor yang dicabut / diganti ikut dihitung supaya tidak pernah terpakai ulang.
+func NextCertificateNumber(batchID uuid.UUID, existing []string) string {
+	lastSeq := 0
+	for _, number := range existing {
+		idx := strings.LastIndex(number, " ")
+		if idx < 0 {
+			continue
+		}
+		seq, err := strconv.Atoi(number[idx+1:])
+		if err == nil && seq > lastSeq {
+			lastSeq = seq
+		}
+	}
+	return fmt.Sprintf("%s-%s %04d", certificateNumberPrefix, batchID.String()[:8], lastSeq+1)
+}
 
-	// ambil nomor urut terakhir untuk batch ini
-	lastSeq, err := s.certRepo.GetLastSequenceByBatch(ctx, batchID)
+func (s *CertificateService) generateCertificateNumber(ctx context.Context, batchID uuid.UUID) (string, error) {
+	// pastikan batch ada
+	batch, err := s.batchRepo.FindByID(ctx, batchID)
 	if err != nil {
 		return "", err
 	}
 
-	// increment
-	nextSeq := lastSeq + 1
-
-	// misal batch keberapa, bisa ambil dari batch table
-	batch, err := s.batchRepo.FindByID(ctx, batchID)
+	numbers, err := s.certRepo.GetNumbersByBatch(ctx, batch.ID)
 	if err != nil {
 		return "", err
 	}
-
-	return fmt.Sprintf("%s-%s %04d", prefix, batch.ID.String()[:8], nextSeq), nil
+	return NextCertificateNumber(batch.ID, numbers), nil
 }
 
 func (s *CertificateService) checkUserAccess(ctx context.Context, user *utils.Claims, batchID uuid.UUID) (bool, error) {
@@ -416,6 +431,11 @@ func (s *CertificateService) checkUserAccess(ctx context.Context, user *utils.Cl
 		return s.purchaseService.HasPaid(ctx, user.UserID, batch.ID)
 	}
 
+	// Admin boleh lihat semua sertifikat
+	if user.Role == string(models.RoleTypeAdmin) {
+		return true, nil
+	}
+
 	// Role lain tidak diizinkan
 	return false, nil
 }
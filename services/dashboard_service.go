@@ -2,6 +2,7 @@ package services
 
 import (
 	"brevet-api/dto"
+	"brevet-api/models"
 	"brevet-api/repository"
 	"context"
 	"database/sql"
@@ -242,7 +243,7 @@ func (s *DashboardService) GetStudentDashboard(ctx context.Context, studentID uu
 	var certificateCount int64
 	if err := s.db.WithContext(ctx).
 		Table("certificates").
-		Where("user_id = ?", studentID).
+		Where("user_id = ? AND status = ?", studentID, models.CertificateActive).
 		Count(&certificateCount).Error; err != nil {
 		return nil, fmt.Errorf("failed to count certificates: %w", err)
 	}
@@ -315,28 +316,35 @@ func (s *DashboardService) GetStudentScoreProgress(ctx context.Context, batchSlu
 		return nil, fmt.Errorf("failed to get meetings: %w", err)
 	}
 
+	// Nilai akhir quiz per meeting sesuai scoring policy masing-masing quiz
+	quizScoresByMeeting, err := s.quizFinalScoresByMeeting(ctx, batch.ID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
 	var items []dto.StudentMeetingScoreItem
 	for _, m := range meetings {
 		var assignmentAvg sql.NullFloat64
 		if err := s.db.WithContext(ctx).
 			Table("assignment_grades").
-			Select("AVG(assignment_grades.grade)").
+			Select("AVG("+repository.MemberGradeSelect("assignment_grades", "adj")+")").
 			Joins("JOIN assignment_submissions ON assignment_submissions.id = assignment_grades.assignment_submission_id").
 			Joins("JOIN assignments ON assignments.id = assignment_submissions.assignment_id").
-			Where("assignments.meeting_id = ? AND assignment_submissions.user_id = ?", m.ID, studentID).
+			Joins("LEFT JOIN assignment_grade_adjustments adj ON adj.grade_id = assignment_grades.id AND adj.user_id = ?", studentID).
+			Where("assignments.meeting_id = ?", m.ID).
+			Where(repository.SubmissionOwnedBy("assignment_submissions"), studentID, studentID).
+			Where(repository.GradeReleased("assignment_grades")).
 			Scan(&assignmentAvg).Error; err != nil {
 			return nil, fmt.Errorf("failed to calculate assignment average: %w", err)
 		}
 
 		var quizAvg sql.NullFloat64
-		if err := s.db.WithContext(ctx).
-			Table("quiz_results").
-			Select("AVG(quiz_results.score_percent)").
-			Joins("JOIN quiz_attempts ON quiz_attempts.id = quiz_results.attempt_id").
-			Joins("JOIN quizzes ON quizzes.id = quiz_attempts.quiz_id").
-			Where("quizzes.meeting_id = ? AND quiz_attempts.user_id = ?", m.ID, studentID).
-			Scan(&quizAvg).Error; err != nil {
-			return nil, fmt.Errorf("failed to calculate quiz average: %w", err)
+		if scores := quizScoresByMeeting[m.ID]; len(scores) > 0 {
+			var sum float64
+			for _, v := range scores {
+				sum += v
+			}
+			quizAvg = sql.NullFloat64{Float64: sum / float64(len(scores)), Valid: true}
 		}
 
 		var assignmentPtr *float64
@@ -369,6 +377,52 @@ func (s *DashboardService) GetStudentScoreProgress(ctx context.Context, batchSlu
 	}, nil
 }
 
+// quizFinalScoresByMeeting nilai akhir tiap quiz (sesuai scoring policy) dikelompokkan per meeting,
+// hanya quiz yang hasilnya sudah dibuka untuk siswa
+func (s *DashboardService) quizFinalScoresByMeeting(ctx context.Context, batchID string, studentID uuid.UUID) (map[string][]float64, error) {
+	now := time.Now()
+	var rows []struct {
+		QuizID                uuid.UUID
+		MeetingID             string
+		ScoringPolicy         models.QuizScoringPolicy
+		AttemptPenaltyPercent float64
+		ScorePercent          float64
+	}
+
+	if err := s.db.WithContext(ctx).
+		Table("quiz_attempts").
+		Select("quizzes.id AS quiz_id, quizzes.meeting_id, quizzes.scoring_policy, quizzes.attempt_penalty_percent, quiz_results.score_percent").
+		Joins("JOIN quiz_results ON quiz_results.attempt_id = quiz_attempts.id").
+		Joins("JOIN quizzes ON quizzes.id = quiz_attempts.quiz_id").
+		Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
+		Where("meetings.batch_id = ? AND quiz_attempts.user_id = ? AND quiz_attempts.ended_at IS NOT NULL AND quiz_attempts.voided_at IS NULL AND quizzes.mode = ?", batchID, studentID, models.QuizModeGraded).
+		Where(repository.QuizReviewReleased("quizzes"), now, now).
+		Order("quiz_attempts.started_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quiz scores: %w", err)
+	}
+
+	// Kumpulkan skor attempt per quiz dengan urutan attempt tetap terjaga
+	var order []uuid.UUID
+	scores := make(map[uuid.UUID][]float64)
+	meta := make(map[uuid.UUID]int)
+	for i, row := range rows {
+		if _, ok := meta[row.QuizID]; !ok {
+			meta[row.QuizID] = i
+			order = append(order, row.QuizID)
+		}
+		scores[row.QuizID] = append(scores[row.QuizID], row.ScorePercent)
+	}
+
+	result := make(map[string][]float64)
+	for _, quizID := range order {
+		first := rows[meta[quizID]]
+		final := first.ScoringPolicy.Aggregate(scores[quizID], first.AttemptPenaltyPercent)
+		result[first.MeetingID] = append(result[first.MeetingID], final)
+	}
+	return result, nil
+}
+
 // calculateStudentProgress calculates progress for a student in a batch
 // Progress = (completed assignments + quizzes + attendances) / total items
 func (s *DashboardService) calculateStudentProgress(ctx context.Context, batchID string, studentID uuid.UUID) (float64, error) {
@@ -383,7 +437,7 @@ func (s *DashboardService) calculateStudentProgress(ctx context.Context, batchID
 	s.db.WithContext(ctx).
 		Table("quizzes").
 		Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
-		Where("meetings.batch_id = ?", batchID).
+		Where("meetings.batch_id = ? AND quizzes.mode = ?", batchID, models.QuizModeGraded).
 		Count(&totalQuizzes)
 
 	s.db.WithContext(ctx).
@@ -402,15 +456,16 @@ func (s *DashboardService) calculateStudentProgress(ctx context.Context, batchID
 		Table("assignment_submissions").
 		Joins("JOIN assignments ON assignments.id = assignment_submissions.assignment_id").
 		Joins("JOIN meetings ON meetings.id = assignments.meeting_id").
-		Where("meetings.batch_id = ? AND assignment_submissions.user_id = ?", batchID, studentID).
+		Where("meetings.batch_id = ?", batchID).
+		Where(repository.SubmissionOwnedBy("assignment_submissions"), studentID, studentID).
 		Count(&completedAssignments)
 
 	s.db.WithContext(ctx).
-		Table("quiz_results").
-		Joins("JOIN quiz_attempts ON quiz_attempts.id = quiz_results.attempt_id").
+		Table("quiz_attempts").
 		Joins("JOIN quizzes ON quizzes.id = quiz_attempts.quiz_id").
 		Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
-		Where("meetings.batch_id = ? AND quiz_attempts.user_id = ?", batchID, studentID).
+		Where("meetings.batch_id = ? AND quiz_attempts.user_id = ? AND quiz_attempts.ended_at IS NOT NULL AND quiz_attempts.voided_at IS NULL AND quizzes.mode = ?", batchID, studentID, models.QuizModeGraded).
+		Distinct("quiz_attempts.quiz_id").
 		Count(&completedQuizzes)
 
 	s.db.WithContext(ctx).
@@ -492,10 +547,11 @@ func (s *DashboardService) GetAdminDashboard(ctx context.Context, period string)
 	response.NewPurchases = newPurchases
 
 	// 5. Total Sertifikat yang diterbitkan dalam periode
+	// Hanya sertifikat aktif (sama dengan completion rate), revoked/superseded tidak dihitung
 	var totalCertificates int64
 	err = s.db.WithContext(ctx).
 		Table("certificates").
-		Where("created_at >= ?", startDate).
+		Where("created_at >= ? AND status = ?", startDate, models.CertificateActive).
 		Count(&totalCertificates).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to count certificates: %w", err)
@@ -509,6 +565,7 @@ func (s *DashboardService) GetAdminDashboard(ctx context.Context, period string)
 		var allTimeCertificates int64
 		err = s.db.WithContext(ctx).
 			Table("certificates").
+			Where("status = ?", models.CertificateActive).
 			Count(&allTimeCertificates).Error
 		if err != nil {
 			return nil, fmt.Errorf("failed to count all certificates: %w", err)
@@ -767,7 +824,7 @@ func (s *DashboardService) calculateBatchAvgProgress(ctx context.Context, batchS
 		s.db.WithContext(ctx).
 			Table("quizzes").
 			Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
-			Where("meetings.batch_id = ?", batchID).
+			Where("meetings.batch_id = ? AND quizzes.mode = ?", batchID, models.QuizModeGraded).
 			Count(&totalQuizzes)
 
 		s.db.WithContext(ctx).
@@ -786,15 +843,16 @@ func (s *DashboardService) calculateBatchAvgProgress(ctx context.Context, batchS
 			Table("assignment_submissions").
 			Joins("JOIN assignments ON assignments.id = assignment_submissions.assignment_id").
 			Joins("JOIN meetings ON meetings.id = assignments.meeting_id").
-			Where("meetings.batch_id = ? AND assignment_submissions.user_id = ?", batchID, studentID).
+			Where("meetings.batch_id = ?", batchID).
+			Where(repository.SubmissionOwnedBy("assignment_submissions"), studentID, studentID).
 			Count(&completedAssignments)
 
 		s.db.WithContext(ctx).
-			Table("quiz_results").
-			Joins("JOIN quiz_attempts ON quiz_attempts.id = quiz_results.attempt_id").
+			Table("quiz_attempts").
 			Joins("JOIN quizzes ON quizzes.id = quiz_attempts.quiz_id").
 			Joins("JOIN meetings ON meetings.id = quizzes.meeting_id").
-			Where("meetings.batch_id = ? AND quiz_attempts.user_id = ?", batchID, studentID).
+			Where("meetings.batch_id = ? AND quiz_attempts.user_id = ? AND quiz_attempts.ended_at IS NOT NULL AND quiz_attempts.voided_at IS NULL AND quizzes.mode = ?", batchID, studentID, models.QuizModeGraded).
+			Distinct("quiz_attempts.quiz_id").
 			Count(&completedQuizzes)
 
 		s.db.WithContext(ctx).
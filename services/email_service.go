@@ -13,6 +13,7 @@ import (
 type IEmailService interface {
 	SendWithAttachment(to, subject, body, attachmentPath string) error
 	SendVerificationEmail(email, code, token string) error
+	SendEmail(to, subject, body string) error
 }
 
 // EmailService for struct
@@ -66,6 +67,22 @@ func (s *EmailService) SendWithAttachment(to, subject, body, attachmentPath stri
 	return nil
 }
 
+// SendEmail mengirim email HTML tanpa attachment
+func (s *EmailService) SendEmail(to, subject, body string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.From)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer(s.SMTPHost, s.SMTPPort, s.Username, s.Password)
+
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
 // SendVerificationEmail send
 func (s *EmailService) SendVerificationEmail(email, code, token string) error {
 	go utils.SendVerificationEmail(email, code, token)
@@ -39,6 +39,9 @@ func (s *FileService) SaveFile(ctx *fiber.Ctx, file *multipart.FileHeader, locat
 	if !utils.IsAllowedExtension(file.Filename, allowedExts) {
 		return "", fmt.Errorf("Ekstensi file tidak diperbolehkan")
 	}
+	if err := checkFileContent(file); err != nil {
+		return "", err
+	}
 
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	filename := uuid.New().String() + ext
@@ -72,6 +75,24 @@ func (s *FileService) SaveFile(ctx *fiber.Ctx, file *multipart.FileHeader, locat
 
 }
 
+// checkFileContent tolak file yang isinya tidak sesuai ekstensi (misal .exe diganti nama jadi .pdf)
+func checkFileContent(file *multipart.FileHeader) error {
+	f, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("Gagal membaca file: %w", err)
+	}
+	defer f.Close()
+
+	detected, err := utils.DetectContentType(f)
+	if err != nil {
+		return fmt.Errorf("Gagal membaca file: %w", err)
+	}
+	if !utils.ContentMatchesExtension(file.Filename, detected) {
+		return fmt.Errorf("Isi file tidak sesuai dengan ekstensi %s (terdeteksi %s)", strings.ToLower(filepath.Ext(file.Filename)), detected.String())
+	}
+	return nil
+}
+
 // SaveGeneratedFile save generated file
 func (s *FileService) SaveGeneratedFile(location, filename string, data []byte) (string, error) {
 	now := time.Now()
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// isExamAssignment assignment di pertemuan ujian, nilainya lewat moderasi penilai kedua
+func (s *SubmissionService) isExamAssignment(ctx context.Context, assignment *models.Assignment) (bool, error) {
+	meeting, err := s.meetingRepo.FindByID(ctx, assignment.MeetingID)
+	if err != nil {
+		return false, err
+	}
+	return meeting.Type == models.ExamMeeting, nil
+}
+
+// initialModerationStatus status nilai baru: tugas biasa langsung rilis, ujian draft / dikirim ke penilai kedua
+func initialModerationStatus(exam, submit bool) models.GradeModerationStatus {
+	switch {
+	case !exam:
+		return models.GradeReleased
+	case submit:
+		return models.GradeSubmitted
+	default:
+		return models.GradeDraft
+	}
+}
+
+// CanRegrade nilai ujian yang sudah dimoderasi / dirilis hanya boleh dinilai ulang admin
+func CanRegrade(grade *models.AssignmentGrade, role string) error {
+	if grade == nil || role == string(models.RoleTypeAdmin) {
+		return nil
+	}
+	if grade.ModerationStatus == models.GradeModerated || grade.ModerationStatus == models.GradeReleased {
+		return fmt.Errorf("nilai ujian sudah %s, hanya admin yang bisa menilai ulang", grade.ModerationStatus)
+	}
+	return nil
+}
+
+// ModerateGrade penilai kedua setuju (req.Grade kosong / sama) atau menyesuaikan nilai yang sudah dikirim.
+// Penyesuaian wajib berkomentar, selisih besar ditandai supaya rilisnya lewat admin.
+func ModerateGrade(grade *models.AssignmentGrade, moderatorID uuid.UUID, req *dto.ModerateGradeRequest, now time.Time) error {
+	if grade.ModerationStatus != models.GradeSubmitted {
+		return fmt.Errorf("nilai berstatus %s, hanya nilai submitted yang bisa dimoderasi", grade.ModerationStatus)
+	}
+	if grade.GradedBy == moderatorID {
+		return fmt.Errorf("moderasi harus oleh guru lain, bukan penilai pertama")
+	}
+
+	grade.OriginalGrade = nil
+	grade.ModerationFlagged = false
+	if req.Grade != nil && *req.Grade != grade.Grade {
+		if req.Comment == nil || strings.TrimSpace(*req.Comment) == "" {
+			return fmt.Errorf("comment wajib diisi kalau nilai disesuaikan")
+		}
+		original := grade.Grade
+		diff := *req.Grade - original
+		if diff < 0 {
+			diff = -diff
+		}
+		grade.OriginalGrade = &original
+		grade.Grade = *req.Grade
+		grade.ModerationFlagged = diff >= models.ModerationDiscrepancyThreshold
+	}
+
+	grade.ModerationStatus = models.GradeModerated
+	grade.ModeratorID = &moderatorID
+	grade.ModeratedAt = &now
+	grade.ModerationComment = req.Comment
+	return nil
+}
+
+// examAssignment assignment ujian beserta cek akses guru pengajar / admin
+func (s *SubmissionService) examAssignment(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*models.Assignment, error) {
+	allowed, err := s.checkUserAccess(ctx, user, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this assignment")
+	}
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	exam, err := s.isExamAssignment(ctx, assignment)
+	if err != nil {
+		return nil, err
+	}
+	if !exam {
+		return nil, fmt.Errorf("moderasi hanya untuk assignment ujian")
+	}
+	return assignment, nil
+}
+
+// ModerateSubmissionGrade penilai kedua memeriksa nilai ujian satu submission
+func (s *SubmissionService) ModerateSubmissionGrade(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, req *dto.ModerateGradeRequest) (*models.AssignmentGrade, error) {
+	submission, err := s.submissionRepo.FindByID(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.examAssignment(ctx, user, submission.AssignmentID); err != nil {
+		return nil, err
+	}
+	grade := submission.AssignmentGrade
+	if grade == nil {
+		return nil, fmt.Errorf("submission belum dinilai")
+	}
+
+	if err := ModerateGrade(grade, user.UserID, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Nilai disesuaikan: potongan telat dihitung ulang sesuai versi yang dinilai
+	if grade.OriginalGrade != nil {
+		lateMinutes := submission.LateMinutes
+		if grade.Version != nil {
+			graded, err := s.submissionRepo.GetVersion(ctx, submission.ID, *grade.Version)
+			if err == nil {
+				lateMinutes = graded.LateMinutes
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+		}
+		applyLatePenalty(grade, &submission.Assignment, lateMinutes)
+	}
+
+	if err := s.submissionRepo.UpdateGradeModeration(ctx, grade); err != nil {
+		return nil, err
+	}
+	return s.submissionRepo.GetGradeBySubmissionID(ctx, submissionID)
+}
+
+// SubmitGradesForModeration kirim semua nilai draft assignment ujian ke penilai kedua
+func (s *SubmissionService) SubmitGradesForModeration(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (int64, error) {
+	if _, err := s.examAssignment(ctx, user, assignmentID); err != nil {
+		return 0, err
+	}
+	return s.submissionRepo.SubmitDraftGrades(ctx, assignmentID)
+}
+
+// ReleaseGrades rilis nilai ujian yang sudah dimoderasi ke siswa.
+// Nilai dengan selisih besar hanya bisa dirilis admin.
+func (s *SubmissionService) ReleaseGrades(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (int64, error) {
+	if _, err := s.examAssignment(ctx, user, assignmentID); err != nil {
+		return 0, err
+	}
+	admin := user.Role == string(models.RoleTypeAdmin)
+	return s.submissionRepo.ReleaseModeratedGrades(ctx, assignmentID, admin, time.Now())
+}
+
+// GetModerationProgress progres moderasi tiap assignment ujian di batch (admin)
+func (s *SubmissionService) GetModerationProgress(ctx context.Context, batchID uuid.UUID) ([]dto.ModerationProgress, error) {
+	return s.submissionRepo.GetModerationProgressByBatch(ctx, batchID)
+}
@@ -0,0 +1,704 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// Kolom spreadsheet nilai, dicocokkan lewat nama header (tidak peka huruf besar & spasi) jadi urutannya bebas
+const (
+	gradeColUserID       = "UserID"
+	gradeColAssignmentID = "AssignmentID"
+	gradeColNo           = "No"
+	gradeColNIM          = "NIM"
+	gradeColEmail        = "Email"
+	gradeColName         = "Nama Siswa"
+	gradeColGrade        = "Nilai"
+	gradeColFeedback     = "Feedback"
+)
+
+// kolom info telat di spreadsheet nilai, setelah kolom rubrik. Hanya informasi, tidak dibaca saat import.
+var lateExcelHeaders = []string{"Telat (menit)", "Potongan Telat (%)", "Nilai Akhir"}
+
+// GradeTarget assignment yang dinilai lewat spreadsheet beserta rubriknya (nil = tanpa rubrik)
+type GradeTarget struct {
+	Assignment *models.Assignment
+	Rubric     *models.Rubric
+}
+
+// GradeSheet satu tabel hasil baca file, baris pertama yang terisi adalah header
+type GradeSheet struct {
+	Name string
+	Rows [][]string
+}
+
+// GradeStudentKey identitas siswa di satu baris, cukup salah satu
+type GradeStudentKey struct {
+	UserID string
+	NIM    string
+	Email  string
+}
+
+// GradeSheetEntry satu nilai hasil baca spreadsheet
+type GradeSheetEntry struct {
+	Sheet      string
+	Row        int // nomor baris di file, mulai 1
+	Target     *GradeTarget
+	Student    GradeStudentKey
+	Grade      int
+	Feedback   string
+	Selections []dto.RubricSelectionRequest // assignment berubrik, nilai dihitung dari sini
+}
+
+// gradeHeader nama kolom; di layout satu kolom per assignment diawali judul assignment, mis. "Tugas 1: Nilai"
+func gradeHeader(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + ": " + name
+}
+
+// gradeColumnPrefixes awalan kolom tiap assignment di layout satu kolom per assignment.
+// Judul yang sama (mis. "Tugas" di tiap pertemuan) diberi potongan ID assignment supaya kolomnya
+// tidak bertabrakan dan tetap cocok saat import walau urutan assignment berubah.
+func gradeColumnPrefixes(targets []GradeTarget) []string {
+	count := make(map[string]int, len(targets))
+	for _, t := range targets {
+		count[normalizeHeader(t.Assignment.Title)]++
+	}
+	prefixes := make([]string, len(targets))
+	for i, t := range targets {
+		prefixes[i] = t.Assignment.Title
+		if count[normalizeHeader(t.Assignment.Title)] > 1 {
+			prefixes[i] = fmt.Sprintf("%s (%s)", t.Assignment.Title, t.Assignment.ID.String()[:8])
+		}
+	}
+	return prefixes
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(h, "\ufeff")), " "))
+}
+
+// headerIndex posisi kolom berdasarkan nama header yang sudah dinormalisasi
+type headerIndex map[string]int
+
+func newHeaderIndex(header []string) headerIndex {
+	idx := make(headerIndex, len(header))
+	for i, h := range header {
+		key := normalizeHeader(h)
+		if _, ok := idx[key]; key != "" && !ok {
+			idx[key] = i
+		}
+	}
+	return idx
+}
+
+// col posisi kolom, -1 kalau tidak ada
+func (h headerIndex) col(name string) int {
+	if i, ok := h[normalizeHeader(name)]; ok {
+		return i
+	}
+	return -1
+}
+
+// criterionCol kolom poin kriteria. Header ekspor berisi daftar poin, mis. "Analisis (0/2/4)", jadi cukup cocok di awal.
+func (h headerIndex) criterionCol(name string) int {
+	if i := h.col(name); i >= 0 {
+		return i
+	}
+	prefix := normalizeHeader(name) + " ("
+	found := -1
+	for key, i := range h {
+		if strings.HasPrefix(key, prefix) && (found < 0 || i < found) {
+			found = i
+		}
+	}
+	return found
+}
+
+// cell isi sel di kolom bernama, kosong kalau kolom tidak ada
+func (h headerIndex) cell(row []string, name string) string {
+	i := h.col(name)
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(excelCell(row, i))
+}
+
+func rowEmpty(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// gradeBinding kolom nilai satu assignment di sheet
+type gradeBinding struct {
+	target *GradeTarget
+	prefix string
+}
+
+// keyCol kolom utama binding: kolom nilai, atau kolom kriteria pertama untuk assignment berubrik
+func (b gradeBinding) keyCol(idx headerIndex) int {
+	if b.target.Rubric == nil || len(b.target.Rubric.Criteria) == 0 {
+		return idx.col(gradeHeader(b.prefix, gradeColGrade))
+	}
+	return idx.criterionCol(gradeHeader(b.prefix, b.target.Rubric.Criteria[0].Title))
+}
+
+// bindGradeColumns cek kolom nilai assignment ada di header. Assignment berubrik butuh semua kolom kriteria.
+func bindGradeColumns(idx headerIndex, target *GradeTarget, prefix string) (gradeBinding, bool, error) {
+	b := gradeBinding{target: target, prefix: prefix}
+	if target.Rubric == nil {
+		return b, idx.col(gradeHeader(prefix, gradeColGrade)) >= 0, nil
+	}
+
+	var missing []string
+	for _, c := range target.Rubric.Criteria {
+		if idx.criterionCol(gradeHeader(prefix, c.Title)) < 0 {
+			missing = append(missing, c.Title)
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return b, true, nil
+	case len(target.Rubric.Criteria):
+		return b, false, nil
+	}
+	return b, false, fmt.Errorf("kolom kriteria %s untuk assignment '%s' tidak ditemukan", strings.Join(missing, ", "), target.Assignment.Title)
+}
+
+// ParseGradeSheet baca nilai satu sheet berdasarkan nama header.
+// Assignment ditentukan dari kolom "<judul>: Nilai" (satu kolom per assignment), kolom AssignmentID,
+// nama sheet yang sama dengan judul assignment, atau satu-satunya target.
+// Baris tanpa nilai dilewati.
+func ParseGradeSheet(sheet GradeSheet, targets []GradeTarget) ([]GradeSheetEntry, error) {
+	start := 0
+	for start < len(sheet.Rows) && rowEmpty(sheet.Rows[start]) {
+		start++
+	}
+	if start == len(sheet.Rows) {
+		return nil, nil
+	}
+	idx := newHeaderIndex(sheet.Rows[start])
+	if idx.col(gradeColUserID) < 0 && idx.col(gradeColNIM) < 0 && idx.col(gradeColEmail) < 0 {
+		return nil, fmt.Errorf("sheet %q: kolom %s, %s, atau %s tidak ditemukan", sheet.Name, gradeColUserID, gradeColNIM, gradeColEmail)
+	}
+
+	// Layout satu kolom per assignment. Satu kolom tidak boleh dipakai dua assignment
+	// (judul sama / header kembar), kalau tidak nilai assignment kedua diam-diam tertimpa.
+	headerCount := make(map[string]int, len(sheet.Rows[start]))
+	for _, h := range sheet.Rows[start] {
+		headerCount[normalizeHeader(h)]++
+	}
+	var wide []gradeBinding
+	claimed := make(map[int]*GradeTarget)
+	for i, prefix := range gradeColumnPrefixes(targets) {
+		b, ok, err := bindGradeColumns(idx, &targets[i], prefix)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %q: %v", sheet.Name, err)
+		}
+		if !ok {
+			continue
+		}
+		col := b.keyCol(idx)
+		header := sheet.Rows[start][col]
+		if other := claimed[col]; other != nil {
+			return nil, fmt.Errorf("sheet %q: kolom %q cocok untuk assignment '%s' dan '%s', ekspor ulang template", sheet.Name, header, other.Assignment.Title, targets[i].Assignment.Title)
+		}
+		if headerCount[normalizeHeader(header)] > 1 {
+			return nil, fmt.Errorf("sheet %q: kolom %q muncul lebih dari sekali, ekspor ulang template", sheet.Name, header)
+		}
+		claimed[col] = &targets[i]
+		wide = append(wide, b)
+	}
+
+	// Layout satu sheet per assignment
+	byID := make(map[uuid.UUID]*GradeTarget, len(targets))
+	var sheetTarget *GradeTarget
+	for i := range targets {
+		byID[targets[i].Assignment.ID] = &targets[i]
+		if normalizeHeader(sheet.Name) == normalizeHeader(gradeSheetName(targets[i].Assignment.Title)) {
+			sheetTarget = &targets[i]
+		}
+	}
+	if sheetTarget == nil && len(targets) == 1 {
+		sheetTarget = &targets[0]
+	}
+	if len(wide) == 0 && sheetTarget == nil && idx.col(gradeColAssignmentID) < 0 {
+		return nil, fmt.Errorf("sheet %q: assignment tidak dikenali, samakan nama sheet dengan judul assignment atau isi kolom %s", sheet.Name, gradeColAssignmentID)
+	}
+
+	bound := make(map[*GradeTarget]bool)
+	var entries []GradeSheetEntry
+	for r := start + 1; r < len(sheet.Rows); r++ {
+		row := sheet.Rows[r]
+		if rowEmpty(row) {
+			continue
+		}
+		rowErr := func(err error) error {
+			return fmt.Errorf("sheet %q baris %d: %v", sheet.Name, r+1, err)
+		}
+
+		key := GradeStudentKey{
+			UserID: idx.cell(row, gradeColUserID),
+			NIM:    idx.cell(row, gradeColNIM),
+			Email:  strings.ToLower(idx.cell(row, gradeColEmail)),
+		}
+		if key == (GradeStudentKey{}) {
+			return nil, rowErr(fmt.Errorf("%s, %s, atau %s wajib diisi", gradeColUserID, gradeColNIM, gradeColEmail))
+		}
+
+		bindings := wide
+		if len(bindings) == 0 {
+			target := sheetTarget
+			if raw := idx.cell(row, gradeColAssignmentID); raw != "" {
+				id, err := uuid.Parse(raw)
+				if err != nil {
+					return nil, rowErr(fmt.Errorf("AssignmentID '%s' tidak valid", raw))
+				}
+				if target = byID[id]; target == nil {
+					return nil, rowErr(fmt.Errorf("assignment %s di luar cakupan import", id))
+				}
+			}
+			if target == nil {
+				return nil, rowErr(fmt.Errorf("%s kosong", gradeColAssignmentID))
+			}
+			if !bound[target] {
+				_, ok, err := bindGradeColumns(idx, target, "")
+				if err != nil {
+					return nil, rowErr(err)
+				}
+				if !ok {
+					return nil, rowErr(fmt.Errorf("kolom %s untuk assignment '%s' tidak ditemukan", gradeColGrade, target.Assignment.Title))
+				}
+				bound[target] = true
+			}
+			bindings = []gradeBinding{{target: target}}
+		}
+
+		for _, b := range bindings {
+			entry, ok, err := parseGradeCells(idx, b, row)
+			if err != nil {
+				return nil, rowErr(err)
+			}
+			if !ok {
+				continue
+			}
+			entry.Sheet, entry.Row, entry.Student = sheet.Name, r+1, key
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseGradeCells nilai satu assignment di satu baris, false kalau belum diisi
+func parseGradeCells(idx headerIndex, b gradeBinding, row []string) (GradeSheetEntry, bool, error) {
+	entry := GradeSheetEntry{
+		Target:   b.target,
+		Feedback: unescapeCSVCell(idx.cell(row, gradeHeader(b.prefix, gradeColFeedback))),
+	}
+	if b.target.Rubric != nil {
+		selections, err := parseRubricColumns(b.target.Rubric, idx, b.prefix, row)
+		if err != nil || selections == nil {
+			return entry, false, err
+		}
+		entry.Selections = selections
+		return entry, true, nil
+	}
+
+	raw := idx.cell(row, gradeHeader(b.prefix, gradeColGrade))
+	if raw == "" {
+		return entry, false, nil
+	}
+	v, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+	if err != nil || v != math.Trunc(v) || v < 0 || v > 100 {
+		return entry, false, fmt.Errorf("nilai '%s' untuk '%s' harus bilangan bulat 0-100", raw, b.target.Assignment.Title)
+	}
+	entry.Grade = int(v)
+	return entry, true, nil
+}
+
+// StudentRoster siswa batch untuk mencocokkan baris spreadsheet
+type StudentRoster struct {
+	ids    map[uuid.UUID]bool
+	nims   map[string]uuid.UUID
+	emails map[string]uuid.UUID
+}
+
+// NewStudentRoster roster dari siswa batch (Profile dipakai untuk NIM). NIM ganda ditandai uuid.Nil.
+func NewStudentRoster(students []models.User) *StudentRoster {
+	r := &StudentRoster{
+		ids:    make(map[uuid.UUID]bool, len(students)),
+		nims:   make(map[string]uuid.UUID, len(students)),
+		emails: make(map[string]uuid.UUID, len(students)),
+	}
+	for _, u := range students {
+		r.ids[u.ID] = true
+		r.emails[strings.ToLower(strings.TrimSpace(u.Email))] = u.ID
+		if u.Profile == nil || !u.Profile.NIM.Valid {
+			continue
+		}
+		nim := strings.TrimSpace(u.Profile.NIM.String)
+		if nim == "" {
+			continue
+		}
+		if _, dup := r.nims[nim]; dup {
+			r.nims[nim] = uuid.Nil
+		} else {
+			r.nims[nim] = u.ID
+		}
+	}
+	return r
+}
+
+// Resolve cari siswa lewat UserID, kalau kosong lewat NIM, lalu email
+func (r *StudentRoster) Resolve(key GradeStudentKey) (uuid.UUID, error) {
+	switch {
+	case key.UserID != "":
+		id, err := uuid.Parse(key.UserID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("UserID '%s' tidak valid", key.UserID)
+		}
+		if !r.ids[id] {
+			return uuid.Nil, fmt.Errorf("UserID %s bukan siswa batch ini", id)
+		}
+		return id, nil
+	case key.NIM != "":
+		id, ok := r.nims[key.NIM]
+		if !ok {
+			return uuid.Nil, fmt.Errorf("NIM %s tidak ditemukan di batch ini", key.NIM)
+		}
+		if id == uuid.Nil {
+			return uuid.Nil, fmt.Errorf("NIM %s dipakai lebih dari satu siswa, gunakan email", key.NIM)
+		}
+		return id, nil
+	case key.Email != "":
+		id, ok := r.emails[strings.ToLower(key.Email)]
+		if !ok {
+			return uuid.Nil, fmt.Errorf("email %s tidak ditemukan di batch ini", key.Email)
+		}
+		return id, nil
+	}
+	return uuid.Nil, fmt.Errorf("identitas siswa kosong")
+}
+
+// ReadGradeSheets baca file nilai: .xlsx semua sheet, .csv satu tabel dengan pemisah koma atau titik koma
+func ReadGradeSheets(filename string, r io.Reader) ([]GradeSheet, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch ext {
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		var sheets []GradeSheet
+		for _, name := range f.GetSheetList() {
+			rows, err := f.GetRows(name)
+			if err != nil {
+				return nil, err
+			}
+			sheets = append(sheets, GradeSheet{Name: name, Rows: rows})
+		}
+		return sheets, nil
+	case ".csv":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		// Excel berlocale Indonesia menyimpan CSV dengan titik koma
+		first, _, _ := strings.Cut(string(data), "\n")
+		if strings.Count(first, ";") > strings.Count(first, ",") {
+			reader.Comma = ';'
+		}
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		return []GradeSheet{{Name: strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)), Rows: rows}}, nil
+	}
+	return nil, fmt.Errorf("format file harus .xlsx atau .csv")
+}
+
+var sheetNameUnsafe = regexp.MustCompile(`[\[\]:*?/\\]+`)
+
+// gradeSheetName nama sheet dari judul assignment, Excel membatasi 31 karakter
+func gradeSheetName(title string) string {
+	name := strings.Join(strings.Fields(sheetNameUnsafe.ReplaceAllString(title, " ")), " ")
+	if name == "" {
+		return "Penilaian"
+	}
+	if r := []rune(name); len(r) > 31 {
+		name = strings.TrimSpace(string(r[:31]))
+	}
+	return name
+}
+
+// GradeExportData submission & nilai satu assignment untuk diekspor (User.Profile & AssignmentGrade.Criteria di-preload)
+type GradeExportData struct {
+	GradeTarget
+	Submissions []models.AssignmentSubmission
+}
+
+// GradeExportSheet satu sheet hasil ekspor
+type GradeExportSheet struct {
+	Name   string
+	Header []string
+	Rows   [][]any
+}
+
+// assignmentGradeHeaders kolom nilai satu assignment: nilai, feedback, rubrik, lalu info telat
+func assignmentGradeHeaders(t *GradeTarget, prefix string) []string {
+	headers := []string{gradeColGrade, gradeColFeedback}
+	if t.Rubric != nil {
+		headers = append(headers, RubricExcelHeaders(t.Rubric)...)
+	}
+	headers = append(headers, lateExcelHeaders...)
+	for i := range headers {
+		headers[i] = gradeHeader(prefix, headers[i])
+	}
+	return headers
+}
+
+// assignmentGradeValues isi kolom assignmentGradeHeaders, sub nil = belum mengumpulkan
+func assignmentGradeValues(t *GradeTarget, sub *models.AssignmentSubmission) []any {
+	rubricCols := 0
+	if t.Rubric != nil {
+		rubricCols = len(t.Rubric.Criteria) * 2
+	}
+	values := make([]any, 2+rubricCols+len(lateExcelHeaders))
+	for i := range values {
+		values[i] = ""
+	}
+	if sub == nil {
+		return values
+	}
+
+	late := 2 + rubricCols
+	values[late] = sub.LateMinutes
+	grade := sub.AssignmentGrade
+	if grade == nil {
+		return values
+	}
+	values[0], values[1] = grade.Grade, grade.Feedback
+	if t.Rubric != nil {
+		for i, v := range RubricExcelValues(t.Rubric, grade.Criteria) {
+			values[2+i] = v
+		}
+	}
+	values[late+1] = grade.LatePenaltyPercent
+	values[late+2] = grade.EffectiveGrade()
+	return values
+}
+
+// studentGradeCells kolom identitas siswa
+func studentGradeCells(u models.User) []any {
+	nim := ""
+	if u.Profile != nil && u.Profile.NIM.Valid {
+		nim = u.Profile.NIM.String
+	}
+	return []any{nim, u.Email, u.Name}
+}
+
+// BuildGradeSheets susun ekspor nilai: satu sheet per assignment, atau kalau wide satu sheet dengan kolom per assignment
+func BuildGradeSheets(data []GradeExportData, wide bool) []GradeExportSheet {
+	if wide {
+		return []GradeExportSheet{buildWideGradeSheet(data)}
+	}
+
+	sheets := make([]GradeExportSheet, 0, len(data))
+	used := make(map[string]bool, len(data))
+	for i := range data {
+		d := &data[i]
+		header := append([]string{gradeColUserID, gradeColAssignmentID, gradeColNo, gradeColNIM, gradeColEmail, gradeColName},
+			assignmentGradeHeaders(&d.GradeTarget, "")...)
+		rows := make([][]any, 0, len(d.Submissions))
+		for n := range d.Submissions {
+			sub := &d.Submissions[n]
+			row := append([]any{sub.UserID.String(), d.Assignment.ID.String(), n + 1}, studentGradeCells(sub.User)...)
+			rows = append(rows, append(row, assignmentGradeValues(&d.GradeTarget, sub)...))
+		}
+
+		name := "Penilaian"
+		if len(data) > 1 {
+			name = uniqueSheetName(gradeSheetName(d.Assignment.Title), used)
+		}
+		used[strings.ToLower(name)] = true
+		sheets = append(sheets, GradeExportSheet{Name: name, Header: header, Rows: rows})
+	}
+	return sheets
+}
+
+// uniqueSheetName tambah akhiran (2), (3), ... kalau judul assignment terpotong jadi sama
+func uniqueSheetName(name string, used map[string]bool) string {
+	base := name
+	for n := 2; used[strings.ToLower(name)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		r := []rune(base)
+		if len(r)+len(suffix) > 31 {
+			r = r[:31-len(suffix)]
+		}
+		name = strings.TrimSpace(string(r)) + suffix
+	}
+	return name
+}
+
+// buildWideGradeSheet satu baris per siswa yang mengumpulkan, kolom nilai diawali judul assignment (lihat gradeColumnPrefixes)
+func buildWideGradeSheet(data []GradeExportData) GradeExportSheet {
+	header := []string{gradeColUserID, gradeColNo, gradeColNIM, gradeColEmail, gradeColName}
+	students := make(map[uuid.UUID]models.User)
+	bySubmitter := make([]map[uuid.UUID]*models.AssignmentSubmission, len(data))
+	targets := make([]GradeTarget, len(data))
+	for i := range data {
+		targets[i] = data[i].GradeTarget
+	}
+	prefixes := gradeColumnPrefixes(targets)
+	for i := range data {
+		header = append(header, assignmentGradeHeaders(&data[i].GradeTarget, prefixes[i])...)
+		bySubmitter[i] = make(map[uuid.UUID]*models.AssignmentSubmission, len(data[i].Submissions))
+		for n := range data[i].Submissions {
+			sub := &data[i].Submissions[n]
+			bySubmitter[i][sub.UserID] = sub
+			students[sub.UserID] = sub.User
+		}
+	}
+
+	ordered := make([]models.User, 0, len(students))
+	for id, u := range students {
+		u.ID = id
+		ordered = append(ordered, u)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Name != ordered[j].Name {
+			return ordered[i].Name < ordered[j].Name
+		}
+		return ordered[i].ID.String() < ordered[j].ID.String()
+	})
+
+	rows := make([][]any, 0, len(ordered))
+	for n, u := range ordered {
+		row := append([]any{u.ID.String(), n + 1}, studentGradeCells(u)...)
+		for i := range data {
+			row = append(row, assignmentGradeValues(&data[i].GradeTarget, bySubmitter[i][u.ID])...)
+		}
+		rows = append(rows, row)
+	}
+	return GradeExportSheet{Name: "Penilaian", Header: header, Rows: rows}
+}
+
+// WriteGradeSheets tulis ekspor nilai sebagai xlsx (kolom ID disembunyikan) atau csv (hanya satu sheet)
+func WriteGradeSheets(w io.Writer, sheets []GradeExportSheet, format string) error {
+	switch format {
+	case "xlsx":
+		return writeGradeXLSX(w, sheets)
+	case "csv":
+		if len(sheets) != 1 {
+			return fmt.Errorf("CSV hanya bisa berisi satu sheet")
+		}
+		return writeGradeCSV(w, sheets[0])
+	}
+	return fmt.Errorf("format %q tidak didukung, gunakan xlsx atau csv", format)
+}
+
+func writeGradeXLSX(w io.Writer, sheets []GradeExportSheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, sheet := range sheets {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.Name); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet.Name); err != nil {
+			return err
+		}
+
+		header := make([]any, len(sheet.Header))
+		for c, h := range sheet.Header {
+			header[c] = h
+		}
+		if err := f.SetSheetRow(sheet.Name, "A1", &header); err != nil {
+			return err
+		}
+		for r := range sheet.Rows {
+			cell, _ := excelize.CoordinatesToCellName(1, r+2)
+			if err := f.SetSheetRow(sheet.Name, cell, &sheet.Rows[r]); err != nil {
+				return err
+			}
+		}
+
+		// Kolom ID hanya untuk mencocokkan saat import
+		for c, h := range sheet.Header {
+			if h == gradeColUserID || h == gradeColAssignmentID {
+				col, _ := excelize.ColumnNumberToName(c + 1)
+				f.SetColVisible(sheet.Name, col, false)
+			}
+		}
+	}
+	return f.Write(w)
+}
+
+func writeGradeCSV(w io.Writer, sheet GradeExportSheet) error {
+	// BOM supaya Excel membaca UTF-8
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(sheet.Header); err != nil {
+		return err
+	}
+	for _, row := range sheet.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = fmt.Sprint(v)
+			if text, ok := v.(string); ok {
+				record[i] = escapeCSVCell(text)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// escapeCSVCell teks (nama siswa, feedback) yang diawali = + - @ diberi ' supaya tidak dijalankan
+// sebagai rumus saat CSV dibuka di Excel
+func escapeCSVCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// unescapeCSVCell kebalikan escapeCSVCell untuk kolom yang diimport balik
+func unescapeCSVCell(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IPeerReviewService interface
+type IPeerReviewService interface {
+	DistributeReviews(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) ([]models.PeerReview, error)
+	RunDueDistributions(ctx context.Context, limit int) (int, error)
+	GetMyReviews(ctx context.Context, user *utils.Claims) ([]models.PeerReview, error)
+	GetMyReview(ctx context.Context, user *utils.Claims, reviewID uuid.UUID) (*models.PeerReview, *models.Rubric, error)
+	SubmitReview(ctx context.Context, user *utils.Claims, reviewID uuid.UUID, body *dto.SubmitPeerReviewRequest) (*models.PeerReview, error)
+	GetReceivedReviews(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) ([]models.PeerReview, error)
+	GetAssignmentReviews(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*models.Assignment, []models.PeerReview, error)
+	SetReviewExcluded(ctx context.Context, user *utils.Claims, reviewID uuid.UUID, excluded bool) (*models.PeerReview, error)
+}
+
+// PeerReviewService pembagian & pengisian peer review assignment
+type PeerReviewService struct {
+	peerRepo       repository.IPeerReviewRepository
+	assignmentRepo repository.IAssignmentRepository
+	submissionRepo repository.ISubmisssionRepository
+	meetingRepo    repository.IMeetingRepository
+	rubricRepo     repository.IRubricRepository
+	db             *gorm.DB
+}
+
+// NewPeerReviewService creates a new instance of PeerReviewService
+func NewPeerReviewService(peerRepo repository.IPeerReviewRepository, assignmentRepo repository.IAssignmentRepository,
+	submissionRepo repository.ISubmisssionRepository, meetingRepo repository.IMeetingRepository,
+	rubricRepo repository.IRubricRepository, db *gorm.DB) IPeerReviewService {
+	return &PeerReviewService{
+		peerRepo:       peerRepo,
+		assignmentRepo: assignmentRepo,
+		submissionRepo: submissionRepo,
+		meetingRepo:    meetingRepo,
+		rubricRepo:     rubricRepo,
+		db:             db,
+	}
+}
+
+func (s *PeerReviewService) checkAssignmentAccess(ctx context.Context, user *utils.Claims, assignment *models.Assignment) error {
+	if user.Role != string(models.RoleTypeGuru) {
+		return nil
+	}
+	ok, err := s.meetingRepo.IsMeetingTaughtByUser(ctx, assignment.MeetingID, user.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check meeting-teacher relation: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("forbidden: user %s is not assigned to teach meeting %s", user.UserID, assignment.MeetingID)
+	}
+	return nil
+}
+
+// DistributeReviews bagikan submission ke reviewer sekarang juga (guru), tanpa menunggu scheduler
+func (s *PeerReviewService) DistributeReviews(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) ([]models.PeerReview, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, user, assignment); err != nil {
+		return nil, err
+	}
+	if !assignment.PeerReviewEnabled {
+		return nil, fmt.Errorf("peer review tidak aktif untuk assignment ini")
+	}
+	if assignment.PeerReviewDistributedAt != nil {
+		return nil, fmt.Errorf("peer review sudah dibagikan")
+	}
+	if time.Now().Before(assignment.EndAt) {
+		return nil, fmt.Errorf("deadline assignment belum lewat")
+	}
+	return s.distribute(ctx, assignment)
+}
+
+// RunDueDistributions dipanggil scheduler: bagikan assignment yang deadline-nya sudah lewat
+func (s *PeerReviewService) RunDueDistributions(ctx context.Context, limit int) (int, error) {
+	assignments, err := s.peerRepo.GetAssignmentsDueForDistribution(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range assignments {
+		if _, err := s.distribute(ctx, &assignments[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(assignments), nil
+}
+
+// distribute pasangkan submission dengan reviewer. Submission yang masuk setelah ini tidak ikut direview.
+func (s *PeerReviewService) distribute(ctx context.Context, assignment *models.Assignment) ([]models.PeerReview, error) {
+	batch, err := s.assignmentRepo.GetBatchByAssignmentID(ctx, assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.peerRepo.GetSubmissionsByAssignmentID(ctx, assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := s.peerRepo.GetPaidStudentIDsByBatchID(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make([]uuid.UUID, len(submissions))
+	for i, sub := range submissions {
+		authors[i] = sub.UserID
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	assigned := AssignPeerReviewers(authors, reviewers, assignment.PeerReviewCount, rng)
+
+	var reviews []models.PeerReview
+	for i, sub := range submissions {
+		for _, reviewerID := range assigned[i] {
+			reviews = append(reviews, models.PeerReview{
+				AssignmentID: assignment.ID,
+				SubmissionID: sub.ID,
+				ReviewerID:   reviewerID,
+			})
+		}
+	}
+
+	now := time.Now()
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if err := s.peerRepo.WithTx(tx).CreateReviews(ctx, reviews); err != nil {
+			return err
+		}
+		return s.peerRepo.WithTx(tx).MarkDistributed(ctx, assignment.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	assignment.PeerReviewDistributedAt = &now
+	return reviews, nil
+}
+
+// AssignPeerReviewers pilih n reviewer untuk tiap submission (authors[i] = pemilik submission ke-i).
+// Reviewer tidak pernah menilai submission sendiri dan beban dibagi serata mungkin.
+func AssignPeerReviewers(authors, reviewers []uuid.UUID, n int, rng *rand.Rand) [][]uuid.UUID {
+	result := make([][]uuid.UUID, len(authors))
+	if n <= 0 || len(reviewers) == 0 {
+		return result
+	}
+
+	pool := slices.Clone(reviewers)
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	load := make(map[uuid.UUID]int, len(pool))
+
+	for _, i := range rng.Perm(len(authors)) {
+		for len(result[i]) < n {
+			best := -1
+			for j, reviewer := range pool {
+				if reviewer == authors[i] || slices.Contains(result[i], reviewer) {
+					continue
+				}
+				if best < 0 || load[reviewer] < load[pool[best]] {
+					best = j
+				}
+			}
+			if best < 0 {
+				break
+			}
+			result[i] = append(result[i], pool[best])
+			load[pool[best]]++
+		}
+	}
+	return result
+}
+
+// PeerScore rata-rata nilai peer. Dengan 3 nilai atau lebih, 20% nilai teratas & terbawah (minimal satu) dibuang.
+func PeerScore(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(scores)
+	slices.Sort(sorted)
+	if len(sorted) >= 3 {
+		trim := max(1, len(sorted)/5)
+		sorted = sorted[trim : len(sorted)-trim]
+	}
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return math.Round(sum/float64(len(sorted))*100) / 100
+}
+
+// blendPeerScore isi nilai peer & bobotnya ke grade, dipanggil sebelum applyLatePenalty
+func blendPeerScore(ctx context.Context, submissionRepo repository.ISubmisssionRepository, grade *models.AssignmentGrade, assignment *models.Assignment) error {
+	grade.PeerWeight = assignment.PeerGradeWeight()
+	grade.PeerScore = nil
+	if !assignment.PeerReviewEnabled {
+		return nil
+	}
+	scores, err := submissionRepo.GetPeerScores(ctx, grade.AssignmentSubmissionID)
+	if err != nil {
+		return err
+	}
+	if len(scores) > 0 {
+		score := PeerScore(scores)
+		grade.PeerScore = &score
+	}
+	return nil
+}
+
+// refreshGrade hitung ulang nilai akhir yang sudah diberikan guru setelah review masuk / dikeluarkan
+func (s *PeerReviewService) refreshGrade(ctx context.Context, tx *gorm.DB, assignment *models.Assignment, submissionID uuid.UUID) error {
+	repo := s.submissionRepo.WithTx(tx)
+	grade, err := repo.GetGradeBySubmissionID(ctx, submissionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := blendPeerScore(ctx, repo, grade, assignment); err != nil {
+		return err
+	}
+	// potongan telat tetap dari versi yang dinilai guru
+	penalized := assignment.ApplyLatePenalty(grade.BlendedGrade(), grade.LatePenaltyPercent)
+	grade.PenalizedGrade = &penalized
+	return repo.UpdateGradeFinal(ctx, grade)
+}
+
+// GetMyReviews tugas review milik siswa
+func (s *PeerReviewService) GetMyReviews(ctx context.Context, user *utils.Claims) ([]models.PeerReview, error) {
+	return s.peerRepo.GetByReviewerID(ctx, user.UserID)
+}
+
+func (s *PeerReviewService) getOwnReview(ctx context.Context, user *utils.Claims, reviewID uuid.UUID) (*models.PeerReview, error) {
+	review, err := s.peerRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.ReviewerID != user.UserID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return review, nil
+}
+
+// GetMyReview detail tugas review: isi submission (tanpa identitas pemilik) & rubrik assignment
+func (s *PeerReviewService) GetMyReview(ctx context.Context, user *utils.Claims, reviewID uuid.UUID) (*models.PeerReview, *models.Rubric, error) {
+	review, err := s.getOwnReview(ctx, user, reviewID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if review.Assignment.RubricID == nil {
+		return review, nil, nil
+	}
+	rubric, err := s.rubricRepo.GetRubricByID(ctx, *review.Assignment.RubricID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return review, rubric, nil
+}
+
+// SubmitReview isi / ubah review selama jendela review masih terbuka
+func (s *PeerReviewService) SubmitReview(ctx context.Context, user *utils.Claims, reviewID uuid.UUID, body *dto.SubmitPeerReviewRequest) (*models.PeerReview, error) {
+	review, rubric, err := s.GetMyReview(ctx, user, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Assignment.PeerReviewOpen(time.Now()) {
+		return nil, fmt.Errorf("waktu peer review sudah ditutup")
+	}
+
+	var criteria []models.PeerReviewCriterion
+	if rubric != nil {
+		if len(body.Criteria) == 0 {
+			return nil, fmt.Errorf("assignment ini dinilai dengan rubrik, criteria wajib diisi")
+		}
+		selected, points, maxPoints, err := ScoreRubric(rubric, body.Criteria)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range selected {
+			criteria = append(criteria, models.PeerReviewCriterion{
+				CriterionID:    c.CriterionID,
+				LevelID:        c.LevelID,
+				CriterionTitle: c.CriterionTitle,
+				LevelTitle:     c.LevelTitle,
+				Points:         c.Points,
+				MaxPoints:      c.MaxPoints,
+				Comment:        c.Comment,
+				Order:          c.Order,
+			})
+		}
+		score := RubricGrade(points, maxPoints)
+		review.Score = &score
+		review.RubricPoints = &points
+		review.RubricMaxPoints = &maxPoints
+	} else {
+		if len(body.Criteria) > 0 {
+			return nil, fmt.Errorf("assignment ini tidak memakai rubrik")
+		}
+		if body.Score == nil {
+			return nil, fmt.Errorf("score wajib diisi")
+		}
+		review.Score = body.Score
+		review.RubricPoints = nil
+		review.RubricMaxPoints = nil
+	}
+	now := time.Now()
+	review.Comment = body.Comment
+	review.SubmittedAt = &now
+
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if err := s.peerRepo.WithTx(tx).Update(ctx, review); err != nil {
+			return err
+		}
+		if err := s.peerRepo.WithTx(tx).ReplaceCriteria(ctx, review.ID, criteria); err != nil {
+			return err
+		}
+		return s.refreshGrade(ctx, tx, &review.Assignment, review.SubmissionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.peerRepo.GetByID(ctx, reviewID)
+}
+
+// GetReceivedReviews review yang diterima siswa atas submission-nya, tanpa identitas reviewer
+func (s *PeerReviewService) GetReceivedReviews(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) ([]models.PeerReview, error) {
+	submission, err := s.submissionRepo.GetByIDUser(ctx, submissionID, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.peerRepo.GetSubmittedBySubmissionID(ctx, submission.ID)
+}
+
+// GetAssignmentReviews semua review assignment untuk pengawasan guru
+func (s *PeerReviewService) GetAssignmentReviews(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*models.Assignment, []models.PeerReview, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, user, assignment); err != nil {
+		return nil, nil, err
+	}
+	reviews, err := s.peerRepo.GetByAssignmentID(ctx, assignmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return assignment, reviews, nil
+}
+
+// SetReviewExcluded guru keluarkan / kembalikan review dari perhitungan nilai peer
+func (s *PeerReviewService) SetReviewExcluded(ctx context.Context, user *utils.Claims, reviewID uuid.UUID, excluded bool) (*models.PeerReview, error) {
+	review, err := s.peerRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, user, &review.Assignment); err != nil {
+		return nil, err
+	}
+
+	review.Excluded = excluded
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if err := s.peerRepo.WithTx(tx).Update(ctx, review); err != nil {
+			return err
+		}
+		return s.refreshGrade(ctx, tx, &review.Assignment, review.SubmissionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// Ambang batas untuk menandai soal yang perlu ditinjau ulang
+const (
+	itemAnalysisGroupRatio      = 0.27
+	itemTooEasyThreshold        = 0.9
+	itemTooHardThreshold        = 0.2
+	itemLowDiscriminationCutoff = 0.2
+)
+
+// Flag yang dipakai di laporan analisis butir soal
+const (
+	ItemFlagTooEasy                = "too_easy"
+	ItemFlagTooHard                = "too_hard"
+	ItemFlagLowDiscrimination      = "low_discrimination"
+	ItemFlagNegativeDiscrimination = "negative_discrimination"
+	ItemFlagUnusedDistractor       = "unused_distractor"
+	ItemFlagAttractiveDistractor   = "attractive_distractor"
+)
+
+// GetQuizItemAnalysis menghitung analisis butir soal untuk quiz yang sudah ditutup
+func (s *QuizService) GetQuizItemAnalysis(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*dto.QuizItemAnalysisResponse, error) {
+	quiz, err := s.quizRepo.GetQuizWithQuestions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	if quiz.IsOpen && (quiz.EndTime.IsZero() || time.Now().Before(quiz.EndTime)) {
+		return nil, fmt.Errorf("analisis soal hanya tersedia setelah quiz ditutup")
+	}
+
+	attempts, err := s.quizRepo.GetFinishedAttemptsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pakai attempt pertama tiap siswa supaya retake tidak mendistorsi statistik
+	seen := make(map[uuid.UUID]bool)
+	firstAttempts := make([]models.QuizAttempt, 0, len(attempts))
+	for _, a := range attempts {
+		if seen[a.UserID] {
+			continue
+		}
+		seen[a.UserID] = true
+		firstAttempts = append(firstAttempts, a)
+	}
+
+	report := CalculateItemAnalysis(quiz.Questions, firstAttempts)
+	report.QuizID = quiz.ID
+	report.Title = quiz.Title
+
+	return report, nil
+}
+
+// GenerateItemAnalysisExcel export analisis butir soal ke XLSX
+func (s *QuizService) GenerateItemAnalysisExcel(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*excelize.File, string, error) {
+	report, err := s.GetQuizItemAnalysis(ctx, user, quizID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	f := excelize.NewFile()
+	summarySheet := "Ringkasan"
+	itemSheet := "Analisis Soal"
+	optionSheet := "Distractor"
+	f.SetSheetName("Sheet1", summarySheet)
+	f.NewSheet(itemSheet)
+	f.NewSheet(optionSheet)
+
+	summary := [][]any{
+		{"Quiz", report.Title},
+		{"Jumlah Peserta", report.TotalAttempts},
+		{"Ukuran Kelompok Atas/Bawah", report.GroupSize},
+		{"Rata-rata Skor", report.MeanScore},
+		{"Standar Deviasi Skor", report.StdDevScore},
+	}
+	for i, row := range summary {
+		f.SetSheetRow(summarySheet, fmt.Sprintf("A%d", i+1), &row)
+	}
+
+	itemHeaders := []any{"No", "Soal", "Benar", "Tidak Dijawab", "Tingkat Kesukaran (p)", "Daya Beda (D)", "Point-Biserial", "Catatan"}
+	f.SetSheetRow(itemSheet, "A1", &itemHeaders)
+
+	optionHeaders := []any{"No Soal", "Opsi", "Teks Opsi", "Kunci", "Dipilih", "Proporsi", "Kelompok Atas", "Kelompok Bawah"}
+	f.SetSheetRow(optionSheet, "A1", &optionHeaders)
+
+	optionRow := 2
+	for i, item := range report.Items {
+		row := []any{
+			i + 1, item.Question, item.CorrectCount, item.OmittedCount,
+			item.Difficulty, item.Discrimination, item.PointBiserial, fmt.Sprint(item.Flags),
+		}
+		f.SetSheetRow(itemSheet, fmt.Sprintf("A%d", i+2), &row)
+
+		for j, opt := range item.Options {
+			key := ""
+			if opt.IsCorrect {
+				key = "✓"
+			}
+			optRow := []any{
+				i + 1, string(rune('A' + j)), opt.OptionText, key,
+				opt.Count, opt.Proportion, opt.UpperCount, opt.LowerCount,
+			}
+			f.SetSheetRow(optionSheet, fmt.Sprintf("A%d", optionRow), &optRow)
+			optionRow++
+		}
+	}
+
+	filename := fmt.Sprintf("analisis_soal_%s.xlsx", quizID.String())
+	return f, filename, nil
+}
+
+// CalculateItemAnalysis menghitung p-value, daya beda, point-biserial dan sebaran distractor.
+// Setiap attempt harus sudah memuat Submissions; soal yang tidak dijawab dihitung salah.
+func CalculateItemAnalysis(questions []models.QuizQuestion, attempts []models.QuizAttempt) *dto.QuizItemAnalysisResponse {
+	n := len(attempts)
+	report := &dto.QuizItemAnalysisResponse{
+		TotalAttempts: n,
+		Items:         make([]dto.QuizItemAnalysis, 0, len(questions)),
+	}
+
+	// answers[attemptIdx][questionID] = submission
+	answers := make([]map[uuid.UUID]models.QuizSubmission, n)
+	totals := make([]float64, n)
+	for i, a := range attempts {
+		answers[i] = make(map[uuid.UUID]models.QuizSubmission, len(a.Submissions))
+		for _, sub := range a.Submissions {
+			answers[i][sub.QuestionID] = sub
+			totals[i] += float64(sub.Score)
+		}
+	}
+
+	mean, stdDev := meanStdDev(totals)
+	report.MeanScore = roundTo(mean, 4)
+	report.StdDevScore = roundTo(stdDev, 4)
+
+	// Urutkan attempt berdasarkan total skor untuk kelompok atas & bawah
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return totals[order[a]] > totals[order[b]] })
+
+	groupSize := 0
+	if n > 0 {
+		groupSize = int(math.Ceil(float64(n) * itemAnalysisGroupRatio))
+		if groupSize*2 > n {
+			groupSize = n / 2
+		}
+	}
+	report.GroupSize = groupSize
+
+	upper := make(map[int]bool, groupSize)
+	lower := make(map[int]bool, groupSize)
+	for i := 0; i < groupSize; i++ {
+		upper[order[i]] = true
+		lower[order[n-1-i]] = true
+	}
+
+	for _, q := range questions {
+		item := dto.QuizItemAnalysis{
+			QuestionID: q.ID,
+			Question:   q.Question,
+			Flags:      []string{},
+		}
+
+		optionIdx := make(map[uuid.UUID]int, len(q.Options))
+		for i, opt := range q.Options {
+			optionIdx[opt.ID] = i
+			item.Options = append(item.Options, dto.QuizOptionAnalysis{
+				OptionID:   opt.ID,
+				OptionText: opt.OptionText,
+				IsCorrect:  opt.IsCorrect,
+			})
+		}
+
+		var upperCorrect, lowerCorrect int
+		var sumCorrect, sumWrong float64
+		for i := 0; i < n; i++ {
+			sub, answered := answers[i][q.ID]
+			correct := answered && sub.Score > 0
+
+			if !answered {
+				item.OmittedCount++
+			} else if idx, ok := optionIdx[sub.SelectedOptionID]; ok {
+				item.Options[idx].Count++
+				if upper[i] {
+					item.Options[idx].UpperCount++
+				}
+				if lower[i] {
+					item.Options[idx].LowerCount++
+				}
+			}
+
+			if correct {
+				item.CorrectCount++
+				sumCorrect += totals[i]
+				if upper[i] {
+					upperCorrect++
+				}
+				if lower[i] {
+					lowerCorrect++
+				}
+			} else {
+				sumWrong += totals[i]
+			}
+		}
+
+		if n > 0 {
+			p := float64(item.CorrectCount) / float64(n)
+			item.Difficulty = roundTo(p, 4)
+
+			if groupSize > 0 {
+				item.Discrimination = roundTo(float64(upperCorrect-lowerCorrect)/float64(groupSize), 4)
+			}
+
+			wrongCount := n - item.CorrectCount
+			if stdDev > 0 && item.CorrectCount > 0 && wrongCount > 0 {
+				m1 := sumCorrect / float64(item.CorrectCount)
+				m0 := sumWrong / float64(wrongCount)
+				item.PointBiserial = roundTo((m1-m0)/stdDev*math.Sqrt(p*(1-p)), 4)
+			}
+
+			for i := range item.Options {
+				item.Options[i].Proportion = roundTo(float64(item.Options[i].Count)/float64(n), 4)
+			}
+
+			item.Flags = itemFlags(item, groupSize)
+		}
+
+		report.Items = append(report.Items, item)
+	}
+
+	return report
+}
+
+// itemFlags menandai soal yang kemungkinan rusak atau ambigu
+func itemFlags(item dto.QuizItemAnalysis, groupSize int) []string {
+	flags := []string{}
+	if item.Difficulty > itemTooEasyThreshold {
+		flags = append(flags, ItemFlagTooEasy)
+	}
+	if item.Difficulty < itemTooHardThreshold {
+		flags = append(flags, ItemFlagTooHard)
+	}
+	if groupSize > 0 {
+		if item.Discrimination < 0 {
+			flags = append(flags, ItemFlagNegativeDiscrimination)
+		} else if item.Discrimination < itemLowDiscriminationCutoff {
+			flags = append(flags, ItemFlagLowDiscrimination)
+		}
+	}
+
+	keyUpper := 0
+	for _, opt := range item.Options {
+		if opt.IsCorrect {
+			keyUpper += opt.UpperCount
+		}
+	}
+
+	unused, attractive := false, false
+	for _, opt := range item.Options {
+		if opt.IsCorrect {
+			continue
+		}
+		if opt.Count == 0 {
+			unused = true
+		}
+		// distractor yang lebih banyak dipilih kelompok atas daripada kunci biasanya tanda kunci salah
+		if groupSize > 0 && opt.UpperCount > keyUpper {
+			attractive = true
+		}
+	}
+	if unused {
+		flags = append(flags, ItemFlagUnusedDistractor)
+	}
+	if attractive {
+		flags = append(flags, ItemFlagAttractiveDistractor)
+	}
+
+	return flags
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	return mean, math.Sqrt(variance)
+}
+
+func roundTo(v float64, places int) float64 {
+	pow := math.Pow(10, float64(places))
+	return math.Round(v*pow) / pow
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// attemptControlFunc ubah state attempt di dalam transaksi (attempt sudah di-lock)
+type attemptControlFunc func(tx *gorm.DB, attempt *models.QuizAttempt, now time.Time) error
+
+// ExtendAttempt tambah waktu attempt yang sedang berjalan
+func (s *QuizService) ExtendAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error) {
+	if body.Minutes <= 0 {
+		return nil, fmt.Errorf("minutes wajib diisi untuk perpanjangan waktu")
+	}
+	return s.controlAttempt(ctx, user, attemptID, models.AttemptActionExtend, body, func(_ *gorm.DB, attempt *models.QuizAttempt, _ time.Time) error {
+		if attempt.EndedAt != nil {
+			return fmt.Errorf("quiz already submitted")
+		}
+		attempt.ExtraMinutes += body.Minutes
+		return nil
+	})
+}
+
+// PauseAttempt jeda attempt; waktu jeda tidak dihitung ke durasi
+func (s *QuizService) PauseAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error) {
+	return s.controlAttempt(ctx, user, attemptID, models.AttemptActionPause, body, func(_ *gorm.DB, attempt *models.QuizAttempt, now time.Time) error {
+		if attempt.EndedAt != nil {
+			return fmt.Errorf("quiz already submitted")
+		}
+		if attempt.PausedAt != nil {
+			return fmt.Errorf("quiz attempt sudah dijeda")
+		}
+		attempt.PausedAt = &now
+		return nil
+	})
+}
+
+// ResumeAttempt lanjutkan attempt yang dijeda
+func (s *QuizService) ResumeAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error) {
+	return s.controlAttempt(ctx, user, attemptID, models.AttemptActionResume, body, func(_ *gorm.DB, attempt *models.QuizAttempt, now time.Time) error {
+		if attempt.PausedAt == nil {
+			return fmt.Errorf("quiz attempt tidak sedang dijeda")
+		}
+		attempt.PausedSeconds += int(now.Sub(*attempt.PausedAt).Seconds())
+		attempt.PausedAt = nil
+		return nil
+	})
+}
+
+// ReopenAttempt buka kembali attempt yang sudah disubmit. Jawaban sementara tetap ada,
+// nilai final dihapus dan dihitung ulang saat attempt disubmit lagi.
+func (s *QuizService) ReopenAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error) {
+	return s.controlAttempt(ctx, user, attemptID, models.AttemptActionReopen, body, func(tx *gorm.DB, attempt *models.QuizAttempt, now time.Time) error {
+		if attempt.EndedAt == nil {
+			return fmt.Errorf("quiz attempt masih berlangsung")
+		}
+
+		active, err := s.quizRepo.WithTx(tx).GetActiveAttemptByQuizAndUser(ctx, attempt.QuizID, attempt.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if active != nil {
+			return fmt.Errorf("siswa masih punya attempt yang berlangsung")
+		}
+
+		if err := s.quizRepo.WithTx(tx).DeleteAttemptGrading(ctx, attempt.ID); err != nil {
+			return err
+		}
+
+		// waktu sejak submit tidak dihitung, sisa waktu siswa sama seperti saat submit
+		attempt.PausedSeconds += int(now.Sub(*attempt.EndedAt).Seconds())
+		attempt.ExtraMinutes += body.Minutes
+		attempt.EndedAt = nil
+		return nil
+	})
+}
+
+// VoidAttempt batalkan attempt; tidak dihitung ke MaxAttempts maupun nilai
+func (s *QuizService) VoidAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error) {
+	return s.controlAttempt(ctx, user, attemptID, models.AttemptActionVoid, body, func(_ *gorm.DB, attempt *models.QuizAttempt, now time.Time) error {
+		attempt.VoidedAt = &now
+		if attempt.EndedAt == nil {
+			attempt.EndedAt = &now
+			attempt.PausedAt = nil
+		}
+		return nil
+	})
+}
+
+// GetAttemptActions riwayat tindakan guru terhadap sebuah attempt
+func (s *QuizService) GetAttemptActions(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) ([]dto.QuizAttemptActionResponse, error) {
+	attempt, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAttemptTeacher(ctx, user, attempt); err != nil {
+		return nil, err
+	}
+
+	actions, err := s.quizRepo.GetAttemptActionsByAttemptID(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.QuizAttemptActionResponse, 0, len(actions))
+	for _, a := range actions {
+		responses = append(responses, dto.QuizAttemptActionResponse{
+			ID:        a.ID,
+			AttemptID: a.AttemptID,
+			ActorID:   a.ActorID,
+			ActorName: a.Actor.Name,
+			Action:    a.Action,
+			Reason:    a.Reason,
+			Minutes:   a.Minutes,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+func (s *QuizService) checkAttemptTeacher(ctx context.Context, user *utils.Claims, attempt *models.QuizAttempt) error {
+	allowed, err := s.checkUserAccess(ctx, user, attempt.Quiz.MeetingID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+	return nil
+}
+
+// controlAttempt jalankan tindakan guru dengan row lock, simpan audit, lalu sinkronkan
+// antrian auto-submit & client live setelah commit.
+func (s *QuizService) controlAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, action models.AttemptActionType, body *dto.AttemptActionRequest, apply attemptControlFunc) (*models.QuizAttempt, error) {
+	current, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAttemptTeacher(ctx, user, current); err != nil {
+		return nil, err
+	}
+
+	var attempt *models.QuizAttempt
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		attempt, err = s.quizRepo.WithTx(tx).GetQuizAttemptByIDForUpdate(ctx, attemptID)
+		if err != nil {
+			return err
+		}
+		if attempt.VoidedAt != nil {
+			return fmt.Errorf("quiz attempt sudah dibatalkan")
+		}
+
+		if err := apply(tx, attempt, time.Now()); err != nil {
+			return err
+		}
+		if err := s.quizRepo.WithTx(tx).UpdateQuizAttempt(ctx, attempt); err != nil {
+			return err
+		}
+
+		auditLog := &models.QuizAttemptAction{
+			AttemptID: attempt.ID,
+			ActorID:   user.UserID,
+			Action:    action,
+			Reason:    body.Reason,
+		}
+		if body.Minutes > 0 {
+			minutes := body.Minutes
+			auditLog.Minutes = &minutes
+		}
+		return s.quizRepo.WithTx(tx).CreateAttemptAction(ctx, auditLog)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncControlledAttempt(ctx, action, attempt, &current.Quiz)
+	return attempt, nil
+}
+
+// syncControlledAttempt perbarui antrian deadline & beritahu client sesuai state baru attempt
+func (s *QuizService) syncControlledAttempt(ctx context.Context, action models.AttemptActionType, attempt *models.QuizAttempt, quiz *models.Quiz) {
+	event := dto.QuizLiveEvent{QuizID: attempt.QuizID, AttemptID: attempt.ID, UserID: attempt.UserID}
+
+	switch {
+	case attempt.EndedAt != nil:
+		if err := s.deadlineQueue.Remove(ctx, attempt.ID); err != nil {
+			log.Errorf("Failed to remove attempt %s from deadline queue: %v", attempt.ID, err)
+		}
+		event.Type = LiveEventSubmitted
+		event.Reason = string(models.AttemptActionVoid)
+
+	case attempt.PausedAt != nil:
+		if err := s.deadlineQueue.Remove(ctx, attempt.ID); err != nil {
+			log.Errorf("Failed to remove attempt %s from deadline queue: %v", attempt.ID, err)
+		}
+		event.Type = LiveEventPaused
+		event.At = *attempt.PausedAt
+
+	default:
+		acc, err := s.accommodationRepo.GetQuizAccommodation(ctx, attempt.QuizID, attempt.UserID)
+		if err != nil {
+			log.Errorf("Failed to load accommodation for attempt %s: %v", attempt.ID, err)
+		}
+		deadline := ComputeAttemptDeadline(attempt, quiz, acc)
+		if err := s.deadlineQueue.Schedule(ctx, attempt.ID, deadline); err != nil {
+			log.Errorf("Failed to reschedule attempt %s: %v", attempt.ID, err)
+		}
+		event.Deadline = &deadline
+
+		switch action {
+		case models.AttemptActionResume:
+			event.Type = LiveEventResumed
+		case models.AttemptActionReopen:
+			event.Type = LiveEventStarted
+			event.Reason = string(models.AttemptActionReopen)
+		default:
+			event.Type = LiveEventDeadlineChanged
+		}
+	}
+
+	s.liveHub.Publish(ctx, event)
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"brevet-api/config"
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// Konten soal, opsi & pembahasan berupa markdown terbatas: teks, list, tabel (pipe table),
+// link, gambar hasil upload, serta rumus LaTeX dengan delimiter \( \), \[ \] atau $$ $$.
+// HTML mentah dibuang supaya aman dirender di client.
+var (
+	rawHTMLTagPattern    = regexp.MustCompile(`(?i)<(/?[a-z][a-z0-9-]*|!--)[^>]*>`)
+	tagOpenPattern       = regexp.MustCompile(`<([a-zA-Z/!?])`)
+	markdownLinkPattern  = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*([^()\s]*(?:\([^()\s]*\)[^()\s]*)*)(\s+"[^"]*")?\s*\)`)
+	referenceDefPattern  = regexp.MustCompile(`(?m)^[ \t]{0,3}\[[^\]]+\]:[ \t]*(\S+).*$`)
+	referenceImgPattern  = regexp.MustCompile(`!\[([^\]]*)\]\[[^\]]*\]`)
+	unsafeLatexPattern   = regexp.MustCompile(`\\(href|url|includegraphics|input|include|write18|def|newcommand|renewcommand|html[a-zA-Z]*)\b`)
+	allowedLinkSchemes   = []string{"http://", "https://", "mailto:"}
+	quizContentImagePath = "quiz"
+)
+
+// SanitizeQuestionContent bersihkan konten soal ke subset markdown yang aman.
+// Error dikembalikan kalau markup rumus tidak seimbang atau memakai perintah LaTeX yang dilarang.
+func SanitizeQuestionContent(text string) (string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	// tag dibuang berulang sampai habis, supaya tag bersarang (<<script>script>) tidak menyatu lagi
+	for {
+		stripped := rawHTMLTagPattern.ReplaceAllString(text, "")
+		if stripped == text {
+			break
+		}
+		text = stripped
+	}
+
+	text = markdownLinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownLinkPattern.FindStringSubmatch(match)
+		isImage, label, target := parts[1] == "!", parts[2], parts[3]
+		if isImage && IsQuizContentImageURL(target) {
+			return match
+		}
+		if !isImage && hasAllowedScheme(target) {
+			return match
+		}
+		// link / gambar dengan target tidak aman hanya disisakan teksnya
+		return label
+	})
+
+	// gambar hanya boleh inline supaya sumbernya tervalidasi, definisi referensi hanya untuk link aman
+	text = referenceImgPattern.ReplaceAllString(text, "$1")
+	text = referenceDefPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if hasAllowedScheme(referenceDefPattern.FindStringSubmatch(match)[1]) {
+			return match
+		}
+		return ""
+	})
+
+	// sisa pembuka tag (tag tanpa penutup '>') di-escape, "a < b" tetap apa adanya
+	text = tagOpenPattern.ReplaceAllString(text, "&lt;$1")
+
+	if m := unsafeLatexPattern.FindString(text); m != "" {
+		return "", fmt.Errorf("perintah rumus %s tidak diizinkan", m)
+	}
+	if strings.Count(text, "$$")%2 != 0 {
+		return "", fmt.Errorf("rumus $$ tidak ditutup")
+	}
+	if strings.Count(text, `\(`) != strings.Count(text, `\)`) {
+		return "", fmt.Errorf(`rumus \( \) tidak seimbang`)
+	}
+	if strings.Count(text, `\[`) != strings.Count(text, `\]`) {
+		return "", fmt.Errorf(`rumus \[ \] tidak seimbang`)
+	}
+
+	return strings.TrimSpace(text), nil
+}
+
+// IsQuizContentImageURL gambar konten soal hanya boleh dari folder upload quiz (lokal / CDN)
+func IsQuizContentImageURL(url string) bool {
+	if strings.Contains(url, "..") {
+		return false
+	}
+	if strings.HasPrefix(url, "/uploads/"+quizContentImagePath+"/") {
+		return true
+	}
+	cdnBase := strings.TrimRight(config.GetEnv("CDN_URL", "https://cdn.tcugapps.com"), "/")
+	return strings.HasPrefix(url, cdnBase+"/"+quizContentImagePath+"/")
+}
+
+func hasAllowedScheme(target string) bool {
+	lower := strings.ToLower(target)
+	for _, scheme := range allowedLinkSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeImageURL "" berarti hapus gambar
+func normalizeImageURL(url string) (*string, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil, nil
+	}
+	if !IsQuizContentImageURL(url) {
+		return nil, fmt.Errorf("gambar harus diupload lewat endpoint upload")
+	}
+	return &url, nil
+}
+
+// replaceImage set gambar baru dan catat gambar lama yang perlu dihapus setelah commit
+func replaceImage(current **string, url string, stale *[]string) error {
+	next, err := normalizeImageURL(url)
+	if err != nil {
+		return err
+	}
+	// hanya gambar dari folder upload quiz yang ikut dihapus, path lama di luar itu dibiarkan
+	if *current != nil && (next == nil || **current != *next) && IsQuizContentImageURL(**current) {
+		*stale = append(*stale, **current)
+	}
+	*current = next
+	return nil
+}
+
+// UpdateQuestionContent ubah teks, gambar & pembahasan soal beserta opsinya
+func (s *QuizService) UpdateQuestionContent(ctx context.Context, user *utils.Claims, quizID, questionID uuid.UUID, body *dto.UpdateQuestionRequest) (*models.QuizQuestion, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	var question *models.QuizQuestion
+	var staleImages []string
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		question, err = s.quizRepo.WithTx(tx).GetQuestionWithOptions(ctx, questionID, quizID)
+		if err != nil {
+			return err
+		}
+
+		if body.Question != nil {
+			text, err := SanitizeQuestionContent(*body.Question)
+			if err != nil {
+				return fmt.Errorf("question: %w", err)
+			}
+			question.Question = text
+		}
+		if body.Explanation != nil {
+			text, err := SanitizeQuestionContent(*body.Explanation)
+			if err != nil {
+				return fmt.Errorf("explanation: %w", err)
+			}
+			question.Explanation = nil
+			if text != "" {
+				question.Explanation = &text
+			}
+		}
+		if body.ImageURL != nil {
+			if err := replaceImage(&question.ImageURL, *body.ImageURL, &staleImages); err != nil {
+				return err
+			}
+		}
+		if strings.TrimSpace(question.Question) == "" && question.ImageURL == nil {
+			return fmt.Errorf("soal harus berisi teks atau gambar")
+		}
+
+		if err := s.quizRepo.WithTx(tx).UpdateQuestionContent(ctx, question); err != nil {
+			return err
+		}
+
+		for _, req := range body.Options {
+			idx := -1
+			for i := range question.Options {
+				if question.Options[i].ID == req.ID {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				return fmt.Errorf("option %s not found in this question", req.ID)
+			}
+
+			opt := &question.Options[idx]
+			if req.OptionText != nil {
+				text, err := SanitizeQuestionContent(*req.OptionText)
+				if err != nil {
+					return fmt.Errorf("option %s: %w", req.ID, err)
+				}
+				opt.OptionText = text
+			}
+			if req.ImageURL != nil {
+				if err := replaceImage(&opt.ImageURL, *req.ImageURL, &staleImages); err != nil {
+					return err
+				}
+			}
+			if err := s.quizRepo.WithTx(tx).UpdateOptionContent(ctx, opt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// hapus gambar lama setelah TX berhasil
+	for _, path := range staleImages {
+		if delErr := s.fileService.DeleteFile(path); delErr != nil {
+			log.Errorf("Gagal hapus file %s: %v", path, delErr)
+		}
+	}
+	return question, nil
+}
+
+// saveImportedImage simpan gambar pertama pada sebuah sel Excel ke folder upload quiz
+func (s *QuizService) saveImportedImage(f *excelize.File, sheet, cell string, saved *[]string) (*string, error) {
+	pics, err := f.GetPictures(sheet, cell)
+	if err != nil || len(pics) == 0 {
+		return nil, err
+	}
+
+	ext := strings.ToLower(pics[0].Extension)
+	if !slices.Contains(utils.AllowedImageExtensions, ext) {
+		return nil, fmt.Errorf("format gambar %s di sel %s tidak didukung", ext, cell)
+	}
+
+	url, err := s.fileService.SaveGeneratedFile(quizContentImagePath, uuid.New().String()+ext, pics[0].File)
+	if err != nil {
+		return nil, err
+	}
+	*saved = append(*saved, url)
+	return &url, nil
+}
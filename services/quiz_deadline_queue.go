@@ -0,0 +1,105 @@
+package services
+
+import (
+	"brevet-api/config"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// QuizDeadlineQueueKey sorted set berisi attemptID dengan score = waktu berakhir attempt (unix)
+const QuizDeadlineQueueKey = "quiz:attempt_deadlines"
+
+// popDueScript ambil & hapus attempt yang sudah lewat deadline secara atomik
+var popDueScript = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+if #ids > 0 then
+	redis.call("ZREM", KEYS[1], unpack(ids))
+end
+return ids
+`)
+
+// IQuizDeadlineQueue interface
+type IQuizDeadlineQueue interface {
+	Schedule(ctx context.Context, attemptID uuid.UUID, deadline time.Time) error
+	ScheduleMany(ctx context.Context, deadlines map[uuid.UUID]time.Time) error
+	Remove(ctx context.Context, attemptID uuid.UUID) error
+	PopDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+}
+
+// QuizDeadlineQueue antrian deadline attempt berbasis Redis sorted set
+type QuizDeadlineQueue struct{}
+
+// NewQuizDeadlineQueue creates a new deadline queue backed by config.RedisClient
+func NewQuizDeadlineQueue() IQuizDeadlineQueue {
+	return &QuizDeadlineQueue{}
+}
+
+func (q *QuizDeadlineQueue) client() (*redis.Client, error) {
+	if config.RedisClient == nil {
+		return nil, fmt.Errorf("redis client belum diinisialisasi")
+	}
+	return config.RedisClient, nil
+}
+
+// Schedule daftarkan (atau perbarui) deadline sebuah attempt
+func (q *QuizDeadlineQueue) Schedule(ctx context.Context, attemptID uuid.UUID, deadline time.Time) error {
+	return q.ScheduleMany(ctx, map[uuid.UUID]time.Time{attemptID: deadline})
+}
+
+// ScheduleMany daftarkan banyak deadline sekaligus
+func (q *QuizDeadlineQueue) ScheduleMany(ctx context.Context, deadlines map[uuid.UUID]time.Time) error {
+	if len(deadlines) == 0 {
+		return nil
+	}
+	client, err := q.client()
+	if err != nil {
+		return err
+	}
+
+	members := make([]redis.Z, 0, len(deadlines))
+	for attemptID, deadline := range deadlines {
+		members = append(members, redis.Z{
+			Score:  float64(deadline.Unix()),
+			Member: attemptID.String(),
+		})
+	}
+	return client.ZAdd(ctx, QuizDeadlineQueueKey, members...).Err()
+}
+
+// Remove hapus attempt dari antrian (misal sudah disubmit manual)
+func (q *QuizDeadlineQueue) Remove(ctx context.Context, attemptID uuid.UUID) error {
+	client, err := q.client()
+	if err != nil {
+		return err
+	}
+	return client.ZRem(ctx, QuizDeadlineQueueKey, attemptID.String()).Err()
+}
+
+// PopDue ambil attempt yang deadline-nya sudah lewat, sekaligus menghapusnya dari antrian
+func (q *QuizDeadlineQueue) PopDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	client, err := q.client()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := popDueScript.Run(ctx, client, []string{QuizDeadlineQueueKey},
+		strconv.FormatInt(now.Unix(), 10), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(raw))
+	for _, r := range raw {
+		id, err := uuid.Parse(r)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"brevet-api/config"
+	"brevet-api/dto"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// QuizLiveChannel channel Redis pub/sub untuk event live quiz antar instance
+const QuizLiveChannel = "quiz:live_events"
+
+// Jenis event live quiz
+const (
+	LiveEventStarted         = "started"
+	LiveEventAnswerSaved     = "answer_saved"
+	LiveEventSubmitted       = "submitted"
+	LiveEventConnected       = "connected"
+	LiveEventDisconnected    = "disconnected"
+	LiveEventDeadlineChanged = "deadline_changed"
+	LiveEventPaused          = "paused"
+	LiveEventResumed         = "resumed"
+)
+
+// livePresenceTTL presence dianggap hilang kalau tidak diperbarui selama ini
+const livePresenceTTL = 30 * time.Second
+
+// quizLivePresenceKey hash attemptID -> waktu terakhir terlihat (unix) untuk satu quiz
+func quizLivePresenceKey(quizID uuid.UUID) string {
+	return "quiz:live_presence:" + quizID.String()
+}
+
+// IQuizLiveHub interface
+type IQuizLiveHub interface {
+	Publish(ctx context.Context, event dto.QuizLiveEvent)
+	SubscribeAttempt(attemptID uuid.UUID) (<-chan dto.QuizLiveEvent, func())
+	SubscribeQuiz(quizID uuid.UUID) (<-chan dto.QuizLiveEvent, func())
+	TouchPresence(ctx context.Context, quizID, attemptID uuid.UUID) error
+	ClearPresence(ctx context.Context, quizID, attemptID uuid.UUID) error
+	Presence(ctx context.Context, quizID uuid.UUID) (map[uuid.UUID]time.Time, error)
+}
+
+type liveSubscribers map[uuid.UUID]map[chan dto.QuizLiveEvent]struct{}
+
+// QuizLiveHub meneruskan event live quiz ke koneksi WebSocket di instance ini.
+// Event dikirim lewat Redis pub/sub supaya worker auto-submit di instance lain ikut tersampaikan.
+type QuizLiveHub struct {
+	mu          sync.RWMutex
+	attemptSubs liveSubscribers
+	quizSubs    liveSubscribers
+	listenOnce  sync.Once
+}
+
+var (
+	defaultLiveHub     IQuizLiveHub
+	defaultLiveHubOnce sync.Once
+)
+
+// NewQuizLiveHub creates a new live hub
+func NewQuizLiveHub() IQuizLiveHub {
+	return &QuizLiveHub{
+		attemptSubs: make(liveSubscribers),
+		quizSubs:    make(liveSubscribers),
+	}
+}
+
+// DefaultQuizLiveHub hub bersama untuk satu proses (dipakai service & controller)
+func DefaultQuizLiveHub() IQuizLiveHub {
+	defaultLiveHubOnce.Do(func() {
+		defaultLiveHub = NewQuizLiveHub()
+	})
+	return defaultLiveHub
+}
+
+// Publish kirim event ke semua instance; tanpa Redis langsung diteruskan secara lokal
+func (h *QuizLiveHub) Publish(ctx context.Context, event dto.QuizLiveEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	if config.RedisClient == nil {
+		h.dispatch(event)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Failed to encode live event: %v", err)
+		return
+	}
+	if err := config.RedisClient.Publish(ctx, QuizLiveChannel, payload).Err(); err != nil {
+		log.Errorf("Failed to publish live event %s for attempt %s: %v", event.Type, event.AttemptID, err)
+	}
+}
+
+// SubscribeAttempt terima event untuk satu attempt; panggil fungsi kedua untuk berhenti
+func (h *QuizLiveHub) SubscribeAttempt(attemptID uuid.UUID) (<-chan dto.QuizLiveEvent, func()) {
+	return h.subscribe(h.attemptSubs, attemptID)
+}
+
+// SubscribeQuiz terima semua event attempt dalam satu quiz (untuk monitor guru)
+func (h *QuizLiveHub) SubscribeQuiz(quizID uuid.UUID) (<-chan dto.QuizLiveEvent, func()) {
+	return h.subscribe(h.quizSubs, quizID)
+}
+
+func (h *QuizLiveHub) subscribe(subs liveSubscribers, key uuid.UUID) (<-chan dto.QuizLiveEvent, func()) {
+	h.listenOnce.Do(h.listen)
+
+	ch := make(chan dto.QuizLiveEvent, 16)
+	h.mu.Lock()
+	if subs[key] == nil {
+		subs[key] = make(map[chan dto.QuizLiveEvent]struct{})
+	}
+	subs[key][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(subs[key], ch)
+			if len(subs[key]) == 0 {
+				delete(subs, key)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// listen berlangganan channel Redis dan meneruskan event ke subscriber lokal
+func (h *QuizLiveHub) listen() {
+	if config.RedisClient == nil {
+		return
+	}
+
+	pubsub := config.RedisClient.Subscribe(context.Background(), QuizLiveChannel)
+	go func() {
+		for msg := range pubsub.Channel() {
+			var event dto.QuizLiveEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Warnf("Invalid live event payload: %v", err)
+				continue
+			}
+			h.dispatch(event)
+		}
+	}()
+}
+
+// dispatch kirim event ke subscriber lokal tanpa blocking; subscriber yang lambat dilewati
+func (h *QuizLiveHub) dispatch(event dto.QuizLiveEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.attemptSubs[event.AttemptID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	for ch := range h.quizSubs[event.QuizID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// TouchPresence tandai attempt sedang terhubung
+func (h *QuizLiveHub) TouchPresence(ctx context.Context, quizID, attemptID uuid.UUID) error {
+	if config.RedisClient == nil {
+		return nil
+	}
+	key := quizLivePresenceKey(quizID)
+	pipe := config.RedisClient.TxPipeline()
+	pipe.HSet(ctx, key, attemptID.String(), time.Now().Unix())
+	pipe.Expire(ctx, key, livePresenceTTL*10)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ClearPresence hapus presence saat koneksi ditutup
+func (h *QuizLiveHub) ClearPresence(ctx context.Context, quizID, attemptID uuid.UUID) error {
+	if config.RedisClient == nil {
+		return nil
+	}
+	return config.RedisClient.HDel(ctx, quizLivePresenceKey(quizID), attemptID.String()).Err()
+}
+
+// Presence attempt yang masih terlihat dalam livePresenceTTL terakhir
+func (h *QuizLiveHub) Presence(ctx context.Context, quizID uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	presence := make(map[uuid.UUID]time.Time)
+	if config.RedisClient == nil {
+		return presence, nil
+	}
+
+	raw, err := config.RedisClient.HGetAll(ctx, quizLivePresenceKey(quizID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-livePresenceTTL)
+	for field, value := range raw {
+		attemptID, err := uuid.Parse(field)
+		if err != nil {
+			continue
+		}
+		unix, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		if seen := time.Unix(unix, 0); seen.After(cutoff) {
+			presence[attemptID] = seen
+		}
+	}
+	return presence, nil
+}
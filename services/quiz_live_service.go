@@ -0,0 +1,124 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpenLiveAttempt validasi attempt milik siswa yang akan dibuka lewat WebSocket
+// dan hitung deadline otoritatif dari server.
+func (s *QuizService) OpenLiveAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) (*dto.QuizLiveSession, error) {
+	attempt, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempt.UserID != user.UserID {
+		return nil, fmt.Errorf("forbidden: not your attempt")
+	}
+	if attempt.EndedAt != nil {
+		return nil, fmt.Errorf("quiz already submitted")
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, attempt.Quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not allowed to access this quiz")
+	}
+
+	acc, err := s.accommodationRepo.GetQuizAccommodation(ctx, attempt.QuizID, attempt.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.QuizLiveSession{
+		AttemptID: attempt.ID,
+		QuizID:    attempt.QuizID,
+		UserID:    attempt.UserID,
+		Deadline:  ComputeAttemptDeadline(attempt, &attempt.Quiz, acc),
+		PausedAt:  attempt.PausedAt,
+	}, nil
+}
+
+// GetLiveMonitor snapshot siswa yang sedang mengerjakan quiz beserta progres & status koneksi
+func (s *QuizService) GetLiveMonitor(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*dto.QuizLiveMonitorResponse, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	attempts, err := s.quizRepo.GetActiveAttemptsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	attemptIDs := make([]uuid.UUID, 0, len(attempts))
+	for _, a := range attempts {
+		attemptIDs = append(attemptIDs, a.ID)
+	}
+	answered, err := s.quizRepo.CountTempSubmissionsByAttemptIDs(ctx, attemptIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	totalQuestions, err := s.quizRepo.CountQuestionsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	accs, err := s.accommodationRepo.ListQuizAccommodations(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	accByUser := make(map[uuid.UUID]*models.QuizAccommodation, len(accs))
+	for i := range accs {
+		accByUser[accs[i].UserID] = &accs[i]
+	}
+
+	presence, err := s.liveHub.Presence(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := make([]dto.QuizLiveParticipant, 0, len(attempts))
+	for i := range attempts {
+		a := &attempts[i]
+		participant := dto.QuizLiveParticipant{
+			AttemptID:     a.ID,
+			UserID:        a.UserID,
+			Name:          a.User.Name,
+			StartedAt:     a.StartedAt,
+			Deadline:      ComputeAttemptDeadline(a, quiz, accByUser[a.UserID]),
+			AnsweredCount: answered[a.ID],
+			PausedAt:      a.PausedAt,
+		}
+		if seen, ok := presence[a.ID]; ok {
+			participant.Connected = true
+			participant.LastSeenAt = &seen
+		}
+		participants = append(participants, participant)
+	}
+
+	return &dto.QuizLiveMonitorResponse{
+		QuizID:         quizID,
+		TotalQuestions: totalQuestions,
+		ServerTime:     time.Now(),
+		Participants:   participants,
+	}, nil
+}
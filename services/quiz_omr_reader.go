@@ -0,0 +1,357 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Tata letak LJK dalam milimeter (A4 potrait). Dipakai bersama oleh generator PDF dan pembaca scan,
+// jadi perubahan posisi cukup dilakukan di sini.
+const (
+	OmrPageWidth  = 210.0
+	OmrPageHeight = 297.0
+
+	// OmrMarkSize sisi kotak tanda registrasi di keempat sudut
+	OmrMarkSize = 8.0
+	// OmrIDBits 32 bit serial + 8 bit checksum
+	OmrIDBits     = 40
+	OmrIDCellSize = 3.0
+
+	OmrMaxQuestions   = 100
+	OmrMaxOptions     = 5
+	OmrRowsPerColumn  = 25
+	OmrBubbleRadius   = 2.2
+	omrBubbleSampleMM = 1.4 // di dalam garis lingkaran supaya outline tidak ikut terhitung
+	omrIDSampleMM     = 1.0
+
+	omrFilledRatio = 0.70 // di atas ini bulatan dianggap terisi
+	omrEmptyRatio  = 0.30 // di bawah ini bulatan dianggap kosong
+	// OmrReviewConfidence scan dengan keyakinan di bawah ini masuk antrian review
+	OmrReviewConfidence = 0.5
+)
+
+// OmrPoint titik di lembar (mm) atau di gambar (pixel)
+type OmrPoint struct {
+	X, Y float64
+}
+
+// OmrMarkCenters pusat tanda registrasi: kiri atas, kanan atas, kiri bawah, kanan bawah
+func OmrMarkCenters() [4]OmrPoint {
+	const m = 10 + OmrMarkSize/2
+	return [4]OmrPoint{
+		{m, m},
+		{OmrPageWidth - m, m},
+		{m, OmrPageHeight - m},
+		{OmrPageWidth - m, OmrPageHeight - m},
+	}
+}
+
+// OmrIDCellCenter pusat kotak bit ke-i kode lembar (dua baris × 20)
+func OmrIDCellCenter(bit int) OmrPoint {
+	row, col := bit/20, bit%20
+	return OmrPoint{X: 40 + float64(col)*5 + OmrIDCellSize/2, Y: 48 + float64(row)*5 + OmrIDCellSize/2}
+}
+
+// OmrBubbleCenter pusat bulatan opsi ke-option (0 = A) untuk nomor soal number (mulai 1)
+func OmrBubbleCenter(number, option int) OmrPoint {
+	idx := number - 1
+	col, row := idx/OmrRowsPerColumn, idx%OmrRowsPerColumn
+	return OmrPoint{X: 15 + float64(col)*47 + 12 + float64(option)*7, Y: 70 + float64(row)*8}
+}
+
+// EncodeOmrID serial lembar ke pola bit yang dicetak (big endian + checksum CRC32 1 byte)
+func EncodeOmrID(serial uint32) [OmrIDBits]bool {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], serial)
+	payload := append(buf[:], byte(crc32.ChecksumIEEE(buf[:])))
+
+	var bits [OmrIDBits]bool
+	for i := range bits {
+		bits[i] = payload[i/8]&(1<<(7-uint(i%8))) != 0
+	}
+	return bits
+}
+
+// DecodeOmrID kebalikan EncodeOmrID; false kalau checksum tidak cocok
+func DecodeOmrID(bits [OmrIDBits]bool) (uint32, bool) {
+	var payload [5]byte
+	for i, b := range bits {
+		if b {
+			payload[i/8] |= 1 << (7 - uint(i%8))
+		}
+	}
+	if byte(crc32.ChecksumIEEE(payload[:4])) != payload[4] {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(payload[:4]), true
+}
+
+// OmrBubbleAnswer bacaan satu nomor soal
+type OmrBubbleAnswer struct {
+	Number     int
+	Label      string // kosong = tidak dijawab, atau tebakan terbaik kalau Ambiguous
+	Confidence float64
+	Ambiguous  bool
+}
+
+// OmrSheetImage gambar scan yang tanda registrasinya sudah ditemukan
+type OmrSheetImage struct {
+	dark          []bool
+	width, height int
+	corners       [4]OmrPoint // posisi tanda registrasi di gambar (pixel)
+	scale         float64     // pixel per mm
+}
+
+// LocateOmrSheet cari empat tanda registrasi supaya posisi bulatan bisa dipetakan walau scan
+// sedikit miring, bergeser, atau beda resolusi.
+func LocateOmrSheet(img image.Image) (*OmrSheetImage, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w < 200 || h < 280 {
+		return nil, fmt.Errorf("resolusi scan terlalu kecil")
+	}
+
+	gray := make([]uint8, w*h)
+	var hist [256]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := grayAt(img, bounds.Min.X+x, bounds.Min.Y+y)
+			gray[y*w+x] = v
+			hist[v]++
+		}
+	}
+
+	threshold := otsuThreshold(hist[:], w*h)
+	sheet := &OmrSheetImage{dark: make([]bool, w*h), width: w, height: h}
+	for i, v := range gray {
+		sheet.dark[i] = v < threshold
+	}
+
+	// tiap tanda dicari di area sudutnya masing-masing
+	rw, rh := w/4, h/5
+	regions := [4]image.Rectangle{
+		image.Rect(0, 0, rw, rh),
+		image.Rect(w-rw, 0, w, rh),
+		image.Rect(0, h-rh, rw, h),
+		image.Rect(w-rw, h-rh, w, h),
+	}
+	visited := make([]bool, w*h)
+	for i, region := range regions {
+		center, ok := sheet.findMark(region, visited)
+		if !ok {
+			return nil, fmt.Errorf("tanda registrasi %s tidak ditemukan", [4]string{"kiri atas", "kanan atas", "kiri bawah", "kanan bawah"}[i])
+		}
+		sheet.corners[i] = center
+	}
+
+	// jarak antar tanda harus sesuai proporsi lembar
+	expected := OmrMarkCenters()
+	top := distance(sheet.corners[0], sheet.corners[1]) / (expected[1].X - expected[0].X)
+	left := distance(sheet.corners[0], sheet.corners[2]) / (expected[2].Y - expected[0].Y)
+	if top <= 0 || math.Abs(top-left)/top > 0.15 {
+		return nil, fmt.Errorf("posisi tanda registrasi tidak sesuai lembar jawaban")
+	}
+	sheet.scale = (top + left) / 2
+	return sheet, nil
+}
+
+// findMark komponen gelap terbesar yang berbentuk kotak penuh di dalam region
+func (s *OmrSheetImage) findMark(region image.Rectangle, visited []bool) (OmrPoint, bool) {
+	var best OmrPoint
+	bestArea := 0
+	minSide := int(math.Max(6, float64(s.width)/OmrPageWidth*OmrMarkSize*0.5))
+
+	var stack []int
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			start := y*s.width + x
+			if !s.dark[start] || visited[start] {
+				continue
+			}
+
+			// flood fill 4 arah, dibatasi region
+			minX, maxX, minY, maxY := x, x, y, y
+			area, sumX, sumY := 0, 0, 0
+			stack = append(stack[:0], start)
+			visited[start] = true
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				px, py := p%s.width, p/s.width
+				area++
+				sumX += px
+				sumY += py
+				minX, maxX = min(minX, px), max(maxX, px)
+				minY, maxY = min(minY, py), max(maxY, py)
+
+				for _, n := range [4][2]int{{px - 1, py}, {px + 1, py}, {px, py - 1}, {px, py + 1}} {
+					if n[0] < region.Min.X || n[0] >= region.Max.X || n[1] < region.Min.Y || n[1] >= region.Max.Y {
+						continue
+					}
+					q := n[1]*s.width + n[0]
+					if s.dark[q] && !visited[q] {
+						visited[q] = true
+						stack = append(stack, q)
+					}
+				}
+			}
+
+			bw, bh := maxX-minX+1, maxY-minY+1
+			if bw < minSide || bh < minSide {
+				continue
+			}
+			aspect := float64(bw) / float64(bh)
+			fill := float64(area) / float64(bw*bh)
+			// kotak yang sedikit miring tetap punya fill ratio tinggi
+			if aspect < 0.6 || aspect > 1.6 || fill < 0.6 {
+				continue
+			}
+			if area > bestArea {
+				bestArea = area
+				best = OmrPoint{X: float64(sumX)/float64(area) + 0.5, Y: float64(sumY)/float64(area) + 0.5}
+			}
+		}
+	}
+	return best, bestArea > 0
+}
+
+// toImage petakan titik lembar (mm) ke pixel dengan interpolasi bilinear antar tanda registrasi
+func (s *OmrSheetImage) toImage(p OmrPoint) OmrPoint {
+	marks := OmrMarkCenters()
+	u := (p.X - marks[0].X) / (marks[1].X - marks[0].X)
+	v := (p.Y - marks[0].Y) / (marks[2].Y - marks[0].Y)
+	c := s.corners
+	return OmrPoint{
+		X: (1-u)*(1-v)*c[0].X + u*(1-v)*c[1].X + (1-u)*v*c[2].X + u*v*c[3].X,
+		Y: (1-u)*(1-v)*c[0].Y + u*(1-v)*c[1].Y + (1-u)*v*c[2].Y + u*v*c[3].Y,
+	}
+}
+
+// darkRatio proporsi pixel gelap dalam lingkaran radius (mm) di sekitar titik lembar p
+func (s *OmrSheetImage) darkRatio(p OmrPoint, radiusMM float64) float64 {
+	c := s.toImage(p)
+	r := radiusMM * s.scale
+	dark, total := 0, 0
+	for y := int(c.Y - r); y <= int(c.Y+r); y++ {
+		for x := int(c.X - r); x <= int(c.X+r); x++ {
+			if x < 0 || y < 0 || x >= s.width || y >= s.height {
+				continue
+			}
+			dx, dy := float64(x)+0.5-c.X, float64(y)+0.5-c.Y
+			if dx*dx+dy*dy > r*r {
+				continue
+			}
+			total++
+			if s.dark[y*s.width+x] {
+				dark++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(dark) / float64(total)
+}
+
+// ReadSerial baca kode biner lembar. Confidence adalah keyakinan bit yang paling meragukan.
+func (s *OmrSheetImage) ReadSerial() (serial uint32, ok bool, confidence float64) {
+	var bits [OmrIDBits]bool
+	confidence = 1
+	for i := range bits {
+		ratio := s.darkRatio(OmrIDCellCenter(i), omrIDSampleMM)
+		bits[i] = ratio > 0.5
+		confidence = math.Min(confidence, math.Abs(ratio-0.5)*2)
+	}
+	serial, ok = DecodeOmrID(bits)
+	return serial, ok, confidence
+}
+
+// ReadAnswers baca bulatan tiap nomor; optionCounts[i] jumlah opsi nomor i+1
+func (s *OmrSheetImage) ReadAnswers(optionCounts []int) []OmrBubbleAnswer {
+	answers := make([]OmrBubbleAnswer, 0, len(optionCounts))
+	for i, count := range optionCounts {
+		number := i + 1
+		answer := OmrBubbleAnswer{Number: number, Confidence: 1}
+
+		filled, unsure := 0, 0
+		bestRatio := 0.0
+		for opt := 0; opt < count; opt++ {
+			ratio := s.darkRatio(OmrBubbleCenter(number, opt), omrBubbleSampleMM)
+			answer.Confidence = math.Min(answer.Confidence, bubbleConfidence(ratio))
+
+			switch {
+			case ratio >= omrFilledRatio:
+				filled++
+			case ratio > omrEmptyRatio:
+				unsure++
+			}
+			if ratio > bestRatio && ratio > omrEmptyRatio {
+				bestRatio = ratio
+				answer.Label = string(paperLabels[opt])
+			}
+		}
+
+		// dua bulatan terisi / bulatan setengah terisi (misal bekas hapusan) perlu dicek manual
+		if filled > 1 || unsure > 0 {
+			answer.Ambiguous = true
+			answer.Confidence = math.Min(answer.Confidence, 0.2)
+		}
+		if filled > 1 {
+			answer.Label = ""
+		}
+		answers = append(answers, answer)
+	}
+	return answers
+}
+
+// bubbleConfidence seberapa jauh rasio gelap dari area abu-abu antara kosong dan terisi
+func bubbleConfidence(ratio float64) float64 {
+	mid := (omrFilledRatio + omrEmptyRatio) / 2
+	return math.Min(1, math.Abs(ratio-mid)/0.3)
+}
+
+// grayAt luminance pixel; hasil decode JPEG & PNG grayscale dibaca langsung tanpa konversi warna
+func grayAt(img image.Image, x, y int) uint8 {
+	switch m := img.(type) {
+	case *image.Gray:
+		return m.GrayAt(x, y).Y
+	case *image.YCbCr:
+		return m.Y[m.YOffset(x, y)]
+	}
+	return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+}
+
+func otsuThreshold(hist []int, total int) uint8 {
+	sum := 0.0
+	for i, n := range hist {
+		sum += float64(i * n)
+	}
+
+	var sumB, wB float64
+	best, threshold := 0.0, 128
+	for t, n := range hist {
+		wB += float64(n)
+		if wB == 0 {
+			continue
+		}
+		wF := float64(total) - wB
+		if wF == 0 {
+			break
+		}
+		sumB += float64(t * n)
+		mB, mF := sumB/wB, (sum-sumB)/wF
+		between := wB * wF * (mB - mF) * (mB - mF)
+		if between > best {
+			best, threshold = between, t+1
+		}
+	}
+	return uint8(min(threshold, 255))
+}
+
+func distance(a, b OmrPoint) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
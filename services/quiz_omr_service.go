@@ -0,0 +1,497 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // decoder scan PNG
+	"io"
+	"math"
+	"math/rand"
+	"mime/multipart"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+)
+
+// omrScanPath folder upload gambar scan LJK (dipakai untuk review manual)
+const omrScanPath = "omr"
+
+// GenerateAnswerSheets buat LJK untuk setiap siswa batch yang belum punya. Versi naskah dibagi
+// bergiliran supaya siswa yang duduk berdekatan mendapat versi berbeda.
+func (s *QuizPaperService) GenerateAnswerSheets(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]models.QuizAnswerSheet, error) {
+	quiz, versions, err := s.loadPrintableVersions(ctx, user, quizID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if err := checkOmrCapacity(&v); err != nil {
+			return nil, err
+		}
+	}
+
+	batch, err := s.checkTeacherAccess(ctx, user, quiz)
+	if err != nil {
+		return nil, err
+	}
+	students, err := s.paperRepo.GetPaidStudentsByBatchID(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		assigned := 0
+		for _, student := range students {
+			_, err := s.paperRepo.WithTx(tx).GetSheetByQuizAndUser(ctx, quizID, student.ID)
+			if err == nil {
+				assigned++
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			serial, err := s.newSheetSerial(ctx, tx, rng)
+			if err != nil {
+				return err
+			}
+			sheet := &models.QuizAnswerSheet{
+				QuizID:    quizID,
+				UserID:    student.ID,
+				VersionID: versions[assigned%len(versions)].ID,
+				Serial:    serial,
+			}
+			if err := s.paperRepo.WithTx(tx).CreateSheet(ctx, sheet); err != nil {
+				return err
+			}
+			assigned++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.paperRepo.GetSheetsByQuizID(ctx, quizID)
+}
+
+// newSheetSerial serial acak 32 bit yang belum dipakai LJK lain
+func (s *QuizPaperService) newSheetSerial(ctx context.Context, tx *gorm.DB, rng *rand.Rand) (int64, error) {
+	for i := 0; i < 10; i++ {
+		serial := int64(rng.Uint32())
+		if serial == 0 {
+			continue
+		}
+		used, err := s.paperRepo.WithTx(tx).IsSheetSerialUsed(ctx, serial)
+		if err != nil {
+			return 0, err
+		}
+		if !used {
+			return serial, nil
+		}
+	}
+	return 0, fmt.Errorf("gagal membuat kode LJK unik")
+}
+
+// checkOmrCapacity LJK hanya muat OmrMaxQuestions nomor dengan maksimal OmrMaxOptions pilihan
+func checkOmrCapacity(version *models.QuizPaperVersion) error {
+	if len(version.Questions) > OmrMaxQuestions {
+		return fmt.Errorf("LJK maksimal %d soal", OmrMaxQuestions)
+	}
+	for _, pq := range version.Questions {
+		if len(pq.Options) > OmrMaxOptions {
+			return fmt.Errorf("LJK maksimal %d pilihan per soal, nomor %d versi %s punya %d", OmrMaxOptions, pq.Number, version.Code, len(pq.Options))
+		}
+	}
+	return nil
+}
+
+// GenerateAnswerSheetsPDF cetak semua LJK quiz, satu halaman per siswa
+func (s *QuizPaperService) GenerateAnswerSheetsPDF(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*bytes.Buffer, string, error) {
+	quiz, versions, err := s.loadPrintableVersions(ctx, user, quizID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sheets, err := s.paperRepo.GetSheetsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(sheets) == 0 {
+		return nil, "", fmt.Errorf("LJK belum dibuat")
+	}
+
+	versionByID := make(map[uuid.UUID]*models.QuizPaperVersion, len(versions))
+	for i := range versions {
+		versionByID[versions[i].ID] = &versions[i]
+	}
+
+	pdf, font := newPaperPDF()
+	pdf.SetAutoPageBreak(false, 0)
+	for _, sheet := range sheets {
+		version, ok := versionByID[sheet.VersionID]
+		if !ok {
+			return nil, "", fmt.Errorf("versi naskah LJK %s tidak ditemukan", sheet.User.Name)
+		}
+		writeAnswerSheet(pdf, font, quiz, &sheet, version)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, "", err
+	}
+	return &buf, fmt.Sprintf("ljk_%s.pdf", quiz.ID.String()), nil
+}
+
+// writeAnswerSheet gambar satu halaman LJK sesuai tata letak di quiz_omr_reader.go
+func writeAnswerSheet(pdf *gofpdf.Fpdf, font string, quiz *models.Quiz, sheet *models.QuizAnswerSheet, version *models.QuizPaperVersion) {
+	pdf.AddPage()
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFillColor(0, 0, 0)
+	pdf.SetDrawColor(0, 0, 0)
+
+	// tanda registrasi
+	for _, c := range OmrMarkCenters() {
+		pdf.Rect(c.X-OmrMarkSize/2, c.Y-OmrMarkSize/2, OmrMarkSize, OmrMarkSize, "F")
+	}
+
+	nim := "-"
+	if sheet.User.Profile != nil && sheet.User.Profile.NIM.Valid {
+		nim = sheet.User.Profile.NIM.String
+	}
+
+	pdf.SetXY(22, 10)
+	pdf.SetFont(font, "B", 14)
+	pdf.CellFormat(166, 8, "LEMBAR JAWABAN", "", 1, "C", false, 0, "")
+	pdf.SetX(22)
+	pdf.SetFont(font, "", 10)
+	pdf.CellFormat(166, 6, quiz.Title, "", 1, "C", false, 0, "")
+	pdf.SetXY(22, 27)
+	pdf.CellFormat(100, 6, "Nama : "+sheet.User.Name, "", 0, "L", false, 0, "")
+	pdf.CellFormat(66, 6, "NIM : "+nim, "", 1, "L", false, 0, "")
+	pdf.SetX(22)
+	pdf.SetFont(font, "B", 10)
+	pdf.CellFormat(100, 6, "Versi Naskah : "+version.Code, "", 0, "L", false, 0, "")
+	pdf.SetFont(font, "", 10)
+	pdf.CellFormat(66, 6, fmt.Sprintf("Kode : %010d", sheet.Serial), "", 1, "L", false, 0, "")
+
+	// kode lembar, jangan dicoret
+	pdf.SetFont(font, "", 7)
+	pdf.SetXY(22, 48)
+	pdf.CellFormat(16, 8, "KODE LJK", "", 0, "L", false, 0, "")
+	for i, bit := range EncodeOmrID(uint32(sheet.Serial)) {
+		if bit {
+			c := OmrIDCellCenter(i)
+			pdf.Rect(c.X-OmrIDCellSize/2, c.Y-OmrIDCellSize/2, OmrIDCellSize, OmrIDCellSize, "F")
+		}
+	}
+
+	pdf.SetXY(22, 59)
+	pdf.SetFont(font, "I", 8)
+	pdf.CellFormat(166, 5, "Hitamkan penuh satu bulatan per nomor dengan pensil 2B. Jangan melipat atau mencoret tanda hitam di sudut.", "", 1, "L", false, 0, "")
+
+	pdf.SetLineWidth(0.25)
+	for _, pq := range version.Questions {
+		first := OmrBubbleCenter(pq.Number, 0)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont(font, "B", 8)
+		pdf.SetXY(first.X-11, first.Y-2.5)
+		pdf.CellFormat(7, 5, fmt.Sprintf("%d", pq.Number), "", 0, "R", false, 0, "")
+
+		// huruf di dalam bulatan dicetak abu-abu muda supaya tidak terbaca sebagai isian
+		pdf.SetFont(font, "", 6)
+		pdf.SetTextColor(170, 170, 170)
+		for opt := range pq.Options {
+			c := OmrBubbleCenter(pq.Number, opt)
+			pdf.Circle(c.X, c.Y, OmrBubbleRadius, "D")
+			pdf.SetXY(c.X-OmrBubbleRadius, c.Y-OmrBubbleRadius)
+			pdf.CellFormat(OmrBubbleRadius*2, OmrBubbleRadius*2, string(paperLabels[opt]), "", 0, "C", false, 0, "")
+		}
+	}
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// UploadOmrScans baca scan LJK (PNG/JPEG, atau PDF berisi gambar scan per halaman). Lembar yang
+// terbaca dengan yakin langsung dinilai, sisanya masuk antrian review.
+func (s *QuizPaperService) UploadOmrScans(ctx context.Context, user *utils.Claims, quizID uuid.UUID, files []*multipart.FileHeader) ([]models.QuizOmrScan, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("tidak ada file scan")
+	}
+	_, versions, err := s.loadPrintableVersions(ctx, user, quizID)
+	if err != nil {
+		return nil, err
+	}
+	versionByID := make(map[uuid.UUID]*models.QuizPaperVersion, len(versions))
+	for i := range versions {
+		versionByID[versions[i].ID] = &versions[i]
+	}
+
+	var scans []models.QuizOmrScan
+	for _, fileHeader := range files {
+		pages, err := readScanPages(fileHeader)
+		if err != nil {
+			return scans, fmt.Errorf("%s: %w", fileHeader.Filename, err)
+		}
+
+		for _, page := range pages {
+			imageURL, err := s.fileService.SaveGeneratedFile(omrScanPath, uuid.New().String()+page.ext, page.data)
+			if err != nil {
+				return scans, err
+			}
+
+			scan, err := s.processOmrPage(ctx, user, quizID, versionByID, page.img, imageURL)
+			if err != nil {
+				if delErr := s.fileService.DeleteFile(imageURL); delErr != nil {
+					log.Errorf("Gagal hapus file %s: %v", imageURL, delErr)
+				}
+				return scans, err
+			}
+			scans = append(scans, *scan)
+		}
+	}
+	return scans, nil
+}
+
+// scanPage satu halaman scan beserta bytes yang disimpan untuk review
+type scanPage struct {
+	img  image.Image
+	data []byte
+	ext  string
+}
+
+func readScanPages(fileHeader *multipart.FileHeader) ([]scanPage, error) {
+	if !utils.IsAllowedExtension(fileHeader.Filename, utils.AllowedScanExtensions) {
+		return nil, fmt.Errorf("format scan harus PNG, JPEG atau PDF")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if ext != ".pdf" {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gambar scan tidak bisa dibaca: %w", err)
+		}
+		return []scanPage{{img: img, data: data, ext: ext}}, nil
+	}
+
+	images, err := utils.ExtractPDFImages(data)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]scanPage, 0, len(images))
+	for _, img := range images {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		pages = append(pages, scanPage{img: img, data: buf.Bytes(), ext: ".jpg"})
+	}
+	return pages, nil
+}
+
+// processOmrPage baca satu halaman, simpan hasil bacaan, lalu nilai kalau cukup yakin
+func (s *QuizPaperService) processOmrPage(ctx context.Context, user *utils.Claims, quizID uuid.UUID, versionByID map[uuid.UUID]*models.QuizPaperVersion, img image.Image, imageURL string) (*models.QuizOmrScan, error) {
+	scan := &models.QuizOmrScan{QuizID: quizID, ImageURL: imageURL, Status: models.OmrScanReview, UploadedBy: user.UserID}
+	var issues []string
+	var sheet *models.QuizAnswerSheet
+	var version *models.QuizPaperVersion
+
+	located, err := LocateOmrSheet(img)
+	if err != nil {
+		issues = append(issues, err.Error())
+	} else {
+		serial, ok, confidence := located.ReadSerial()
+		scan.Confidence = confidence
+		if !ok {
+			issues = append(issues, "kode LJK tidak terbaca")
+		} else {
+			sheet, err = s.paperRepo.GetSheetBySerial(ctx, quizID, int64(serial))
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if sheet == nil {
+				issues = append(issues, fmt.Sprintf("kode LJK %010d bukan milik quiz ini", serial))
+			}
+		}
+
+		if sheet != nil {
+			scan.SheetID = &sheet.ID
+			version = versionByID[sheet.VersionID]
+		}
+		if version != nil {
+			counts := make([]int, len(version.Questions))
+			for i, pq := range version.Questions {
+				counts[i] = len(pq.Options)
+			}
+			for _, ans := range located.ReadAnswers(counts) {
+				scan.Confidence = math.Min(scan.Confidence, ans.Confidence)
+				if ans.Ambiguous {
+					issues = append(issues, fmt.Sprintf("nomor %d perlu dicek", ans.Number))
+				}
+				scan.Answers = append(scan.Answers, models.QuizOmrScanAnswer{
+					Number: ans.Number, Label: ans.Label, Confidence: ans.Confidence, Ambiguous: ans.Ambiguous,
+				})
+			}
+		}
+	}
+
+	if version != nil && len(issues) == 0 && scan.Confidence >= OmrReviewConfidence {
+		result, err := s.GradePaperAnswers(ctx, user, quizID, &dto.SubmitPaperAnswersRequest{
+			UserID: sheet.UserID, VersionCode: version.Code, Answers: scanAnswersToPaper(scan.Answers),
+		})
+		if err != nil {
+			issues = append(issues, err.Error())
+		} else {
+			scan.Status = models.OmrScanGraded
+			scan.AttemptID = &result.AttemptID
+		}
+	}
+
+	if len(issues) > 0 {
+		joined := strings.Join(issues, "; ")
+		scan.Issues = &joined
+	}
+	if err := s.paperRepo.CreateScan(ctx, scan); err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
+
+func scanAnswersToPaper(answers []models.QuizOmrScanAnswer) []dto.PaperAnswerRequest {
+	paper := make([]dto.PaperAnswerRequest, 0, len(answers))
+	for _, ans := range answers {
+		paper = append(paper, dto.PaperAnswerRequest{Number: ans.Number, Label: ans.Label})
+	}
+	return paper
+}
+
+// GetOmrScans daftar scan quiz; status "review" untuk antrian koreksi manual
+func (s *QuizPaperService) GetOmrScans(ctx context.Context, user *utils.Claims, quizID uuid.UUID, status string) ([]models.QuizOmrScan, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkTeacherAccess(ctx, user, quiz); err != nil {
+		return nil, err
+	}
+
+	valid := []models.OmrScanStatus{models.OmrScanGraded, models.OmrScanReview, models.OmrScanResolved, models.OmrScanRejected}
+	if status != "" && !slices.Contains(valid, models.OmrScanStatus(status)) {
+		return nil, fmt.Errorf("status scan %s tidak dikenal", status)
+	}
+	return s.paperRepo.GetScansByQuizID(ctx, quizID, status)
+}
+
+// loadScanForReview ambil scan dan pastikan guru mengajar quiz-nya
+func (s *QuizPaperService) loadScanForReview(ctx context.Context, user *utils.Claims, scanID uuid.UUID) (*models.QuizOmrScan, error) {
+	scan, err := s.paperRepo.GetScanByID(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+	quiz, err := s.quizRepo.GetQuizByID(ctx, scan.QuizID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkTeacherAccess(ctx, user, quiz); err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
+
+// ResolveOmrScan nilai scan dengan jawaban hasil koreksi manual. Bacaan asli scan tetap disimpan.
+func (s *QuizPaperService) ResolveOmrScan(ctx context.Context, user *utils.Claims, scanID uuid.UUID, body *dto.ResolveOmrScanRequest) (*models.QuizOmrScan, error) {
+	scan, err := s.loadScanForReview(ctx, user, scanID)
+	if err != nil {
+		return nil, err
+	}
+	// scan yang sudah dinilai/ditolak tidak boleh dinilai ulang, nanti attempt-nya dobel
+	if scan.Status != models.OmrScanReview {
+		return nil, fmt.Errorf("scan berstatus %s, hanya scan yang perlu review yang bisa dikoreksi", scan.Status)
+	}
+
+	sheet := scan.Sheet
+	if sheet == nil {
+		if body.UserID == nil {
+			return nil, fmt.Errorf("kode LJK tidak terbaca, user_id wajib diisi")
+		}
+		sheet, err = s.paperRepo.GetSheetByQuizAndUser(ctx, scan.QuizID, *body.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("siswa belum punya LJK untuk quiz ini")
+			}
+			return nil, err
+		}
+	}
+
+	result, err := s.GradePaperAnswers(ctx, user, scan.QuizID, &dto.SubmitPaperAnswersRequest{
+		UserID: sheet.UserID, VersionCode: sheet.Version.Code, Answers: body.Answers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scan.SheetID = &sheet.ID
+	scan.Sheet = sheet
+	scan.AttemptID = &result.AttemptID
+	scan.Status = models.OmrScanResolved
+	scan.ReviewedBy = &user.UserID
+	scan.ReviewedAt = &now
+	if err := s.paperRepo.UpdateScan(ctx, scan); err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
+
+// RejectOmrScan buang scan dari antrian review. Attempt yang sudah terbentuk tidak ikut dihapus,
+// batalkan lewat void attempt kalau perlu.
+func (s *QuizPaperService) RejectOmrScan(ctx context.Context, user *utils.Claims, scanID uuid.UUID, body *dto.RejectOmrScanRequest) (*models.QuizOmrScan, error) {
+	scan, err := s.loadScanForReview(ctx, user, scanID)
+	if err != nil {
+		return nil, err
+	}
+	// scan yang sudah dinilai tidak boleh ditolak, nilainya tetap terhitung
+	if scan.Status != models.OmrScanReview {
+		return nil, fmt.Errorf("scan berstatus %s, hanya scan yang perlu review yang bisa ditolak", scan.Status)
+	}
+
+	now := time.Now()
+	reason := body.Reason
+	if scan.Issues != nil {
+		reason = *scan.Issues + "; ditolak: " + body.Reason
+	}
+	scan.Issues = &reason
+	scan.Status = models.OmrScanRejected
+	scan.ReviewedBy = &user.UserID
+	scan.ReviewedAt = &now
+	if err := s.paperRepo.UpdateScan(ctx, scan); err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
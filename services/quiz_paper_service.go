@@ -0,0 +1,539 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+)
+
+// paperLabels huruf kode versi naskah & huruf opsi
+const paperLabels = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// IQuizPaperService interface
+type IQuizPaperService interface {
+	GeneratePaperVersions(ctx context.Context, user *utils.Claims, quizID uuid.UUID, body *dto.GeneratePaperVersionsRequest) ([]models.QuizPaperVersion, error)
+	GetPaperVersions(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]models.QuizPaperVersion, error)
+	GeneratePaperBookletPDF(ctx context.Context, user *utils.Claims, quizID uuid.UUID, code string) (*bytes.Buffer, string, error)
+	GeneratePaperAnswerKeyPDF(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*bytes.Buffer, string, error)
+	GradePaperAnswers(ctx context.Context, user *utils.Claims, quizID uuid.UUID, body *dto.SubmitPaperAnswersRequest) (*models.QuizResult, error)
+	GenerateAnswerSheets(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]models.QuizAnswerSheet, error)
+	GenerateAnswerSheetsPDF(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*bytes.Buffer, string, error)
+	UploadOmrScans(ctx context.Context, user *utils.Claims, quizID uuid.UUID, files []*multipart.FileHeader) ([]models.QuizOmrScan, error)
+	GetOmrScans(ctx context.Context, user *utils.Claims, quizID uuid.UUID, status string) ([]models.QuizOmrScan, error)
+	ResolveOmrScan(ctx context.Context, user *utils.Claims, scanID uuid.UUID, body *dto.ResolveOmrScanRequest) (*models.QuizOmrScan, error)
+	RejectOmrScan(ctx context.Context, user *utils.Claims, scanID uuid.UUID, body *dto.RejectOmrScanRequest) (*models.QuizOmrScan, error)
+}
+
+// QuizPaperService naskah cetak quiz untuk ujian offline: versi acak, booklet & kunci PDF,
+// serta penilaian lembar jawaban yang diinput ulang per versi.
+type QuizPaperService struct {
+	paperRepo       repository.IQuizPaperRepository
+	quizRepo        repository.IQuizRepository
+	batchRepo       repository.IBatchRepository
+	meetingRepo     repository.IMeetingRepository
+	purchaseService IPurchaseService
+	fileService     IFileService
+	db              *gorm.DB
+}
+
+// NewQuizPaperService creates a new instance of QuizPaperService
+func NewQuizPaperService(paperRepo repository.IQuizPaperRepository, quizRepo repository.IQuizRepository,
+	batchRepo repository.IBatchRepository, meetingRepo repository.IMeetingRepository,
+	purchaseService IPurchaseService, fileService IFileService, db *gorm.DB) IQuizPaperService {
+	return &QuizPaperService{paperRepo: paperRepo, quizRepo: quizRepo, batchRepo: batchRepo,
+		meetingRepo: meetingRepo, purchaseService: purchaseService, fileService: fileService, db: db}
+}
+
+// checkTeacherAccess admin selalu boleh, guru hanya untuk batch yang dia ajar
+func (s *QuizPaperService) checkTeacherAccess(ctx context.Context, user *utils.Claims, quiz *models.Quiz) (*models.Batch, error) {
+	batch, err := s.batchRepo.GetBatchByMeetingID(ctx, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == string(models.RoleTypeAdmin) {
+		return &batch, nil
+	}
+	if user.Role == string(models.RoleTypeGuru) {
+		ok, err := s.meetingRepo.IsBatchOwnedByUser(ctx, user.UserID, batch.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &batch, nil
+		}
+	}
+	return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+}
+
+// BuildPaperVersion acak urutan soal & opsi untuk satu versi naskah
+func BuildPaperVersion(questions []models.QuizQuestion, code string, rng *rand.Rand) models.QuizPaperVersion {
+	version := models.QuizPaperVersion{Code: code}
+
+	order := rng.Perm(len(questions))
+	for n, idx := range order {
+		question := questions[idx]
+		paperQuestion := models.QuizPaperQuestion{Number: n + 1, QuestionID: question.ID}
+
+		for l, optIdx := range rng.Perm(len(question.Options)) {
+			paperQuestion.Options = append(paperQuestion.Options, models.QuizPaperOption{
+				Label:    string(paperLabels[l]),
+				OptionID: question.Options[optIdx].ID,
+			})
+		}
+		version.Questions = append(version.Questions, paperQuestion)
+	}
+	return version
+}
+
+// MapPaperAnswers terjemahkan jawaban nomor → huruf pada versi naskah menjadi soal → opsi quiz.
+// Nomor tanpa huruf dianggap tidak dijawab.
+func MapPaperAnswers(version *models.QuizPaperVersion, answers []dto.PaperAnswerRequest) (map[uuid.UUID]uuid.UUID, error) {
+	byNumber := make(map[int]*models.QuizPaperQuestion, len(version.Questions))
+	for i := range version.Questions {
+		byNumber[version.Questions[i].Number] = &version.Questions[i]
+	}
+
+	selected := make(map[uuid.UUID]uuid.UUID, len(answers))
+	seen := make(map[int]bool, len(answers))
+	for _, ans := range answers {
+		if seen[ans.Number] {
+			return nil, fmt.Errorf("nomor %d diisi lebih dari sekali", ans.Number)
+		}
+		seen[ans.Number] = true
+
+		paperQuestion, ok := byNumber[ans.Number]
+		if !ok {
+			return nil, fmt.Errorf("nomor %d tidak ada di naskah versi %s", ans.Number, version.Code)
+		}
+
+		label := strings.ToUpper(strings.TrimSpace(ans.Label))
+		if label == "" {
+			continue
+		}
+
+		idx := slices.IndexFunc(paperQuestion.Options, func(o models.QuizPaperOption) bool { return o.Label == label })
+		if idx < 0 {
+			return nil, fmt.Errorf("pilihan %s tidak ada pada nomor %d", label, ans.Number)
+		}
+		selected[paperQuestion.QuestionID] = paperQuestion.Options[idx].OptionID
+	}
+	return selected, nil
+}
+
+// GeneratePaperVersions buat ulang versi naskah. Ditolak kalau sudah ada LJK atau lembar jawaban
+// yang dinilai, karena pemetaan nomor & huruf versi lama masih dipakai.
+func (s *QuizPaperService) GeneratePaperVersions(ctx context.Context, user *utils.Claims, quizID uuid.UUID, body *dto.GeneratePaperVersionsRequest) ([]models.QuizPaperVersion, error) {
+	quiz, err := s.quizRepo.GetQuizWithQuestions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkTeacherAccess(ctx, user, quiz); err != nil {
+		return nil, err
+	}
+	if len(quiz.Questions) == 0 {
+		return nil, fmt.Errorf("quiz belum memiliki soal")
+	}
+	for _, q := range quiz.Questions {
+		if len(q.Options) > len(paperLabels) {
+			return nil, fmt.Errorf("soal memiliki lebih dari %d opsi", len(paperLabels))
+		}
+	}
+
+	graded, err := s.paperRepo.CountPaperAttemptsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if graded > 0 {
+		return nil, fmt.Errorf("naskah tidak bisa dibuat ulang, sudah ada %d lembar jawaban yang dinilai", graded)
+	}
+	sheets, err := s.paperRepo.CountSheetsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if sheets > 0 {
+		return nil, fmt.Errorf("naskah tidak bisa dibuat ulang, LJK sudah dibuat untuk versi yang ada")
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if err := s.paperRepo.WithTx(tx).DeleteVersionsByQuizID(ctx, quizID); err != nil {
+			return err
+		}
+		for i := 0; i < body.Versions; i++ {
+			version := BuildPaperVersion(quiz.Questions, string(paperLabels[i]), rng)
+			version.QuizID = quizID
+			version.CreatedBy = user.UserID
+			if err := s.paperRepo.WithTx(tx).CreateVersion(ctx, &version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.paperRepo.GetVersionsByQuizID(ctx, quizID)
+}
+
+// GetPaperVersions daftar versi naskah beserta pemetaan soal & opsi
+func (s *QuizPaperService) GetPaperVersions(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]models.QuizPaperVersion, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkTeacherAccess(ctx, user, quiz); err != nil {
+		return nil, err
+	}
+	return s.paperRepo.GetVersionsByQuizID(ctx, quizID)
+}
+
+// loadPrintableVersions ambil quiz & versi naskah, pastikan naskah masih sesuai soal quiz
+func (s *QuizPaperService) loadPrintableVersions(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*models.Quiz, []models.QuizPaperVersion, error) {
+	quiz, err := s.quizRepo.GetQuizWithQuestions(ctx, quizID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.checkTeacherAccess(ctx, user, quiz); err != nil {
+		return nil, nil, err
+	}
+
+	versions, err := s.paperRepo.GetVersionsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil, fmt.Errorf("naskah belum dibuat, generate versi terlebih dahulu")
+	}
+
+	// soal / opsi yang ditambah setelah naskah dibuat tidak ada di pemetaan
+	for _, v := range versions {
+		if len(v.Questions) != len(quiz.Questions) {
+			return nil, nil, fmt.Errorf("soal quiz sudah berubah, generate ulang naskah")
+		}
+		for _, pq := range v.Questions {
+			if len(pq.Options) != len(pq.Question.Options) {
+				return nil, nil, fmt.Errorf("opsi soal nomor %d versi %s sudah berubah, generate ulang naskah", pq.Number, v.Code)
+			}
+		}
+	}
+	return quiz, versions, nil
+}
+
+// GeneratePaperBookletPDF naskah soal siap cetak. Kode kosong = semua versi dalam satu file,
+// masing-masing mulai di halaman baru. Teks soal dicetak apa adanya (markdown & rumus mentah).
+func (s *QuizPaperService) GeneratePaperBookletPDF(ctx context.Context, user *utils.Claims, quizID uuid.UUID, code string) (*bytes.Buffer, string, error) {
+	quiz, versions, err := s.loadPrintableVersions(ctx, user, quizID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code != "" {
+		idx := slices.IndexFunc(versions, func(v models.QuizPaperVersion) bool { return v.Code == code })
+		if idx < 0 {
+			return nil, "", fmt.Errorf("versi naskah %s tidak ditemukan", code)
+		}
+		versions = versions[idx : idx+1]
+	}
+
+	pdf, font := newPaperPDF()
+	currentCode := ""
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(font, "", 9)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Versi %s - Halaman %d", currentCode, pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	for _, version := range versions {
+		currentCode = version.Code
+		pdf.AddPage()
+		writePaperHeader(pdf, font, quiz, version.Code)
+
+		for _, pq := range version.Questions {
+			optionByID := make(map[uuid.UUID]models.QuizOption, len(pq.Question.Options))
+			for _, opt := range pq.Question.Options {
+				optionByID[opt.ID] = opt
+			}
+
+			pdf.SetFont(font, "", 11)
+			pdf.SetX(15)
+			pdf.CellFormat(8, 6, fmt.Sprintf("%d.", pq.Number), "", 0, "L", false, 0, "")
+			pdf.MultiCell(0, 6, pq.Question.Question, "", "L", false)
+			writePaperImage(pdf, pq.Question.ImageURL, 23, 80)
+
+			for _, po := range pq.Options {
+				opt := optionByID[po.OptionID]
+				pdf.SetX(23)
+				pdf.CellFormat(8, 6, po.Label+".", "", 0, "L", false, 0, "")
+				pdf.MultiCell(0, 6, opt.OptionText, "", "L", false)
+				writePaperImage(pdf, opt.ImageURL, 31, 50)
+			}
+			pdf.Ln(3)
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, "", err
+	}
+
+	filename := fmt.Sprintf("naskah_%s.pdf", quiz.ID.String())
+	if code != "" {
+		filename = fmt.Sprintf("naskah_%s_versi_%s.pdf", quiz.ID.String(), code)
+	}
+	return &buf, filename, nil
+}
+
+// GeneratePaperAnswerKeyPDF kunci jawaban, satu halaman terpisah untuk setiap versi naskah
+func (s *QuizPaperService) GeneratePaperAnswerKeyPDF(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*bytes.Buffer, string, error) {
+	quiz, versions, err := s.loadPrintableVersions(ctx, user, quizID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf, font := newPaperPDF()
+	const columns = 5
+	colWidth := 180.0 / columns
+
+	for _, version := range versions {
+		pdf.AddPage()
+		pdf.SetFont(font, "B", 14)
+		pdf.CellFormat(0, 8, "KUNCI JAWABAN - "+quiz.Title, "", 1, "C", false, 0, "")
+		pdf.SetFont(font, "B", 12)
+		pdf.CellFormat(0, 8, "Versi "+version.Code, "", 1, "C", false, 0, "")
+		pdf.Ln(4)
+
+		pdf.SetFont(font, "", 11)
+		for i, pq := range version.Questions {
+			pdf.CellFormat(colWidth, 8, fmt.Sprintf("%d. %s", pq.Number, PaperAnswerKey(&pq)), "1", 0, "L", false, 0, "")
+			if (i+1)%columns == 0 {
+				pdf.Ln(-1)
+			}
+		}
+		pdf.Ln(12)
+		pdf.SetFont(font, "I", 9)
+		pdf.MultiCell(0, 5, "* = semua jawaban dianggap benar, X = soal dibatalkan (tidak dihitung)", "", "L", false)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, "", err
+	}
+	return &buf, fmt.Sprintf("kunci_jawaban_%s.pdf", quiz.ID.String()), nil
+}
+
+// PaperAnswerKey huruf kunci satu nomor naskah; lebih dari satu kunci dipisah "/"
+func PaperAnswerKey(pq *models.QuizPaperQuestion) string {
+	switch pq.Question.ScoringMode {
+	case models.ScoringVoid:
+		return "X"
+	case models.ScoringFreeCredit:
+		return "*"
+	}
+
+	correct := make(map[uuid.UUID]bool, len(pq.Question.Options))
+	for _, opt := range pq.Question.Options {
+		correct[opt.ID] = opt.IsCorrect
+	}
+	var labels []string
+	for _, po := range pq.Options {
+		if correct[po.OptionID] {
+			labels = append(labels, po.Label)
+		}
+	}
+	if len(labels) == 0 {
+		return "-"
+	}
+	return strings.Join(labels, "/")
+}
+
+// GradePaperAnswers nilai lembar jawaban siswa untuk versi naskah tertentu. Hasilnya disimpan
+// sebagai attempt biasa (submission & result), jadi ikut rekap nilai, regrade dan analisis butir.
+// Input ulang untuk siswa yang sama menggantikan penilaian sebelumnya.
+func (s *QuizPaperService) GradePaperAnswers(ctx context.Context, user *utils.Claims, quizID uuid.UUID, body *dto.SubmitPaperAnswersRequest) (*models.QuizResult, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := s.checkTeacherAccess(ctx, user, quiz)
+	if err != nil {
+		return nil, err
+	}
+
+	paid, err := s.purchaseService.HasPaid(ctx, body.UserID, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !paid {
+		return nil, fmt.Errorf("siswa bukan peserta batch ini")
+	}
+
+	version, err := s.paperRepo.GetVersionByCode(ctx, quizID, strings.ToUpper(strings.TrimSpace(body.VersionCode)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("versi naskah %s tidak ditemukan", body.VersionCode)
+		}
+		return nil, err
+	}
+
+	selected, err := MapPaperAnswers(version, body.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.QuizResult
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		now := time.Now()
+
+		attempt, err := s.paperRepo.WithTx(tx).GetPaperAttemptByQuizAndUser(ctx, quizID, body.UserID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			attempt = &models.QuizAttempt{QuizID: quizID, UserID: body.UserID, StartedAt: now, EndedAt: &now, PaperVersionID: &version.ID}
+			if err := s.quizRepo.WithTx(tx).CreateQuizAttempt(ctx, attempt); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if err := s.quizRepo.WithTx(tx).DeleteAttemptGrading(ctx, attempt.ID); err != nil {
+				return err
+			}
+			attempt.EndedAt = &now
+			attempt.PaperVersionID = &version.ID
+			if err := s.quizRepo.WithTx(tx).UpdateQuizAttempt(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		// sama seperti attempt online, hanya nomor yang dijawab yang dihitung
+		correctAnswers := 0
+		totalQuestions := 0
+		for i := range version.Questions {
+			pq := &version.Questions[i]
+			optionID, answered := selected[pq.QuestionID]
+			if !answered {
+				continue
+			}
+
+			score, counted := GradeAnswer(&pq.Question, optionID)
+			if counted {
+				totalQuestions++
+				correctAnswers += score
+			}
+
+			sub := &models.QuizSubmission{
+				AttemptID:        attempt.ID,
+				QuestionID:       pq.QuestionID,
+				SelectedOptionID: optionID,
+				Score:            score,
+			}
+			if err := s.quizRepo.WithTx(tx).SaveQuizSubmission(ctx, sub); err != nil {
+				return err
+			}
+		}
+
+		result = &models.QuizResult{
+			ID:             uuid.New(),
+			AttemptID:      attempt.ID,
+			TotalQuestions: totalQuestions,
+			CorrectAnswers: correctAnswers,
+			WrongAnswers:   totalQuestions - correctAnswers,
+			ScorePercent:   ScorePercent(correctAnswers, totalQuestions),
+		}
+		return s.quizRepo.WithTx(tx).CreateQuizResult(ctx, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// newPaperPDF PDF A4 potrait dengan font Calibri kalau tersedia, fallback ke Arial
+func newPaperPDF() (*gofpdf.Fpdf, string) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	return pdf, paperFont(pdf)
+}
+
+// paperFont daftarkan Calibri kalau tersedia, fallback ke Arial
+func paperFont(pdf *gofpdf.Fpdf) string {
+	if _, err := os.Stat("./fonts/calibri.ttf"); err != nil {
+		return "Arial"
+	}
+	pdf.AddUTF8Font("Calibri", "", "./fonts/calibri.ttf")
+	pdf.AddUTF8Font("Calibri", "B", "./fonts/calibrib.ttf")
+	pdf.AddUTF8Font("Calibri", "I", "./fonts/calibrii.ttf")
+	return "Calibri"
+}
+
+// writePaperHeader judul quiz, kode versi, dan isian nama & NIM siswa
+func writePaperHeader(pdf *gofpdf.Fpdf, font string, quiz *models.Quiz, code string) {
+	pdf.SetFont(font, "B", 14)
+	pdf.CellFormat(150, 8, quiz.Title, "", 0, "L", false, 0, "")
+	pdf.SetFont(font, "B", 16)
+	pdf.CellFormat(30, 12, code, "1", 1, "C", false, 0, "")
+
+	pdf.SetFont(font, "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Waktu: %d menit", quiz.DurationMinute), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont(font, "", 11)
+	pdf.CellFormat(25, 8, "Nama", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, ": ............................................................................", "", 1, "L", false, 0, "")
+	pdf.CellFormat(25, 8, "NIM", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, ": ............................................................................", "", 1, "L", false, 0, "")
+	pdf.CellFormat(25, 8, "Kode Versi", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, ": "+code, "", 1, "L", false, 0, "")
+
+	x, y := pdf.GetXY()
+	pdf.Line(x, y+2, 195, y+2)
+	pdf.Ln(6)
+}
+
+// writePaperImage sisipkan gambar soal / opsi yang tersimpan di folder upload lokal
+func writePaperImage(pdf *gofpdf.Fpdf, imageURL *string, x, width float64) {
+	if imageURL == nil {
+		return
+	}
+	path := paperImagePath(*imageURL)
+	if path == "" {
+		pdf.SetX(x)
+		pdf.CellFormat(0, 6, "[gambar tidak tersedia]", "", 1, "L", false, 0, "")
+		return
+	}
+	pdf.ImageOptions(path, x, pdf.GetY()+1, width, 0, true, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+	pdf.Ln(2)
+}
+
+// paperImagePath terjemahkan URL upload (/uploads/... atau CDN) ke file lokal, "" kalau tidak bisa dicetak
+func paperImagePath(imageURL string) string {
+	ext := strings.ToLower(filepath.Ext(imageURL))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".gif" {
+		return ""
+	}
+	return utils.LocalUploadPath(imageURL)
+}
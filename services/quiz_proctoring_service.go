@@ -0,0 +1,218 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+// proctorEventWeights bobot tiap jenis kejadian untuk suspicion score
+var proctorEventWeights = map[models.ProctorEventType]float64{
+	models.ProctorTabBlur:        5,
+	models.ProctorFullscreenExit: 5,
+	models.ProctorCopy:           10,
+	models.ProctorPaste:          15,
+	models.ProctorReconnect:      2,
+}
+
+const (
+	// suspicionPerExtraClient bobot tiap IP / user agent tambahan selama satu attempt
+	suspicionPerExtraClient = 20
+	// suspicionPerAnswerAfterBlur bobot jawaban yang diubah sesaat setelah kembali dari tab lain
+	suspicionPerAnswerAfterBlur = 5
+	// answerAfterBlurWindow jeda maksimum antara tab_blur dan perubahan jawaban
+	answerAfterBlurWindow = 30 * time.Second
+)
+
+// SummarizeAttemptIntegrity hitung ringkasan & suspicion score (0-100) dari log sebuah attempt.
+// events & answerLogs diasumsikan sudah urut waktu.
+func SummarizeAttemptIntegrity(events []models.QuizProctorEvent, answerLogs []models.QuizAnswerLog) dto.AttemptIntegritySummary {
+	summary := dto.AttemptIntegritySummary{
+		EventCounts: make(map[models.ProctorEventType]int),
+	}
+
+	ips := make(map[string]struct{})
+	userAgents := make(map[string]struct{})
+	trackClient := func(ip, userAgent string) {
+		if ip != "" {
+			ips[ip] = struct{}{}
+		}
+		if userAgent != "" {
+			userAgents[userAgent] = struct{}{}
+		}
+	}
+
+	var score float64
+	var blurs []time.Time
+	for _, e := range events {
+		summary.EventCounts[e.Type]++
+		score += proctorEventWeights[e.Type]
+		trackClient(e.IPAddress, e.UserAgent)
+		if e.Type == models.ProctorTabBlur {
+			blurs = append(blurs, e.OccurredAt)
+		}
+	}
+
+	for _, l := range answerLogs {
+		trackClient(l.IPAddress, l.UserAgent)
+		if l.PreviousOptionID == nil {
+			continue
+		}
+		summary.AnswerChanges++
+
+		// cari tab_blur terakhir sebelum perubahan jawaban
+		idx := sort.Search(len(blurs), func(i int) bool { return blurs[i].After(l.CreatedAt) })
+		if idx > 0 && l.CreatedAt.Sub(blurs[idx-1]) <= answerAfterBlurWindow {
+			summary.AnswersAfterBlur++
+		}
+	}
+
+	summary.DistinctIPs = len(ips)
+	summary.DistinctUserAgents = len(userAgents)
+	if summary.DistinctIPs > 1 {
+		score += float64(summary.DistinctIPs-1) * suspicionPerExtraClient
+	}
+	if summary.DistinctUserAgents > 1 {
+		score += float64(summary.DistinctUserAgents-1) * suspicionPerExtraClient
+	}
+	score += float64(summary.AnswersAfterBlur) * suspicionPerAnswerAfterBlur
+
+	summary.SuspicionScore = math.Min(score, 100)
+	return summary
+}
+
+// ReportProctorEvent catat kejadian integritas yang dilaporkan client selama attempt
+func (s *QuizService) ReportProctorEvent(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.ReportProctorEventRequest, client dto.ClientInfo) error {
+	attempt, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
+	if err != nil {
+		return err
+	}
+
+	if attempt.UserID != user.UserID {
+		return fmt.Errorf("forbidden: not your attempt")
+	}
+	if attempt.EndedAt != nil {
+		return fmt.Errorf("quiz already submitted")
+	}
+
+	// waktu dari client hanya dipakai kalau masuk akal (antara mulai attempt dan sekarang)
+	now := time.Now()
+	occurredAt := now
+	if body.OccurredAt != nil && !body.OccurredAt.Before(attempt.StartedAt) && !body.OccurredAt.After(now) {
+		occurredAt = *body.OccurredAt
+	}
+
+	return s.proctoringRepo.CreateEvent(ctx, &models.QuizProctorEvent{
+		AttemptID:  attempt.ID,
+		Type:       body.Type,
+		Detail:     body.Detail,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		OccurredAt: occurredAt,
+	})
+}
+
+// GetAttemptTimeline timeline lengkap sebuah attempt (mulai, kejadian integritas, perubahan jawaban, submit)
+func (s *QuizService) GetAttemptTimeline(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) (*dto.AttemptTimelineResponse, error) {
+	attempt, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, attempt.Quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	attemptIDs := []uuid.UUID{attempt.ID}
+	events, err := s.proctoringRepo.GetEventsByAttemptIDs(ctx, attemptIDs)
+	if err != nil {
+		return nil, err
+	}
+	answerLogs, err := s.proctoringRepo.GetAnswerLogsByAttemptIDs(ctx, attemptIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]dto.AttemptTimelineEntry, 0, len(events)+len(answerLogs)+2)
+	entries = append(entries, dto.AttemptTimelineEntry{At: attempt.StartedAt, Kind: "started"})
+	for _, e := range events {
+		entries = append(entries, dto.AttemptTimelineEntry{
+			At:        e.OccurredAt,
+			Kind:      "event",
+			EventType: e.Type,
+			Detail:    e.Detail,
+			IPAddress: e.IPAddress,
+			UserAgent: e.UserAgent,
+		})
+	}
+	for _, l := range answerLogs {
+		questionID, selectedID := l.QuestionID, l.SelectedOptionID
+		entries = append(entries, dto.AttemptTimelineEntry{
+			At:               l.CreatedAt,
+			Kind:             "answer",
+			QuestionID:       &questionID,
+			SelectedOptionID: &selectedID,
+			PreviousOptionID: l.PreviousOptionID,
+			IPAddress:        l.IPAddress,
+			UserAgent:        l.UserAgent,
+		})
+	}
+	if attempt.EndedAt != nil {
+		entries = append(entries, dto.AttemptTimelineEntry{At: *attempt.EndedAt, Kind: "submitted"})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+
+	response := &dto.AttemptTimelineResponse{
+		Integrity: SummarizeAttemptIntegrity(events, answerLogs),
+		Entries:   entries,
+	}
+	if err := copier.Copy(&response.Attempt, attempt); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// attachIntegrity isi ringkasan integritas untuk daftar attempt (dipakai di list guru)
+func (s *QuizService) attachIntegrity(ctx context.Context, attempts []models.QuizAttempt) ([]dto.QuizAttemptSummary, error) {
+	attemptIDs := make([]uuid.UUID, 0, len(attempts))
+	for _, a := range attempts {
+		attemptIDs = append(attemptIDs, a.ID)
+	}
+
+	events, err := s.proctoringRepo.GetEventsByAttemptIDs(ctx, attemptIDs)
+	if err != nil {
+		return nil, err
+	}
+	answerLogs, err := s.proctoringRepo.GetAnswerLogsByAttemptIDs(ctx, attemptIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	eventsByAttempt := make(map[uuid.UUID][]models.QuizProctorEvent)
+	for _, e := range events {
+		eventsByAttempt[e.AttemptID] = append(eventsByAttempt[e.AttemptID], e)
+	}
+	logsByAttempt := make(map[uuid.UUID][]models.QuizAnswerLog)
+	for _, l := range answerLogs {
+		logsByAttempt[l.AttemptID] = append(logsByAttempt[l.AttemptID], l)
+	}
+
+	summaries := make([]dto.QuizAttemptSummary, 0, len(attempts))
+	for _, a := range attempts {
+		integrity := SummarizeAttemptIntegrity(eventsByAttempt[a.ID], logsByAttempt[a.ID])
+		summaries = append(summaries, dto.QuizAttemptSummary{Attempt: a, Integrity: &integrity})
+	}
+	return summaries, nil
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// regradeNotice data untuk email pemberitahuan perubahan skor
+type regradeNotice struct {
+	email     string
+	name      string
+	quizTitle string
+	oldScore  float64
+	newScore  float64
+}
+
+// GradeAnswer menilai satu jawaban sesuai kunci dan mode penilaian soal.
+// counted=false berarti soal di-void sehingga tidak masuk total soal.
+func GradeAnswer(question *models.QuizQuestion, selectedOptionID uuid.UUID) (score int, counted bool) {
+	switch question.ScoringMode {
+	case models.ScoringVoid:
+		return 0, false
+	case models.ScoringFreeCredit:
+		return 1, true
+	}
+
+	for _, opt := range question.Options {
+		if opt.ID == selectedOptionID && opt.IsCorrect {
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// ScorePercent hitung persentase skor, 0 kalau tidak ada soal yang dihitung
+func ScorePercent(correct, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(correct*100) / float64(total)
+}
+
+// RegradeAttempt menghitung ulang skor semua submission sebuah attempt.
+// Mengembalikan submission yang skornya berubah beserta total soal & jumlah benar yang baru.
+func RegradeAttempt(subs []models.QuizSubmission, questions map[uuid.UUID]*models.QuizQuestion) (changed []models.QuizSubmission, total, correct int) {
+	for _, sub := range subs {
+		question, ok := questions[sub.QuestionID]
+		if !ok {
+			// soal tidak dikenal, pertahankan skor lama
+			total++
+			correct += sub.Score
+			continue
+		}
+
+		score, counted := GradeAnswer(question, sub.SelectedOptionID)
+		if counted {
+			total++
+			correct += score
+		}
+		if score != sub.Score {
+			sub.Score = score
+			changed = append(changed, sub)
+		}
+	}
+	return changed, total, correct
+}
+
+// RegradeQuiz menghitung ulang submission & result setelah kunci jawaban dikoreksi,
+// soal di-void, atau diberi free credit. Siswa yang skornya berubah diberi tahu lewat email
+// begitu review quiz dibuka (lihat NotifyPendingRegrades) supaya nilai tidak bocor sebelum rilis.
+func (s *QuizService) RegradeQuiz(ctx context.Context, user *utils.Claims, quizID uuid.UUID, req *dto.RegradeQuizRequest) (*dto.QuizRegradeLogResponse, error) {
+	quiz, err := s.quizRepo.GetQuizWithQuestions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	questions := make(map[uuid.UUID]*models.QuizQuestion, len(quiz.Questions))
+	for i := range quiz.Questions {
+		questions[quiz.Questions[i].ID] = &quiz.Questions[i]
+	}
+
+	var regradeLog *models.QuizRegradeLog
+
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		quizRepo := s.quizRepo.WithTx(tx)
+		mode := models.ScoringNormal
+
+		// 1️⃣ Terapkan koreksi kunci / mode penilaian
+		if req.QuestionID == nil {
+			if len(req.CorrectOptionIDs) > 0 || req.ScoringMode != nil {
+				return fmt.Errorf("question_id wajib diisi untuk mengubah kunci atau mode penilaian")
+			}
+		} else {
+			question, ok := questions[*req.QuestionID]
+			if !ok {
+				return fmt.Errorf("soal tidak ditemukan di quiz ini")
+			}
+
+			if len(req.CorrectOptionIDs) > 0 {
+				correctSet := make(map[uuid.UUID]bool, len(req.CorrectOptionIDs))
+				for _, id := range req.CorrectOptionIDs {
+					correctSet[id] = true
+				}
+
+				found := 0
+				for _, opt := range question.Options {
+					if correctSet[opt.ID] {
+						found++
+					}
+				}
+				if found != len(correctSet) {
+					return fmt.Errorf("opsi jawaban tidak valid untuk soal ini")
+				}
+
+				if err := quizRepo.UpdateOptionsCorrectness(ctx, question.ID, req.CorrectOptionIDs); err != nil {
+					return err
+				}
+				for i := range question.Options {
+					question.Options[i].IsCorrect = correctSet[question.Options[i].ID]
+				}
+			}
+
+			if req.ScoringMode != nil {
+				if err := quizRepo.UpdateQuestionScoringMode(ctx, question.ID, *req.ScoringMode); err != nil {
+					return err
+				}
+				question.ScoringMode = *req.ScoringMode
+			}
+			mode = question.ScoringMode
+		}
+
+		// 2️⃣ Ambil semua attempt yang sudah selesai beserta result-nya
+		attempts, err := quizRepo.GetFinishedAttemptsByQuizID(ctx, quizID)
+		if err != nil {
+			return err
+		}
+		results, err := quizRepo.GetResultsByQuizID(ctx, quizID)
+		if err != nil {
+			return err
+		}
+		resultByAttempt := make(map[uuid.UUID]*models.QuizResult, len(results))
+		for i := range results {
+			resultByAttempt[results[i].AttemptID] = &results[i]
+		}
+
+		regradeLog = &models.QuizRegradeLog{
+			ID:         uuid.New(),
+			QuizID:     quizID,
+			QuestionID: req.QuestionID,
+			ActorID:    user.UserID,
+			Mode:       mode,
+			Reason:     req.Reason,
+		}
+
+		// 3️⃣ Hitung ulang tiap attempt yang terdampak. Soal yang tidak dijawab tidak masuk total
+		// (sama seperti saat submit), jadi attempt yang mengosongkan soal ini tidak berubah, termasuk free credit.
+		for _, attempt := range attempts {
+			if req.QuestionID != nil && !hasSubmissionForQuestion(attempt.Submissions, *req.QuestionID) {
+				continue
+			}
+			regradeLog.AffectedAttempts++
+
+			changed, total, correct := RegradeAttempt(attempt.Submissions, questions)
+			for _, sub := range changed {
+				if err := quizRepo.UpdateSubmissionScore(ctx, sub.ID, sub.Score); err != nil {
+					return err
+				}
+			}
+
+			result, exists := resultByAttempt[attempt.ID]
+			if exists && result.TotalQuestions == total && result.CorrectAnswers == correct {
+				continue
+			}
+
+			var oldScore float64
+			var oldCorrect int
+			if exists {
+				oldScore, oldCorrect = result.ScorePercent, result.CorrectAnswers
+			} else {
+				result = &models.QuizResult{ID: uuid.New(), AttemptID: attempt.ID}
+			}
+
+			result.TotalQuestions = total
+			result.CorrectAnswers = correct
+			result.WrongAnswers = total - correct
+			result.ScorePercent = ScorePercent(correct, total)
+
+			if exists {
+				err = quizRepo.UpdateQuizResult(ctx, result)
+			} else {
+				err = quizRepo.CreateQuizResult(ctx, result)
+			}
+			if err != nil {
+				return err
+			}
+
+			regradeLog.ChangedAttempts++
+			regradeLog.Items = append(regradeLog.Items, models.QuizRegradeChange{
+				ID:              uuid.New(),
+				RegradeLogID:    regradeLog.ID,
+				AttemptID:       attempt.ID,
+				UserID:          attempt.UserID,
+				OldScorePercent: oldScore,
+				NewScorePercent: result.ScorePercent,
+				OldCorrect:      oldCorrect,
+				NewCorrect:      correct,
+				PendingNotice:   attempt.User.Email != "" && oldScore != result.ScorePercent,
+			})
+		}
+
+		// 4️⃣ Simpan log regrade
+		return quizRepo.CreateRegradeLog(ctx, regradeLog)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// quiz yang review-nya sudah dibuka langsung dikirim, sisanya menunggu rilis
+	go s.sendPendingRegradeNotices()
+
+	response := mapRegradeLog(regradeLog)
+	return &response, nil
+}
+
+// GetRegradeLogs riwayat regrade sebuah quiz
+func (s *QuizService) GetRegradeLogs(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]dto.QuizRegradeLogResponse, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	logs, err := s.quizRepo.GetRegradeLogsByQuizID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.QuizRegradeLogResponse, 0, len(logs))
+	for i := range logs {
+		responses = append(responses, mapRegradeLog(&logs[i]))
+	}
+	return responses, nil
+}
+
+// NotifyPendingRegrades kirim email perubahan nilai yang tertunda untuk quiz yang review-nya sudah dibuka.
+// Perubahan ditandai terkirim sebelum email dikirim, jadi tiap perubahan paling banyak dikirim sekali.
+func (s *QuizService) NotifyPendingRegrades(ctx context.Context) (int, error) {
+	var changes []models.QuizRegradeChange
+	err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		quizRepo := s.quizRepo.WithTx(tx)
+
+		var err error
+		changes, err = quizRepo.GetReleasedPendingRegradeChanges(ctx, time.Now())
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(changes))
+		for _, c := range changes {
+			ids = append(ids, c.ID)
+		}
+		return quizRepo.ClearRegradeNotices(ctx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	notices := buildRegradeNotices(changes)
+	s.notifyRegrade(notices)
+	return len(notices), nil
+}
+
+// sendPendingRegradeNotices dipanggil di background setelah regrade / rilis review
+func (s *QuizService) sendPendingRegradeNotices() {
+	if _, err := s.NotifyPendingRegrades(context.Background()); err != nil {
+		log.Errorf("Failed to send pending regrade notifications: %v", err)
+	}
+}
+
+// buildRegradeNotices gabungkan perubahan per attempt (urut created_at): nilai sebelum regrade pertama
+// dibandingkan dengan nilai terbaru, attempt yang nilainya kembali sama tidak dikirimi email.
+func buildRegradeNotices(changes []models.QuizRegradeChange) []regradeNotice {
+	byAttempt := make(map[uuid.UUID]*regradeNotice)
+	var order []uuid.UUID
+	for _, c := range changes {
+		n, ok := byAttempt[c.AttemptID]
+		if !ok {
+			n = &regradeNotice{
+				email:     c.User.Email,
+				name:      c.User.Name,
+				quizTitle: c.RegradeLog.Quiz.Title,
+				oldScore:  c.OldScorePercent,
+			}
+			byAttempt[c.AttemptID] = n
+			order = append(order, c.AttemptID)
+		}
+		n.newScore = c.NewScorePercent
+	}
+
+	notices := make([]regradeNotice, 0, len(order))
+	for _, id := range order {
+		if n := byAttempt[id]; n.email != "" && n.oldScore != n.newScore {
+			notices = append(notices, *n)
+		}
+	}
+	return notices
+}
+
+// notifyRegrade kirim email ke siswa yang skornya berubah
+func (s *QuizService) notifyRegrade(notices []regradeNotice) {
+	if s.emailService == nil {
+		return
+	}
+
+	for _, n := range notices {
+		body := fmt.Sprintf(`
+			<div style="font-family:Arial,sans-serif;max-width:600px;margin:auto;padding:20px;">
+				<p>Halo %s,</p>
+				<p>Kunci jawaban quiz <b>%s</b> telah dikoreksi oleh pengajar dan nilai Anda dihitung ulang.</p>
+				<p>Nilai sebelumnya: <b>%.2f</b><br/>Nilai baru: <b>%.2f</b></p>
+				<hr style="margin:40px 0;border:none;border-top:1px solid #ccc;" />
+				<footer style="text-align:center;color:#888;font-size:12px;">
+					Tax Center Gunadarma<br/>
+					© %d All rights reserved.
+				</footer>
+			</div>
+		`, html.EscapeString(n.name), html.EscapeString(n.quizTitle), n.oldScore, n.newScore, time.Now().Year())
+
+		if err := s.emailService.SendEmail(n.email, "Perubahan Nilai Quiz", body); err != nil {
+			log.Errorf("Failed to send regrade notification to %s: %v", n.email, err)
+		}
+	}
+}
+
+func hasSubmissionForQuestion(subs []models.QuizSubmission, questionID uuid.UUID) bool {
+	for _, sub := range subs {
+		if sub.QuestionID == questionID {
+			return true
+		}
+	}
+	return false
+}
+
+func mapRegradeLog(l *models.QuizRegradeLog) dto.QuizRegradeLogResponse {
+	response := dto.QuizRegradeLogResponse{
+		ID:               l.ID,
+		QuizID:           l.QuizID,
+		QuestionID:       l.QuestionID,
+		ActorID:          l.ActorID,
+		ActorName:        l.Actor.Name,
+		Mode:             l.Mode,
+		Reason:           l.Reason,
+		AffectedAttempts: l.AffectedAttempts,
+		ChangedAttempts:  l.ChangedAttempts,
+		Items:            make([]dto.QuizRegradeChangeResponse, 0, len(l.Items)),
+		CreatedAt:        l.CreatedAt,
+	}
+	for _, item := range l.Items {
+		response.Items = append(response.Items, dto.QuizRegradeChangeResponse{
+			AttemptID:       item.AttemptID,
+			UserID:          item.UserID,
+			UserName:        item.User.Name,
+			OldScorePercent: item.OldScorePercent,
+			NewScorePercent: item.NewScorePercent,
+			OldCorrect:      item.OldCorrect,
+			NewCorrect:      item.NewCorrect,
+		})
+	}
+	return response
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewReleaseTime kapan review sebuah attempt dibuka untuk siswa.
+// latestEnd adalah EndTime quiz paling akhir termasuk jendela accommodation.
+// nil berarti belum bisa dibuka (attempt belum selesai / menunggu rilis manual).
+func ReviewReleaseTime(quiz *models.Quiz, attempt *models.QuizAttempt, latestEnd time.Time) *time.Time {
+	if attempt.EndedAt == nil {
+		return nil
+	}
+
+	var releaseAt time.Time
+	switch {
+	case quiz.Mode == models.QuizModePractice:
+		// latihan selalu langsung dibahas setelah attempt selesai
+		releaseAt = *attempt.EndedAt
+	case quiz.ReviewRelease == models.ReleaseManual:
+		if quiz.ReviewReleasedAt == nil {
+			return nil
+		}
+		releaseAt = *quiz.ReviewReleasedAt
+	case quiz.ReviewRelease == models.ReleaseAfterEnd:
+		releaseAt = latestEnd
+	default:
+		releaseAt = *attempt.EndedAt
+	}
+
+	// review tidak pernah dibuka sebelum attempt itu sendiri selesai
+	if releaseAt.Before(*attempt.EndedAt) {
+		releaseAt = *attempt.EndedAt
+	}
+	return &releaseAt
+}
+
+// IsReviewReleased hasil attempt sudah boleh dilihat siswa pada waktu now
+func IsReviewReleased(quiz *models.Quiz, attempt *models.QuizAttempt, latestEnd, now time.Time) bool {
+	releaseAt := ReviewReleaseTime(quiz, attempt, latestEnd)
+	return releaseAt != nil && !now.Before(*releaseAt)
+}
+
+// BuildAttemptReview susun review per soal sesuai level yang diizinkan
+func BuildAttemptReview(level models.QuizReviewLevel, questions []models.QuizQuestion, subs []models.QuizSubmission) []dto.QuestionReview {
+	if level != models.ReviewWrongAnswers && level != models.ReviewFullSolution {
+		return nil
+	}
+
+	subByQuestion := make(map[uuid.UUID]models.QuizSubmission, len(subs))
+	for _, sub := range subs {
+		subByQuestion[sub.QuestionID] = sub
+	}
+
+	reviews := make([]dto.QuestionReview, 0, len(questions))
+	for i := range questions {
+		q := &questions[i]
+		review := dto.QuestionReview{
+			QuestionID:  q.ID,
+			Question:    q.Question,
+			ImageURL:    q.ImageURL,
+			ScoringMode: q.ScoringMode,
+			Options:     make([]dto.QuizOptionForUserResponse, 0, len(q.Options)),
+		}
+
+		if sub, ok := subByQuestion[q.ID]; ok {
+			selected := sub.SelectedOptionID
+			review.SelectedOptionID = &selected
+			review.IsCorrect = sub.Score > 0
+		} else {
+			score, _ := GradeAnswer(q, uuid.Nil)
+			review.IsCorrect = score > 0
+		}
+
+		for _, opt := range q.Options {
+			review.Options = append(review.Options, dto.QuizOptionForUserResponse{
+				ID:         opt.ID,
+				QuestionID: opt.QuestionID,
+				OptionText: opt.OptionText,
+				ImageURL:   opt.ImageURL,
+				CreatedAt:  opt.CreatedAt,
+				UpdatedAt:  opt.UpdatedAt,
+			})
+			if level == models.ReviewFullSolution && opt.IsCorrect {
+				review.CorrectOptionIDs = append(review.CorrectOptionIDs, opt.ID)
+			}
+		}
+
+		if level == models.ReviewFullSolution {
+			review.Explanation = q.Explanation
+		}
+
+		reviews = append(reviews, review)
+	}
+	return reviews
+}
+
+// latestQuizEnd EndTime paling akhir untuk quiz, termasuk jendela accommodation siswa
+func (s *QuizService) latestQuizEnd(ctx context.Context, quiz *models.Quiz) (time.Time, error) {
+	latest := quiz.EndTime
+
+	accs, err := s.accommodationRepo.ListQuizAccommodations(ctx, quiz.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, acc := range accs {
+		if acc.EndTime != nil && acc.EndTime.After(latest) {
+			latest = *acc.EndTime
+		}
+	}
+	return latest, nil
+}
+
+// buildReview review attempt untuk user; guru/admin & quiz latihan selalu pembahasan lengkap
+func (s *QuizService) buildReview(ctx context.Context, user *utils.Claims, attempt *models.QuizAttempt) (*dto.QuizAttemptReview, error) {
+	quiz, err := s.quizRepo.GetQuizWithQuestions(ctx, attempt.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &dto.QuizAttemptReview{Level: quiz.ReviewLevel}
+	if user.Role != string(models.RoleTypeSiswa) || quiz.Mode == models.QuizModePractice {
+		review.Level = models.ReviewFullSolution
+		review.Released = attempt.EndedAt != nil
+		review.ReleaseAt = attempt.EndedAt
+	} else {
+		latestEnd, err := s.latestQuizEnd(ctx, quiz)
+		if err != nil {
+			return nil, err
+		}
+		review.ReleaseAt = ReviewReleaseTime(quiz, attempt, latestEnd)
+		review.Released = IsReviewReleased(quiz, attempt, latestEnd, time.Now())
+	}
+
+	if !review.Released {
+		return review, nil
+	}
+
+	subs, err := s.quizRepo.GetSubmissionsByAttemptID(ctx, attempt.ID)
+	if err != nil {
+		return nil, err
+	}
+	review.Questions = BuildAttemptReview(review.Level, quiz.Questions, subs)
+	return review, nil
+}
+
+// SetReviewReleased buka/tutup review quiz secara manual
+func (s *QuizService) SetReviewReleased(ctx context.Context, user *utils.Claims, quizID uuid.UUID, released bool) (*models.Quiz, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.checkUserAccess(ctx, user, quiz.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this meeting")
+	}
+
+	var releasedAt *time.Time
+	if released {
+		now := time.Now()
+		releasedAt = &now
+	}
+
+	if err := s.quizRepo.UpdateReviewReleasedAt(ctx, quizID, releasedAt); err != nil {
+		return nil, err
+	}
+	quiz.ReviewReleasedAt = releasedAt
+
+	// perubahan nilai dari regrade sebelum rilis baru boleh dikirim sekarang
+	if released {
+		go s.sendPendingRegradeNotices()
+	}
+	return quiz, nil
+}
@@ -12,12 +12,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gofiber/fiber/v2/log"
 	"github.com/google/uuid"
 	"github.com/jinzhu/copier"
 	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
+// ErrAttemptNotFound dikembalikan kalau attempt yang diproses sudah tidak ada
+var ErrAttemptNotFound = repository.ErrQuizAttemptNotFound
+
 // IQuizService interface
 type IQuizService interface {
 	GetQuizByMeetingIDFiltered(ctx context.Context, meetingID uuid.UUID, opts utils.QueryOptions, user *utils.Claims) ([]models.Quiz, int64, error)
@@ -25,7 +29,7 @@ type IQuizService interface {
 	ImportQuestionsFromExcel(ctx context.Context, user *utils.Claims, quizID uuid.UUID, fileHeader *multipart.FileHeader) error
 	AutoSubmitQuiz(ctx context.Context, attemptID uuid.UUID) error
 	CreateQuizMetadata(ctx context.Context, user *utils.Claims, meetingID uuid.UUID, req *dto.ImportQuizzesRequest) (*models.Quiz, error)
-	SaveTempSubmission(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.SaveTempSubmissionRequest) error
+	SaveTempSubmission(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.SaveTempSubmissionRequest, client dto.ClientInfo) error
 	StartQuiz(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*models.QuizAttempt, error)
 	SubmitQuiz(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) error
 	GetQuizMetadata(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*models.Quiz, error)
@@ -34,21 +38,45 @@ type IQuizService interface {
 	GetAttemptDetail(ctx context.Context, attemptID uuid.UUID, user *utils.Claims) (*dto.QuizAttemptFull, error)
 	UpdateQuiz(ctx context.Context, quizID uuid.UUID, user *utils.Claims, body *dto.UpdateQuizRequest) (*models.Quiz, error)
 	DeleteQuiz(ctx context.Context, quizID uuid.UUID, user *utils.Claims) error
-	GetAttemptResult(ctx context.Context, attemptID uuid.UUID, user *utils.Claims) (*models.QuizResult, error)
-	GetListAttempt(ctx context.Context, quizID uuid.UUID, user *utils.Claims) ([]models.QuizAttempt, error)
+	GetAttemptResult(ctx context.Context, attemptID uuid.UUID, user *utils.Claims) (*dto.QuizAttemptResult, error)
+	GetListAttempt(ctx context.Context, quizID uuid.UUID, user *utils.Claims) ([]dto.QuizAttemptSummary, error)
+	GetQuizItemAnalysis(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*dto.QuizItemAnalysisResponse, error)
+	GenerateItemAnalysisExcel(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*excelize.File, string, error)
+	HandleAttemptDeadline(ctx context.Context, attemptID uuid.UUID) error
+	ReconcileAttemptDeadlines(ctx context.Context) (int, error)
+	RegradeQuiz(ctx context.Context, user *utils.Claims, quizID uuid.UUID, req *dto.RegradeQuizRequest) (*dto.QuizRegradeLogResponse, error)
+	NotifyPendingRegrades(ctx context.Context) (int, error)
+	GetRegradeLogs(ctx context.Context, user *utils.Claims, quizID uuid.UUID) ([]dto.QuizRegradeLogResponse, error)
+	SetReviewReleased(ctx context.Context, user *utils.Claims, quizID uuid.UUID, released bool) (*models.Quiz, error)
+	UpdateQuestionContent(ctx context.Context, user *utils.Claims, quizID, questionID uuid.UUID, body *dto.UpdateQuestionRequest) (*models.QuizQuestion, error)
+	ReportProctorEvent(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.ReportProctorEventRequest, client dto.ClientInfo) error
+	GetAttemptTimeline(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) (*dto.AttemptTimelineResponse, error)
+	OpenLiveAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) (*dto.QuizLiveSession, error)
+	GetLiveMonitor(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*dto.QuizLiveMonitorResponse, error)
+	ExtendAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error)
+	PauseAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error)
+	ResumeAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error)
+	ReopenAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error)
+	VoidAttempt(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.AttemptActionRequest) (*models.QuizAttempt, error)
+	GetAttemptActions(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) ([]dto.QuizAttemptActionResponse, error)
 }
 
 // QuizService provides methods for managing quizzes
 type QuizService struct {
-	quizRepo        repository.IQuizRepository
-	batchRepo       repository.IBatchRepository
-	meetingRepo     repository.IMeetingRepository
-	attendanceRepo  repository.IAttendanceRepository
-	assignmentRepo  repository.IAssignmentRepository
-	submissionRepo  repository.ISubmisssionRepository
-	purchaseService IPurchaseService
-	fileService     IFileService
-	db              *gorm.DB
+	quizRepo          repository.IQuizRepository
+	batchRepo         repository.IBatchRepository
+	meetingRepo       repository.IMeetingRepository
+	attendanceRepo    repository.IAttendanceRepository
+	assignmentRepo    repository.IAssignmentRepository
+	submissionRepo    repository.ISubmisssionRepository
+	accommodationRepo repository.IAccommodationRepository
+	proctoringRepo    repository.IProctoringRepository
+	purchaseService   IPurchaseService
+	fileService       IFileService
+	emailService      IEmailService
+	deadlineQueue     IQuizDeadlineQueue
+	liveHub           IQuizLiveHub
+	db                *gorm.DB
 }
 
 // NewQuizService creates a new instance of QuizService
@@ -56,10 +84,61 @@ func NewQuizService(quizRepo repository.IQuizRepository, batchRepo repository.IB
 	attendanceRepo repository.IAttendanceRepository,
 	assignmentRepo repository.IAssignmentRepository,
 	submissionRepo repository.ISubmisssionRepository,
-	purchaseService IPurchaseService, fileService IFileService, db *gorm.DB) IQuizService {
+	accommodationRepo repository.IAccommodationRepository,
+	proctoringRepo repository.IProctoringRepository,
+	purchaseService IPurchaseService, fileService IFileService, emailService IEmailService, db *gorm.DB) IQuizService {
 	return &QuizService{quizRepo: quizRepo, batchRepo: batchRepo, meetingRepo: meetingRepo,
 		attendanceRepo: attendanceRepo, assignmentRepo: assignmentRepo, submissionRepo: submissionRepo,
-		purchaseService: purchaseService, fileService: fileService, db: db}
+		accommodationRepo: accommodationRepo, proctoringRepo: proctoringRepo,
+		purchaseService: purchaseService, fileService: fileService, emailService: emailService, deadlineQueue: NewQuizDeadlineQueue(), liveHub: DefaultQuizLiveHub(), db: db}
+}
+
+// ComputeAttemptDeadline hitung kapan sebuah attempt harus berakhir.
+// acc boleh nil; kalau ada, tambahan menit & jendela alternatif ikut diperhitungkan.
+// Perpanjangan dari guru & waktu jeda attempt menggeser durasi sekaligus batas jendela.
+func ComputeAttemptDeadline(attempt *models.QuizAttempt, quiz *models.Quiz, acc *models.QuizAccommodation) time.Time {
+	duration := quiz.DurationMinute
+	windowEnd := quiz.EndTime
+	if acc != nil {
+		duration += acc.ExtraMinutes
+		if acc.EndTime != nil {
+			windowEnd = *acc.EndTime
+		}
+	}
+
+	shift := time.Duration(attempt.ExtraMinutes)*time.Minute + time.Duration(attempt.PausedSeconds)*time.Second
+	endTime := attempt.StartedAt.Add(time.Duration(duration)*time.Minute + shift)
+	if !windowEnd.IsZero() && windowEnd.Add(shift).Before(endTime) {
+		endTime = windowEnd.Add(shift)
+	}
+	return endTime
+}
+
+// QuizWindowFor jendela waktu quiz yang berlaku untuk user (memperhitungkan accommodation)
+func QuizWindowFor(quiz *models.Quiz, acc *models.QuizAccommodation) (time.Time, time.Time) {
+	start, end := quiz.StartTime, quiz.EndTime
+	if acc != nil {
+		if acc.StartTime != nil {
+			start = *acc.StartTime
+		}
+		if acc.EndTime != nil {
+			end = *acc.EndTime
+		}
+	}
+	return start, end
+}
+
+// ApplyQuizModeDefaults paksa aturan mode quiz: latihan tanpa batas attempt & pembahasan langsung terbuka
+func ApplyQuizModeDefaults(quiz *models.Quiz) {
+	if quiz.Mode == "" {
+		quiz.Mode = models.QuizModeGraded
+	}
+	if quiz.Mode == models.QuizModePractice {
+		quiz.MaxAttempts = 0
+		quiz.AttemptPenaltyPercent = 0
+		quiz.ReviewLevel = models.ReviewFullSolution
+		quiz.ReviewRelease = models.ReleaseImmediate
+	}
 }
 
 func (s *QuizService) checkUserAccess(ctx context.Context, user *utils.Claims, meetingID uuid.UUID) (bool, error) {
@@ -99,6 +178,7 @@ func (s *QuizService) GetAllUpcomingQuizzes(ctx context.Context, user *utils.Cla
 // StartQuiz start quiz
 func (s *QuizService) StartQuiz(ctx context.Context, user *utils.Claims, quizID uuid.UUID) (*models.QuizAttempt, error) {
 	var attempt *models.QuizAttempt
+	var deadline time.Time
 
 	err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
 		// --- 1. Ambil quiz ---
@@ -116,26 +196,61 @@ func (s *QuizService) StartQuiz(ctx context.Context, user *utils.Claims, quizID
 			return fmt.Errorf("forbidden")
 		}
 
-		// --- 3. Cek jadwal quiz ---
+		// --- 3. Cek jadwal quiz (termasuk accommodation siswa) ---
+		acc, err := s.accommodationRepo.WithTx(tx).GetQuizAccommodation(ctx, quizID, user.UserID)
+		if err != nil {
+			return err
+		}
+
 		now := time.Now()
-		if !quiz.IsOpen || now.Before(quiz.StartTime) || (!quiz.EndTime.IsZero() && now.After(quiz.EndTime)) {
+		startTime, endTime := QuizWindowFor(quiz, acc)
+		if !quiz.IsOpen || now.Before(startTime) || (!endTime.IsZero() && now.After(endTime)) {
 			return fmt.Errorf("quiz tidak bisa diikuti saat ini")
 		}
 
-		// --- 4. Validasi meeting rules (mirip submission) ---
-		if err := s.validateMeetingRulesForQuiz(ctx, tx, quiz.MeetingID, user.UserID); err != nil {
-			return err
+		// --- 4. Validasi meeting rules (mirip submission), latihan & survei bebas prasyarat ---
+		if quiz.Mode.CountsTowardGrade() {
+			if err := s.validateMeetingRulesForQuiz(ctx, tx, quiz.MeetingID, user.UserID); err != nil {
+				return err
+			}
 		}
 
 		// --- 5. Cek jumlah attempt ---
-		attempts, err := s.quizRepo.WithTx(tx).GetAttemptsByQuizAndUser(ctx, quizID, user.UserID)
+		allAttempts, err := s.quizRepo.WithTx(tx).GetAttemptsByQuizAndUser(ctx, quizID, user.UserID)
 		if err != nil {
 			return err
 		}
-		if quiz.MaxAttempts > 0 && len(attempts) >= quiz.MaxAttempts {
+		// attempt yang dibatalkan guru tidak dihitung
+		attempts := make([]models.QuizAttempt, 0, len(allAttempts))
+		for _, a := range allAttempts {
+			if a.VoidedAt == nil {
+				attempts = append(attempts, a)
+			}
+		}
+		maxAttempts := quiz.MaxAttempts
+		if acc != nil && maxAttempts > 0 {
+			maxAttempts += acc.ExtraAttempts
+		}
+		if maxAttempts > 0 && len(attempts) >= maxAttempts {
 			return fmt.Errorf("maximum attempts reached")
 		}
 
+		// --- 5b. Cek cooldown antar attempt ---
+		if quiz.CooldownMinutes > 0 {
+			var lastEnded *time.Time
+			for _, a := range attempts {
+				if a.EndedAt != nil && (lastEnded == nil || a.EndedAt.After(*lastEnded)) {
+					lastEnded = a.EndedAt
+				}
+			}
+			if lastEnded != nil {
+				availableAt := lastEnded.Add(time.Duration(quiz.CooldownMinutes) * time.Minute)
+				if now.Before(availableAt) {
+					return fmt.Errorf("quiz bisa dicoba lagi setelah %s", availableAt.Format("15:04"))
+				}
+			}
+		}
+
 		// --- 6. Cek apakah ada attempt aktif ---
 		activeAttempt, err := s.quizRepo.WithTx(tx).GetActiveAttemptByQuizAndUser(ctx, quizID, user.UserID)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -156,6 +271,7 @@ func (s *QuizService) StartQuiz(ctx context.Context, user *utils.Claims, quizID
 		if err := s.quizRepo.WithTx(tx).CreateQuizAttempt(ctx, attempt); err != nil {
 			return err
 		}
+		deadline = ComputeAttemptDeadline(attempt, quiz, acc)
 
 		return nil
 	})
@@ -164,6 +280,14 @@ func (s *QuizService) StartQuiz(ctx context.Context, user *utils.Claims, quizID
 		return nil, err
 	}
 
+	// --- Daftarkan deadline ke antrian auto-submit ---
+	if err := s.deadlineQueue.Schedule(ctx, attempt.ID, deadline); err != nil {
+		log.Errorf("Failed to schedule auto-submit for attempt %s: %v", attempt.ID, err)
+	}
+	s.liveHub.Publish(ctx, dto.QuizLiveEvent{
+		Type: LiveEventStarted, QuizID: attempt.QuizID, AttemptID: attempt.ID, UserID: attempt.UserID, Deadline: &deadline,
+	})
+
 	// --- Ambil attempt lengkap ---
 	attempt, err = s.quizRepo.GetAttemptByID(ctx, attempt.ID)
 	if err != nil {
@@ -210,6 +334,9 @@ func (s *QuizService) validateMeetingRulesForQuiz(ctx context.Context, tx *gorm.
 		return fmt.Errorf("gagal mengambil quiz meeting sebelumnya")
 	}
 	for _, q := range prevQuizzes {
+		if !q.Mode.CountsTowardGrade() {
+			continue
+		}
 		subs, err := s.quizRepo.WithTx(tx).GetQuizSubmissionByQuizAndUser(ctx, q.ID, userID)
 		if errors.Is(err, gorm.ErrRecordNotFound) || len(subs) == 0 {
 			return fmt.Errorf("anda belum mengerjakan quiz '%s' di meeting sebelumnya", q.Title)
@@ -263,45 +390,126 @@ func (s *QuizService) ImportQuestionsFromExcel(
 		return fmt.Errorf("excel kosong / tidak ada soal")
 	}
 
+	// kolom pembahasan opsional: header kolom terakhir "Pembahasan" / "Explanation"
+	explanationIdx := -1
+	if header := rows[0]; len(header) > 0 {
+		last := strings.ToLower(strings.TrimSpace(header[len(header)-1]))
+		if last == "pembahasan" || last == "explanation" {
+			explanationIdx = len(header) - 1
+		}
+	}
+
+	// gambar yang ditempel di sel soal / opsi ikut diimpor
+	pictureCells, err := f.GetPictureCells(sheetName)
+	if err != nil {
+		return err
+	}
+	hasPicture := make(map[string]bool, len(pictureCells))
+	for _, cell := range pictureCells {
+		hasPicture[cell] = true
+	}
+
+	var savedImages []string
+	importImage := func(col, row int) (*string, error) {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil || !hasPicture[cell] {
+			return nil, err
+		}
+		return s.saveImportedImage(f, sheetName, cell, &savedImages)
+	}
+
 	// transaksi DB
-	return utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
 		for i := 1; i < len(rows); i++ {
-			if len(rows[i]) < 3 {
+			row := rows[i]
+			excelRow := i + 1
+
+			var explanation *string
+			if explanationIdx >= 0 && len(row) > explanationIdx {
+				text, err := SanitizeQuestionContent(row[explanationIdx])
+				if err != nil {
+					return fmt.Errorf("row %d: %w", excelRow, err)
+				}
+				if text != "" {
+					explanation = &text
+				}
+				row = row[:explanationIdx]
+			}
+
+			// survei tidak punya kolom kunci jawaban
+			minCols := 3
+			if quiz.Mode == models.QuizModeSurvey {
+				minCols = 2
+			}
+			if len(row) < minCols {
 				continue
 			}
 
-			questionText := rows[i][0]
-			correctLetter := strings.ToUpper(rows[i][len(rows[i])-1])
-			optionCols := rows[i][1 : len(rows[i])-1]
+			questionText, err := SanitizeQuestionContent(row[0])
+			if err != nil {
+				return fmt.Errorf("row %d: %w", excelRow, err)
+			}
+			correctLetter := ""
+			optionCols := row[1:]
+			if quiz.Mode != models.QuizModeSurvey {
+				correctLetter = strings.ToUpper(strings.TrimSpace(row[len(row)-1]))
+				optionCols = row[1 : len(row)-1]
+			}
+
+			questionImage, err := importImage(1, excelRow)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", excelRow, err)
+			}
 
 			// simpan ke tabel quiz_questions
 			q := models.QuizQuestion{
-				ID:       uuid.New(),
-				QuizID:   quiz.ID,
-				Question: questionText,
+				ID:          uuid.New(),
+				QuizID:      quiz.ID,
+				Question:    questionText,
+				ImageURL:    questionImage,
+				Explanation: explanation,
 			}
 			if err := s.quizRepo.WithTx(tx).CreateQuestion(ctx, &q); err != nil {
-				return fmt.Errorf("row %d: %w", i+1, err)
+				return fmt.Errorf("row %d: %w", excelRow, err)
 			}
 
 			// buat opsi
 			var options []models.QuizOption
 			for idx, optText := range optionCols {
 				letter := string(rune('A' + idx))
+				text, err := SanitizeQuestionContent(optText)
+				if err != nil {
+					return fmt.Errorf("row %d option %s: %w", excelRow, letter, err)
+				}
+				optionImage, err := importImage(idx+2, excelRow)
+				if err != nil {
+					return fmt.Errorf("row %d option %s: %w", excelRow, letter, err)
+				}
 				options = append(options, models.QuizOption{
 					ID:         uuid.New(),
 					QuestionID: q.ID,
-					OptionText: optText,
+					OptionText: text,
+					ImageURL:   optionImage,
 					IsCorrect:  (letter == correctLetter),
 				})
 			}
 
 			if err := s.quizRepo.WithTx(tx).CreateOptions(ctx, options); err != nil {
-				return fmt.Errorf("row %d: %w", i+1, err)
+				return fmt.Errorf("row %d: %w", excelRow, err)
 			}
 		}
 		return nil
 	})
+
+	// gambar yang sudah tersimpan dibuang lagi kalau import gagal
+	if err != nil {
+		for _, path := range savedImages {
+			if delErr := s.fileService.DeleteFile(path); delErr != nil {
+				log.Errorf("Gagal hapus file %s: %v", path, delErr)
+			}
+		}
+	}
+	return err
 }
 
 // CreateQuizMetadata for create
@@ -327,12 +535,30 @@ func (s *QuizService) CreateQuizMetadata(
 		Title:          req.Title,
 		Description:    req.Description,
 		Type:           req.Type,
+		Mode:           req.Mode,
 		IsOpen:         req.IsOpen,
 		MaxAttempts:    req.MaxAttempts,
 		DurationMinute: req.DurationMinute,
 		StartTime:      req.StartTime,
 		EndTime:        req.EndTime,
+
+		ScoringPolicy:         req.ScoringPolicy,
+		CooldownMinutes:       req.CooldownMinutes,
+		AttemptPenaltyPercent: req.AttemptPenaltyPercent,
+
+		ReviewLevel:   req.ReviewLevel,
+		ReviewRelease: req.ReviewRelease,
 	}
+	if quiz.ScoringPolicy == "" {
+		quiz.ScoringPolicy = models.ScoringPolicyHighest
+	}
+	if quiz.ReviewLevel == "" {
+		quiz.ReviewLevel = models.ReviewScoreOnly
+	}
+	if quiz.ReviewRelease == "" {
+		quiz.ReviewRelease = models.ReleaseImmediate
+	}
+	ApplyQuizModeDefaults(quiz)
 
 	if err := s.quizRepo.Create(ctx, quiz); err != nil {
 		return nil, err
@@ -341,19 +567,21 @@ func (s *QuizService) CreateQuizMetadata(
 	return quiz, nil
 }
 
-// AutoSubmitQuiz submit quiz without checking, for scheduler purpose
+// AutoSubmitQuiz submit quiz without checking, for scheduler purpose.
+// Idempotent: attempt yang sudah selesai dilewati tanpa error.
 func (s *QuizService) AutoSubmitQuiz(ctx context.Context, attemptID uuid.UUID) error {
-	return utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+	var submitted *models.QuizAttempt
+	err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
 
-		// 1️⃣ Ambil attempt berdasarkan attemptID
-		attempt, err := s.quizRepo.WithTx(tx).GetQuizAttemptByID(ctx, attemptID)
+		// 1️⃣ Ambil attempt berdasarkan attemptID (row lock supaya tidak balapan dengan submit manual)
+		attempt, err := s.quizRepo.WithTx(tx).GetQuizAttemptByIDForUpdate(ctx, attemptID)
 		if err != nil {
 			return err
 		}
 
-		// 3️⃣ Pastikan attempt belum selesai
-		if attempt.EndedAt != nil {
-			return fmt.Errorf("quiz already submitted")
+		// 3️⃣ Attempt yang sudah selesai / sedang dijeda tidak perlu diproses
+		if attempt.EndedAt != nil || attempt.PausedAt != nil {
+			return nil
 		}
 
 		// 4️⃣ Ambil semua temp submission milik attempt ini
@@ -363,18 +591,15 @@ func (s *QuizService) AutoSubmitQuiz(ctx context.Context, attemptID uuid.UUID) e
 		}
 
 		correctAnswers := 0
-		totalQuestions := len(temps)
+		totalQuestions := 0
 
 		// 5️⃣ Kalau ada jawaban sementara, proses simpan
-		if totalQuestions > 0 {
+		if len(temps) > 0 {
 			for _, temp := range temps {
-				score := 0
-				for _, opt := range temp.Question.Options {
-					if opt.ID == temp.SelectedOptionID && opt.IsCorrect {
-						score = 1
-						correctAnswers++
-						break
-					}
+				score, counted := GradeAnswer(&temp.Question, temp.SelectedOptionID)
+				if counted {
+					totalQuestions++
+					correctAnswers += score
 				}
 
 				sub := &models.QuizSubmission{
@@ -392,10 +617,7 @@ func (s *QuizService) AutoSubmitQuiz(ctx context.Context, attemptID uuid.UUID) e
 
 		// 6️⃣ Hitung skor & simpan result
 		wrongAnswers := totalQuestions - correctAnswers
-		scorePercentage := 0.0
-		if totalQuestions > 0 {
-			scorePercentage = float64(correctAnswers*100) / float64(totalQuestions)
-		}
+		scorePercentage := ScorePercent(correctAnswers, totalQuestions)
 
 		quizResult := &models.QuizResult{
 			ID:             uuid.New(),
@@ -417,16 +639,29 @@ func (s *QuizService) AutoSubmitQuiz(ctx context.Context, attemptID uuid.UUID) e
 			return err
 		}
 
+		submitted = attempt
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Beritahu client yang masih terhubung bahwa attempt ditutup paksa
+	if submitted != nil {
+		s.liveHub.Publish(ctx, dto.QuizLiveEvent{
+			Type: LiveEventSubmitted, QuizID: submitted.QuizID, AttemptID: submitted.ID, UserID: submitted.UserID, Reason: "auto",
+		})
+	}
+	return nil
 }
 
 // SubmitQuiz submit quiz
 func (s *QuizService) SubmitQuiz(ctx context.Context, user *utils.Claims, attemptID uuid.UUID) error {
-	return utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+	var quizID uuid.UUID
+	err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
 
-		// 1️⃣ Ambil attempt berdasarkan attemptID
-		attempt, err := s.quizRepo.WithTx(tx).GetQuizAttemptByID(ctx, attemptID)
+		// 1️⃣ Ambil attempt berdasarkan attemptID (row lock supaya tidak balapan dengan auto-submit)
+		attempt, err := s.quizRepo.WithTx(tx).GetQuizAttemptByIDForUpdate(ctx, attemptID)
 		if err != nil {
 			return err
 		}
@@ -436,10 +671,14 @@ func (s *QuizService) SubmitQuiz(ctx context.Context, user *utils.Claims, attemp
 			return fmt.Errorf("forbidden: not your attempt")
 		}
 
-		// 3️⃣ Pastikan attempt belum selesai
+		// 3️⃣ Pastikan attempt belum selesai & tidak sedang dijeda
 		if attempt.EndedAt != nil {
 			return fmt.Errorf("quiz already submitted")
 		}
+		if attempt.PausedAt != nil {
+			return fmt.Errorf("quiz attempt sedang dijeda")
+		}
+		quizID = attempt.QuizID
 
 		quiz, err := s.quizRepo.WithTx(tx).GetQuizByID(ctx, attempt.QuizID)
 		if err != nil {
@@ -466,14 +705,12 @@ func (s *QuizService) SubmitQuiz(ctx context.Context, user *utils.Claims, attemp
 
 		// 5️⃣ Simpan final submission & hitung total benar
 		correctAnswers := 0
+		totalQuestions := 0
 		for _, temp := range temps {
-			score := 0
-			for _, opt := range temp.Question.Options {
-				if opt.ID == temp.SelectedOptionID && opt.IsCorrect {
-					score = 1
-					correctAnswers++
-					break
-				}
+			score, counted := GradeAnswer(&temp.Question, temp.SelectedOptionID)
+			if counted {
+				totalQuestions++
+				correctAnswers += score
 			}
 
 			sub := &models.QuizSubmission{
@@ -488,9 +725,8 @@ func (s *QuizService) SubmitQuiz(ctx context.Context, user *utils.Claims, attemp
 			}
 		}
 
-		totalQuestions := len(temps)
 		wrongAnswers := totalQuestions - correctAnswers
-		scorePercentage := (correctAnswers * 100) / totalQuestions
+		scorePercentage := ScorePercent(correctAnswers, totalQuestions)
 
 		// 6️⃣ Simpan QuizResult
 		quizResult := &models.QuizResult{
@@ -499,7 +735,7 @@ func (s *QuizService) SubmitQuiz(ctx context.Context, user *utils.Claims, attemp
 			TotalQuestions: totalQuestions,
 			CorrectAnswers: correctAnswers,
 			WrongAnswers:   wrongAnswers,
-			ScorePercent:   float64(scorePercentage),
+			ScorePercent:   scorePercentage,
 		}
 
 		if err := s.quizRepo.WithTx(tx).CreateQuizResult(ctx, quizResult); err != nil {
@@ -515,10 +751,83 @@ func (s *QuizService) SubmitQuiz(ctx context.Context, user *utils.Claims, attemp
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Attempt sudah selesai, keluarkan dari antrian auto-submit
+	if err := s.deadlineQueue.Remove(ctx, attemptID); err != nil {
+		log.Errorf("Failed to remove attempt %s from deadline queue: %v", attemptID, err)
+	}
+	s.liveHub.Publish(ctx, dto.QuizLiveEvent{
+		Type: LiveEventSubmitted, QuizID: quizID, AttemptID: attemptID, UserID: user.UserID, Reason: "manual",
+	})
+
+	return nil
+}
+
+// HandleAttemptDeadline dipanggil worker saat deadline attempt jatuh tempo.
+// Kalau deadline ternyata mundur (misal quiz diperpanjang), attempt dijadwalkan ulang.
+func (s *QuizService) HandleAttemptDeadline(ctx context.Context, attemptID uuid.UUID) error {
+	attempt, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
+	if err != nil {
+		return err
+	}
+	// attempt yang dijeda dijadwalkan ulang saat dilanjutkan
+	if attempt.EndedAt != nil || attempt.PausedAt != nil {
+		return nil
+	}
+
+	acc, err := s.accommodationRepo.GetQuizAccommodation(ctx, attempt.QuizID, attempt.UserID)
+	if err != nil {
+		return err
+	}
+
+	deadline := ComputeAttemptDeadline(attempt, &attempt.Quiz, acc)
+	if time.Now().Before(deadline) {
+		return s.deadlineQueue.Schedule(ctx, attempt.ID, deadline)
+	}
+
+	return s.AutoSubmitQuiz(ctx, attempt.ID)
+}
+
+// ReconcileAttemptDeadlines isi ulang antrian dari semua attempt aktif (misal setelah Redis restart)
+func (s *QuizService) ReconcileAttemptDeadlines(ctx context.Context) (int, error) {
+	attempts, err := s.quizRepo.GetActiveAttempts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	quizIDs := make([]uuid.UUID, 0, len(attempts))
+	for _, a := range attempts {
+		quizIDs = append(quizIDs, a.QuizID)
+	}
+	accs, err := s.accommodationRepo.GetQuizAccommodationsByQuizIDs(ctx, quizIDs)
+	if err != nil {
+		return 0, err
+	}
+	accByKey := make(map[[2]uuid.UUID]*models.QuizAccommodation, len(accs))
+	for i := range accs {
+		accByKey[[2]uuid.UUID{accs[i].QuizID, accs[i].UserID}] = &accs[i]
+	}
+
+	deadlines := make(map[uuid.UUID]time.Time, len(attempts))
+	for i := range attempts {
+		if attempts[i].PausedAt != nil {
+			continue
+		}
+		acc := accByKey[[2]uuid.UUID{attempts[i].QuizID, attempts[i].UserID}]
+		deadlines[attempts[i].ID] = ComputeAttemptDeadline(&attempts[i], &attempts[i].Quiz, acc)
+	}
+
+	if err := s.deadlineQueue.ScheduleMany(ctx, deadlines); err != nil {
+		return 0, err
+	}
+	return len(deadlines), nil
 }
 
 // SaveTempSubmission service
-func (s *QuizService) SaveTempSubmission(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.SaveTempSubmissionRequest) error {
+func (s *QuizService) SaveTempSubmission(ctx context.Context, user *utils.Claims, attemptID uuid.UUID, body *dto.SaveTempSubmissionRequest, client dto.ClientInfo) error {
 	// 1️⃣ Ambil attempt
 	attempt, err := s.quizRepo.GetQuizAttemptByID(ctx, attemptID)
 	if err != nil {
@@ -534,6 +843,9 @@ func (s *QuizService) SaveTempSubmission(ctx context.Context, user *utils.Claims
 	if attempt.EndedAt != nil {
 		return fmt.Errorf("quiz already submitted")
 	}
+	if attempt.PausedAt != nil {
+		return fmt.Errorf("quiz attempt sedang dijeda")
+	}
 
 	// 2️⃣ Ambil quiz dari attempt
 	quiz, err := s.quizRepo.GetQuizByID(ctx, attempt.QuizID)
@@ -562,14 +874,56 @@ func (s *QuizService) SaveTempSubmission(ctx context.Context, user *utils.Claims
 		return fmt.Errorf("selected option does not belong to the question")
 	}
 
-	// 6️⃣ Save atau update temp submission
+	// 6️⃣ Cari jawaban sebelumnya untuk riwayat perubahan
+	var previousOptionID *uuid.UUID
+	tempSubs, err := s.quizRepo.GetTempSubmissionsByAttemptID(ctx, attempt.ID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tempSubs {
+		if t.QuestionID == question.ID {
+			if t.SelectedOptionID == option.ID {
+				return nil // jawaban sama, tidak ada perubahan
+			}
+			prev := t.SelectedOptionID
+			previousOptionID = &prev
+			break
+		}
+	}
+
+	// 7️⃣ Save atau update temp submission + catat riwayat
 	temp := &models.QuizTempSubmission{
 		AttemptID:        attempt.ID,
 		QuestionID:       question.ID,
 		SelectedOptionID: option.ID,
 	}
 
-	return s.quizRepo.SaveTempSubmission(ctx, temp)
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if err := s.quizRepo.WithTx(tx).SaveTempSubmission(ctx, temp); err != nil {
+			return err
+		}
+		return s.proctoringRepo.WithTx(tx).CreateAnswerLog(ctx, &models.QuizAnswerLog{
+			AttemptID:        attempt.ID,
+			QuestionID:       question.ID,
+			SelectedOptionID: option.ID,
+			PreviousOptionID: previousOptionID,
+			IPAddress:        client.IPAddress,
+			UserAgent:        client.UserAgent,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// progres untuk monitor guru
+	answered := len(tempSubs)
+	if previousOptionID == nil {
+		answered++
+	}
+	s.liveHub.Publish(ctx, dto.QuizLiveEvent{
+		Type: LiveEventAnswerSaved, QuizID: quiz.ID, AttemptID: attempt.ID, UserID: attempt.UserID, AnsweredCount: &answered,
+	})
+	return nil
 }
 
 // GetQuizMetadata get quiz by id
@@ -658,8 +1012,9 @@ func (s *QuizService) GetActiveAttempt(ctx context.Context, quizID uuid.UUID, us
 	return attempt, nil
 }
 
-// GetListAttempt get list attempt
-func (s *QuizService) GetListAttempt(ctx context.Context, quizID uuid.UUID, user *utils.Claims) ([]models.QuizAttempt, error) {
+// GetListAttempt get list attempt. Siswa hanya melihat attempt miliknya,
+// guru/admin melihat semua attempt beserta ringkasan integritas.
+func (s *QuizService) GetListAttempt(ctx context.Context, quizID uuid.UUID, user *utils.Claims) ([]dto.QuizAttemptSummary, error) {
 	quiz, err := s.quizRepo.GetQuizWithQuestions(ctx, quizID)
 	if err != nil {
 		return nil, err
@@ -673,11 +1028,24 @@ func (s *QuizService) GetListAttempt(ctx context.Context, quizID uuid.UUID, user
 		return nil, fmt.Errorf("forbidden")
 	}
 
+	if user.Role != string(models.RoleTypeSiswa) {
+		attempts, err := s.quizRepo.GetAttemptsByQuizID(ctx, quizID)
+		if err != nil {
+			return nil, err
+		}
+		return s.attachIntegrity(ctx, attempts)
+	}
+
 	attempts, err := s.quizRepo.GetAttemptsByQuizAndUser(ctx, quizID, user.UserID)
 	if err != nil {
 		return nil, err
 	}
-	return attempts, nil
+
+	summaries := make([]dto.QuizAttemptSummary, 0, len(attempts))
+	for _, a := range attempts {
+		summaries = append(summaries, dto.QuizAttemptSummary{Attempt: a})
+	}
+	return summaries, nil
 }
 
 // GetAttemptDetail detail
@@ -713,11 +1081,28 @@ func (s *QuizService) GetAttemptDetail(ctx context.Context, attemptID uuid.UUID,
 	// Ambil temp submissions jika ada
 	tempSubs, _ := s.quizRepo.GetTempSubmissionsByAttemptID(ctx, attempt.ID)
 
-	return &dto.QuizAttemptFull{
+	acc, err := s.accommodationRepo.GetQuizAccommodation(ctx, quiz.ID, attempt.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	full := &dto.QuizAttemptFull{
 		Attempt:         attempt,
 		Quiz:            quiz,
 		TempSubmissions: tempSubs,
-	}, nil
+		Deadline:        ComputeAttemptDeadline(attempt, quiz, acc),
+	}
+
+	// attempt yang sudah selesai ikut membawa review sesuai aturan rilis
+	if attempt.EndedAt != nil {
+		review, err := s.buildReview(ctx, user, attempt)
+		if err != nil {
+			return nil, err
+		}
+		full.Review = review
+	}
+
+	return full, nil
 
 }
 
@@ -740,6 +1125,7 @@ func (s *QuizService) UpdateQuiz(ctx context.Context, quizID uuid.UUID, user *ut
 	if err := copier.CopyWithOption(quiz, body, copier.Option{IgnoreEmpty: true, DeepCopy: true}); err != nil {
 		return nil, fmt.Errorf("failed to copy quiz data: %w", err)
 	}
+	ApplyQuizModeDefaults(quiz)
 
 	if err := s.quizRepo.UpdateQuiz(ctx, quiz); err != nil {
 		return nil, err
@@ -767,15 +1153,13 @@ func (s *QuizService) DeleteQuiz(ctx context.Context, quizID uuid.UUID, user *ut
 }
 
 // GetAttemptResult result
-func (s *QuizService) GetAttemptResult(ctx context.Context, attemptID uuid.UUID, user *utils.Claims) (*models.QuizResult, error) {
+func (s *QuizService) GetAttemptResult(ctx context.Context, attemptID uuid.UUID, user *utils.Claims) (*dto.QuizAttemptResult, error) {
 	result, err := s.quizRepo.GetQuizResultByAttemptID(ctx, attemptID)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("CEK MEETING ID : ", result.Attempt.Quiz.MeetingID)
 	allowed, err := s.checkUserAccess(ctx, user, result.Attempt.Quiz.MeetingID)
-	fmt.Println(allowed, err, "TEST TES")
 	if err != nil {
 		return nil, err
 	}
@@ -788,5 +1172,17 @@ func (s *QuizService) GetAttemptResult(ctx context.Context, attemptID uuid.UUID,
 		return nil, fmt.Errorf("forbidden")
 	}
 
-	return result, nil
+	review, err := s.buildReview(ctx, user, &result.Attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	// nilai disembunyikan sampai review dibuka
+	if !review.Released {
+		result.CorrectAnswers = 0
+		result.WrongAnswers = 0
+		result.ScorePercent = 0
+	}
+
+	return &dto.QuizAttemptResult{Result: result, Review: review}, nil
 }
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IRubricService interface
+type IRubricService interface {
+	GetRubrics(ctx context.Context, user *utils.Claims) ([]models.Rubric, error)
+	GetRubricByID(ctx context.Context, user *utils.Claims, rubricID uuid.UUID) (*models.Rubric, error)
+	CreateRubric(ctx context.Context, user *utils.Claims, body *dto.SaveRubricRequest) (*models.Rubric, error)
+	UpdateRubric(ctx context.Context, user *utils.Claims, rubricID uuid.UUID, body *dto.SaveRubricRequest) (*models.Rubric, error)
+	DeleteRubric(ctx context.Context, user *utils.Claims, rubricID uuid.UUID) error
+	AttachRubric(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, body *dto.AttachRubricRequest) (*models.Assignment, error)
+}
+
+// RubricService mengelola rubrik penilaian assignment
+type RubricService struct {
+	rubricRepo     repository.IRubricRepository
+	assignmentRepo repository.IAssignmentRepository
+	meetingRepo    repository.IMeetingRepository
+	db             *gorm.DB
+}
+
+// NewRubricService creates a new instance of RubricService
+func NewRubricService(rubricRepo repository.IRubricRepository, assignmentRepo repository.IAssignmentRepository,
+	meetingRepo repository.IMeetingRepository, db *gorm.DB) IRubricService {
+	return &RubricService{rubricRepo: rubricRepo, assignmentRepo: assignmentRepo, meetingRepo: meetingRepo, db: db}
+}
+
+// BuildRubricCriteria ubah request jadi kriteria & level. Poin level dalam satu kriteria harus unik
+// supaya import Excel bisa memetakan poin kembali ke level.
+func BuildRubricCriteria(body *dto.SaveRubricRequest) ([]models.RubricCriterion, error) {
+	criteria := make([]models.RubricCriterion, 0, len(body.Criteria))
+	for i, c := range body.Criteria {
+		criterion := models.RubricCriterion{Title: c.Title, Description: c.Description, Order: i + 1}
+		seen := map[int]bool{}
+		for j, l := range c.Levels {
+			if seen[l.Points] {
+				return nil, fmt.Errorf("kriteria '%s': poin %d dipakai lebih dari satu level", c.Title, l.Points)
+			}
+			seen[l.Points] = true
+			criterion.Levels = append(criterion.Levels, models.RubricLevel{
+				Title: l.Title, Description: l.Description, Points: l.Points, Order: j + 1,
+			})
+		}
+		if criterion.MaxPoints() == 0 {
+			return nil, fmt.Errorf("kriteria '%s' harus punya level dengan poin di atas 0", c.Title)
+		}
+		criteria = append(criteria, criterion)
+	}
+	return criteria, nil
+}
+
+// RubricMaxPoints total poin maksimal rubrik
+func RubricMaxPoints(rubric *models.Rubric) int {
+	total := 0
+	for _, c := range rubric.Criteria {
+		total += c.MaxPoints()
+	}
+	return total
+}
+
+// ScoreRubric hitung rincian & total poin dari pilihan level guru. Semua kriteria wajib dipilih tepat satu kali.
+func ScoreRubric(rubric *models.Rubric, selections []dto.RubricSelectionRequest) ([]models.AssignmentGradeCriterion, int, int, error) {
+	bySelection := make(map[uuid.UUID]dto.RubricSelectionRequest, len(selections))
+	for _, sel := range selections {
+		if _, dup := bySelection[sel.CriterionID]; dup {
+			return nil, 0, 0, fmt.Errorf("kriteria %s dipilih lebih dari sekali", sel.CriterionID)
+		}
+		bySelection[sel.CriterionID] = sel
+	}
+
+	var criteria []models.AssignmentGradeCriterion
+	points, maxPoints := 0, 0
+	for _, c := range rubric.Criteria {
+		sel, ok := bySelection[c.ID]
+		if !ok {
+			return nil, 0, 0, fmt.Errorf("kriteria '%s' belum dinilai", c.Title)
+		}
+		delete(bySelection, c.ID)
+
+		var level *models.RubricLevel
+		for i := range c.Levels {
+			if c.Levels[i].ID == sel.LevelID {
+				level = &c.Levels[i]
+				break
+			}
+		}
+		if level == nil {
+			return nil, 0, 0, fmt.Errorf("level %s bukan bagian dari kriteria '%s'", sel.LevelID, c.Title)
+		}
+
+		criteria = append(criteria, models.AssignmentGradeCriterion{
+			CriterionID:    c.ID,
+			LevelID:        level.ID,
+			CriterionTitle: c.Title,
+			LevelTitle:     level.Title,
+			Points:         level.Points,
+			MaxPoints:      c.MaxPoints(),
+			Comment:        sel.Comment,
+			Order:          c.Order,
+		})
+		points += level.Points
+		maxPoints += c.MaxPoints()
+	}
+	for id := range bySelection {
+		return nil, 0, 0, fmt.Errorf("kriteria %s tidak ada di rubrik assignment ini", id)
+	}
+	return criteria, points, maxPoints, nil
+}
+
+// RubricGrade konversi poin rubrik ke nilai 0-100
+func RubricGrade(points, maxPoints int) int {
+	if maxPoints <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(points) * 100 / float64(maxPoints)))
+}
+
+func (s *RubricService) loadOwnedRubric(ctx context.Context, user *utils.Claims, rubricID uuid.UUID) (*models.Rubric, error) {
+	rubric, err := s.rubricRepo.GetRubricByID(ctx, rubricID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != string(models.RoleTypeAdmin) && rubric.CreatedBy != user.UserID {
+		return nil, fmt.Errorf("forbidden: rubrik milik guru lain")
+	}
+	return rubric, nil
+}
+
+// GetRubrics guru melihat rubrik miliknya, admin melihat semua
+func (s *RubricService) GetRubrics(ctx context.Context, user *utils.Claims) ([]models.Rubric, error) {
+	if user.Role == string(models.RoleTypeAdmin) {
+		return s.rubricRepo.GetRubrics(ctx, nil)
+	}
+	return s.rubricRepo.GetRubrics(ctx, &user.UserID)
+}
+
+// GetRubricByID detail rubrik
+func (s *RubricService) GetRubricByID(ctx context.Context, user *utils.Claims, rubricID uuid.UUID) (*models.Rubric, error) {
+	return s.loadOwnedRubric(ctx, user, rubricID)
+}
+
+// CreateRubric buat rubrik baru
+func (s *RubricService) CreateRubric(ctx context.Context, user *utils.Claims, body *dto.SaveRubricRequest) (*models.Rubric, error) {
+	criteria, err := BuildRubricCriteria(body)
+	if err != nil {
+		return nil, err
+	}
+
+	rubric := models.Rubric{
+		Title:       body.Title,
+		Description: body.Description,
+		CreatedBy:   user.UserID,
+		Criteria:    criteria,
+	}
+	if err := s.rubricRepo.CreateRubric(ctx, &rubric); err != nil {
+		return nil, err
+	}
+	return s.rubricRepo.GetRubricByID(ctx, rubric.ID)
+}
+
+// UpdateRubric ganti isi rubrik. Nilai yang sudah diberikan tidak berubah karena rinciannya disalin saat menilai.
+func (s *RubricService) UpdateRubric(ctx context.Context, user *utils.Claims, rubricID uuid.UUID, body *dto.SaveRubricRequest) (*models.Rubric, error) {
+	rubric, err := s.loadOwnedRubric(ctx, user, rubricID)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := BuildRubricCriteria(body)
+	if err != nil {
+		return nil, err
+	}
+	for i := range criteria {
+		criteria[i].RubricID = rubric.ID
+	}
+
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		rubric.Title = body.Title
+		rubric.Description = body.Description
+		if err := s.rubricRepo.WithTx(tx).UpdateRubric(ctx, rubric); err != nil {
+			return err
+		}
+		if err := s.rubricRepo.WithTx(tx).DeleteCriteriaByRubricID(ctx, rubric.ID); err != nil {
+			return err
+		}
+		return s.rubricRepo.WithTx(tx).CreateCriteria(ctx, criteria)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.rubricRepo.GetRubricByID(ctx, rubric.ID)
+}
+
+// DeleteRubric hapus rubrik, assignment yang memakainya kembali ke penilaian biasa
+func (s *RubricService) DeleteRubric(ctx context.Context, user *utils.Claims, rubricID uuid.UUID) error {
+	if _, err := s.loadOwnedRubric(ctx, user, rubricID); err != nil {
+		return err
+	}
+	return s.rubricRepo.DeleteRubric(ctx, rubricID)
+}
+
+// AttachRubric pasang rubrik ke assignment, RubricID nil = lepas rubrik
+func (s *RubricService) AttachRubric(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, body *dto.AttachRubricRequest) (*models.Assignment, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == string(models.RoleTypeGuru) {
+		ok, err := s.meetingRepo.IsMeetingTaughtByUser(ctx, assignment.MeetingID, user.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check meeting-teacher relation: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("forbidden: user %s is not assigned to teach meeting %s", user.UserID, assignment.MeetingID)
+		}
+	}
+
+	if body.RubricID != nil {
+		if _, err := s.loadOwnedRubric(ctx, user, *body.RubricID); err != nil {
+			return nil, err
+		}
+	}
+
+	assignment.RubricID = body.RubricID
+	assignment.Rubric = nil
+	if err := s.assignmentRepo.Update(ctx, assignment); err != nil {
+		return nil, err
+	}
+	return s.assignmentRepo.FindByID(ctx, assignmentID)
+}
+
+// RubricExcelHeaders dua kolom per kriteria: poin lalu komentar
+func RubricExcelHeaders(rubric *models.Rubric) []string {
+	headers := make([]string, 0, len(rubric.Criteria)*2)
+	for _, c := range rubric.Criteria {
+		headers = append(headers,
+			fmt.Sprintf("%s (%s)", c.Title, rubricLevelPoints(c)),
+			fmt.Sprintf("Komentar %s", c.Title),
+		)
+	}
+	return headers
+}
+
+// rubricLevelPoints daftar poin yang valid untuk kriteria, mis. "0/2/4"
+func rubricLevelPoints(c models.RubricCriterion) string {
+	points := make([]string, 0, len(c.Levels))
+	for _, l := range c.Levels {
+		points = append(points, strconv.Itoa(l.Points))
+	}
+	return strings.Join(points, "/")
+}
+
+// RubricExcelValues isi kolom rubrik dari rincian nilai yang tersimpan
+func RubricExcelValues(rubric *models.Rubric, criteria []models.AssignmentGradeCriterion) []string {
+	values := make([]string, len(rubric.Criteria)*2)
+	for i, c := range rubric.Criteria {
+		for _, gc := range criteria {
+			if gc.CriterionID != c.ID {
+				continue
+			}
+			values[i*2] = strconv.Itoa(gc.Points)
+			if gc.Comment != nil {
+				values[i*2+1] = *gc.Comment
+			}
+		}
+	}
+	return values
+}
+
+// ParseRubricExcelRow baca kolom rubrik satu baris Excel berdasarkan header. Poin dipetakan ke level dengan poin yang sama.
+// Hasil nil berarti baris belum dinilai (semua kolom poin kosong).
+func ParseRubricExcelRow(rubric *models.Rubric, header, row []string) ([]dto.RubricSelectionRequest, error) {
+	return parseRubricColumns(rubric, newHeaderIndex(header), "", row)
+}
+
+// parseRubricColumns kolom rubrik dengan awalan judul assignment (layout satu kolom per assignment)
+func parseRubricColumns(rubric *models.Rubric, idx headerIndex, prefix string, row []string) ([]dto.RubricSelectionRequest, error) {
+	var selections []dto.RubricSelectionRequest
+	filled := 0
+	for _, c := range rubric.Criteria {
+		col := idx.criterionCol(gradeHeader(prefix, c.Title))
+		if col < 0 {
+			return nil, fmt.Errorf("kolom kriteria '%s' tidak ditemukan", c.Title)
+		}
+		raw := strings.TrimSpace(excelCell(row, col))
+		if raw == "" {
+			continue
+		}
+		filled++
+
+		points, err := strconv.ParseFloat(raw, 64)
+		if err != nil || points != math.Trunc(points) {
+			return nil, fmt.Errorf("poin kriteria '%s' harus bilangan bulat", c.Title)
+		}
+		var levelID uuid.UUID
+		for _, l := range c.Levels {
+			if l.Points == int(points) {
+				levelID = l.ID
+				break
+			}
+		}
+		if levelID == uuid.Nil {
+			return nil, fmt.Errorf("poin %s tidak ada di kriteria '%s', pilih salah satu dari %s", raw, c.Title, rubricLevelPoints(c))
+		}
+
+		sel := dto.RubricSelectionRequest{CriterionID: c.ID, LevelID: levelID}
+		if comment := idx.cell(row, gradeHeader(prefix, "Komentar "+c.Title)); comment != "" {
+			sel.Comment = &comment
+		}
+		selections = append(selections, sel)
+	}
+
+	if filled == 0 {
+		return nil, nil
+	}
+	if filled < len(rubric.Criteria) {
+		return nil, fmt.Errorf("semua kriteria rubrik harus diisi")
+	}
+	return selections, nil
+}
+
+// excelCell GetRows memotong sel kosong di ujung baris
+func excelCell(row []string, col int) string {
+	if col < len(row) {
+		return row[col]
+	}
+	return ""
+}
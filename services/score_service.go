@@ -67,13 +67,13 @@ func (s *ScoreService) GetScoresByBatchUser(ctx context.Context, batchID uuid.UU
 	}
 
 	// Ambil assignment scores
-	assignScores, err := s.submissionRepo.GetAssignmentsWithScoresByBatchUser(ctx, batchID, user.UserID)
+	assignScores, err := s.submissionRepo.GetAssignmentsWithScoresByBatchUser(ctx, batchID, user.UserID, true)
 	if err != nil {
 		return nil, err
 	}
 
 	// Ambil quiz scores
-	quizScores, err := s.quizRepo.GetQuizzesWithScoresByBatchUser(ctx, batchID, user.UserID)
+	quizScores, err := s.quizRepo.GetQuizzesWithScoresByBatchUser(ctx, batchID, user.UserID, true)
 	if err != nil {
 		return nil, err
 	}
@@ -104,14 +104,14 @@ func (s *ScoreService) GetScoresByBatchStudentSlug(ctx context.Context, batchSlu
 		return nil, err
 	}
 
-	// Ambil assignment scores
-	assignScores, err := s.submissionRepo.GetAssignmentsWithScoresByBatchUser(ctx, batch.ID, studentID)
+	// Ambil assignment scores, termasuk nilai draft/moderasi yang belum dirilis
+	assignScores, err := s.submissionRepo.GetAssignmentsWithScoresByBatchUser(ctx, batch.ID, studentID, false)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ambil quiz scores
-	quizScores, err := s.quizRepo.GetQuizzesWithScoresByBatchUser(ctx, batch.ID, studentID)
+	// Ambil quiz scores, guru/admin lihat nilai meski hasil quiz belum dibuka untuk siswa
+	quizScores, err := s.quizRepo.GetQuizzesWithScoresByBatchUser(ctx, batch.ID, studentID, false)
 	if err != nil {
 		return nil, err
 	}
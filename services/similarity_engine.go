@@ -0,0 +1,197 @@
+package services
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// SimilarityShingleWords panjang shingle (jumlah kata) yang di-hash
+	SimilarityShingleWords = 5
+	// SimilarityWindow ukuran window winnowing; kecocokan minimal SimilarityShingleWords+SimilarityWindow-1 kata pasti terdeteksi
+	SimilarityWindow = 4
+	// SimilarityMinWords dokumen lebih pendek dari ini tidak dibandingkan
+	SimilarityMinWords = 20
+)
+
+// similarityWord posisi satu kata di teks asli, dalam offset rune
+type similarityWord struct {
+	start, end int
+}
+
+// similarityPrint fingerprint hasil winnowing: hash shingle & indeks kata awalnya
+type similarityPrint struct {
+	hash uint64
+	pos  int
+}
+
+// SimilarityText teks yang sudah dipecah kata & diambil fingerprint-nya
+type SimilarityText struct {
+	Text   string
+	words  []similarityWord
+	tokens []string
+	prints []similarityPrint
+	first  map[uint64]int // hash -> posisi kata pertama
+}
+
+// SimilarityPassage potongan teks yang sama di kedua dokumen, offset rune [start, end)
+type SimilarityPassage struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+// SimilarityMatch hasil perbandingan dua dokumen
+type SimilarityMatch struct {
+	ScoreA   float64 // porsi fingerprint A yang ada di B
+	ScoreB   float64 // porsi fingerprint B yang ada di A
+	Score    float64 // yang terbesar dari keduanya
+	Passages []SimilarityPassage
+}
+
+// NewSimilarityText normalisasi teks (huruf kecil, hanya huruf & angka) lalu ambil fingerprint winnowing
+func NewSimilarityText(text string) *SimilarityText {
+	st := &SimilarityText{Text: text, first: map[uint64]int{}}
+
+	var tokens []string
+	var cur strings.Builder
+	start, i := -1, 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			cur.WriteRune(unicode.ToLower(r))
+		} else if start >= 0 {
+			st.words = append(st.words, similarityWord{start: start, end: i})
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+			start = -1
+		}
+		i++
+	}
+	if start >= 0 {
+		st.words = append(st.words, similarityWord{start: start, end: i})
+		tokens = append(tokens, cur.String())
+	}
+
+	st.tokens = tokens
+	if len(tokens) < SimilarityShingleWords {
+		return st
+	}
+	hashes := make([]uint64, len(tokens)-SimilarityShingleWords+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:i+SimilarityShingleWords], " ")))
+		hashes[i] = h.Sum64()
+	}
+
+	// winnowing: ambil hash terkecil (paling kanan kalau seri) di tiap window
+	window := min(SimilarityWindow, len(hashes))
+	lastPicked := -1
+	for w := 0; w+window <= len(hashes); w++ {
+		minAt := w
+		for j := w; j < w+window; j++ {
+			if hashes[j] <= hashes[minAt] {
+				minAt = j
+			}
+		}
+		if minAt != lastPicked {
+			st.prints = append(st.prints, similarityPrint{hash: hashes[minAt], pos: minAt})
+			if _, ok := st.first[hashes[minAt]]; !ok {
+				st.first[hashes[minAt]] = minAt
+			}
+			lastPicked = minAt
+		}
+	}
+	return st
+}
+
+// WordCount jumlah kata hasil normalisasi
+func (st *SimilarityText) WordCount() int {
+	return len(st.words)
+}
+
+// Comparable dokumen cukup panjang untuk dibandingkan
+func (st *SimilarityText) Comparable() bool {
+	return len(st.words) >= SimilarityMinWords && len(st.first) > 0
+}
+
+// CompareSimilarity bandingkan fingerprint dua dokumen dan gabungkan kecocokan berurutan jadi passage
+func CompareSimilarity(a, b *SimilarityText) SimilarityMatch {
+	var match SimilarityMatch
+	if len(a.first) == 0 || len(b.first) == 0 {
+		return match
+	}
+
+	common := 0
+	for hash := range a.first {
+		if _, ok := b.first[hash]; ok {
+			common++
+		}
+	}
+	match.ScoreA = float64(common) / float64(len(a.first))
+	match.ScoreB = float64(common) / float64(len(b.first))
+	match.Score = max(match.ScoreA, match.ScoreB)
+	if common == 0 {
+		return match
+	}
+
+	type pair struct{ a, b int }
+	var pairs []pair
+	for _, p := range a.prints {
+		if pb, ok := b.first[p.hash]; ok {
+			pairs = append(pairs, pair{a: p.pos, b: pb})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].a < pairs[j].a })
+
+	// rentang kata [start, end), digabung kalau lanjutan di kedua dokumen
+	gap := SimilarityWindow
+	var runs [][4]int
+	for _, p := range pairs {
+		aEnd, bEnd := p.a+SimilarityShingleWords, p.b+SimilarityShingleWords
+		if n := len(runs); n > 0 {
+			r := &runs[n-1]
+			if p.a <= r[1]+gap && p.b >= r[2] && p.b <= r[3]+gap {
+				r[1], r[3] = max(r[1], aEnd), max(r[3], bEnd)
+				continue
+			}
+		}
+		runs = append(runs, [4]int{p.a, aEnd, p.b, bEnd})
+	}
+
+	// fingerprint hanya sampel, jadi rentang diperlebar selama kata di kedua dokumen masih sama
+	merged := runs[:0]
+	for _, r := range runs {
+		for r[0] > 0 && r[2] > 0 && a.tokens[r[0]-1] == b.tokens[r[2]-1] {
+			r[0], r[2] = r[0]-1, r[2]-1
+		}
+		for r[1] < len(a.tokens) && r[3] < len(b.tokens) && a.tokens[r[1]] == b.tokens[r[3]] {
+			r[1], r[3] = r[1]+1, r[3]+1
+		}
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1] && r[2] >= merged[n-1][2] && r[2] <= merged[n-1][3] {
+			merged[n-1][1], merged[n-1][3] = max(merged[n-1][1], r[1]), max(merged[n-1][3], r[3])
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	for _, r := range merged {
+		match.Passages = append(match.Passages, SimilarityPassage{
+			AStart: a.words[r[0]].start, AEnd: a.words[r[1]-1].end,
+			BStart: b.words[r[2]].start, BEnd: b.words[r[3]-1].end,
+		})
+	}
+	return match
+}
+
+// Excerpt potongan teks asli pada offset rune [start, end)
+func (st *SimilarityText) Excerpt(start, end int) string {
+	runes := []rune(st.Text)
+	if start < 0 || end > len(runes) || start >= end {
+		return ""
+	}
+	return string(runes[start:end])
+}
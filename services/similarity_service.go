@@ -0,0 +1,369 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultSimilarityMinScore pasangan dengan skor di bawah ini tidak dimasukkan ke laporan
+const DefaultSimilarityMinScore = 0.15
+
+// SimilarityStaleAfter laporan pending / running lebih lama dari ini dianggap terputus dan ditandai failed
+const SimilarityStaleAfter = time.Hour
+
+// similarityJobs laporan on-demand yang diproses di background. Context-nya dibatalkan saat shutdown
+// supaya laporan yang terputus langsung ditandai failed, bukan tertinggal running.
+var similarityJobs = struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}{}
+
+func init() {
+	similarityJobs.ctx, similarityJobs.cancel = context.WithCancel(context.Background())
+}
+
+// StopSimilarityJobs batalkan laporan yang masih diproses dan tunggu selesai paling lama timeout
+func StopSimilarityJobs(timeout time.Duration) bool {
+	similarityJobs.cancel()
+	done := make(chan struct{})
+	go func() {
+		similarityJobs.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// ISimilarityService interface
+type ISimilarityService interface {
+	RunSimilarityCheck(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, body *dto.RunSimilarityRequest) (*models.SimilarityReport, error)
+	GetReportsByAssignmentID(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) ([]models.SimilarityReport, error)
+	GetReportByID(ctx context.Context, user *utils.Claims, reportID uuid.UUID) (*models.SimilarityReport, error)
+	RunDueReports(ctx context.Context, limit int) (int, error)
+	RecoverStaleReports(ctx context.Context) (int64, error)
+}
+
+// SimilarityService cek kemiripan antar submission assignment
+type SimilarityService struct {
+	similarityRepo repository.ISimilarityRepository
+	assignmentRepo repository.IAssignmentRepository
+	meetingRepo    repository.IMeetingRepository
+	batchRepo      repository.IBatchRepository
+	db             *gorm.DB
+}
+
+// NewSimilarityService creates a new instance of SimilarityService
+func NewSimilarityService(similarityRepo repository.ISimilarityRepository, assignmentRepo repository.IAssignmentRepository,
+	meetingRepo repository.IMeetingRepository, batchRepo repository.IBatchRepository, db *gorm.DB) ISimilarityService {
+	return &SimilarityService{
+		similarityRepo: similarityRepo,
+		assignmentRepo: assignmentRepo,
+		meetingRepo:    meetingRepo,
+		batchRepo:      batchRepo,
+		db:             db,
+	}
+}
+
+func (s *SimilarityService) checkAssignmentAccess(ctx context.Context, user *utils.Claims, assignment *models.Assignment) error {
+	if user.Role != string(models.RoleTypeGuru) {
+		return nil
+	}
+	ok, err := s.meetingRepo.IsMeetingTaughtByUser(ctx, assignment.MeetingID, user.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check meeting-teacher relation: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("forbidden: user %s is not assigned to teach meeting %s", user.UserID, assignment.MeetingID)
+	}
+	return nil
+}
+
+// RunSimilarityCheck buat laporan baru dan proses di background
+func (s *SimilarityService) RunSimilarityCheck(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, body *dto.RunSimilarityRequest) (*models.SimilarityReport, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, user, assignment); err != nil {
+		return nil, err
+	}
+
+	// laporan yang terputus tidak boleh memblokir cek baru selamanya
+	if _, err := s.RecoverStaleReports(ctx); err != nil {
+		return nil, err
+	}
+	active, err := s.similarityRepo.HasActiveReport(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, fmt.Errorf("cek kemiripan untuk assignment ini masih berjalan")
+	}
+
+	minScore := DefaultSimilarityMinScore
+	if body.MinScore != nil {
+		minScore = *body.MinScore
+	}
+	report := &models.SimilarityReport{
+		AssignmentID:           assignmentID,
+		Status:                 models.SimilarityPending,
+		IncludePreviousBatches: body.IncludePreviousBatches,
+		MinScore:               minScore,
+		RequestedBy:            &user.UserID,
+	}
+	if err := s.similarityRepo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	similarityJobs.wg.Add(1)
+	go func() {
+		defer similarityJobs.wg.Done()
+		s.runReport(similarityJobs.ctx, report.ID)
+	}()
+	return report, nil
+}
+
+// RecoverStaleReports tandai failed laporan yang prosesnya terputus (lebih lama dari SimilarityStaleAfter)
+func (s *SimilarityService) RecoverStaleReports(ctx context.Context) (int64, error) {
+	return s.similarityRepo.FailStaleReports(ctx, time.Now().Add(-SimilarityStaleAfter),
+		"proses cek kemiripan terputus (server berhenti / timeout), silakan jalankan ulang")
+}
+
+// GetReportsByAssignmentID riwayat laporan kemiripan assignment
+func (s *SimilarityService) GetReportsByAssignmentID(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) ([]models.SimilarityReport, error) {
+	assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, user, assignment); err != nil {
+		return nil, err
+	}
+	return s.similarityRepo.GetReportsByAssignmentID(ctx, assignmentID)
+}
+
+// GetReportByID detail laporan beserta pasangan & passage
+func (s *SimilarityService) GetReportByID(ctx context.Context, user *utils.Claims, reportID uuid.UUID) (*models.SimilarityReport, error) {
+	report, err := s.similarityRepo.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := s.assignmentRepo.FindByID(ctx, report.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, user, assignment); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// RunDueReports dipanggil scheduler: cek otomatis assignment yang deadline-nya sudah lewat
+func (s *SimilarityService) RunDueReports(ctx context.Context, limit int) (int, error) {
+	assignments, err := s.similarityRepo.GetAssignmentsDueForReport(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, assignment := range assignments {
+		report := &models.SimilarityReport{
+			AssignmentID: assignment.ID,
+			Status:       models.SimilarityPending,
+			MinScore:     DefaultSimilarityMinScore,
+			Automatic:    true,
+		}
+		if err := s.similarityRepo.CreateReport(ctx, report); err != nil {
+			return processed, err
+		}
+		s.runReport(ctx, report.ID)
+		processed++
+	}
+	return processed, nil
+}
+
+// runReport proses laporan: ekstrak teks, bandingkan semua pasangan, simpan hasil
+func (s *SimilarityService) runReport(ctx context.Context, reportID uuid.UUID) {
+	report, err := s.similarityRepo.GetReportByID(ctx, reportID)
+	if err != nil {
+		log.Println("Failed to load similarity report:", reportID, err)
+		return
+	}
+
+	now := time.Now()
+	report.Status = models.SimilarityRunning
+	report.StartedAt = &now
+	if err := s.similarityRepo.UpdateReport(ctx, report); err != nil {
+		log.Println("Failed to start similarity report:", reportID, err)
+		return
+	}
+
+	if err := s.buildReport(ctx, report); err != nil {
+		msg := err.Error()
+		report.Status = models.SimilarityFailed
+		report.Error = &msg
+	} else {
+		report.Status = models.SimilarityCompleted
+	}
+	finished := time.Now()
+	report.FinishedAt = &finished
+	// status akhir tetap disimpan walau context dibatalkan saat shutdown
+	if err := s.similarityRepo.UpdateReport(context.WithoutCancel(ctx), report); err != nil {
+		log.Println("Failed to finish similarity report:", reportID, err)
+	}
+}
+
+func (s *SimilarityService) buildReport(ctx context.Context, report *models.SimilarityReport) error {
+	assignment, err := s.assignmentRepo.FindByID(ctx, report.AssignmentID)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := s.similarityRepo.GetSubmissionsByAssignmentID(ctx, report.AssignmentID)
+	if err != nil {
+		return err
+	}
+	currentCount := len(submissions)
+	if report.IncludePreviousBatches {
+		batch, err := s.batchRepo.GetBatchByMeetingID(ctx, assignment.MeetingID)
+		if err != nil {
+			return err
+		}
+		previous, err := s.similarityRepo.GetPreviousBatchSubmissions(ctx, assignment, &batch)
+		if err != nil {
+			return err
+		}
+		submissions = append(submissions, previous...)
+	}
+
+	documents := make([]models.SimilarityDocument, len(submissions))
+	texts := make([]*SimilarityText, len(submissions))
+	for i, sub := range submissions {
+		text, skipped := submissionText(&sub)
+		texts[i] = NewSimilarityText(text)
+		if skipped == nil && !texts[i].Comparable() {
+			reason := fmt.Sprintf("teks terlalu pendek (minimal %d kata)", SimilarityMinWords)
+			skipped = &reason
+		}
+		documents[i] = models.SimilarityDocument{
+			ID:           uuid.New(),
+			ReportID:     report.ID,
+			SubmissionID: sub.ID,
+			UserID:       sub.UserID,
+			AssignmentID: sub.AssignmentID,
+			Previous:     i >= currentCount,
+			Text:         text,
+			WordCount:    texts[i].WordCount(),
+			Skipped:      skipped,
+		}
+	}
+
+	var pairs []models.SimilarityPair
+	for i := 0; i < currentCount; i++ {
+		if documents[i].Skipped != nil {
+			continue
+		}
+		for j := i + 1; j < len(documents); j++ {
+			// siswa yang sama (mis. mengulang di batch lain) tidak dibandingkan dengan dirinya sendiri
+			if documents[j].Skipped != nil || documents[i].UserID == documents[j].UserID {
+				continue
+			}
+			match := CompareSimilarity(texts[i], texts[j])
+			if match.Score < report.MinScore || len(match.Passages) == 0 {
+				continue
+			}
+			pair := models.SimilarityPair{
+				ID:          uuid.New(),
+				ReportID:    report.ID,
+				DocumentAID: documents[i].ID,
+				DocumentBID: documents[j].ID,
+				Score:       roundScore(match.Score),
+				ScoreA:      roundScore(match.ScoreA),
+				ScoreB:      roundScore(match.ScoreB),
+			}
+			for _, p := range match.Passages {
+				pair.Passages = append(pair.Passages, models.SimilarityPassage{
+					AStart: p.AStart, AEnd: p.AEnd, BStart: p.BStart, BEnd: p.BEnd,
+				})
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		repo := s.similarityRepo.WithTx(tx)
+		if err := repo.CreateDocuments(ctx, documents); err != nil {
+			return err
+		}
+		return repo.CreatePairs(ctx, pairs)
+	})
+}
+
+// submissionText gabungkan EssayText & teks file (.txt/.docx/.pdf). Skipped terisi kalau tidak ada teks sama sekali.
+func submissionText(sub *models.AssignmentSubmission) (string, *string) {
+	var parts, problems []string
+	if sub.EssayText != nil && strings.TrimSpace(*sub.EssayText) != "" {
+		parts = append(parts, *sub.EssayText)
+	}
+
+	for _, f := range sub.SubmissionFiles {
+		name := filepath.Base(f.FileURL)
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".txt", ".docx", ".pdf":
+		default:
+			problems = append(problems, fmt.Sprintf("%s: format tidak didukung", name))
+			continue
+		}
+		path := utils.LocalUploadPath(f.FileURL)
+		if path == "" {
+			problems = append(problems, fmt.Sprintf("%s: file tidak ditemukan", name))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		text, err := utils.ExtractDocumentText(name, data)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			problems = append(problems, fmt.Sprintf("%s: tidak ada teks yang bisa dibaca", name))
+			continue
+		}
+		parts = append(parts, text)
+	}
+
+	if len(parts) == 0 {
+		reason := "submission tidak berisi teks"
+		if len(problems) > 0 {
+			reason = strings.Join(problems, "; ")
+		}
+		return "", &reason
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
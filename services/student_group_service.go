@@ -0,0 +1,253 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultGroupSize ukuran kelompok kalau generate tanpa group_size
+const DefaultGroupSize = 4
+
+// IStudentGroupService interface
+type IStudentGroupService interface {
+	GetGroups(ctx context.Context, user *utils.Claims, batchID uuid.UUID) ([]models.StudentGroup, error)
+	GetMyGroup(ctx context.Context, user *utils.Claims, batchID uuid.UUID) (*models.StudentGroup, error)
+	CreateGroup(ctx context.Context, user *utils.Claims, batchID uuid.UUID, req *dto.CreateStudentGroupRequest) (*models.StudentGroup, error)
+	UpdateGroup(ctx context.Context, user *utils.Claims, groupID uuid.UUID, req *dto.UpdateStudentGroupRequest) (*models.StudentGroup, error)
+	DeleteGroup(ctx context.Context, user *utils.Claims, groupID uuid.UUID) error
+	GenerateGroups(ctx context.Context, user *utils.Claims, batchID uuid.UUID, req *dto.GenerateStudentGroupsRequest) ([]models.StudentGroup, error)
+}
+
+// StudentGroupService kelola kelompok siswa per batch
+type StudentGroupService struct {
+	groupRepo   repository.IStudentGroupRepository
+	batchRepo   repository.IBatchRepository
+	meetingRepo repository.IMeetingRepository
+	db          *gorm.DB
+}
+
+// NewStudentGroupService creates a new instance of StudentGroupService
+func NewStudentGroupService(groupRepo repository.IStudentGroupRepository, batchRepo repository.IBatchRepository,
+	meetingRepo repository.IMeetingRepository, db *gorm.DB) IStudentGroupService {
+	return &StudentGroupService{
+		groupRepo:   groupRepo,
+		batchRepo:   batchRepo,
+		meetingRepo: meetingRepo,
+		db:          db,
+	}
+}
+
+// checkBatchAccess guru harus mengajar di batch, admin bebas
+func (s *StudentGroupService) checkBatchAccess(ctx context.Context, user *utils.Claims, batchID uuid.UUID) error {
+	batch, err := s.batchRepo.FindByID(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	if user.Role != string(models.RoleTypeGuru) {
+		return nil
+	}
+	ok, err := s.meetingRepo.IsBatchOwnedByUser(ctx, user.UserID, batch.Slug)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("forbidden: not teacher of this batch")
+	}
+	return nil
+}
+
+// GetGroups semua kelompok di batch
+func (s *StudentGroupService) GetGroups(ctx context.Context, user *utils.Claims, batchID uuid.UUID) ([]models.StudentGroup, error) {
+	if err := s.checkBatchAccess(ctx, user, batchID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetByBatchID(ctx, batchID)
+}
+
+// GetMyGroup kelompok siswa di batch
+func (s *StudentGroupService) GetMyGroup(ctx context.Context, user *utils.Claims, batchID uuid.UUID) (*models.StudentGroup, error) {
+	return s.groupRepo.GetByBatchAndUser(ctx, batchID, user.UserID)
+}
+
+// CreateGroup buat kelompok manual
+func (s *StudentGroupService) CreateGroup(ctx context.Context, user *utils.Claims, batchID uuid.UUID, req *dto.CreateStudentGroupRequest) (*models.StudentGroup, error) {
+	if err := s.checkBatchAccess(ctx, user, batchID); err != nil {
+		return nil, err
+	}
+
+	group := models.StudentGroup{BatchID: batchID, Name: req.Name}
+	err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if err := s.validateMembers(ctx, tx, batchID, uuid.Nil, req.MemberIDs); err != nil {
+			return err
+		}
+		if err := s.groupRepo.WithTx(tx).Create(ctx, &group); err != nil {
+			return err
+		}
+		return s.groupRepo.WithTx(tx).ReplaceMembers(ctx, &group, req.MemberIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetByID(ctx, group.ID)
+}
+
+// UpdateGroup ganti nama dan/atau anggota kelompok
+func (s *StudentGroupService) UpdateGroup(ctx context.Context, user *utils.Claims, groupID uuid.UUID, req *dto.UpdateStudentGroupRequest) (*models.StudentGroup, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBatchAccess(ctx, user, group.BatchID); err != nil {
+		return nil, err
+	}
+
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		if req.Name != nil {
+			group.Name = *req.Name
+			if err := s.groupRepo.WithTx(tx).Update(ctx, group); err != nil {
+				return err
+			}
+		}
+		if req.MemberIDs == nil {
+			return nil
+		}
+		if err := s.validateMembers(ctx, tx, group.BatchID, group.ID, req.MemberIDs); err != nil {
+			return err
+		}
+		return s.groupRepo.WithTx(tx).ReplaceMembers(ctx, group, req.MemberIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetByID(ctx, groupID)
+}
+
+// DeleteGroup hapus kelompok yang belum pernah mengumpulkan
+func (s *StudentGroupService) DeleteGroup(ctx context.Context, user *utils.Claims, groupID uuid.UUID) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkBatchAccess(ctx, user, group.BatchID); err != nil {
+		return err
+	}
+
+	submitted, err := s.groupRepo.HasSubmissions(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if submitted {
+		return fmt.Errorf("kelompok sudah punya submission, tidak bisa dihapus")
+	}
+	return s.groupRepo.Delete(ctx, groupID)
+}
+
+// GenerateGroups bagi siswa yang belum punya kelompok secara acak
+func (s *StudentGroupService) GenerateGroups(ctx context.Context, user *utils.Claims, batchID uuid.UUID, req *dto.GenerateStudentGroupsRequest) ([]models.StudentGroup, error) {
+	if err := s.checkBatchAccess(ctx, user, batchID); err != nil {
+		return nil, err
+	}
+
+	size := req.GroupSize
+	if size == 0 {
+		size = DefaultGroupSize
+	}
+
+	err := utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		ids, err := s.groupRepo.WithTx(tx).GetUngroupedStudentIDs(ctx, batchID)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("semua siswa sudah punya kelompok")
+		}
+		existing, err := s.groupRepo.WithTx(tx).GetByBatchID(ctx, batchID)
+		if err != nil {
+			return err
+		}
+
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		for i, memberIDs := range SplitIntoGroups(ids, size, rng) {
+			group := models.StudentGroup{
+				BatchID: batchID,
+				Name:    fmt.Sprintf("Kelompok %d", len(existing)+i+1),
+			}
+			if err := s.groupRepo.WithTx(tx).Create(ctx, &group); err != nil {
+				return err
+			}
+			if err := s.groupRepo.WithTx(tx).ReplaceMembers(ctx, &group, memberIDs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetByBatchID(ctx, batchID)
+}
+
+// validateMembers anggota harus siswa lunas di batch, tidak dobel, dan belum ada di kelompok lain
+func (s *StudentGroupService) validateMembers(ctx context.Context, tx *gorm.DB, batchID, groupID uuid.UUID, memberIDs []uuid.UUID) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		if seen[id] {
+			return fmt.Errorf("anggota %s tercantum lebih dari satu kali", id)
+		}
+		seen[id] = true
+	}
+
+	paid, err := s.groupRepo.WithTx(tx).CountPaidStudents(ctx, batchID, memberIDs)
+	if err != nil {
+		return err
+	}
+	if int(paid) != len(memberIDs) {
+		return fmt.Errorf("semua anggota harus siswa yang sudah lunas di batch ini")
+	}
+
+	for _, id := range memberIDs {
+		other, err := s.groupRepo.WithTx(tx).GetByBatchAndUser(ctx, batchID, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if other.ID != groupID {
+			return fmt.Errorf("siswa %s sudah tergabung di %s", id, other.Name)
+		}
+	}
+	return nil
+}
+
+// SplitIntoGroups acak ids lalu bagi rata ke ceil(n/size) kelompok, selisih ukuran antar kelompok paling banyak satu
+func SplitIntoGroups(ids []uuid.UUID, size int, rng *rand.Rand) [][]uuid.UUID {
+	if len(ids) == 0 || size < 1 {
+		return nil
+	}
+
+	shuffled := make([]uuid.UUID, len(ids))
+	copy(shuffled, ids)
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	count := (len(shuffled) + size - 1) / size
+	groups := make([][]uuid.UUID, count)
+	for i, id := range shuffled {
+		groups[i%count] = append(groups[i%count], id)
+	}
+	return groups
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"archive/zip"
+	"brevet-api/models"
+	"brevet-api/utils"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubmissionArchive data ZIP submission satu assignment. Isi file dibaca dari storage saat ditulis.
+type SubmissionArchive struct {
+	Filename    string
+	Submissions []models.AssignmentSubmission
+}
+
+var archiveNameUnsafe = regexp.MustCompile(`[^\p{L}\p{N}._ -]+`)
+
+// GetSubmissionArchive ambil semua submission assignment untuk diunduh sekaligus (guru/admin)
+func (s *SubmissionService) GetSubmissionArchive(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*SubmissionArchive, error) {
+	allowed, err := s.checkUserAccess(ctx, user, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this assignment")
+	}
+
+	submissions, err := s.submissionRepo.GetArchiveByAssignmentID(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(submissions, func(a, b models.AssignmentSubmission) int {
+		return strings.Compare(strings.ToLower(a.User.Name), strings.ToLower(b.User.Name))
+	})
+
+	return &SubmissionArchive{
+		Filename:    fmt.Sprintf("submission_%s.zip", assignmentID.String()),
+		Submissions: submissions,
+	}, nil
+}
+
+// WriteSubmissionArchive tulis ZIP langsung ke w: satu folder per siswa berisi file, essay.txt, note.txt,
+// lalu manifest.csv. File disalin satu per satu sehingga tidak ditampung di memory.
+func WriteSubmissionArchive(w io.Writer, archive *SubmissionArchive) error {
+	zw := zip.NewWriter(w)
+
+	manifest := [][]string{{"No", "Nama", "NIM", "Email", "Folder", "Dikumpulkan", "Telat", "Telat (menit)", "Versi", "Jumlah File", "File Tidak Ditemukan"}}
+	folders := map[string]int{}
+	for i, sub := range archive.Submissions {
+		name, email, nim := sub.User.Name, sub.User.Email, ""
+		if sub.User.Profile != nil && sub.User.Profile.NIM.Valid {
+			nim = sub.User.Profile.NIM.String
+		}
+		folder := uniqueArchiveName(folders, archiveFolderName(name, nim, sub.UserID))
+
+		if err := writeArchiveText(zw, folder+"/essay.txt", sub.EssayText, sub.UpdatedAt); err != nil {
+			return err
+		}
+		if err := writeArchiveText(zw, folder+"/note.txt", sub.Note, sub.UpdatedAt); err != nil {
+			return err
+		}
+
+		var missing []string
+		files := map[string]int{"essay.txt": 1, "note.txt": 1}
+		for _, f := range sub.SubmissionFiles {
+			base := path.Base(f.FileURL)
+			ok, err := copyArchiveFile(zw, folder+"/"+uniqueArchiveName(files, sanitizeArchiveName(base)), f.FileURL, sub.UpdatedAt)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing = append(missing, base)
+			}
+		}
+
+		late := "Tidak"
+		if sub.IsLate {
+			late = "Ya"
+		}
+		manifest = append(manifest, []string{
+			strconv.Itoa(i + 1),
+			name,
+			nim,
+			email,
+			folder,
+			sub.UpdatedAt.Format("2006-01-02 15:04:05"),
+			late,
+			strconv.Itoa(sub.LateMinutes),
+			strconv.Itoa(sub.Version),
+			strconv.Itoa(len(sub.SubmissionFiles) - len(missing)),
+			strings.Join(missing, "; "),
+		})
+
+		// dorong data yang sudah ada ke client per siswa
+		if err := zw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.csv", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(mw)
+	if err := cw.WriteAll(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func archiveFolderName(name, nim string, userID uuid.UUID) string {
+	parts := []string{}
+	if n := sanitizeArchiveName(name); n != "" {
+		parts = append(parts, n)
+	}
+	if n := sanitizeArchiveName(nim); n != "" {
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		return userID.String()
+	}
+	return strings.Join(parts, "_")
+}
+
+func sanitizeArchiveName(name string) string {
+	name = strings.TrimSpace(archiveNameUnsafe.ReplaceAllString(name, "_"))
+	return strings.Trim(name, ". ")
+}
+
+// uniqueArchiveName beri akhiran (2), (3), ... kalau nama sudah dipakai
+func uniqueArchiveName(used map[string]int, name string) string {
+	if name == "" {
+		name = "file"
+	}
+	used[name]++
+	if used[name] == 1 {
+		return name
+	}
+	ext := path.Ext(name)
+	candidate := fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), used[name], ext)
+	used[candidate]++
+	return candidate
+}
+
+func writeArchiveText(zw *zip.Writer, name string, text *string, modified time.Time) error {
+	if text == nil || strings.TrimSpace(*text) == "" {
+		return nil
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, *text)
+	return err
+}
+
+// copyArchiveFile salin file upload lokal ke ZIP; false kalau file tidak ada di storage
+func copyArchiveFile(zw *zip.Writer, name, fileURL string, modified time.Time) (bool, error) {
+	localPath := utils.LocalUploadPath(fileURL)
+	if localPath == "" {
+		return false, nil
+	}
+	src, err := os.Open(localPath)
+	if err != nil {
+		return false, nil
+	}
+	defer src.Close()
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(fw, src); err != nil {
+		return false, err
+	}
+	return true, nil
+}
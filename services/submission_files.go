@@ -0,0 +1,69 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ValidateSubmissionFiles cek file submission terhadap aturan assignment: jumlah, ekstensi, ukuran,
+// dan isi file (sniffing) harus sesuai ekstensinya
+func ValidateSubmissionFiles(assignment *models.Assignment, fileURLs []string) error {
+	if assignment.MinFiles > 0 && len(fileURLs) < assignment.MinFiles {
+		return fmt.Errorf("minimal %d file harus dikumpulkan", assignment.MinFiles)
+	}
+	if assignment.MaxFiles > 0 && len(fileURLs) > assignment.MaxFiles {
+		return fmt.Errorf("maksimal %d file boleh dikumpulkan", assignment.MaxFiles)
+	}
+
+	allowed := assignment.AllowedFileTypes
+	if len(allowed) == 0 {
+		allowed = utils.SubmissionFileExtensions
+	}
+
+	for i, url := range fileURLs {
+		if err := validateSubmissionFile(assignment, allowed, url); err != nil {
+			return fmt.Errorf("file ke-%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateSubmissionFile(assignment *models.Assignment, allowed []string, fileURL string) error {
+	ext := strings.ToLower(filepath.Ext(fileURL))
+	if !slices.Contains(allowed, ext) {
+		return fmt.Errorf("tipe file %s tidak diperbolehkan, hanya %s", ext, strings.Join(allowed, ", "))
+	}
+
+	path := utils.LocalUploadPath(fileURL)
+	if path == "" {
+		return fmt.Errorf("file tidak ditemukan di server, upload ulang file")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if assignment.MaxFileSizeMB > 0 && info.Size() > int64(assignment.MaxFileSizeMB)<<20 {
+		return fmt.Errorf("ukuran file melebihi batas %d MB", assignment.MaxFileSizeMB)
+	}
+
+	detected, err := utils.DetectContentType(f)
+	if err != nil {
+		return err
+	}
+	if !utils.ContentMatchesExtension(path, detected) {
+		return fmt.Errorf("isi file tidak sesuai dengan ekstensi %s (terdeteksi %s)", ext, detected.String())
+	}
+	return nil
+}
@@ -5,16 +5,17 @@ import (
 	"brevet-api/models"
 	"brevet-api/repository"
 	"brevet-api/utils"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"mime/multipart"
-	"strconv"
+	"slices"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/google/uuid"
 	"github.com/jinzhu/copier"
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
@@ -28,8 +29,16 @@ type ISubmissionService interface {
 	DeleteSubmission(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) error
 	GetSubmissionGrade(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) (*models.AssignmentGrade, error)
 	GradeSubmission(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, req *dto.GradeSubmissionRequest) (models.AssignmentGrade, error)
-	GenerateGradesExcel(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*excelize.File, string, error)
-	ImportGradesFromExcel(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, fileHeader *multipart.FileHeader) error
+	ExportGrades(ctx context.Context, user *utils.Claims, scope GradeSheetScope, format string) (*bytes.Buffer, string, error)
+	ImportGrades(ctx context.Context, user *utils.Claims, scope GradeSheetScope, fileHeader *multipart.FileHeader) (*dto.GradeImportResponse, error)
+	GetSubmissionVersions(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) ([]models.SubmissionVersion, error)
+	DiffSubmissionVersions(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, from, to int) (*dto.SubmissionVersionDiffResponse, error)
+	ReturnForRevision(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, body *dto.ReturnSubmissionRequest) (*models.AssignmentSubmission, error)
+	GetSubmissionArchive(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*SubmissionArchive, error)
+	ModerateSubmissionGrade(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, req *dto.ModerateGradeRequest) (*models.AssignmentGrade, error)
+	SubmitGradesForModeration(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (int64, error)
+	ReleaseGrades(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (int64, error)
+	GetModerationProgress(ctx context.Context, batchID uuid.UUID) ([]dto.ModerationProgress, error)
 }
 
 // SubmissionService provides methods for managing submissions
@@ -39,6 +48,7 @@ type SubmissionService struct {
 	meetingRepo     repository.IMeetingRepository
 	attendanceRepo  repository.IAttendanceRepository
 	quizRepo        repository.IQuizRepository
+	rubricRepo      repository.IRubricRepository
 	purchaseService IPurchaseService
 	fileService     IFileService
 	db              *gorm.DB
@@ -47,9 +57,9 @@ type SubmissionService struct {
 // NewSubmissionService creates a new instance of SubmissionService
 func NewSubmissionService(submissionRepo repository.ISubmisssionRepository, assignmentRepo repository.IAssignmentRepository,
 	meetingRepo repository.IMeetingRepository, attendanceRepo repository.IAttendanceRepository,
-	quizRepo repository.IQuizRepository, purchaseService IPurchaseService,
+	quizRepo repository.IQuizRepository, rubricRepo repository.IRubricRepository, purchaseService IPurchaseService,
 	fileService IFileService, db *gorm.DB) ISubmissionService {
-	return &SubmissionService{submissionRepo: submissionRepo, assignmentRepo: assignmentRepo, attendanceRepo: attendanceRepo, quizRepo: quizRepo, meetingRepo: meetingRepo, purchaseService: purchaseService, fileService: fileService, db: db}
+	return &SubmissionService{submissionRepo: submissionRepo, assignmentRepo: assignmentRepo, attendanceRepo: attendanceRepo, quizRepo: quizRepo, rubricRepo: rubricRepo, meetingRepo: meetingRepo, purchaseService: purchaseService, fileService: fileService, db: db}
 }
 
 func (s *SubmissionService) checkUserAccess(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (bool, error) {
@@ -98,7 +108,7 @@ func (s *SubmissionService) GetSubmissionDetail(ctx context.Context, submissionI
 	var submission models.AssignmentSubmission
 	var err error
 
-	if user.Role == string(models.RoleTypeGuru) {
+	if user.Role == string(models.RoleTypeGuru) || user.Role == string(models.RoleTypeAdmin) {
 		submission, err = s.submissionRepo.FindByID(ctx, submissionID)
 	} else {
 		subPtr, err2 := s.submissionRepo.GetByIDUser(ctx, submissionID, user.UserID)
@@ -228,20 +238,44 @@ func (s *SubmissionService) CreateSubmission(
 			return err
 		}
 
-		// --- 4. Simpan submission utama ---
+		// --- 4. Cek deadline sesuai aturan telat assignment ---
+		lateMinutes, err := s.submissionLateness(ctx, tx, assignmentID, user.UserID)
+		if err != nil {
+			return err
+		}
+
+		// --- 4b. Assignment kelompok: submission atas nama kelompok user ---
+		assignment, err := s.assignmentRepo.FindByID(ctx, assignmentID)
+		if err != nil {
+			return err
+		}
+		groupID, err := s.submissionGroup(ctx, assignment, user.UserID)
+		if err != nil {
+			return err
+		}
+
+		// --- 4c. Validasi file sesuai aturan assignment ---
+		if err := ValidateSubmissionFiles(assignment, fileURLs); err != nil {
+			return err
+		}
+
+		// --- 5. Simpan submission utama ---
 		submission = models.AssignmentSubmission{
 			ID:           uuid.New(),
 			AssignmentID: assignmentID,
 			UserID:       user.UserID,
 			Note:         req.Note,
 			EssayText:    req.EssayText,
+			IsLate:       lateMinutes > 0,
+			LateMinutes:  lateMinutes,
+			GroupID:      groupID,
 		}
 
 		if err := s.submissionRepo.WithTx(tx).Create(ctx, &submission); err != nil {
 			return err
 		}
 
-		// --- 5. Simpan file submissions ---
+		// --- 6. Simpan file submissions ---
 		var submissionFiles []models.SubmissionFile
 		for _, url := range fileURLs {
 			submissionFiles = append(submissionFiles, models.SubmissionFile{
@@ -257,7 +291,10 @@ func (s *SubmissionService) CreateSubmission(
 			}
 		}
 
-		return nil
+		// --- 7. Simpan sebagai versi pertama ---
+		submission.Version = 1
+		submission.SubmissionFiles = submissionFiles
+		return s.snapshotVersion(ctx, tx, &submission, false, time.Now())
 	})
 
 	if err != nil {
@@ -273,6 +310,133 @@ func (s *SubmissionService) CreateSubmission(
 	return &submission, nil
 }
 
+// submissionGroup kelompok pengumpul untuk assignment kelompok, nil untuk assignment individu
+func (s *SubmissionService) submissionGroup(ctx context.Context, assignment *models.Assignment, userID uuid.UUID) (*uuid.UUID, error) {
+	if !assignment.GroupSubmission {
+		return nil, nil
+	}
+
+	groupID, err := s.submissionRepo.FindUserGroupID(ctx, assignment.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if groupID == nil {
+		return nil, fmt.Errorf("assignment ini dikerjakan berkelompok, anda belum tergabung dalam kelompok")
+	}
+	return groupID, nil
+}
+
+// snapshotVersion simpan isi submission saat ini sebagai versi submission.Version
+func (s *SubmissionService) snapshotVersion(ctx context.Context, tx *gorm.DB, submission *models.AssignmentSubmission, isRevision bool, createdAt time.Time) error {
+	version := versionFromSubmission(submission)
+	version.IsRevision = isRevision
+	version.CreatedAt = createdAt
+	return s.submissionRepo.WithTx(tx).CreateVersion(ctx, &version)
+}
+
+func versionFromSubmission(submission *models.AssignmentSubmission) models.SubmissionVersion {
+	version := models.SubmissionVersion{
+		AssignmentSubmissionID: submission.ID,
+		Version:                submission.Version,
+		Note:                   submission.Note,
+		EssayText:              submission.EssayText,
+		IsLate:                 submission.IsLate,
+		LateMinutes:            submission.LateMinutes,
+		CreatedAt:              submission.UpdatedAt,
+	}
+	for _, f := range submission.SubmissionFiles {
+		version.Files = append(version.Files, models.SubmissionVersionFile{FileURL: f.FileURL})
+	}
+	return version
+}
+
+// GetSubmissionVersions riwayat versi submission, untuk siswa pemilik & guru pengajar
+func (s *SubmissionService) GetSubmissionVersions(ctx context.Context, user *utils.Claims, submissionID uuid.UUID) ([]models.SubmissionVersion, error) {
+	submission, err := s.GetSubmissionDetail(ctx, submissionID, user)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.submissionRepo.GetVersions(ctx, submission.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		// submission lama: satu-satunya versi adalah isi saat ini
+		versions = append(versions, versionFromSubmission(&submission))
+	}
+	return versions, nil
+}
+
+// DiffSubmissionVersions perbedaan essay antara versi from dan to
+func (s *SubmissionService) DiffSubmissionVersions(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, from, to int) (*dto.SubmissionVersionDiffResponse, error) {
+	versions, err := s.GetSubmissionVersions(ctx, user, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	find := func(n int) (*models.SubmissionVersion, error) {
+		for i := range versions {
+			if versions[i].Version == n {
+				return &versions[i], nil
+			}
+		}
+		return nil, fmt.Errorf("versi %d tidak ditemukan", n)
+	}
+	a, err := find(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := find(to)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SubmissionVersionDiffResponse{
+		From: from,
+		To:   to,
+		Ops:  DiffText(utils.Safe(a.EssayText, ""), utils.Safe(b.EssayText, "")),
+	}, nil
+}
+
+// ReturnForRevision kembalikan submission ke siswa; siswa boleh kumpul ulang walau deadline sudah lewat
+func (s *SubmissionService) ReturnForRevision(ctx context.Context, user *utils.Claims, submissionID uuid.UUID, body *dto.ReturnSubmissionRequest) (*models.AssignmentSubmission, error) {
+	submission, err := s.submissionRepo.FindByID(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.checkUserAccess(ctx, user, submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("forbidden: not teacher of this assignment")
+	}
+	if body.DueAt != nil && !body.DueAt.After(time.Now()) {
+		return nil, fmt.Errorf("batas revisi harus di masa depan")
+	}
+
+	if err := s.submissionRepo.SetRevisionRequest(ctx, submissionID, body.Note, body.DueAt); err != nil {
+		return nil, err
+	}
+	fresh, err := s.submissionRepo.FindByID(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	return &fresh, nil
+}
+
+// submissionLateness menit keterlambatan kalau dikumpulkan sekarang. Error kalau assignment
+// memakai hard deadline atau batas akhir telat sudah lewat.
+func (s *SubmissionService) submissionLateness(ctx context.Context, tx *gorm.DB, assignmentID, userID uuid.UUID) (int, error) {
+	assignment, err := s.assignmentRepo.WithTx(tx).FindByID(ctx, assignmentID)
+	if err != nil {
+		return 0, err
+	}
+	deadline, err := s.submissionRepo.WithTx(tx).GetUserDeadline(ctx, assignmentID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return assignment.LateMinutes(time.Now(), deadline)
+}
+
 func (s *SubmissionService) validateMeetingRules(
 	ctx context.Context,
 	tx *gorm.DB,
@@ -333,6 +497,10 @@ func (s *SubmissionService) validateMeetingRules(
 	}
 
 	for _, q := range prevQuizzes {
+		// latihan & survei tidak jadi prasyarat
+		if !q.Mode.CountsTowardGrade() {
+			continue
+		}
 		subs, err := s.quizRepo.WithTx(tx).GetQuizSubmissionByQuizAndUser(ctx, q.ID, userID)
 		if errors.Is(err, gorm.ErrRecordNotFound) || len(subs) == 0 {
 			return fmt.Errorf("anda belum mengerjakan quiz '%s' di meeting sebelumnya", q.Title)
@@ -369,6 +537,37 @@ func (s *SubmissionService) UpdateSubmission(ctx context.Context, user *utils.Cl
 			return fmt.Errorf("forbidden: user has not purchased this course")
 		}
 
+		// Submission lama (sebelum ada riwayat versi) disimpan dulu sebagai versi pertamanya
+		versions, err := s.submissionRepo.WithTx(tx).GetVersions(ctx, submission.ID)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			if err := s.snapshotVersion(ctx, tx, submission, false, submission.UpdatedAt); err != nil {
+				return err
+			}
+		}
+
+		// Perubahan dihitung sebagai pengumpulan ulang, jadi ikut aturan telat,
+		// kecuali revisi yang diminta guru
+		isRevision := submission.RevisionRequested
+		if isRevision {
+			if submission.RevisionDueAt != nil && time.Now().After(*submission.RevisionDueAt) {
+				return fmt.Errorf("batas waktu revisi sudah lewat")
+			}
+		} else {
+			lateMinutes, err := s.submissionLateness(ctx, tx, submission.AssignmentID, user.UserID)
+			if err != nil {
+				return err
+			}
+			submission.IsLate = lateMinutes > 0
+			submission.LateMinutes = lateMinutes
+		}
+		submission.Version++
+		submission.RevisionRequested = false
+		submission.RevisionNote = nil
+		submission.RevisionDueAt = nil
+
 		// Update data (ignore empty)
 		if err := copier.CopyWithOption(&submission, body, copier.Option{
 			IgnoreEmpty: true,
@@ -383,6 +582,18 @@ func (s *SubmissionService) UpdateSubmission(ctx context.Context, user *utils.Cl
 
 		// Replace files jika dikirim
 		if body.SubmissionFiles != nil {
+			assignment, err := s.assignmentRepo.FindByID(ctx, submission.AssignmentID)
+			if err != nil {
+				return err
+			}
+			fileURLs := make([]string, 0, len(*body.SubmissionFiles))
+			for _, f := range *body.SubmissionFiles {
+				fileURLs = append(fileURLs, f.FileURL)
+			}
+			if err := ValidateSubmissionFiles(assignment, fileURLs); err != nil {
+				return err
+			}
+
 			if err := s.submissionRepo.WithTx(tx).DeleteFilesBySubmissionID(ctx, submission.ID); err != nil {
 				return err
 			}
@@ -407,7 +618,11 @@ func (s *SubmissionService) UpdateSubmission(ctx context.Context, user *utils.Cl
 			return err
 		}
 		updatedSubmission = utils.Safe(&fresh, models.AssignmentSubmission{})
-		return nil
+		// response ke siswa: nilai ujian yang belum dirilis tidak ikut
+		if grade := updatedSubmission.AssignmentGrade; grade != nil && !grade.IsReleased() {
+			updatedSubmission.AssignmentGrade = nil
+		}
+		return s.snapshotVersion(ctx, tx, &fresh, isRevision, time.Now())
 	})
 
 	if err != nil {
@@ -428,6 +643,19 @@ func (s *SubmissionService) DeleteSubmission(ctx context.Context, user *utils.Cl
 		}
 		submission = utils.Safe(submissionRsp, models.AssignmentSubmission{})
 
+		// File versi lama juga ikut dihapus dari storage
+		versions, err := s.submissionRepo.WithTx(tx).GetVersions(ctx, submissionID)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			for _, f := range v.Files {
+				if !slices.ContainsFunc(submission.SubmissionFiles, func(sf models.SubmissionFile) bool { return sf.FileURL == f.FileURL }) {
+					submission.SubmissionFiles = append(submission.SubmissionFiles, models.SubmissionFile{FileURL: f.FileURL})
+				}
+			}
+		}
+
 		// Cek pembayaran
 		batchID, err := s.assignmentRepo.GetBatchIDByAssignmentID(ctx, submission.AssignmentID)
 		if err != nil {
@@ -449,16 +677,18 @@ func (s *SubmissionService) DeleteSubmission(ctx context.Context, user *utils.Cl
 		return nil
 	})
 
+	if err != nil {
+		return err
+	}
+
 	// Setelah commit, hapus file di storage
-	if len(submission.SubmissionFiles) > 0 {
-		for _, f := range submission.SubmissionFiles {
-			if err := s.fileService.DeleteFile(f.FileURL); err != nil {
-				log.Errorf("Failed to delete file %s: %v", f.FileURL, err)
-			}
+	for _, f := range submission.SubmissionFiles {
+		if err := s.fileService.DeleteFile(f.FileURL); err != nil {
+			log.Errorf("Failed to delete file %s: %v", f.FileURL, err)
 		}
 	}
 
-	return err
+	return nil
 }
 
 // GetSubmissionGrade for get submission grade
@@ -476,7 +706,7 @@ func (s *SubmissionService) GetSubmissionGrade(ctx context.Context, user *utils.
 		return nil, fmt.Errorf("forbidden: no access to this assignment")
 	}
 
-	if user.Role == string(models.RoleTypeSiswa) && submission.UserID != user.UserID {
+	if user.Role == string(models.RoleTypeSiswa) && !submission.IsOwnedBy(user.UserID) {
 		return nil, fmt.Errorf("forbidden: not your submission")
 	}
 
@@ -485,6 +715,20 @@ func (s *SubmissionService) GetSubmissionGrade(ctx context.Context, user *utils.
 		return nil, err
 	}
 
+	// Siswa hanya melihat nilai yang sudah dirilis & penyesuaian nilainya sendiri
+	if user.Role == string(models.RoleTypeSiswa) {
+		if !grade.IsReleased() {
+			return nil, gorm.ErrRecordNotFound
+		}
+		own := make([]models.AssignmentGradeAdjustment, 0, 1)
+		for _, adj := range grade.Adjustments {
+			if adj.UserID == user.UserID {
+				own = append(own, adj)
+			}
+		}
+		grade.Adjustments = own
+	}
+
 	return grade, nil
 }
 
@@ -504,15 +748,81 @@ func (s *SubmissionService) GradeSubmission(ctx context.Context, user *utils.Cla
 		return models.AssignmentGrade{}, fmt.Errorf("forbidden: not teacher of this assignment")
 	}
 
+	// Nilai ujian dimoderasi penilai kedua sebelum dirilis
+	exam, err := s.isExamAssignment(ctx, &submission.Assignment)
+	if err != nil {
+		return models.AssignmentGrade{}, err
+	}
+	if exam {
+		if err := CanRegrade(submission.AssignmentGrade, user.Role); err != nil {
+			return models.AssignmentGrade{}, err
+		}
+	}
+
 	gradeModel := models.AssignmentGrade{
 		AssignmentSubmissionID: submission.ID,
 		Grade:                  req.Grade,
 		Feedback:               req.Feedback,
 		GradedBy:               user.UserID,
+		ModerationStatus:       initialModerationStatus(exam, req.Submit),
 	}
 
-	// Upsert nilai
-	if _, err := s.submissionRepo.UpsertGrade(ctx, gradeModel); err != nil {
+	// Assignment dengan rubrik: nilai dihitung dari level yang dipilih per kriteria
+	rubric, err := s.assignmentRubric(ctx, &submission.Assignment)
+	if err != nil {
+		return models.AssignmentGrade{}, err
+	}
+	var criteria []models.AssignmentGradeCriterion
+	if rubric != nil {
+		if len(req.Criteria) == 0 {
+			return models.AssignmentGrade{}, fmt.Errorf("assignment ini dinilai dengan rubrik, criteria wajib diisi")
+		}
+		var points, maxPoints int
+		criteria, points, maxPoints, err = ScoreRubric(rubric, req.Criteria)
+		if err != nil {
+			return models.AssignmentGrade{}, err
+		}
+		applyRubricScore(&gradeModel, rubric.ID, points, maxPoints)
+	} else if len(req.Criteria) > 0 {
+		return models.AssignmentGrade{}, fmt.Errorf("assignment ini tidak memakai rubrik")
+	}
+	// Nilai untuk versi tertentu, potongan telat ikut versi tersebut
+	version := submission.Version
+	if req.Version != nil {
+		version = *req.Version
+	}
+	lateMinutes := submission.LateMinutes
+	graded, err := s.submissionRepo.GetVersion(ctx, submission.ID, version)
+	if err == nil {
+		lateMinutes = graded.LateMinutes
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.AssignmentGrade{}, err
+	} else if version != submission.Version {
+		// submission lama tanpa riwayat hanya punya versi terbaru
+		return models.AssignmentGrade{}, fmt.Errorf("versi %d tidak ditemukan", version)
+	}
+	gradeModel.Version = &version
+	adjustments, err := gradeAdjustments(&submission, req.Adjustments)
+	if err != nil {
+		return models.AssignmentGrade{}, err
+	}
+	if err := blendPeerScore(ctx, s.submissionRepo, &gradeModel, &submission.Assignment); err != nil {
+		return models.AssignmentGrade{}, err
+	}
+	applyLatePenalty(&gradeModel, &submission.Assignment, lateMinutes)
+
+	// Upsert nilai beserta rincian rubrik
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		saved, err := s.submissionRepo.WithTx(tx).UpsertGrade(ctx, gradeModel)
+		if err != nil {
+			return err
+		}
+		if err := s.submissionRepo.WithTx(tx).ReplaceGradeCriteria(ctx, saved.ID, criteria); err != nil {
+			return err
+		}
+		return s.submissionRepo.WithTx(tx).ReplaceGradeAdjustments(ctx, saved.ID, adjustments)
+	})
+	if err != nil {
 		return models.AssignmentGrade{}, err
 	}
 
@@ -525,126 +835,268 @@ func (s *SubmissionService) GradeSubmission(ctx context.Context, user *utils.Cla
 	return *grade, nil
 }
 
-// GenerateGradesExcel services
-func (s *SubmissionService) GenerateGradesExcel(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID) (*excelize.File, string, error) {
-	// Cek akses guru
-	allowed, err := s.checkUserAccess(ctx, user, assignmentID)
-	if err != nil {
-		return nil, "", err
+// gradeAdjustments validasi penyesuaian nilai individu: hanya untuk submission kelompok dan anggotanya
+func gradeAdjustments(submission *models.AssignmentSubmission, reqs []dto.GradeAdjustmentRequest) ([]models.AssignmentGradeAdjustment, error) {
+	if len(reqs) == 0 {
+		return nil, nil
 	}
-	if !allowed {
-		return nil, "", fmt.Errorf("forbidden: not teacher of this assignment")
+	if submission.GroupID == nil {
+		return nil, fmt.Errorf("penyesuaian nilai hanya untuk submission kelompok")
 	}
 
-	// Ambil data submission + grade
-	submissions, err := s.submissionRepo.GetGradesByAssignmentID(ctx, assignmentID)
-	if err != nil {
-		return nil, "", err
+	seen := make(map[uuid.UUID]bool, len(reqs))
+	adjustments := make([]models.AssignmentGradeAdjustment, 0, len(reqs))
+	for _, r := range reqs {
+		if !submission.IsOwnedBy(r.UserID) {
+			return nil, fmt.Errorf("user %s bukan anggota kelompok", r.UserID)
+		}
+		if seen[r.UserID] {
+			return nil, fmt.Errorf("penyesuaian untuk user %s lebih dari satu", r.UserID)
+		}
+		seen[r.UserID] = true
+		if r.Points == 0 {
+			continue
+		}
+		adjustments = append(adjustments, models.AssignmentGradeAdjustment{
+			UserID: r.UserID,
+			Points: r.Points,
+			Reason: r.Reason,
+		})
 	}
+	return adjustments, nil
+}
+
+// assignmentRubric rubrik yang terpasang di assignment, nil kalau dinilai biasa
+func (s *SubmissionService) assignmentRubric(ctx context.Context, assignment *models.Assignment) (*models.Rubric, error) {
+	if assignment.RubricID == nil {
+		return nil, nil
+	}
+	return s.rubricRepo.GetRubricByID(ctx, *assignment.RubricID)
+}
+
+func applyRubricScore(grade *models.AssignmentGrade, rubricID uuid.UUID, points, maxPoints int) {
+	grade.Grade = RubricGrade(points, maxPoints)
+	grade.RubricID = &rubricID
+	grade.RubricPoints = &points
+	grade.RubricMaxPoints = &maxPoints
+}
+
+// applyLatePenalty isi potongan telat dari nilai campuran guru & peer, nilai mentah tetap disimpan di Grade
+func applyLatePenalty(grade *models.AssignmentGrade, assignment *models.Assignment, lateMinutes int) {
+	penalty := assignment.LatePenalty(lateMinutes)
+	penalized := assignment.ApplyLatePenalty(grade.BlendedGrade(), penalty)
+	grade.LatePenaltyPercent = penalty
+	grade.PenalizedGrade = &penalized
+}
+
+// GradeSheetScope cakupan spreadsheet nilai: satu assignment, semua assignment meeting, atau semua assignment batch
+type GradeSheetScope struct {
+	Kind string
+	ID   uuid.UUID
+}
 
-	// Buat file Excel
-	f := excelize.NewFile()
-	sheet := "Penilaian"
-	f.SetSheetName("Sheet1", sheet)
+// Jenis cakupan spreadsheet nilai
+const (
+	GradeScopeAssignment = "assignment"
+	GradeScopeMeeting    = "meeting"
+	GradeScopeBatch      = "batch"
+)
+
+// gradeTargets assignment dalam cakupan beserta rubrik & batch-nya, sekaligus cek akses guru
+func (s *SubmissionService) gradeTargets(ctx context.Context, user *utils.Claims, scope GradeSheetScope) ([]GradeTarget, uuid.UUID, error) {
+	var assignments []models.Assignment
+	switch scope.Kind {
+	case GradeScopeAssignment:
+		assignment, err := s.assignmentRepo.FindByID(ctx, scope.ID)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		assignments = []models.Assignment{*assignment}
+	case GradeScopeMeeting:
+		var err error
+		if assignments, err = s.assignmentRepo.GetAllByMeetingID(ctx, scope.ID); err != nil {
+			return nil, uuid.Nil, err
+		}
+	case GradeScopeBatch:
+		var err error
+		if assignments, err = s.assignmentRepo.GetAllByBatchID(ctx, scope.ID); err != nil {
+			return nil, uuid.Nil, err
+		}
+	default:
+		return nil, uuid.Nil, fmt.Errorf("cakupan %q tidak dikenal", scope.Kind)
+	}
+	if len(assignments) == 0 {
+		return nil, uuid.Nil, fmt.Errorf("tidak ada assignment untuk dinilai")
+	}
 
-	// Header
-	headers := []string{"UserID", "No", "Nama Siswa", "Nilai", "Feedback"}
-	for i, h := range headers {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		f.SetCellValue(sheet, cell, h)
+	// Semua assignment dalam cakupan ada di batch yang sama
+	allowed, err := s.checkUserAccess(ctx, user, assignments[0].ID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if !allowed {
+		return nil, uuid.Nil, fmt.Errorf("forbidden: not teacher of this assignment")
+	}
+	batchID, err := s.assignmentRepo.GetBatchIDByAssignmentID(ctx, assignments[0].ID)
+	if err != nil {
+		return nil, uuid.Nil, err
 	}
 
-	// Isi data
-	for i, sub := range submissions {
-		no := i + 1
-		userID := sub.UserID.String()
-		name := sub.User.Name
-		grade := ""
-		feedback := ""
-		if sub.AssignmentGrade != nil {
-			grade = fmt.Sprintf("%d", sub.AssignmentGrade.Grade)
-			feedback = sub.AssignmentGrade.Feedback
+	targets := make([]GradeTarget, len(assignments))
+	for i := range assignments {
+		rubric, err := s.assignmentRubric(ctx, &assignments[i])
+		if err != nil {
+			return nil, uuid.Nil, err
 		}
-		row := i + 2
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), userID) // hidden column
-		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), no)
-		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), name)
-		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), grade)
-		f.SetCellValue(sheet, fmt.Sprintf("E%d", row), feedback)
+		targets[i] = GradeTarget{Assignment: &assignments[i], Rubric: rubric}
 	}
+	return targets, batchID, nil
+}
 
-	// Sembunyikan kolom UserID
-	f.SetColVisible(sheet, "A", false)
+// ExportGrades spreadsheet nilai. XLSX: satu sheet per assignment, CSV: satu kolom per assignment.
+func (s *SubmissionService) ExportGrades(ctx context.Context, user *utils.Claims, scope GradeSheetScope, format string) (*bytes.Buffer, string, error) {
+	if format != "xlsx" && format != "csv" {
+		return nil, "", fmt.Errorf("format %q tidak didukung, gunakan xlsx atau csv", format)
+	}
+	targets, _, err := s.gradeTargets(ctx, user, scope)
+	if err != nil {
+		return nil, "", err
+	}
 
-	// Nama file
-	filename := fmt.Sprintf("penilaian_%s.xlsx", assignmentID.String())
+	data := make([]GradeExportData, 0, len(targets))
+	for _, t := range targets {
+		submissions, err := s.submissionRepo.GetGradesByAssignmentID(ctx, t.Assignment.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		data = append(data, GradeExportData{GradeTarget: t, Submissions: submissions})
+	}
 
-	return f, filename, nil
+	var buf bytes.Buffer
+	wide := format == "csv" && len(data) > 1
+	if err := WriteGradeSheets(&buf, BuildGradeSheets(data, wide), format); err != nil {
+		return nil, "", err
+	}
+	return &buf, fmt.Sprintf("penilaian_%s.%s", scope.ID, format), nil
 }
 
-// ImportGradesFromExcel excel
-func (s *SubmissionService) ImportGradesFromExcel(ctx context.Context, user *utils.Claims, assignmentID uuid.UUID, fileHeader *multipart.FileHeader) error {
-	// Cek akses guru
-	allowed, err := s.checkUserAccess(ctx, user, assignmentID)
+// ImportGrades baca spreadsheet nilai (XLSX/CSV) berdasarkan header. Siswa dicocokkan lewat UserID, NIM, atau email.
+// Semua baris divalidasi dulu, lalu nilai disimpan dalam satu transaksi.
+func (s *SubmissionService) ImportGrades(ctx context.Context, user *utils.Claims, scope GradeSheetScope, fileHeader *multipart.FileHeader) (*dto.GradeImportResponse, error) {
+	targets, batchID, err := s.gradeTargets(ctx, user, scope)
 	if err != nil {
-		return err
-	}
-	if !allowed {
-		return fmt.Errorf("forbidden: not teacher of this assignment")
+		return nil, err
 	}
 
-	// Buka file Excel
 	file, err := fileHeader.Open()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
-
-	f, err := excelize.OpenReader(file)
+	sheets, err := ReadGradeSheets(fileHeader.Filename, file)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var entries []GradeSheetEntry
+	for _, sheet := range sheets {
+		parsed, err := ParseGradeSheet(sheet, targets)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, parsed...)
 	}
 
-	sheetName := f.GetSheetName(0)
-	rows, err := f.GetRows(sheetName)
+	students, err := s.submissionRepo.GetStudentsByBatchID(ctx, batchID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	roster := NewStudentRoster(students)
 
-	// Loop data mulai dari row 2 (skip header)
-	for i := 1; i < len(rows); i++ {
-		if len(rows[i]) < 5 {
-			continue // skip kalau kolom tidak lengkap
+	// Nilai ujian hasil import masuk sebagai draft
+	exams := make(map[uuid.UUID]bool, len(targets))
+	for _, t := range targets {
+		exam, err := s.isExamAssignment(ctx, t.Assignment)
+		if err != nil {
+			return nil, err
 		}
+		exams[t.Assignment.ID] = exam
+	}
 
-		userIDStr := rows[i][0] // hidden column
-		gradeStr := rows[i][3]
-		feedback := rows[i][4]
+	type gradeWrite struct {
+		grade    models.AssignmentGrade
+		criteria []models.AssignmentGradeCriterion
+	}
+	var writes []gradeWrite
+	result := &dto.GradeImportResponse{}
+	for _, e := range entries {
+		rowErr := func(err error) error {
+			return fmt.Errorf("sheet %q baris %d: %v", e.Sheet, e.Row, err)
+		}
+		assignment := e.Target.Assignment
 
-		userID, err := uuid.Parse(userIDStr)
+		userID, err := roster.Resolve(e.Student)
 		if err != nil {
-			return fmt.Errorf("row %d: invalid UserID", i+1)
+			return nil, rowErr(err)
+		}
+		submission, err := s.submissionRepo.FindByAssignmentAndUserID(ctx, assignment.ID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, rowErr(fmt.Errorf("siswa belum mengumpulkan '%s'", assignment.Title))
+		} else if err != nil {
+			return nil, rowErr(err)
 		}
 
-		grade, _ := strconv.Atoi(gradeStr)
-
-		// Cari submission ID berdasarkan assignmentID + userID
-		submission, err := s.submissionRepo.FindByAssignmentAndUserID(ctx, assignmentID, userID)
-		if err != nil {
-			return fmt.Errorf("row %d: %v", i+1, err)
+		// Nilai ujian yang sudah dimoderasi / dirilis tidak ditimpa lewat import
+		exam := exams[assignment.ID]
+		if exam {
+			existing, err := s.submissionRepo.GetGradeBySubmissionID(ctx, submission.ID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, rowErr(err)
+			}
+			if err == nil && CanRegrade(existing, user.Role) != nil {
+				result.Skipped++
+				continue
+			}
 		}
 
-		// Upsert nilai
 		gradeModel := models.AssignmentGrade{
 			AssignmentSubmissionID: submission.ID,
-			Grade:                  grade,
-			Feedback:               feedback,
+			Grade:                  e.Grade,
+			Feedback:               e.Feedback,
 			GradedBy:               user.UserID,
+			ModerationStatus:       initialModerationStatus(exam, false),
+		}
+		var criteria []models.AssignmentGradeCriterion
+		if e.Target.Rubric != nil {
+			var points, maxPoints int
+			criteria, points, maxPoints, err = ScoreRubric(e.Target.Rubric, e.Selections)
+			if err != nil {
+				return nil, rowErr(err)
+			}
+			applyRubricScore(&gradeModel, e.Target.Rubric.ID, points, maxPoints)
 		}
-		if _, err := s.submissionRepo.UpsertGrade(ctx, gradeModel); err != nil {
-			return fmt.Errorf("row %d: %v", i+1, err)
+		gradeModel.Version = &submission.Version
+		if err := blendPeerScore(ctx, s.submissionRepo, &gradeModel, assignment); err != nil {
+			return nil, rowErr(err)
 		}
+		applyLatePenalty(&gradeModel, assignment, submission.LateMinutes)
+		writes = append(writes, gradeWrite{grade: gradeModel, criteria: criteria})
 	}
 
-	return nil
+	err = utils.WithTransaction(s.db, func(tx *gorm.DB) error {
+		for _, w := range writes {
+			saved, err := s.submissionRepo.WithTx(tx).UpsertGrade(ctx, w.grade)
+			if err != nil {
+				return err
+			}
+			if err := s.submissionRepo.WithTx(tx).ReplaceGradeCriteria(ctx, saved.ID, w.criteria); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(writes)
+	return result, nil
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TextDiffMaxEdits batas jumlah perubahan yang dicari; lebih dari ini teks dianggap diganti seluruhnya
+const TextDiffMaxEdits = 2000
+
+const (
+	// TextDiffEqual potongan yang sama di kedua versi
+	TextDiffEqual = "equal"
+	// TextDiffInsert potongan yang hanya ada di versi baru
+	TextDiffInsert = "insert"
+	// TextDiffDelete potongan yang hanya ada di versi lama
+	TextDiffDelete = "delete"
+)
+
+// kata dan spasi jadi token terpisah supaya teks bisa disusun ulang persis
+var diffTokenPattern = regexp.MustCompile(`\s+|\S+`)
+
+type diffEdit struct {
+	op    string
+	token string
+}
+
+// DiffText diff per kata antara dua teks (algoritma Myers). Potongan berurutan dengan op yang sama digabung.
+func DiffText(a, b string) []dto.TextDiffOp {
+	x := diffTokenPattern.FindAllString(a, -1)
+	y := diffTokenPattern.FindAllString(b, -1)
+
+	// buang awalan & akhiran yang sama dulu, biasanya sebagian besar teks
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	ops := []dto.TextDiffOp{}
+	add := func(op, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text
+			return
+		}
+		ops = append(ops, dto.TextDiffOp{Op: op, Text: text})
+	}
+
+	add(TextDiffEqual, strings.Join(x[:prefix], ""))
+	for _, e := range myersDiff(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]) {
+		add(e.op, e.token)
+	}
+	add(TextDiffEqual, strings.Join(x[len(x)-suffix:], ""))
+	return ops
+}
+
+func myersDiff(x, y []string) []diffEdit {
+	n, m := len(x), len(y)
+	limit := min(n+m, TextDiffMaxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] = v setelah langkah d, hanya diagonal k di [-d, d]
+	var trace [][]int
+	found := -1
+	for d := 0; d <= limit && found < 0; d++ {
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				px = v[offset+k+1]
+			} else {
+				px = v[offset+k-1] + 1
+			}
+			py := px - k
+			for px < n && py < m && x[px] == y[py] {
+				px++
+				py++
+			}
+			v[offset+k] = px
+			if px >= n && py >= m {
+				found = d
+			}
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+	}
+
+	if found < 0 {
+		edits := make([]diffEdit, 0, n+m)
+		for _, t := range x {
+			edits = append(edits, diffEdit{op: TextDiffDelete, token: t})
+		}
+		for _, t := range y {
+			edits = append(edits, diffEdit{op: TextDiffInsert, token: t})
+		}
+		return edits
+	}
+
+	// telusuri balik dari ujung ke awal
+	var edits []diffEdit
+	px, py := n, m
+	for d := found; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := px - py
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for px > prevX && py > prevY {
+			px--
+			py--
+			edits = append(edits, diffEdit{op: TextDiffEqual, token: x[px]})
+		}
+		if px == prevX {
+			py--
+			edits = append(edits, diffEdit{op: TextDiffInsert, token: y[py]})
+		} else {
+			px--
+			edits = append(edits, diffEdit{op: TextDiffDelete, token: x[px]})
+		}
+	}
+	for px > 0 && py > 0 {
+		px--
+		py--
+		edits = append(edits, diffEdit{op: TextDiffEqual, token: x[px]})
+	}
+	slices.Reverse(edits)
+	return edits
+}
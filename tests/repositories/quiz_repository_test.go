@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"brevet-api/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAttemptScoresByBatchUser_OnlyReleasedReviews(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewQuizRepository(db)
+	batchID, userID := uuid.New(), uuid.New()
+
+	// skor hanya diambil dari quiz yang review-nya sudah dibuka (immediate / manual / after_end)
+	mock.ExpectQuery(`SELECT qa.quiz_id, qa.user_id, qa.started_at, qr.score_percent FROM quiz_attempts qa .*`+
+		`q.review_release = 'immediate'.*q.review_released_at <= \$4.*quiz_accommodations.*<= \$5`).
+		WithArgs(batchID, userID, "graded", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"quiz_id", "user_id", "started_at", "score_percent"}))
+
+	rows, err := repo.GetAttemptScoresByBatchUser(context.Background(), batchID, userID, true)
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAttemptScoresByBatchUser_TeacherViewIncludesUnreleased(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewQuizRepository(db)
+	batchID, userID := uuid.New(), uuid.New()
+
+	// guru/admin tidak dibatasi aturan rilis review
+	mock.ExpectQuery(`SELECT qa.quiz_id, qa.user_id, qa.started_at, qr.score_percent FROM quiz_attempts qa .*`+
+		`q.mode = \$3 ORDER BY qa.started_at ASC`).
+		WithArgs(batchID, userID, "graded").
+		WillReturnRows(sqlmock.NewRows([]string{"quiz_id", "user_id", "started_at", "score_percent"}).
+			AddRow(uuid.New(), userID, time.Now(), 80.0))
+
+	rows, err := repo.GetAttemptScoresByBatchUser(context.Background(), batchID, userID, false)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReleasedPendingRegradeChanges_LocksReleasedPendingRows(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewQuizRepository(db)
+
+	// hanya perubahan tertunda dari quiz yang review-nya sudah dibuka, dikunci supaya tidak dikirim dua kali
+	mock.ExpectQuery(`SELECT "quiz_regrade_changes".* FROM "quiz_regrade_changes" JOIN quiz_regrade_logs rl .*JOIN quizzes q .*`+
+		`quiz_regrade_changes.pending_notice = \$1.*q.review_released_at <= \$2.*<= \$3.*`+
+		`ORDER BY quiz_regrade_changes.created_at ASC FOR UPDATE OF "quiz_regrade_changes" SKIP LOCKED`).
+		WithArgs(true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	changes, err := repo.GetReleasedPendingRegradeChanges(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
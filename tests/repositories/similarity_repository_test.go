@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"brevet-api/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailStaleReports(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewSimilarityRepository(db)
+	before := time.Now().Add(-time.Hour)
+
+	// laporan pending / running yang terputus ditandai failed supaya tidak memblokir cek baru
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "similarity_reports" SET .*"status"=.*WHERE status IN \(\$\d+,\$\d+\) AND COALESCE\(started_at, created_at\) < \$\d+`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "failed", sqlmock.AnyArg(), "pending", "running", before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	n, err := repo.FailStaleReports(context.Background(), before, "terputus")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"brevet-api/repository"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAssignmentsWithScoresByBatchUser_ReleaseFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		releasedOnly bool
+		gradeJoin    string
+	}{
+		// siswa hanya melihat nilai yang sudah dirilis
+		{"student", true, `ag.assignment_submission_id = s.id AND ag.moderation_status = 'released' LEFT JOIN assignment_grade_adjustments`},
+		// guru/admin tetap melihat nilai draft/submitted/moderated
+		{"teacher", false, `ag.assignment_submission_id = s.id LEFT JOIN assignment_grade_adjustments`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			repo := repository.NewSubmissionRepository(db)
+			batchID, userID := uuid.New(), uuid.New()
+
+			mock.ExpectQuery(`SELECT assignments\.\*.* FROM "assignments" .*`+tt.gradeJoin).
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), batchID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "score"}))
+
+			rows, err := repo.GetAssignmentsWithScoresByBatchUser(context.Background(), batchID, userID, tt.releasedOnly)
+			assert.NoError(t, err)
+			assert.Empty(t, rows)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
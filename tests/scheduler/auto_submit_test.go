@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"brevet-api/scheduler"
+	"brevet-api/services"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDeadlineQueue mengembalikan batch yang sudah disiapkan secara berurutan
+type fakeDeadlineQueue struct {
+	services.IQuizDeadlineQueue
+	batches   [][]uuid.UUID
+	pops      int
+	scheduled []uuid.UUID
+}
+
+func (q *fakeDeadlineQueue) PopDue(_ context.Context, _ time.Time, _ int) ([]uuid.UUID, error) {
+	if q.pops >= len(q.batches) {
+		return nil, nil
+	}
+	ids := q.batches[q.pops]
+	q.pops++
+	return ids, nil
+}
+
+func (q *fakeDeadlineQueue) Schedule(_ context.Context, attemptID uuid.UUID, _ time.Time) error {
+	q.scheduled = append(q.scheduled, attemptID)
+	return nil
+}
+
+// fakeQuizService mencatat attempt yang diproses, errors menentukan hasil per attempt
+type fakeQuizService struct {
+	services.IQuizService
+	errors  map[uuid.UUID]error
+	handled []uuid.UUID
+}
+
+func (s *fakeQuizService) HandleAttemptDeadline(_ context.Context, attemptID uuid.UUID) error {
+	s.handled = append(s.handled, attemptID)
+	return s.errors[attemptID]
+}
+
+func newIDs(n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	return ids
+}
+
+func renewCounter(results ...bool) (func(context.Context) (bool, error), *int) {
+	calls := 0
+	return func(context.Context) (bool, error) {
+		ok := results[calls]
+		calls++
+		return ok, nil
+	}, &calls
+}
+
+func TestProcessDueAttempts_RequeuesOnlyRetryableErrors(t *testing.T) {
+	ids := newIDs(3)
+	queue := &fakeDeadlineQueue{batches: [][]uuid.UUID{ids}}
+	svc := &fakeQuizService{errors: map[uuid.UUID]error{
+		ids[0]: services.ErrAttemptNotFound,
+		ids[1]: errors.New("database down"),
+	}}
+	renew, calls := renewCounter()
+
+	leader := scheduler.ProcessDueAttempts(context.Background(), svc, queue, renew)
+
+	assert.True(t, leader)
+	assert.Equal(t, ids, svc.handled)
+	assert.Equal(t, []uuid.UUID{ids[1]}, queue.scheduled)
+	assert.Zero(t, *calls, "batch tidak penuh, lease tidak perlu diperpanjang")
+}
+
+func TestProcessDueAttempts_RenewsLeaseBetweenFullBatches(t *testing.T) {
+	first, second := newIDs(100), newIDs(2)
+	queue := &fakeDeadlineQueue{batches: [][]uuid.UUID{first, second}}
+	svc := &fakeQuizService{}
+	renew, calls := renewCounter(true)
+
+	leader := scheduler.ProcessDueAttempts(context.Background(), svc, queue, renew)
+
+	assert.True(t, leader)
+	assert.Equal(t, 1, *calls)
+	assert.Len(t, svc.handled, 102)
+	assert.Equal(t, 2, queue.pops)
+}
+
+func TestProcessDueAttempts_StopsWhenLeaseLost(t *testing.T) {
+	queue := &fakeDeadlineQueue{batches: [][]uuid.UUID{newIDs(100), newIDs(100)}}
+	svc := &fakeQuizService{}
+	renew, calls := renewCounter(false)
+
+	leader := scheduler.ProcessDueAttempts(context.Background(), svc, queue, renew)
+
+	assert.False(t, leader)
+	assert.Equal(t, 1, *calls)
+	assert.Len(t, svc.handled, 100)
+	assert.Equal(t, 1, queue.pops, "batch berikutnya tidak boleh diambil tanpa lease")
+}
+
+func TestProcessDueAttempts_StopsWhenRenewFails(t *testing.T) {
+	queue := &fakeDeadlineQueue{batches: [][]uuid.UUID{newIDs(100), newIDs(1)}}
+	svc := &fakeQuizService{}
+	renew := func(context.Context) (bool, error) { return true, errors.New("redis timeout") }
+
+	leader := scheduler.ProcessDueAttempts(context.Background(), svc, queue, renew)
+
+	assert.False(t, leader)
+	assert.Equal(t, 1, queue.pops)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAccommodationQuizRepo struct {
+	repository.IQuizRepository
+}
+
+func (fakeAccommodationQuizRepo) GetQuizByID(_ context.Context, quizID uuid.UUID) (*models.Quiz, error) {
+	return &models.Quiz{ID: quizID, MeetingID: uuid.New()}, nil
+}
+
+type fakeAccommodationAssignmentRepo struct {
+	repository.IAssignmentRepository
+}
+
+func (fakeAccommodationAssignmentRepo) FindByID(_ context.Context, id uuid.UUID) (*models.Assignment, error) {
+	return &models.Assignment{ID: id, MeetingID: uuid.New(), EndAt: time.Now()}, nil
+}
+
+type fakeAccommodationUserRepo struct {
+	repository.IUserRepository
+}
+
+func (fakeAccommodationUserRepo) FindByID(_ context.Context, userID uuid.UUID) (*models.User, error) {
+	return &models.User{ID: userID, RoleType: models.RoleTypeSiswa}, nil
+}
+
+type fakeAccommodationBatchRepo struct {
+	repository.IBatchRepository
+}
+
+func (fakeAccommodationBatchRepo) GetBatchByMeetingID(_ context.Context, _ uuid.UUID) (models.Batch, error) {
+	return models.Batch{ID: uuid.New()}, nil
+}
+
+// fakeUnpaidPurchaseService siswa tidak pernah membeli batch
+type fakeUnpaidPurchaseService struct {
+	services.IPurchaseService
+}
+
+func (fakeUnpaidPurchaseService) HasPaid(_ context.Context, _, _ uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func TestAccommodation_RejectsStudentOutsideBatch(t *testing.T) {
+	ctx := context.Background()
+	admin := &utils.Claims{UserID: uuid.New(), Role: string(models.RoleTypeAdmin)}
+	// repo accommodation nil: kalau lolos validasi, penyimpanan akan panic
+	svc := services.NewAccommodationService(nil, fakeAccommodationQuizRepo{}, fakeAccommodationAssignmentRepo{}, nil,
+		fakeAccommodationBatchRepo{}, fakeAccommodationUserRepo{}, fakeUnpaidPurchaseService{}, nil)
+
+	end := time.Now().Add(48 * time.Hour)
+	_, err := svc.UpsertQuizAccommodation(ctx, admin, uuid.New(), &dto.UpsertQuizAccommodationRequest{UserID: uuid.New(), EndTime: &end})
+	assert.ErrorContains(t, err, "bukan peserta batch")
+
+	_, err = svc.UpsertAssignmentExtension(ctx, admin, uuid.New(), &dto.UpsertAssignmentExtensionRequest{UserID: uuid.New(), EndAt: end})
+	assert.ErrorContains(t, err, "bukan peserta batch")
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/phpdave11/gofpdf/contrib/gofpdi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAnnotation(t *testing.T) {
+	comment := "perhitungan PPh salah"
+
+	box := models.SubmissionAnnotation{Type: models.AnnotationBox, X: 0.1, Y: 0.2, Width: 0.5, Height: 0.1}
+	assert.NoError(t, services.ValidateAnnotation(&box))
+
+	empty := models.SubmissionAnnotation{Type: models.AnnotationHighlight, X: 0.1, Y: 0.2}
+	assert.Error(t, services.ValidateAnnotation(&empty))
+
+	overflow := models.SubmissionAnnotation{Type: models.AnnotationBox, X: 0.8, Y: 0.2, Width: 0.3, Height: 0.1}
+	assert.ErrorContains(t, services.ValidateAnnotation(&overflow), "batas halaman")
+
+	note := models.SubmissionAnnotation{Type: models.AnnotationNote, X: 0.5, Y: 0.5, Width: 0.2, Comment: &comment}
+	assert.NoError(t, services.ValidateAnnotation(&note))
+	assert.Zero(t, note.Width)
+
+	silent := models.SubmissionAnnotation{Type: models.AnnotationNote, X: 0.5, Y: 0.5}
+	assert.ErrorContains(t, services.ValidateAnnotation(&silent), "comment")
+}
+
+func TestWriteAnnotatedPDF_FlattensPagesAndAddsNotes(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "jawaban.pdf")
+	doc := gofpdf.New("P", "mm", "A4", "")
+	doc.SetFont("Arial", "", 12)
+	for i := 0; i < 2; i++ {
+		doc.AddPage()
+		doc.Cell(40, 10, "Jawaban siswa")
+	}
+	require.NoError(t, doc.OutputFileAndClose(src))
+
+	comment := "kurang lengkap"
+	annotations := []models.SubmissionAnnotation{
+		{Page: 2, Type: models.AnnotationHighlight, X: 0.1, Y: 0.1, Width: 0.4, Height: 0.05, Comment: &comment},
+		{Page: 1, Type: models.AnnotationBox, X: 0.1, Y: 0.3, Width: 0.3, Height: 0.2},
+		{Page: 1, Type: models.AnnotationNote, X: 0.7, Y: 0.2, Comment: &comment},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteAnnotatedPDF(&buf, src, annotations))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+
+	out := filepath.Join(t.TempDir(), "annotated.pdf")
+	require.NoError(t, os.WriteFile(out, buf.Bytes(), 0644))
+	check := gofpdf.New("P", "pt", "A4", "")
+	importer := gofpdi.NewImporter()
+	importer.ImportPage(check, out, 1, "/MediaBox")
+	// 2 halaman asli + 1 halaman catatan koreksi
+	assert.Len(t, importer.GetPageSizes(), 3)
+}
+
+func TestWriteAnnotatedPDF_InvalidSource(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "rusak.pdf")
+	require.NoError(t, os.WriteFile(src, []byte("bukan pdf"), 0644))
+
+	var buf bytes.Buffer
+	assert.Error(t, services.WriteAnnotatedPDF(&buf, src, nil))
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"brevet-api/mocks"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCertificateRepo simpan sertifikat di memori, method lain tidak dipakai
+type fakeCertificateRepo struct {
+	repository.ICertificateRepository
+	certs map[uuid.UUID]*models.Certificate
+}
+
+func (r *fakeCertificateRepo) GetByID(_ context.Context, id uuid.UUID) (*models.Certificate, error) {
+	cert := *r.certs[id]
+	return &cert, nil
+}
+
+func (r *fakeCertificateRepo) GetNumbersByBatch(_ context.Context, batchID uuid.UUID) ([]string, error) {
+	var numbers []string
+	for _, cert := range r.certs {
+		if cert.BatchID == batchID {
+			numbers = append(numbers, cert.Number)
+		}
+	}
+	return numbers, nil
+}
+
+func (r *fakeCertificateRepo) Supersede(_ context.Context, old, replacement *models.Certificate) error {
+	for _, cert := range r.certs {
+		if cert.Number == replacement.Number {
+			return assert.AnError
+		}
+	}
+	stored := r.certs[old.ID]
+	stored.Status = models.CertificateSuperseded
+	stored.SupersededByID = &replacement.ID
+	r.certs[replacement.ID] = replacement
+	return nil
+}
+
+type fakeCertificateUserRepo struct {
+	repository.IUserRepository
+	user *models.User
+}
+
+func (r *fakeCertificateUserRepo) FindByID(context.Context, uuid.UUID) (*models.User, error) {
+	return r.user, nil
+}
+
+type fakeCertificateBatchRepo struct {
+	repository.IBatchRepository
+	batch *models.Batch
+}
+
+func (r *fakeCertificateBatchRepo) FindByID(context.Context, uuid.UUID) (*models.Batch, error) {
+	return r.batch, nil
+}
+
+func (r *fakeCertificateBatchRepo) GetBatchWithCourse(context.Context, uuid.UUID) (*models.Batch, error) {
+	return r.batch, nil
+}
+
+type fakeCertificateMeetingRepo struct {
+	repository.IMeetingRepository
+}
+
+func (fakeCertificateMeetingRepo) GetMeetingNamesByBatchID(context.Context, uuid.UUID) ([]string, error) {
+	return []string{"PPh 21"}, nil
+}
+
+// chdirWithCertificateAssets jalankan test di folder sementara berisi template & font sertifikat
+func chdirWithCertificateAssets(t *testing.T) {
+	root, err := filepath.Abs("../..")
+	require.NoError(t, err)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, asset := range []string{"templates", "fonts"} {
+		require.NoError(t, os.Symlink(filepath.Join(root, asset), filepath.Join(dir, asset)))
+	}
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestMarkCertificateRevoked(t *testing.T) {
+	admin := uuid.New()
+	now := time.Now()
+
+	cert := models.Certificate{Number: "20100112-abcd1234 0001", Status: models.CertificateActive}
+	assert.ErrorContains(t, services.MarkCertificateRevoked(&cert, admin, "  ", now), "alasan")
+
+	require.NoError(t, services.MarkCertificateRevoked(&cert, admin, "plagiarisme tugas akhir", now))
+	assert.Equal(t, models.CertificateRevoked, cert.Status)
+	assert.Equal(t, admin, *cert.RevokedBy)
+	assert.Equal(t, "plagiarisme tugas akhir", *cert.RevocationReason)
+
+	assert.Error(t, services.MarkCertificateRevoked(&cert, admin, "lagi", now))
+}
+
+func TestCertificateVerification(t *testing.T) {
+	active := &models.Certificate{ID: uuid.New(), Number: "20100112-abcd1234 0002", Status: models.CertificateActive}
+	res, err := services.CertificateVerification(active)
+	require.NoError(t, err)
+	assert.True(t, res.Valid)
+	assert.Equal(t, active.Number, res.Certificate.Number)
+
+	old := &models.Certificate{Number: "20100112-abcd1234 0001", Status: models.CertificateSuperseded, SupersededBy: active}
+	res, err = services.CertificateVerification(old)
+	require.NoError(t, err)
+	assert.False(t, res.Valid)
+	assert.Equal(t, active.Number, *res.SupersededByNumber)
+	assert.Contains(t, res.Message, "diganti oleh 20100112-abcd1234 0002")
+
+	reason := "data batch salah"
+	revoked := &models.Certificate{Status: models.CertificateRevoked, RevocationReason: &reason}
+	res, err = services.CertificateVerification(revoked)
+	require.NoError(t, err)
+	assert.False(t, res.Valid)
+	assert.Equal(t, models.CertificateRevoked, res.Status)
+	assert.Equal(t, reason, *res.RevocationReason)
+}
+
+func TestNextCertificateNumber(t *testing.T) {
+	batchID := uuid.MustParse("abcd1234-0000-0000-0000-000000000000")
+	assert.Equal(t, "20100112-abcd1234 0001", services.NextCertificateNumber(batchID, nil))
+	assert.Equal(t, "20100112-abcd1234 0011", services.NextCertificateNumber(batchID, []string{
+		"20100112-abcd1234 0002", "20100112-abcd1234 0010", "20100112-abcd1234 0009", "rusak",
+	}))
+}
+
+func TestReissueCertificate_NewNumberAndSupersedesOld(t *testing.T) {
+	chdirWithCertificateAssets(t)
+
+	batch := &models.Batch{ID: uuid.New(), Course: models.Course{Title: "Brevet AB"}, StartAt: time.Now().AddDate(0, -2, 0), EndAt: time.Now().AddDate(0, -1, 0)}
+	student := &models.User{ID: uuid.New(), Name: "Budi Santoso"}
+	old := &models.Certificate{
+		ID:      uuid.New(),
+		BatchID: batch.ID,
+		UserID:  student.ID,
+		Number:  services.NextCertificateNumber(batch.ID, nil),
+		Status:  models.CertificateActive,
+	}
+	certRepo := &fakeCertificateRepo{certs: map[uuid.UUID]*models.Certificate{old.ID: old}}
+
+	fileService := new(mocks.IFileService)
+	fileService.On("SaveGeneratedFile", "certificates", testifymock.Anything, testifymock.Anything).
+		Return("/uploads/certificates/baru.pdf", nil)
+
+	service := services.NewCertificateService(certRepo, &fakeCertificateUserRepo{user: student},
+		&fakeCertificateBatchRepo{batch: batch}, nil, fakeCertificateMeetingRepo{}, nil, nil, fileService)
+
+	admin := &utils.Claims{UserID: uuid.New(), Role: string(models.RoleTypeAdmin)}
+	replacement, err := service.ReissueCertificate(context.Background(), old.ID, admin, "nama salah ketik")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, old.Number, replacement.Number)
+	assert.Equal(t, services.NextCertificateNumber(batch.ID, []string{old.Number}), replacement.Number)
+	assert.Equal(t, "/uploads/certificates/baru.pdf", replacement.URL)
+	assert.Equal(t, models.CertificateSuperseded, certRepo.certs[old.ID].Status)
+	assert.Equal(t, replacement.ID, *certRepo.certs[old.ID].SupersededByID)
+
+	// sertifikat yang sudah diganti tidak bisa diterbitkan ulang lagi
+	_, err = service.ReissueCertificate(context.Background(), old.ID, admin, "lagi")
+	assert.Error(t, err)
+}
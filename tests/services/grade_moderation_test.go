@@ -0,0 +1,61 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerateGrade_AgreeAndAdjust(t *testing.T) {
+	marker, moderator := uuid.New(), uuid.New()
+	now := time.Now()
+
+	agree := models.AssignmentGrade{Grade: 80, GradedBy: marker, ModerationStatus: models.GradeSubmitted}
+	require.NoError(t, services.ModerateGrade(&agree, moderator, &dto.ModerateGradeRequest{}, now))
+	assert.Equal(t, models.GradeModerated, agree.ModerationStatus)
+	assert.Equal(t, 80, agree.Grade)
+	assert.Nil(t, agree.OriginalGrade)
+	assert.Equal(t, moderator, *agree.ModeratorID)
+
+	small, comment := 85, "jawaban no. 3 kurang dihargai"
+	adjusted := models.AssignmentGrade{Grade: 80, GradedBy: marker, ModerationStatus: models.GradeSubmitted}
+	require.NoError(t, services.ModerateGrade(&adjusted, moderator, &dto.ModerateGradeRequest{Grade: &small, Comment: &comment}, now))
+	assert.Equal(t, 85, adjusted.Grade)
+	assert.Equal(t, 80, *adjusted.OriginalGrade)
+	assert.False(t, adjusted.ModerationFlagged)
+
+	large := 60
+	flagged := models.AssignmentGrade{Grade: 80, GradedBy: marker, ModerationStatus: models.GradeSubmitted}
+	require.NoError(t, services.ModerateGrade(&flagged, moderator, &dto.ModerateGradeRequest{Grade: &large, Comment: &comment}, now))
+	assert.True(t, flagged.ModerationFlagged)
+}
+
+func TestModerateGrade_Rules(t *testing.T) {
+	marker := uuid.New()
+	grade := 70
+
+	self := models.AssignmentGrade{Grade: 80, GradedBy: marker, ModerationStatus: models.GradeSubmitted}
+	assert.ErrorContains(t, services.ModerateGrade(&self, marker, &dto.ModerateGradeRequest{}, time.Now()), "guru lain")
+
+	silent := models.AssignmentGrade{Grade: 80, GradedBy: marker, ModerationStatus: models.GradeSubmitted}
+	assert.ErrorContains(t, services.ModerateGrade(&silent, uuid.New(), &dto.ModerateGradeRequest{Grade: &grade}, time.Now()), "comment")
+
+	draft := models.AssignmentGrade{Grade: 80, GradedBy: marker, ModerationStatus: models.GradeDraft}
+	assert.Error(t, services.ModerateGrade(&draft, uuid.New(), &dto.ModerateGradeRequest{}, time.Now()))
+}
+
+func TestCanRegrade(t *testing.T) {
+	guru, admin := string(models.RoleTypeGuru), string(models.RoleTypeAdmin)
+
+	assert.NoError(t, services.CanRegrade(nil, guru))
+	assert.NoError(t, services.CanRegrade(&models.AssignmentGrade{ModerationStatus: models.GradeSubmitted}, guru))
+	assert.Error(t, services.CanRegrade(&models.AssignmentGrade{ModerationStatus: models.GradeModerated}, guru))
+	assert.Error(t, services.CanRegrade(&models.AssignmentGrade{ModerationStatus: models.GradeReleased}, guru))
+	assert.NoError(t, services.CanRegrade(&models.AssignmentGrade{ModerationStatus: models.GradeReleased}, admin))
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gradeSheetStudent(name, nim, email string) models.User {
+	return models.User{
+		ID:      uuid.New(),
+		Name:    name,
+		Email:   email,
+		Profile: &models.Profile{NIM: sql.NullString{String: nim, Valid: nim != ""}},
+	}
+}
+
+func gradedSubmission(user models.User, grade int, feedback string) models.AssignmentSubmission {
+	return models.AssignmentSubmission{
+		ID:              uuid.New(),
+		UserID:          user.ID,
+		User:            user,
+		AssignmentGrade: &models.AssignmentGrade{Grade: grade, Feedback: feedback},
+	}
+}
+
+func TestGradeSheets_XLSXRoundTripPerAssignmentSheet(t *testing.T) {
+	budi := gradeSheetStudent("Budi", "2101", "budi@mail.com")
+	sari := gradeSheetStudent("Sari", "2102", "sari@mail.com")
+	tugas1 := &models.Assignment{ID: uuid.New(), Title: "Tugas 1: PPh 21"}
+	tugas2 := &models.Assignment{ID: uuid.New(), Title: "Tugas 2"}
+	targets := []services.GradeTarget{{Assignment: tugas1}, {Assignment: tugas2}}
+
+	data := []services.GradeExportData{
+		{GradeTarget: targets[0], Submissions: []models.AssignmentSubmission{gradedSubmission(budi, 80, "baik"), gradedSubmission(sari, 70, "")}},
+		{GradeTarget: targets[1], Submissions: []models.AssignmentSubmission{{ID: uuid.New(), UserID: sari.ID, User: sari}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteGradeSheets(&buf, services.BuildGradeSheets(data, false), "xlsx"))
+
+	sheets, err := services.ReadGradeSheets("penilaian.xlsx", &buf)
+	require.NoError(t, err)
+	require.Len(t, sheets, 2)
+	assert.Equal(t, "Tugas 1 PPh 21", sheets[0].Name)
+
+	var entries []services.GradeSheetEntry
+	for _, sheet := range sheets {
+		parsed, err := services.ParseGradeSheet(sheet, targets)
+		require.NoError(t, err)
+		entries = append(entries, parsed...)
+	}
+	// submission Tugas 2 belum dinilai, dilewati
+	require.Len(t, entries, 2)
+	assert.Equal(t, tugas1.ID, entries[0].Target.Assignment.ID)
+	assert.Equal(t, budi.ID.String(), entries[0].Student.UserID)
+	assert.Equal(t, 80, entries[0].Grade)
+	assert.Equal(t, "baik", entries[0].Feedback)
+	assert.Equal(t, 70, entries[1].Grade)
+}
+
+func TestGradeSheets_CSVColumnPerAssignment(t *testing.T) {
+	budi := gradeSheetStudent("Budi", "2101", "budi@mail.com")
+	tugas1 := &models.Assignment{ID: uuid.New(), Title: "Tugas 1"}
+	tugas2 := &models.Assignment{ID: uuid.New(), Title: "Tugas 2"}
+	targets := []services.GradeTarget{{Assignment: tugas1}, {Assignment: tugas2}}
+	data := []services.GradeExportData{
+		{GradeTarget: targets[0], Submissions: []models.AssignmentSubmission{gradedSubmission(budi, 75, "")}},
+		{GradeTarget: targets[1], Submissions: []models.AssignmentSubmission{gradedSubmission(budi, 90, "rapi")}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteGradeSheets(&buf, services.BuildGradeSheets(data, true), "csv"))
+	header, _, _ := strings.Cut(strings.TrimPrefix(buf.String(), "\xef\xbb\xbf"), "\n")
+	assert.Contains(t, header, "Tugas 1: Nilai,Tugas 1: Feedback")
+	assert.Contains(t, header, "Tugas 2: Nilai")
+
+	sheets, err := services.ReadGradeSheets("penilaian.csv", &buf)
+	require.NoError(t, err)
+	entries, err := services.ParseGradeSheet(sheets[0], targets)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 75, entries[0].Grade)
+	assert.Equal(t, 90, entries[1].Grade)
+	assert.Equal(t, "rapi", entries[1].Feedback)
+}
+
+func TestGradeSheets_WideSameTitleAssignments(t *testing.T) {
+	budi := gradeSheetStudent("Budi", "2101", "budi@mail.com")
+	pertemuan1 := &models.Assignment{ID: uuid.New(), Title: "Tugas"}
+	pertemuan2 := &models.Assignment{ID: uuid.New(), Title: "Tugas"}
+	targets := []services.GradeTarget{{Assignment: pertemuan1}, {Assignment: pertemuan2}}
+	data := []services.GradeExportData{
+		{GradeTarget: targets[0], Submissions: []models.AssignmentSubmission{gradedSubmission(budi, 60, "")}},
+		{GradeTarget: targets[1], Submissions: []models.AssignmentSubmission{gradedSubmission(budi, 95, "")}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteGradeSheets(&buf, services.BuildGradeSheets(data, true), "csv"))
+	sheets, err := services.ReadGradeSheets("penilaian.csv", &buf)
+	require.NoError(t, err)
+
+	// urutan target saat import berbeda dari ekspor, nilai tetap masuk ke assignment yang benar
+	entries, err := services.ParseGradeSheet(sheets[0], []services.GradeTarget{targets[1], targets[0]})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	grades := map[uuid.UUID]int{}
+	for _, e := range entries {
+		grades[e.Target.Assignment.ID] = e.Grade
+	}
+	assert.Equal(t, 60, grades[pertemuan1.ID])
+	assert.Equal(t, 95, grades[pertemuan2.ID])
+
+	// template lama dengan header kembar ditolak, bukan diam-diam memakai kolom pertama
+	old := services.GradeSheet{Name: "Penilaian", Rows: [][]string{
+		{"NIM", "Tugas: Nilai", "Tugas: Nilai"},
+		{"2101", "60", "95"},
+	}}
+	_, err = services.ParseGradeSheet(old, targets)
+	assert.Error(t, err)
+	kuis := services.GradeTarget{Assignment: &models.Assignment{ID: uuid.New(), Title: "Kuis"}}
+	_, err = services.ParseGradeSheet(old, []services.GradeTarget{{Assignment: pertemuan1}, kuis})
+	assert.ErrorContains(t, err, "lebih dari sekali")
+}
+
+func TestGradeSheets_CSVEscapesFormulas(t *testing.T) {
+	evil := gradeSheetStudent(`=HYPERLINK("http://evil.example","klik")`, "2101", "evil@mail.com")
+	tugas := &models.Assignment{ID: uuid.New(), Title: "Tugas 1"}
+	targets := []services.GradeTarget{{Assignment: tugas}}
+	data := []services.GradeExportData{
+		{GradeTarget: targets[0], Submissions: []models.AssignmentSubmission{gradedSubmission(evil, 80, "-5 karena telat")}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteGradeSheets(&buf, services.BuildGradeSheets(data, false), "csv"))
+	assert.Contains(t, buf.String(), `"'=HYPERLINK(""http://evil.example"",""klik"")"`)
+	assert.Contains(t, buf.String(), "'-5 karena telat")
+
+	// feedback yang diimport balik tidak membawa tanda kutip tambahan
+	sheets, err := services.ReadGradeSheets("penilaian.csv", &buf)
+	require.NoError(t, err)
+	entries, err := services.ParseGradeSheet(sheets[0], targets)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "-5 karena telat", entries[0].Feedback)
+}
+
+func TestParseGradeSheet_ReorderedColumnsWithoutUserID(t *testing.T) {
+	tugas := &models.Assignment{ID: uuid.New(), Title: "Ujian Akhir"}
+	targets := []services.GradeTarget{{Assignment: tugas}}
+
+	// CSV dari Excel berlocale Indonesia: pemisah titik koma, kolom diacak, tanpa UserID
+	file := "Catatan;nilai;EMAIL;Nim\n" +
+		"bagus;85;;2101\n" +
+		";;kosong@mail.com;\n" +
+		";60;Sari@Mail.com;\n"
+	sheets, err := services.ReadGradeSheets("nilai.csv", strings.NewReader(file))
+	require.NoError(t, err)
+
+	entries, err := services.ParseGradeSheet(sheets[0], targets)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "2101", entries[0].Student.NIM)
+	assert.Equal(t, 85, entries[0].Grade)
+	assert.Equal(t, "sari@mail.com", entries[1].Student.Email)
+	assert.Equal(t, 4, entries[1].Row)
+
+	_, err = services.ParseGradeSheet(services.GradeSheet{Name: "x", Rows: [][]string{{"NIM", "Nilai"}, {"2101", "101"}}}, targets)
+	assert.ErrorContains(t, err, "0-100")
+
+	_, err = services.ParseGradeSheet(services.GradeSheet{Name: "x", Rows: [][]string{{"Nama", "Nilai"}, {"Budi", "80"}}}, targets)
+	assert.ErrorContains(t, err, "tidak ditemukan")
+
+	two := append(targets, services.GradeTarget{Assignment: &models.Assignment{ID: uuid.New(), Title: "Kuis"}})
+	_, err = services.ParseGradeSheet(services.GradeSheet{Name: "Sheet1", Rows: [][]string{{"NIM", "Nilai"}, {"2101", "80"}}}, two)
+	assert.ErrorContains(t, err, "tidak dikenali")
+}
+
+func TestStudentRoster_Resolve(t *testing.T) {
+	budi := gradeSheetStudent("Budi", "2101", "budi@mail.com")
+	sari := gradeSheetStudent("Sari", "2102", "Sari@Mail.com")
+	kembar := gradeSheetStudent("Kembar", "2102", "kembar@mail.com")
+	roster := services.NewStudentRoster([]models.User{budi, sari, kembar})
+
+	id, err := roster.Resolve(services.GradeStudentKey{UserID: budi.ID.String(), NIM: "2102"})
+	require.NoError(t, err)
+	assert.Equal(t, budi.ID, id)
+
+	id, err = roster.Resolve(services.GradeStudentKey{NIM: "2101"})
+	require.NoError(t, err)
+	assert.Equal(t, budi.ID, id)
+
+	id, err = roster.Resolve(services.GradeStudentKey{Email: "sari@mail.com"})
+	require.NoError(t, err)
+	assert.Equal(t, sari.ID, id)
+
+	_, err = roster.Resolve(services.GradeStudentKey{NIM: "2102"})
+	assert.ErrorContains(t, err, "lebih dari satu")
+
+	_, err = roster.Resolve(services.GradeStudentKey{UserID: uuid.NewString()})
+	assert.ErrorContains(t, err, "bukan siswa batch")
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"brevet-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLateMinutes_HardDeadlineAndCutoff(t *testing.T) {
+	deadline := time.Date(2025, 3, 1, 23, 59, 0, 0, time.UTC)
+	cutoff := deadline.Add(48 * time.Hour)
+
+	a := &models.Assignment{EndAt: deadline, LateCutoffAt: &cutoff}
+	late, err := a.LateMinutes(deadline, deadline)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, late)
+
+	late, err = a.LateMinutes(deadline.Add(90*time.Second), deadline)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, late) // dibulatkan ke atas
+
+	_, err = a.LateMinutes(cutoff.Add(time.Minute), deadline)
+	assert.ErrorIs(t, err, models.ErrLateCutoff)
+
+	// perpanjangan melewati cutoff tetap boleh selama belum lewat deadline siswa
+	extended := cutoff.Add(24 * time.Hour)
+	late, err = a.LateMinutes(cutoff.Add(time.Hour), extended)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, late)
+
+	a.HardDeadline = true
+	_, err = a.LateMinutes(deadline.Add(time.Minute), deadline)
+	assert.ErrorIs(t, err, models.ErrHardDeadline)
+}
+
+func TestLatePenalty_GraceAndUnits(t *testing.T) {
+	a := &models.Assignment{LateGraceMinutes: 30, LatePenaltyPercent: 10, LatePenaltyUnit: models.LatePenaltyPerDay}
+
+	assert.Equal(t, 0.0, a.LatePenalty(30))            // masih masa tenggang
+	assert.Equal(t, 10.0, a.LatePenalty(31))           // hari pertama
+	assert.Equal(t, 20.0, a.LatePenalty(30+24*60+1))   // masuk hari kedua
+	assert.Equal(t, 100.0, a.LatePenalty(30+30*24*60)) // maksimal 100%
+
+	a.LatePenaltyUnit = models.LatePenaltyPerHour
+	a.LatePenaltyPercent = 5
+	assert.Equal(t, 15.0, a.LatePenalty(30+121))
+}
+
+func TestApplyLatePenalty_Floor(t *testing.T) {
+	a := &models.Assignment{LatePenaltyFloor: 50}
+
+	assert.Equal(t, 80, a.ApplyLatePenalty(80, 0))
+	assert.Equal(t, 72, a.ApplyLatePenalty(80, 10))
+	assert.Equal(t, 50, a.ApplyLatePenalty(80, 60)) // tidak di bawah floor
+	assert.Equal(t, 40, a.ApplyLatePenalty(40, 30)) // nilai mentah sudah di bawah floor
+
+	grade := models.AssignmentGrade{Grade: 80}
+	assert.Equal(t, 80, grade.EffectiveGrade())
+	penalized := 72
+	grade.PenalizedGrade = &penalized
+	assert.Equal(t, 72, grade.EffectiveGrade())
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignPeerReviewers_NoSelfReviewAndBalanced(t *testing.T) {
+	students := make([]uuid.UUID, 7)
+	for i := range students {
+		students[i] = uuid.New()
+	}
+	// siswa terakhir tidak mengumpulkan tapi tetap boleh mereview
+	authors := students[:6]
+
+	assigned := services.AssignPeerReviewers(authors, students, 3, rand.New(rand.NewSource(1)))
+	load := map[uuid.UUID]int{}
+	for i, reviewers := range assigned {
+		assert.Len(t, reviewers, 3)
+		assert.NotContains(t, reviewers, authors[i])
+		for j, r := range reviewers {
+			assert.False(t, slices.Contains(reviewers[:j], r), "reviewer ganda")
+			load[r]++
+		}
+	}
+	minLoad, maxLoad := 100, 0
+	for _, s := range students {
+		minLoad, maxLoad = min(minLoad, load[s]), max(maxLoad, load[s])
+	}
+	assert.LessOrEqual(t, maxLoad-minLoad, 1)
+
+	// reviewer kurang dari N: pakai yang ada saja
+	two := services.AssignPeerReviewers(students[:2], students[:2], 3, rand.New(rand.NewSource(1)))
+	assert.Equal(t, [][]uuid.UUID{{students[1]}, {students[0]}}, two)
+}
+
+func TestPeerScore_TrimsOutliers(t *testing.T) {
+	assert.Equal(t, 80.0, services.PeerScore([]float64{80}))
+	assert.Equal(t, 75.0, services.PeerScore([]float64{70, 80}))
+	assert.Equal(t, 80.0, services.PeerScore([]float64{0, 80, 100}))                              // median
+	assert.Equal(t, 82.5, services.PeerScore([]float64{10, 80, 85, 100}))                         // buang 10 & 100
+	assert.Equal(t, 81.67, services.PeerScore([]float64{0, 5, 78, 80, 80, 80, 82, 90, 100, 100})) // buang 2 teratas & terbawah
+}
+
+func TestBlendedGrade_WithLatePenalty(t *testing.T) {
+	peer := 60.0
+	grade := models.AssignmentGrade{Grade: 90, PeerScore: &peer, PeerWeight: 30}
+	assert.Equal(t, 81, grade.BlendedGrade()) // 90*0.7 + 60*0.3
+
+	a := &models.Assignment{LatePenaltyPercent: 10, LatePenaltyUnit: models.LatePenaltyPerDay}
+	assert.Equal(t, 73, a.ApplyLatePenalty(grade.BlendedGrade(), a.LatePenalty(60)))
+
+	grade.PeerScore = nil
+	assert.Equal(t, 90, grade.BlendedGrade())
+}
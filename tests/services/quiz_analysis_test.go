@@ -0,0 +1,85 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildAnalysisAttempt(answers map[uuid.UUID]uuid.UUID, keys map[uuid.UUID]uuid.UUID) models.QuizAttempt {
+	attempt := models.QuizAttempt{ID: uuid.New(), UserID: uuid.New()}
+	for questionID, optionID := range answers {
+		score := 0
+		if keys[questionID] == optionID {
+			score = 1
+		}
+		attempt.Submissions = append(attempt.Submissions, models.QuizSubmission{
+			AttemptID:        attempt.ID,
+			QuestionID:       questionID,
+			SelectedOptionID: optionID,
+			Score:            score,
+		})
+	}
+	return attempt
+}
+
+func TestCalculateItemAnalysis(t *testing.T) {
+	q1 := models.QuizQuestion{ID: uuid.New(), Question: "Q1"}
+	q1Key, q1Wrong, q1Unused := uuid.New(), uuid.New(), uuid.New()
+	q1.Options = []models.QuizOption{
+		{ID: q1Key, IsCorrect: true},
+		{ID: q1Wrong},
+		{ID: q1Unused},
+	}
+
+	q2 := models.QuizQuestion{ID: uuid.New(), Question: "Q2"}
+	q2Key, q2Wrong := uuid.New(), uuid.New()
+	q2.Options = []models.QuizOption{
+		{ID: q2Key, IsCorrect: true},
+		{ID: q2Wrong},
+	}
+
+	keys := map[uuid.UUID]uuid.UUID{q1.ID: q1Key, q2.ID: q2Key}
+
+	// Dua siswa terbaik menjawab semua benar, dua terbawah salah di Q1
+	attempts := []models.QuizAttempt{
+		buildAnalysisAttempt(map[uuid.UUID]uuid.UUID{q1.ID: q1Key, q2.ID: q2Key}, keys),
+		buildAnalysisAttempt(map[uuid.UUID]uuid.UUID{q1.ID: q1Key, q2.ID: q2Key}, keys),
+		buildAnalysisAttempt(map[uuid.UUID]uuid.UUID{q1.ID: q1Wrong, q2.ID: q2Key}, keys),
+		buildAnalysisAttempt(map[uuid.UUID]uuid.UUID{q1.ID: q1Wrong}, keys),
+	}
+
+	report := services.CalculateItemAnalysis([]models.QuizQuestion{q1, q2}, attempts)
+
+	assert.Equal(t, 4, report.TotalAttempts)
+	assert.Equal(t, 2, report.GroupSize)
+	assert.Len(t, report.Items, 2)
+
+	item1 := report.Items[0]
+	assert.Equal(t, 2, item1.CorrectCount)
+	assert.Equal(t, 0.5, item1.Difficulty)
+	assert.Equal(t, 1.0, item1.Discrimination)
+	assert.Greater(t, item1.PointBiserial, 0.0)
+	assert.Equal(t, 2, item1.Options[1].Count)
+	assert.Equal(t, 2, item1.Options[1].LowerCount)
+	assert.Contains(t, item1.Flags, services.ItemFlagUnusedDistractor)
+
+	item2 := report.Items[1]
+	assert.Equal(t, 3, item2.CorrectCount)
+	assert.Equal(t, 1, item2.OmittedCount)
+	assert.Equal(t, 0.75, item2.Difficulty)
+	assert.Equal(t, 0.5, item2.Discrimination)
+}
+
+func TestCalculateItemAnalysis_NoAttempts(t *testing.T) {
+	q := models.QuizQuestion{ID: uuid.New(), Options: []models.QuizOption{{ID: uuid.New(), IsCorrect: true}}}
+
+	report := services.CalculateItemAnalysis([]models.QuizQuestion{q}, nil)
+
+	assert.Equal(t, 0, report.TotalAttempts)
+	assert.Equal(t, 0, report.GroupSize)
+	assert.Equal(t, 0.0, report.Items[0].Difficulty)
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeAttemptControlRepo simpan satu attempt di memori, method lain tidak dipakai
+type fakeAttemptControlRepo struct {
+	repository.IQuizRepository
+	attempt        models.QuizAttempt
+	active         *models.QuizAttempt
+	actions        []models.QuizAttemptAction
+	gradingDeleted bool
+}
+
+func (r *fakeAttemptControlRepo) WithTx(_ *gorm.DB) repository.IQuizRepository {
+	return r
+}
+
+func (r *fakeAttemptControlRepo) GetQuizAttemptByID(_ context.Context, _ uuid.UUID) (*models.QuizAttempt, error) {
+	attempt := r.attempt
+	return &attempt, nil
+}
+
+func (r *fakeAttemptControlRepo) GetQuizAttemptByIDForUpdate(_ context.Context, _ uuid.UUID) (*models.QuizAttempt, error) {
+	attempt := r.attempt
+	return &attempt, nil
+}
+
+func (r *fakeAttemptControlRepo) UpdateQuizAttempt(_ context.Context, attempt *models.QuizAttempt) error {
+	r.attempt = *attempt
+	return nil
+}
+
+func (r *fakeAttemptControlRepo) CreateAttemptAction(_ context.Context, action *models.QuizAttemptAction) error {
+	r.actions = append(r.actions, *action)
+	return nil
+}
+
+func (r *fakeAttemptControlRepo) GetActiveAttemptByQuizAndUser(_ context.Context, _, _ uuid.UUID) (*models.QuizAttempt, error) {
+	if r.active == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.active, nil
+}
+
+func (r *fakeAttemptControlRepo) DeleteAttemptGrading(_ context.Context, _ uuid.UUID) error {
+	r.gradingDeleted = true
+	return nil
+}
+
+type fakeAttemptControlBatchRepo struct {
+	repository.IBatchRepository
+}
+
+func (fakeAttemptControlBatchRepo) GetBatchByMeetingID(_ context.Context, _ uuid.UUID) (models.Batch, error) {
+	return models.Batch{ID: uuid.New()}, nil
+}
+
+type fakeAttemptControlAccommodationRepo struct {
+	repository.IAccommodationRepository
+}
+
+func (fakeAttemptControlAccommodationRepo) GetQuizAccommodation(_ context.Context, _, _ uuid.UUID) (*models.QuizAccommodation, error) {
+	return nil, nil
+}
+
+// newAttemptControlService siapkan service di atas repo; commit=false berarti transaksi diharapkan rollback
+func newAttemptControlService(t *testing.T, repo *fakeAttemptControlRepo, commit bool) (services.IQuizService, sqlmock.Sqlmock) {
+	db, mock := setupMockDB(t)
+	mock.ExpectBegin()
+	if commit {
+		mock.ExpectCommit()
+	} else {
+		mock.ExpectRollback()
+	}
+
+	svc := services.NewQuizService(repo, fakeAttemptControlBatchRepo{}, nil, nil, nil, nil,
+		fakeAttemptControlAccommodationRepo{}, nil, nil, nil, nil, db)
+	return svc, mock
+}
+
+func newControlledAttempt() models.QuizAttempt {
+	quizID := uuid.New()
+	return models.QuizAttempt{
+		ID:        uuid.New(),
+		QuizID:    quizID,
+		UserID:    uuid.New(),
+		StartedAt: time.Now().Add(-30 * time.Minute),
+		Quiz:      models.Quiz{ID: quizID, MeetingID: uuid.New(), DurationMinute: 60},
+	}
+}
+
+func attemptControlAdmin() *utils.Claims {
+	return &utils.Claims{UserID: uuid.New(), Role: string(models.RoleTypeAdmin)}
+}
+
+func TestAttemptControl_PauseResumeExcludesPausedTime(t *testing.T) {
+	ctx := context.Background()
+	body := &dto.AttemptActionRequest{Reason: "listrik padam"}
+
+	repo := &fakeAttemptControlRepo{attempt: newControlledAttempt()}
+	repo.attempt.PausedSeconds = 60
+	svc, mock := newAttemptControlService(t, repo, true)
+
+	paused, err := svc.PauseAttempt(ctx, attemptControlAdmin(), repo.attempt.ID, body)
+	require.NoError(t, err)
+	require.NotNil(t, paused.PausedAt)
+	assert.Equal(t, 60, paused.PausedSeconds, "pause belum menambah waktu jeda")
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// geser awal jeda ke 5 menit lalu, lalu lanjutkan
+	pausedAt := time.Now().Add(-5 * time.Minute)
+	repo.attempt.PausedAt = &pausedAt
+	svc, mock = newAttemptControlService(t, repo, true)
+
+	resumed, err := svc.ResumeAttempt(ctx, attemptControlAdmin(), repo.attempt.ID, body)
+	require.NoError(t, err)
+	assert.Nil(t, resumed.PausedAt)
+	assert.InDelta(t, 60+300, resumed.PausedSeconds, 1)
+	assert.Equal(t, resumed.PausedSeconds, repo.attempt.PausedSeconds)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, repo.actions, 2)
+	assert.Equal(t, models.AttemptActionPause, repo.actions[0].Action)
+	assert.Equal(t, models.AttemptActionResume, repo.actions[1].Action)
+}
+
+func TestAttemptControl_ReopenAfterSubmit(t *testing.T) {
+	repo := &fakeAttemptControlRepo{attempt: newControlledAttempt()}
+	endedAt := time.Now().Add(-10 * time.Minute)
+	repo.attempt.EndedAt = &endedAt
+	repo.attempt.ExtraMinutes = 5
+	svc, mock := newAttemptControlService(t, repo, true)
+
+	reopened, err := svc.ReopenAttempt(context.Background(), attemptControlAdmin(), repo.attempt.ID,
+		&dto.AttemptActionRequest{Reason: "salah tekan submit", Minutes: 10})
+
+	require.NoError(t, err)
+	assert.Nil(t, reopened.EndedAt)
+	assert.Equal(t, 15, reopened.ExtraMinutes)
+	assert.InDelta(t, 600, reopened.PausedSeconds, 1, "waktu sejak submit tidak dihitung ke durasi")
+	assert.True(t, repo.gradingDeleted)
+	require.Len(t, repo.actions, 1)
+	assert.Equal(t, models.AttemptActionReopen, repo.actions[0].Action)
+	require.NotNil(t, repo.actions[0].Minutes)
+	assert.Equal(t, 10, *repo.actions[0].Minutes)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptControl_VoidInProgress(t *testing.T) {
+	repo := &fakeAttemptControlRepo{attempt: newControlledAttempt()}
+	pausedAt := time.Now().Add(-time.Minute)
+	repo.attempt.PausedAt = &pausedAt
+	svc, mock := newAttemptControlService(t, repo, true)
+
+	voided, err := svc.VoidAttempt(context.Background(), attemptControlAdmin(), repo.attempt.ID,
+		&dto.AttemptActionRequest{Reason: "terindikasi curang"})
+
+	require.NoError(t, err)
+	require.NotNil(t, voided.VoidedAt)
+	require.NotNil(t, voided.EndedAt)
+	assert.Equal(t, *voided.VoidedAt, *voided.EndedAt)
+	assert.Nil(t, voided.PausedAt)
+	assert.Equal(t, voided.VoidedAt, repo.attempt.VoidedAt)
+	require.Len(t, repo.actions, 1)
+	assert.Equal(t, models.AttemptActionVoid, repo.actions[0].Action)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptControl_InvalidTransitions(t *testing.T) {
+	body := &dto.AttemptActionRequest{Reason: "tes", Minutes: 5}
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		prepare func(r *fakeAttemptControlRepo)
+		run     func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error)
+		errMsg  string
+	}{
+		{
+			name:    "pause attempt yang sudah disubmit",
+			prepare: func(r *fakeAttemptControlRepo) { r.attempt.EndedAt = &past },
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.PauseAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "quiz already submitted",
+		},
+		{
+			name:    "pause attempt yang sedang dijeda",
+			prepare: func(r *fakeAttemptControlRepo) { r.attempt.PausedAt = &past },
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.PauseAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "sudah dijeda",
+		},
+		{
+			name:    "resume attempt yang tidak dijeda",
+			prepare: func(*fakeAttemptControlRepo) {},
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.ResumeAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "tidak sedang dijeda",
+		},
+		{
+			name:    "reopen attempt yang masih berlangsung",
+			prepare: func(*fakeAttemptControlRepo) {},
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.ReopenAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "masih berlangsung",
+		},
+		{
+			name: "reopen saat siswa punya attempt lain yang berlangsung",
+			prepare: func(r *fakeAttemptControlRepo) {
+				r.attempt.EndedAt = &past
+				r.active = &models.QuizAttempt{ID: uuid.New()}
+			},
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.ReopenAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "masih punya attempt",
+		},
+		{
+			name:    "extend attempt yang sudah disubmit",
+			prepare: func(r *fakeAttemptControlRepo) { r.attempt.EndedAt = &past },
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.ExtendAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "quiz already submitted",
+		},
+		{
+			name: "tindakan apa pun pada attempt yang dibatalkan",
+			prepare: func(r *fakeAttemptControlRepo) {
+				r.attempt.EndedAt = &past
+				r.attempt.VoidedAt = &past
+			},
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.ReopenAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "sudah dibatalkan",
+		},
+		{
+			name: "void ulang attempt yang dibatalkan",
+			prepare: func(r *fakeAttemptControlRepo) {
+				r.attempt.EndedAt = &past
+				r.attempt.VoidedAt = &past
+			},
+			run: func(svc services.IQuizService, id uuid.UUID) (*models.QuizAttempt, error) {
+				return svc.VoidAttempt(context.Background(), attemptControlAdmin(), id, body)
+			},
+			errMsg: "sudah dibatalkan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAttemptControlRepo{attempt: newControlledAttempt()}
+			tt.prepare(repo)
+			svc, mock := newAttemptControlService(t, repo, false)
+			before := repo.attempt
+
+			_, err := tt.run(svc, repo.attempt.ID)
+
+			assert.ErrorContains(t, err, tt.errMsg)
+			assert.Equal(t, before, repo.attempt, "attempt tidak boleh berubah")
+			assert.Empty(t, repo.actions)
+			assert.False(t, repo.gradingDeleted)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAttemptControl_ExtendRequiresMinutes(t *testing.T) {
+	repo := &fakeAttemptControlRepo{attempt: newControlledAttempt()}
+	db, _ := setupMockDB(t)
+	svc := services.NewQuizService(repo, fakeAttemptControlBatchRepo{}, nil, nil, nil, nil,
+		fakeAttemptControlAccommodationRepo{}, nil, nil, nil, nil, db)
+
+	_, err := svc.ExtendAttempt(context.Background(), attemptControlAdmin(), repo.attempt.ID, &dto.AttemptActionRequest{Reason: "tes"})
+
+	assert.ErrorContains(t, err, "minutes wajib diisi")
+	assert.Empty(t, repo.actions)
+}
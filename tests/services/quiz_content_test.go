@@ -0,0 +1,54 @@
+package services
+
+import (
+	"brevet-api/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeQuestionContent(t *testing.T) {
+	// Tabel markdown & rumus dipertahankan, HTML mentah dibuang
+	text, err := services.SanitizeQuestionContent("<script>alert(1)</script>Hitung PPh:\n\n| Komponen | Nilai |\n|---|---|\n| Gaji | 10.000.000 |\n\n\\( PKP \\times 5\\% \\)")
+	assert.NoError(t, err)
+	assert.Equal(t, "alert(1)Hitung PPh:\n\n| Komponen | Nilai |\n|---|---|\n| Gaji | 10.000.000 |\n\n\\( PKP \\times 5\\% \\)", text)
+
+	// Perbandingan biasa tidak dianggap tag
+	text, err = services.SanitizeQuestionContent("Jika PTKP < PKP maka ...")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jika PTKP < PKP maka ...", text)
+
+	// Gambar dari luar & link javascript hanya disisakan teksnya
+	text, err = services.SanitizeQuestionContent("![form](https://evil.example/x.png) [klik](javascript:alert(1)) ![ok](/uploads/quiz/a.png)")
+	assert.NoError(t, err)
+	assert.Equal(t, "form klik ![ok](/uploads/quiz/a.png)", text)
+
+	// Rumus tidak seimbang & perintah berbahaya ditolak
+	_, err = services.SanitizeQuestionContent("$$ a + b")
+	assert.Error(t, err)
+	_, err = services.SanitizeQuestionContent(`\( \href{http://x}{y} \)`)
+	assert.Error(t, err)
+}
+
+func TestSanitizeQuestionContent_Bypass(t *testing.T) {
+	// Tag bersarang tidak boleh menyatu kembali jadi tag utuh
+	text, err := services.SanitizeQuestionContent("<<script>script>alert(1)<</script>/script>")
+	assert.NoError(t, err)
+	assert.Equal(t, "alert(1)", text)
+
+	// Tag tanpa penutup di-escape
+	text, err = services.SanitizeQuestionContent("<img src=x onerror=alert(1)")
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;img src=x onerror=alert(1)", text)
+
+	// Definisi link referensi hanya untuk skema aman, gambar referensi disisakan teksnya
+	text, err = services.SanitizeQuestionContent("[klik][1] ![x][2]\n\n[1]: javascript:alert(1)\n[2]: https://evil.example/x.png\n[3]: https://pajak.go.id")
+	assert.NoError(t, err)
+	assert.Equal(t, "[klik][1] x\n\n\n[2]: https://evil.example/x.png\n[3]: https://pajak.go.id", text)
+}
+
+func TestIsQuizContentImageURL(t *testing.T) {
+	assert.True(t, services.IsQuizContentImageURL("/uploads/quiz/2025/07/11/a.png"))
+	assert.False(t, services.IsQuizContentImageURL("/uploads/submissions/2025/07/11/jawaban.pdf"))
+	assert.False(t, services.IsQuizContentImageURL("/uploads/quiz/../submissions/jawaban.pdf"))
+}
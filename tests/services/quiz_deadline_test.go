@@ -0,0 +1,41 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeAttemptDeadline(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	quiz := &models.Quiz{DurationMinute: 60, EndTime: start.Add(90 * time.Minute)}
+	attempt := &models.QuizAttempt{StartedAt: start}
+
+	// Tanpa accommodation: durasi normal
+	assert.Equal(t, start.Add(60*time.Minute), services.ComputeAttemptDeadline(attempt, quiz, nil))
+
+	// Extra time dibatasi EndTime quiz
+	acc := &models.QuizAccommodation{ExtraMinutes: 60}
+	assert.Equal(t, quiz.EndTime, services.ComputeAttemptDeadline(attempt, quiz, acc))
+
+	// Jendela alternatif menggantikan EndTime quiz
+	altEnd := start.Add(3 * time.Hour)
+	acc.EndTime = &altEnd
+	assert.Equal(t, start.Add(120*time.Minute), services.ComputeAttemptDeadline(attempt, quiz, acc))
+}
+
+func TestComputeAttemptDeadlineWithTeacherControls(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	quiz := &models.Quiz{DurationMinute: 60, EndTime: start.Add(60 * time.Minute)}
+
+	// Perpanjangan guru ikut menggeser batas EndTime quiz
+	attempt := &models.QuizAttempt{StartedAt: start, ExtraMinutes: 15}
+	assert.Equal(t, start.Add(75*time.Minute), services.ComputeAttemptDeadline(attempt, quiz, nil))
+
+	// Waktu jeda tidak dihitung ke durasi
+	attempt.PausedSeconds = 10 * 60
+	assert.Equal(t, start.Add(85*time.Minute), services.ComputeAttemptDeadline(attempt, quiz, nil))
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"brevet-api/config"
+	"brevet-api/dto"
+	"brevet-api/services"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// withoutRedis jalankan hub dalam mode lokal (tanpa pub/sub Redis)
+func withoutRedis(t *testing.T) {
+	prev := config.RedisClient
+	config.RedisClient = nil
+	t.Cleanup(func() { config.RedisClient = prev })
+}
+
+func TestQuizLiveHub_LocalDispatch(t *testing.T) {
+	withoutRedis(t)
+	hub := services.NewQuizLiveHub()
+	quizID, attemptID := uuid.New(), uuid.New()
+
+	attemptEvents, stopAttempt := hub.SubscribeAttempt(attemptID)
+	quizEvents, stopQuiz := hub.SubscribeQuiz(quizID)
+	defer stopQuiz()
+
+	hub.Publish(context.Background(), dto.QuizLiveEvent{
+		Type: services.LiveEventSubmitted, QuizID: quizID, AttemptID: attemptID, Reason: "auto",
+	})
+
+	select {
+	case ev := <-attemptEvents:
+		assert.Equal(t, services.LiveEventSubmitted, ev.Type)
+		assert.Equal(t, "auto", ev.Reason)
+		assert.False(t, ev.At.IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("attempt subscriber did not receive event")
+	}
+
+	select {
+	case ev := <-quizEvents:
+		assert.Equal(t, attemptID, ev.AttemptID)
+	case <-time.After(time.Second):
+		t.Fatal("quiz subscriber did not receive event")
+	}
+
+	// setelah berhenti berlangganan, event attempt tidak diterima lagi
+	stopAttempt()
+	hub.Publish(context.Background(), dto.QuizLiveEvent{Type: services.LiveEventAnswerSaved, QuizID: quizID, AttemptID: attemptID})
+	select {
+	case <-attemptEvents:
+		t.Fatal("unsubscribed channel still received event")
+	case ev := <-quizEvents:
+		assert.Equal(t, services.LiveEventAnswerSaved, ev.Type)
+	}
+}
+
+func TestQuizLiveHub_PresenceWithoutRedis(t *testing.T) {
+	withoutRedis(t)
+	hub := services.NewQuizLiveHub()
+
+	presence, err := hub.Presence(context.Background(), uuid.New())
+	assert.NoError(t, err)
+	assert.Empty(t, presence)
+}
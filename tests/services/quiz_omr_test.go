@@ -0,0 +1,278 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+)
+
+// syntheticScan gambar LJK langsung ke pixel, dengan skala (px/mm) & rotasi (derajat) seperti hasil scanner
+type syntheticScan struct {
+	img        *image.Gray
+	scale, rad float64
+	offset     services.OmrPoint
+}
+
+func newSyntheticScan(scale, degrees float64) *syntheticScan {
+	w, h := int(230*scale), int(317*scale)
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 245
+	}
+	return &syntheticScan{img: img, scale: scale, rad: degrees * math.Pi / 180, offset: services.OmrPoint{X: 10 * scale, Y: 10 * scale}}
+}
+
+func (s *syntheticScan) toPixel(p services.OmrPoint) services.OmrPoint {
+	x, y := p.X*s.scale, p.Y*s.scale
+	return services.OmrPoint{
+		X: s.offset.X + x*math.Cos(s.rad) - y*math.Sin(s.rad),
+		Y: s.offset.Y + x*math.Sin(s.rad) + y*math.Cos(s.rad),
+	}
+}
+
+func (s *syntheticScan) toSheet(x, y float64) services.OmrPoint {
+	x, y = x-s.offset.X, y-s.offset.Y
+	return services.OmrPoint{
+		X: (x*math.Cos(s.rad) + y*math.Sin(s.rad)) / s.scale,
+		Y: (-x*math.Sin(s.rad) + y*math.Cos(s.rad)) / s.scale,
+	}
+}
+
+// paint hitamkan pixel di sekitar center (mm) yang memenuhi inside
+func (s *syntheticScan) paint(center services.OmrPoint, reach float64, inside func(dx, dy float64) bool) {
+	c := s.toPixel(center)
+	r := reach * s.scale * 1.5
+	for y := int(c.Y - r); y <= int(c.Y+r); y++ {
+		for x := int(c.X - r); x <= int(c.X+r); x++ {
+			p := s.toSheet(float64(x), float64(y))
+			if inside(p.X-center.X, p.Y-center.Y) {
+				s.img.SetGray(x, y, color.Gray{Y: 20})
+			}
+		}
+	}
+}
+
+func (s *syntheticScan) square(center services.OmrPoint, side float64) {
+	s.paint(center, side, func(dx, dy float64) bool { return math.Abs(dx) <= side/2 && math.Abs(dy) <= side/2 })
+}
+
+func (s *syntheticScan) bubble(number, option int, fill float64) {
+	center := services.OmrBubbleCenter(number, option)
+	s.paint(center, services.OmrBubbleRadius, func(dx, dy float64) bool {
+		d := math.Hypot(dx, dy)
+		ring := d >= services.OmrBubbleRadius-0.15 && d <= services.OmrBubbleRadius+0.15
+		// fill < 1 = hanya sebagian kiri bulatan yang dihitamkan
+		filled := d <= services.OmrBubbleRadius-0.2 && dx <= -services.OmrBubbleRadius+fill*2*services.OmrBubbleRadius
+		return ring || (fill > 0 && filled)
+	})
+}
+
+func drawSheet(s *syntheticScan, serial uint32, questions int, answers map[int]int, partial map[int]int) {
+	for _, c := range services.OmrMarkCenters() {
+		s.square(c, services.OmrMarkSize)
+	}
+	for i, bit := range services.EncodeOmrID(serial) {
+		if bit {
+			s.square(services.OmrIDCellCenter(i), services.OmrIDCellSize)
+		}
+	}
+	for n := 1; n <= questions; n++ {
+		for opt := 0; opt < 4; opt++ {
+			fill := 0.0
+			if o, ok := answers[n]; ok && o == opt {
+				fill = 1
+			}
+			if o, ok := partial[n]; ok && o == opt {
+				fill = 0.4
+			}
+			s.bubble(n, opt, fill)
+		}
+	}
+}
+
+func optionCounts(n int) []int {
+	counts := make([]int, n)
+	for i := range counts {
+		counts[i] = 4
+	}
+	return counts
+}
+
+func TestOmrID_RoundTrip(t *testing.T) {
+	bits := services.EncodeOmrID(3141592653)
+	serial, ok := services.DecodeOmrID(bits)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(3141592653), serial)
+
+	bits[5] = !bits[5]
+	_, ok = services.DecodeOmrID(bits)
+	assert.False(t, ok)
+}
+
+func TestReadOmrSheet_RotatedScan(t *testing.T) {
+	scan := newSyntheticScan(4, 1.2)
+	answers := map[int]int{1: 0, 2: 3, 3: 1, 26: 2, 40: 1}
+	drawSheet(scan, 987654, 40, answers, nil)
+
+	sheet, err := services.LocateOmrSheet(scan.img)
+	assert.NoError(t, err)
+
+	serial, ok, confidence := sheet.ReadSerial()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(987654), serial)
+	assert.GreaterOrEqual(t, confidence, services.OmrReviewConfidence)
+
+	read := sheet.ReadAnswers(optionCounts(40))
+	assert.Len(t, read, 40)
+	for _, ans := range read {
+		assert.False(t, ans.Ambiguous, "nomor %d", ans.Number)
+		if opt, ok := answers[ans.Number]; ok {
+			assert.Equal(t, string("ABCD"[opt]), ans.Label, "nomor %d", ans.Number)
+		} else {
+			assert.Empty(t, ans.Label, "nomor %d", ans.Number)
+		}
+	}
+}
+
+func TestReadOmrSheet_FlagsDoubleAndPartialMarks(t *testing.T) {
+	scan := newSyntheticScan(4, 0)
+	drawSheet(scan, 42, 5, map[int]int{1: 1, 2: 0}, map[int]int{3: 2})
+	// nomor 2 dihitamkan dua kali
+	scan.bubble(2, 3, 1)
+
+	sheet, err := services.LocateOmrSheet(scan.img)
+	assert.NoError(t, err)
+
+	read := sheet.ReadAnswers(optionCounts(5))
+	assert.False(t, read[0].Ambiguous)
+	assert.Equal(t, "B", read[0].Label)
+	assert.True(t, read[1].Ambiguous)
+	assert.Empty(t, read[1].Label)
+	assert.True(t, read[2].Ambiguous)
+	assert.Less(t, read[2].Confidence, services.OmrReviewConfidence)
+}
+
+func TestReadOmrSheet_FromScannedPDF(t *testing.T) {
+	scan := newSyntheticScan(3, -0.8)
+	drawSheet(scan, 20250101, 10, map[int]int{4: 3}, nil)
+
+	var jpg bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&jpg, scan.img, &jpeg.Options{Quality: 80}))
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.RegisterImageOptionsReader("scan", gofpdf.ImageOptions{ImageType: "JPG"}, &jpg)
+	pdf.ImageOptions("scan", 0, 0, 210, 0, false, gofpdf.ImageOptions{ImageType: "JPG"}, 0, "")
+	var out bytes.Buffer
+	assert.NoError(t, pdf.Output(&out))
+
+	pages, err := utils.ExtractPDFImages(out.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, pages, 1)
+
+	sheet, err := services.LocateOmrSheet(pages[0])
+	assert.NoError(t, err)
+	serial, ok, _ := sheet.ReadSerial()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(20250101), serial)
+	assert.Equal(t, "D", sheet.ReadAnswers(optionCounts(10))[3].Label)
+}
+
+func TestLocateOmrSheet_MissingMarks(t *testing.T) {
+	scan := newSyntheticScan(3, 0)
+	scan.bubble(1, 0, 1)
+
+	_, err := services.LocateOmrSheet(scan.img)
+	assert.Error(t, err)
+}
+
+// fakeOmrPaperRepo simpan satu scan, method lain tidak dipakai
+type fakeOmrPaperRepo struct {
+	repository.IQuizPaperRepository
+	scan    *models.QuizOmrScan
+	updated bool
+}
+
+func (r *fakeOmrPaperRepo) GetScanByID(_ context.Context, _ uuid.UUID) (*models.QuizOmrScan, error) {
+	scan := *r.scan
+	return &scan, nil
+}
+
+func (r *fakeOmrPaperRepo) UpdateScan(_ context.Context, _ *models.QuizOmrScan) error {
+	r.updated = true
+	return nil
+}
+
+type fakeOmrQuizRepo struct {
+	repository.IQuizRepository
+}
+
+func (fakeOmrQuizRepo) GetQuizByID(_ context.Context, quizID uuid.UUID) (*models.Quiz, error) {
+	return &models.Quiz{ID: quizID, MeetingID: uuid.New()}, nil
+}
+
+type fakeOmrBatchRepo struct {
+	repository.IBatchRepository
+}
+
+func (fakeOmrBatchRepo) GetBatchByMeetingID(_ context.Context, _ uuid.UUID) (models.Batch, error) {
+	return models.Batch{ID: uuid.New()}, nil
+}
+
+func TestResolveOmrScan_OnlyReviewScans(t *testing.T) {
+	admin := &utils.Claims{UserID: uuid.New(), Role: string(models.RoleTypeAdmin)}
+	body := &dto.ResolveOmrScanRequest{Answers: []dto.PaperAnswerRequest{{Number: 1, Label: "A"}}}
+
+	for _, status := range []models.OmrScanStatus{models.OmrScanGraded, models.OmrScanResolved, models.OmrScanRejected} {
+		t.Run(string(status), func(t *testing.T) {
+			paperRepo := &fakeOmrPaperRepo{scan: &models.QuizOmrScan{ID: uuid.New(), QuizID: uuid.New(), Status: status}}
+			svc := services.NewQuizPaperService(paperRepo, fakeOmrQuizRepo{}, fakeOmrBatchRepo{}, nil, nil, nil, nil)
+
+			_, err := svc.ResolveOmrScan(context.Background(), admin, paperRepo.scan.ID, body)
+
+			assert.ErrorContains(t, err, string(status))
+			assert.False(t, paperRepo.updated)
+		})
+	}
+}
+
+func TestRejectOmrScan_OnlyReviewScans(t *testing.T) {
+	admin := &utils.Claims{UserID: uuid.New(), Role: string(models.RoleTypeAdmin)}
+	body := &dto.RejectOmrScanRequest{Reason: "bukan LJK quiz ini"}
+
+	for _, status := range []models.OmrScanStatus{models.OmrScanGraded, models.OmrScanResolved, models.OmrScanRejected} {
+		t.Run(string(status), func(t *testing.T) {
+			paperRepo := &fakeOmrPaperRepo{scan: &models.QuizOmrScan{ID: uuid.New(), QuizID: uuid.New(), Status: status}}
+			svc := services.NewQuizPaperService(paperRepo, fakeOmrQuizRepo{}, fakeOmrBatchRepo{}, nil, nil, nil, nil)
+
+			_, err := svc.RejectOmrScan(context.Background(), admin, paperRepo.scan.ID, body)
+
+			assert.ErrorContains(t, err, string(status))
+			assert.False(t, paperRepo.updated)
+		})
+	}
+
+	t.Run("review", func(t *testing.T) {
+		paperRepo := &fakeOmrPaperRepo{scan: &models.QuizOmrScan{ID: uuid.New(), QuizID: uuid.New(), Status: models.OmrScanReview}}
+		svc := services.NewQuizPaperService(paperRepo, fakeOmrQuizRepo{}, fakeOmrBatchRepo{}, nil, nil, nil, nil)
+
+		scan, err := svc.RejectOmrScan(context.Background(), admin, paperRepo.scan.ID, body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.OmrScanRejected, scan.Status)
+		assert.True(t, paperRepo.updated)
+	})
+}
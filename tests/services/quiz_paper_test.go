@@ -0,0 +1,68 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func paperQuestion(optionCount int) models.QuizQuestion {
+	q := models.QuizQuestion{ID: uuid.New(), ScoringMode: models.ScoringNormal}
+	for i := 0; i < optionCount; i++ {
+		q.Options = append(q.Options, models.QuizOption{ID: uuid.New(), IsCorrect: i == 0})
+	}
+	return q
+}
+
+func TestBuildPaperVersion_MapsAnswersBackToQuiz(t *testing.T) {
+	questions := []models.QuizQuestion{paperQuestion(4), paperQuestion(4), paperQuestion(5)}
+	version := services.BuildPaperVersion(questions, "B", rand.New(rand.NewSource(7)))
+
+	assert.Equal(t, "B", version.Code)
+	assert.Len(t, version.Questions, 3)
+
+	byID := map[uuid.UUID]*models.QuizQuestion{}
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+
+	// isi lembar jawaban dengan kunci versi ini, hasilnya harus benar semua
+	var answers []dto.PaperAnswerRequest
+	for i := range version.Questions {
+		pq := &version.Questions[i]
+		assert.Equal(t, i+1, pq.Number)
+		pq.Question = *byID[pq.QuestionID]
+		answers = append(answers, dto.PaperAnswerRequest{Number: pq.Number, Label: services.PaperAnswerKey(pq)})
+	}
+
+	selected, err := services.MapPaperAnswers(&version, answers)
+	assert.NoError(t, err)
+	assert.Len(t, selected, 3)
+	for questionID, optionID := range selected {
+		score, counted := services.GradeAnswer(byID[questionID], optionID)
+		assert.True(t, counted)
+		assert.Equal(t, 1, score)
+	}
+}
+
+func TestMapPaperAnswers_Invalid(t *testing.T) {
+	version := services.BuildPaperVersion([]models.QuizQuestion{paperQuestion(3)}, "A", rand.New(rand.NewSource(1)))
+
+	selected, err := services.MapPaperAnswers(&version, []dto.PaperAnswerRequest{{Number: 1, Label: ""}})
+	assert.NoError(t, err)
+	assert.Empty(t, selected)
+
+	_, err = services.MapPaperAnswers(&version, []dto.PaperAnswerRequest{{Number: 1, Label: "D"}})
+	assert.Error(t, err)
+
+	_, err = services.MapPaperAnswers(&version, []dto.PaperAnswerRequest{{Number: 2, Label: "A"}})
+	assert.Error(t, err)
+
+	_, err = services.MapPaperAnswers(&version, []dto.PaperAnswerRequest{{Number: 1, Label: "a"}, {Number: 1, Label: "B"}})
+	assert.Error(t, err)
+}
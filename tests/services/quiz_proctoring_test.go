@@ -0,0 +1,54 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeAttemptIntegrity(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	prev := uuid.New()
+
+	events := []models.QuizProctorEvent{
+		{Type: models.ProctorTabBlur, OccurredAt: start.Add(time.Minute), IPAddress: "10.0.0.1", UserAgent: "firefox"},
+		{Type: models.ProctorPaste, OccurredAt: start.Add(2 * time.Minute), IPAddress: "10.0.0.1", UserAgent: "firefox"},
+		{Type: models.ProctorReconnect, OccurredAt: start.Add(5 * time.Minute), IPAddress: "10.0.0.2", UserAgent: "firefox"},
+	}
+	logs := []models.QuizAnswerLog{
+		// jawaban pertama, bukan perubahan
+		{QuestionID: uuid.New(), CreatedAt: start.Add(30 * time.Second), IPAddress: "10.0.0.1", UserAgent: "firefox"},
+		// diubah 10 detik setelah tab_blur
+		{QuestionID: uuid.New(), PreviousOptionID: &prev, CreatedAt: start.Add(70 * time.Second), IPAddress: "10.0.0.1", UserAgent: "firefox"},
+		// diubah jauh setelah tab_blur
+		{QuestionID: uuid.New(), PreviousOptionID: &prev, CreatedAt: start.Add(4 * time.Minute), IPAddress: "10.0.0.1", UserAgent: "firefox"},
+	}
+
+	summary := services.SummarizeAttemptIntegrity(events, logs)
+
+	assert.Equal(t, 1, summary.EventCounts[models.ProctorTabBlur])
+	assert.Equal(t, 1, summary.EventCounts[models.ProctorPaste])
+	assert.Equal(t, 2, summary.AnswerChanges)
+	assert.Equal(t, 1, summary.AnswersAfterBlur)
+	assert.Equal(t, 2, summary.DistinctIPs)
+	assert.Equal(t, 1, summary.DistinctUserAgents)
+	// 5 (blur) + 15 (paste) + 2 (reconnect) + 20 (IP tambahan) + 5 (jawaban setelah blur)
+	assert.Equal(t, 47.0, summary.SuspicionScore)
+}
+
+func TestSummarizeAttemptIntegrity_Capped(t *testing.T) {
+	var events []models.QuizProctorEvent
+	for i := 0; i < 20; i++ {
+		events = append(events, models.QuizProctorEvent{Type: models.ProctorPaste})
+	}
+
+	summary := services.SummarizeAttemptIntegrity(events, nil)
+	assert.Equal(t, 100.0, summary.SuspicionScore)
+
+	clean := services.SummarizeAttemptIntegrity(nil, nil)
+	assert.Equal(t, 0.0, clean.SuspicionScore)
+}
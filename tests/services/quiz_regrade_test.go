@@ -0,0 +1,191 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/mocks"
+	"brevet-api/models"
+	"brevet-api/repository"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRegradeAttempt(t *testing.T) {
+	oldKey, newKey := uuid.New(), uuid.New()
+	keyFixed := &models.QuizQuestion{ID: uuid.New(), ScoringMode: models.ScoringNormal,
+		Options: []models.QuizOption{{ID: oldKey}, {ID: newKey, IsCorrect: true}}}
+	voided := &models.QuizQuestion{ID: uuid.New(), ScoringMode: models.ScoringVoid,
+		Options: []models.QuizOption{{ID: uuid.New(), IsCorrect: true}}}
+	freeWrong := uuid.New()
+	free := &models.QuizQuestion{ID: uuid.New(), ScoringMode: models.ScoringFreeCredit,
+		Options: []models.QuizOption{{ID: uuid.New(), IsCorrect: true}, {ID: freeWrong}}}
+
+	questions := map[uuid.UUID]*models.QuizQuestion{keyFixed.ID: keyFixed, voided.ID: voided, free.ID: free}
+
+	subs := []models.QuizSubmission{
+		{ID: uuid.New(), QuestionID: keyFixed.ID, SelectedOptionID: oldKey, Score: 1},
+		{ID: uuid.New(), QuestionID: voided.ID, SelectedOptionID: voided.Options[0].ID, Score: 1},
+		{ID: uuid.New(), QuestionID: free.ID, SelectedOptionID: freeWrong, Score: 0},
+	}
+
+	changed, total, correct := services.RegradeAttempt(subs, questions)
+
+	assert.Len(t, changed, 3)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, correct)
+	assert.Equal(t, 50.0, services.ScorePercent(correct, total))
+}
+
+func TestScorePercent_NoCountedQuestions(t *testing.T) {
+	assert.Equal(t, 0.0, services.ScorePercent(0, 0))
+}
+
+// fakeRegradeRepo quiz, attempt & result di memori untuk RegradeQuiz / NotifyPendingRegrades
+type fakeRegradeRepo struct {
+	repository.IQuizRepository
+	quiz      *models.Quiz
+	attempts  []models.QuizAttempt
+	results   []models.QuizResult
+	pending   []models.QuizRegradeChange
+	log       *models.QuizRegradeLog
+	updated   map[uuid.UUID]float64
+	clearedID []uuid.UUID
+}
+
+func (r *fakeRegradeRepo) WithTx(_ *gorm.DB) repository.IQuizRepository {
+	return r
+}
+
+func (r *fakeRegradeRepo) GetQuizWithQuestions(_ context.Context, _ uuid.UUID) (*models.Quiz, error) {
+	return r.quiz, nil
+}
+
+func (r *fakeRegradeRepo) UpdateQuestionScoringMode(_ context.Context, _ uuid.UUID, _ models.QuestionScoringMode) error {
+	return nil
+}
+
+func (r *fakeRegradeRepo) GetFinishedAttemptsByQuizID(_ context.Context, _ uuid.UUID) ([]models.QuizAttempt, error) {
+	return r.attempts, nil
+}
+
+func (r *fakeRegradeRepo) GetResultsByQuizID(_ context.Context, _ uuid.UUID) ([]models.QuizResult, error) {
+	return r.results, nil
+}
+
+func (r *fakeRegradeRepo) UpdateSubmissionScore(_ context.Context, _ uuid.UUID, _ int) error {
+	return nil
+}
+
+func (r *fakeRegradeRepo) UpdateQuizResult(_ context.Context, result *models.QuizResult) error {
+	r.updated[result.AttemptID] = result.ScorePercent
+	return nil
+}
+
+func (r *fakeRegradeRepo) CreateRegradeLog(_ context.Context, regradeLog *models.QuizRegradeLog) error {
+	r.log = regradeLog
+	return nil
+}
+
+func (r *fakeRegradeRepo) GetReleasedPendingRegradeChanges(_ context.Context, _ time.Time) ([]models.QuizRegradeChange, error) {
+	return r.pending, nil
+}
+
+func (r *fakeRegradeRepo) ClearRegradeNotices(_ context.Context, ids []uuid.UUID) error {
+	r.clearedID = append(r.clearedID, ids...)
+	return nil
+}
+
+type fakeRegradeBatchRepo struct {
+	repository.IBatchRepository
+}
+
+func (fakeRegradeBatchRepo) GetBatchByMeetingID(_ context.Context, _ uuid.UUID) (models.Batch, error) {
+	return models.Batch{ID: uuid.New()}, nil
+}
+
+func TestRegradeQuiz_FreeCreditSkipsBlankAnswers(t *testing.T) {
+	answered := &models.QuizQuestion{ID: uuid.New(), Options: []models.QuizOption{{ID: uuid.New(), IsCorrect: true}, {ID: uuid.New()}}}
+	credited := &models.QuizQuestion{ID: uuid.New(), Options: []models.QuizOption{{ID: uuid.New(), IsCorrect: true}, {ID: uuid.New()}}}
+	quiz := &models.Quiz{ID: uuid.New(), MeetingID: uuid.New(), Questions: []models.QuizQuestion{*answered, *credited}}
+
+	// siswa A mengosongkan soal yang diberi free credit: soal itu memang tidak masuk total sejak submit
+	blank := models.QuizAttempt{ID: uuid.New(), User: models.User{Email: "a@example.com"}, Submissions: []models.QuizSubmission{
+		{ID: uuid.New(), QuestionID: answered.ID, SelectedOptionID: answered.Options[0].ID, Score: 1},
+	}}
+	// siswa B menjawab salah soal tersebut
+	wrong := models.QuizAttempt{ID: uuid.New(), User: models.User{Email: "b@example.com"}, Submissions: []models.QuizSubmission{
+		{ID: uuid.New(), QuestionID: answered.ID, SelectedOptionID: answered.Options[1].ID, Score: 0},
+		{ID: uuid.New(), QuestionID: credited.ID, SelectedOptionID: credited.Options[1].ID, Score: 0},
+	}}
+
+	repo := &fakeRegradeRepo{
+		quiz:     quiz,
+		attempts: []models.QuizAttempt{blank, wrong},
+		results: []models.QuizResult{
+			{AttemptID: blank.ID, TotalQuestions: 1, CorrectAnswers: 1, ScorePercent: 100},
+			{AttemptID: wrong.ID, TotalQuestions: 2, CorrectAnswers: 0, WrongAnswers: 2, ScorePercent: 0},
+		},
+		updated: map[uuid.UUID]float64{},
+	}
+	db, mock := setupMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	svc := services.NewQuizService(repo, fakeRegradeBatchRepo{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, db)
+
+	mode := models.ScoringFreeCredit
+	admin := &utils.Claims{UserID: uuid.New(), Role: string(models.RoleTypeAdmin)}
+	res, err := svc.RegradeQuiz(context.Background(), admin, quiz.ID, &dto.RegradeQuizRequest{QuestionID: &credited.ID, ScoringMode: &mode})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.AffectedAttempts)
+	assert.Equal(t, 1, res.ChangedAttempts)
+	assert.NotContains(t, repo.updated, blank.ID)
+	assert.Equal(t, 50.0, repo.updated[wrong.ID])
+
+	// email tidak dikirim saat regrade, ditandai tertunda sampai review dibuka
+	require.Len(t, repo.log.Items, 1)
+	assert.True(t, repo.log.Items[0].PendingNotice)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotifyPendingRegrades_OneEmailPerChangedAttempt(t *testing.T) {
+	quizLog := models.QuizRegradeLog{Quiz: models.Quiz{Title: "Quiz PPh"}}
+	twice, reverted := uuid.New(), uuid.New()
+	change := func(attemptID uuid.UUID, email string, oldScore, newScore float64) models.QuizRegradeChange {
+		return models.QuizRegradeChange{ID: uuid.New(), AttemptID: attemptID, OldScorePercent: oldScore, NewScorePercent: newScore,
+			User: models.User{Name: "Siswa", Email: email}, RegradeLog: quizLog, PendingNotice: true}
+	}
+
+	repo := &fakeRegradeRepo{pending: []models.QuizRegradeChange{
+		change(twice, "twice@example.com", 60, 80),
+		change(reverted, "reverted@example.com", 50, 70),
+		change(twice, "twice@example.com", 80, 90),
+		change(reverted, "reverted@example.com", 70, 50),
+	}}
+	db, mock := setupMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	emailService := new(mocks.IEmailService)
+	emailService.On("SendEmail", "twice@example.com", "Perubahan Nilai Quiz",
+		testifymock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "Quiz PPh") && assert.Contains(t, body, "60.00") && assert.Contains(t, body, "90.00")
+		})).Return(nil).Once()
+
+	svc := services.NewQuizService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, emailService, db)
+	n, err := svc.NotifyPendingRegrades(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, repo.clearedID, 4, "semua perubahan ditandai terkirim, termasuk yang nilainya kembali sama")
+	emailService.AssertExpectations(t)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
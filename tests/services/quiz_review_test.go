@@ -0,0 +1,101 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewReleaseTime(t *testing.T) {
+	ended := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	quizEnd := ended.Add(2 * time.Hour)
+	attempt := &models.QuizAttempt{EndedAt: &ended}
+
+	quiz := &models.Quiz{ReviewRelease: models.ReleaseImmediate}
+	assert.Equal(t, ended, *services.ReviewReleaseTime(quiz, attempt, quizEnd))
+
+	quiz.ReviewRelease = models.ReleaseAfterEnd
+	assert.Equal(t, quizEnd, *services.ReviewReleaseTime(quiz, attempt, quizEnd))
+
+	quiz.ReviewRelease = models.ReleaseManual
+	assert.Nil(t, services.ReviewReleaseTime(quiz, attempt, quizEnd))
+
+	// rilis manual sebelum attempt selesai tetap menunggu attempt selesai
+	released := ended.Add(-time.Hour)
+	quiz.ReviewReleasedAt = &released
+	assert.Equal(t, ended, *services.ReviewReleaseTime(quiz, attempt, quizEnd))
+
+	// attempt yang masih berjalan tidak pernah dibuka
+	assert.Nil(t, services.ReviewReleaseTime(quiz, &models.QuizAttempt{}, quizEnd))
+}
+
+func TestIsReviewReleased(t *testing.T) {
+	ended := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	quizEnd := ended.Add(2 * time.Hour)
+	attempt := &models.QuizAttempt{EndedAt: &ended}
+
+	// after_end: notifikasi regrade baru boleh setelah quiz (termasuk accommodation) berakhir
+	quiz := &models.Quiz{ReviewRelease: models.ReleaseAfterEnd}
+	assert.False(t, services.IsReviewReleased(quiz, attempt, quizEnd, ended.Add(time.Hour)))
+	assert.True(t, services.IsReviewReleased(quiz, attempt, quizEnd, quizEnd))
+
+	quiz.ReviewRelease = models.ReleaseManual
+	assert.False(t, services.IsReviewReleased(quiz, attempt, quizEnd, quizEnd.Add(time.Hour)))
+
+	quiz.ReviewRelease = models.ReleaseImmediate
+	assert.True(t, services.IsReviewReleased(quiz, attempt, quizEnd, ended))
+}
+
+func TestBuildAttemptReview(t *testing.T) {
+	explanation := "Karena A"
+	keyID, wrongID := uuid.New(), uuid.New()
+	q1 := models.QuizQuestion{ID: uuid.New(), Question: "Q1", Explanation: &explanation,
+		Options: []models.QuizOption{{ID: keyID, IsCorrect: true}, {ID: wrongID}}}
+	q2 := models.QuizQuestion{ID: uuid.New(), Question: "Q2",
+		Options: []models.QuizOption{{ID: uuid.New(), IsCorrect: true}}}
+
+	subs := []models.QuizSubmission{{QuestionID: q1.ID, SelectedOptionID: wrongID, Score: 0}}
+	questions := []models.QuizQuestion{q1, q2}
+
+	assert.Nil(t, services.BuildAttemptReview(models.ReviewScoreOnly, questions, subs))
+
+	wrong := services.BuildAttemptReview(models.ReviewWrongAnswers, questions, subs)
+	assert.Len(t, wrong, 2)
+	assert.False(t, wrong[0].IsCorrect)
+	assert.Equal(t, wrongID, *wrong[0].SelectedOptionID)
+	assert.Empty(t, wrong[0].CorrectOptionIDs)
+	assert.Nil(t, wrong[0].Explanation)
+	assert.Nil(t, wrong[1].SelectedOptionID)
+
+	full := services.BuildAttemptReview(models.ReviewFullSolution, questions, subs)
+	assert.Equal(t, []uuid.UUID{keyID}, full[0].CorrectOptionIDs)
+	assert.Equal(t, &explanation, full[0].Explanation)
+}
+
+func TestPracticeQuizMode(t *testing.T) {
+	quiz := &models.Quiz{
+		Mode:          models.QuizModePractice,
+		MaxAttempts:   3,
+		ReviewLevel:   models.ReviewScoreOnly,
+		ReviewRelease: models.ReleaseManual,
+	}
+	services.ApplyQuizModeDefaults(quiz)
+	assert.Equal(t, 0, quiz.MaxAttempts)
+	assert.Equal(t, models.ReviewFullSolution, quiz.ReviewLevel)
+	assert.Equal(t, models.ReleaseImmediate, quiz.ReviewRelease)
+	assert.False(t, quiz.Mode.CountsTowardGrade())
+
+	// Latihan langsung dibahas walau release policy tersimpan masih manual
+	ended := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	quiz.ReviewRelease = models.ReleaseManual
+	assert.Equal(t, ended, *services.ReviewReleaseTime(quiz, &models.QuizAttempt{EndedAt: &ended}, ended.Add(time.Hour)))
+
+	graded := &models.Quiz{}
+	services.ApplyQuizModeDefaults(graded)
+	assert.Equal(t, models.QuizModeGraded, graded.Mode)
+	assert.True(t, graded.Mode.CountsTowardGrade())
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"brevet-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizScoringPolicyAggregate(t *testing.T) {
+	scores := []float64{60, 90, 70}
+
+	assert.Equal(t, 90.0, models.ScoringPolicyHighest.Aggregate(scores, 0))
+	assert.Equal(t, 70.0, models.ScoringPolicyLatest.Aggregate(scores, 0))
+	assert.Equal(t, 60.0, models.ScoringPolicyFirst.Aggregate(scores, 0))
+	assert.InDelta(t, 73.33, models.ScoringPolicyAverage.Aggregate(scores, 0), 0.01)
+
+	// Penalti 10 poin per attempt ulang: 60, 80, 50
+	assert.Equal(t, 80.0, models.ScoringPolicyHighest.Aggregate(scores, 10))
+	assert.Equal(t, 50.0, models.ScoringPolicyLatest.Aggregate(scores, 10))
+
+	// Policy kosong (data lama) diperlakukan sebagai highest
+	assert.Equal(t, 90.0, models.QuizScoringPolicy("").Aggregate(scores, 0))
+	assert.Equal(t, 0.0, models.ScoringPolicyHighest.Aggregate(nil, 0))
+}
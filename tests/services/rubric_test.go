@@ -0,0 +1,120 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/models"
+	"brevet-api/services"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// rubric dua kriteria: Analisis (0/2/4) dan Penulisan (0/3/6), total maks 10
+func testRubric() *models.Rubric {
+	criterion := func(title string, order int, points ...int) models.RubricCriterion {
+		c := models.RubricCriterion{ID: uuid.New(), Title: title, Order: order}
+		for i, p := range points {
+			c.Levels = append(c.Levels, models.RubricLevel{ID: uuid.New(), Title: title + " L" + string(rune('1'+i)), Points: p, Order: i + 1})
+		}
+		return c
+	}
+	return &models.Rubric{ID: uuid.New(), Criteria: []models.RubricCriterion{
+		criterion("Analisis", 1, 0, 2, 4),
+		criterion("Penulisan", 2, 0, 3, 6),
+	}}
+}
+
+func TestBuildRubricCriteria_RejectsDuplicatePoints(t *testing.T) {
+	_, err := services.BuildRubricCriteria(&dto.SaveRubricRequest{Title: "R", Criteria: []dto.RubricCriterionRequest{
+		{Title: "A", Levels: []dto.RubricLevelRequest{{Title: "Kurang", Points: 2}, {Title: "Baik", Points: 2}}},
+	}})
+	assert.Error(t, err)
+
+	_, err = services.BuildRubricCriteria(&dto.SaveRubricRequest{Title: "R", Criteria: []dto.RubricCriterionRequest{
+		{Title: "A", Levels: []dto.RubricLevelRequest{{Title: "Kurang", Points: 0}, {Title: "Baik", Points: 0}}},
+	}})
+	assert.Error(t, err)
+
+	criteria, err := services.BuildRubricCriteria(&dto.SaveRubricRequest{Title: "R", Criteria: []dto.RubricCriterionRequest{
+		{Title: "A", Levels: []dto.RubricLevelRequest{{Title: "Kurang", Points: 1}, {Title: "Baik", Points: 5}}},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, criteria[0].MaxPoints())
+	assert.Equal(t, 2, criteria[0].Levels[1].Order)
+}
+
+func TestScoreRubric_ComputesTotalAndBreakdown(t *testing.T) {
+	rubric := testRubric()
+	comment := "kesimpulan kurang tajam"
+	criteria, points, maxPoints, err := services.ScoreRubric(rubric, []dto.RubricSelectionRequest{
+		{CriterionID: rubric.Criteria[1].ID, LevelID: rubric.Criteria[1].Levels[1].ID, Comment: &comment},
+		{CriterionID: rubric.Criteria[0].ID, LevelID: rubric.Criteria[0].Levels[2].ID},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, points)
+	assert.Equal(t, 10, maxPoints)
+	assert.Equal(t, 70, services.RubricGrade(points, maxPoints))
+
+	// rincian ikut urutan kriteria rubrik, bukan urutan request
+	assert.Equal(t, "Analisis", criteria[0].CriterionTitle)
+	assert.Equal(t, 4, criteria[0].Points)
+	assert.Equal(t, 6, criteria[1].MaxPoints)
+	assert.Equal(t, &comment, criteria[1].Comment)
+}
+
+func TestScoreRubric_RejectsIncompleteOrForeignSelections(t *testing.T) {
+	rubric := testRubric()
+
+	_, _, _, err := services.ScoreRubric(rubric, []dto.RubricSelectionRequest{
+		{CriterionID: rubric.Criteria[0].ID, LevelID: rubric.Criteria[0].Levels[0].ID},
+	})
+	assert.Error(t, err, "kriteria kedua belum dinilai")
+
+	_, _, _, err = services.ScoreRubric(rubric, []dto.RubricSelectionRequest{
+		{CriterionID: rubric.Criteria[0].ID, LevelID: rubric.Criteria[1].Levels[0].ID},
+		{CriterionID: rubric.Criteria[1].ID, LevelID: rubric.Criteria[1].Levels[0].ID},
+	})
+	assert.Error(t, err, "level milik kriteria lain")
+
+	_, _, _, err = services.ScoreRubric(rubric, []dto.RubricSelectionRequest{
+		{CriterionID: rubric.Criteria[0].ID, LevelID: rubric.Criteria[0].Levels[0].ID},
+		{CriterionID: rubric.Criteria[1].ID, LevelID: rubric.Criteria[1].Levels[0].ID},
+		{CriterionID: uuid.New(), LevelID: uuid.New()},
+	})
+	assert.Error(t, err, "kriteria di luar rubrik")
+}
+
+func TestRubricExcel_RoundTrip(t *testing.T) {
+	rubric := testRubric()
+	assert.Equal(t, []string{"Analisis (0/2/4)", "Komentar Analisis", "Penulisan (0/3/6)", "Komentar Penulisan"},
+		services.RubricExcelHeaders(rubric))
+
+	comment := "rapi"
+	values := services.RubricExcelValues(rubric, []models.AssignmentGradeCriterion{
+		{CriterionID: rubric.Criteria[0].ID, Points: 2},
+		{CriterionID: rubric.Criteria[1].ID, Points: 6, Comment: &comment},
+	})
+	// urutan kolom bebas, kolom dicari lewat header
+	header := append([]string{"Komentar Penulisan", "Nilai"}, services.RubricExcelHeaders(rubric)[:3]...)
+	row := []string{values[3], "80", values[0], values[1], values[2]}
+
+	selections, err := services.ParseRubricExcelRow(rubric, header, row)
+	assert.NoError(t, err)
+	assert.Len(t, selections, 2)
+	assert.Equal(t, rubric.Criteria[0].Levels[1].ID, selections[0].LevelID)
+	assert.Nil(t, selections[0].Comment)
+	assert.Equal(t, rubric.Criteria[1].Levels[2].ID, selections[1].LevelID)
+	assert.Equal(t, "rapi", *selections[1].Comment)
+
+	// baris belum dinilai (GetRows memotong sel kosong di ujung)
+	selections, err = services.ParseRubricExcelRow(rubric, header, []string{"", "80"})
+	assert.NoError(t, err)
+	assert.Nil(t, selections)
+
+	_, err = services.ParseRubricExcelRow(rubric, header, []string{"", "", "3"})
+	assert.Error(t, err, "poin 3 bukan level kriteria Analisis")
+
+	_, err = services.ParseRubricExcelRow(rubric, header, []string{"", "", "4"})
+	assert.Error(t, err, "kriteria Penulisan kosong")
+}
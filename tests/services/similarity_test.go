@@ -0,0 +1,86 @@
+package services
+
+import (
+	"archive/zip"
+	"brevet-api/services"
+	"brevet-api/utils"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const similarityBaseEssay = "Pajak penghasilan dikenakan terhadap subjek pajak atas penghasilan yang diterima " +
+	"atau diperoleh dalam tahun pajak. Penghasilan tersebut meliputi setiap tambahan kemampuan ekonomis " +
+	"baik yang berasal dari Indonesia maupun dari luar Indonesia yang dapat dipakai untuk konsumsi atau " +
+	"untuk menambah kekayaan wajib pajak yang bersangkutan dengan nama dan dalam bentuk apa pun."
+
+const similarityOtherEssay = "Pajak pertambahan nilai dipungut atas penyerahan barang kena pajak di dalam daerah " +
+	"pabean oleh pengusaha kena pajak. Tarif yang berlaku saat ini ditetapkan oleh undang undang dan " +
+	"faktur pajak wajib dibuat pada saat penyerahan sehingga pembeli dapat mengkreditkan pajak masukan " +
+	"sesuai dengan ketentuan yang berlaku di Indonesia setiap masa pajak."
+
+func TestCompareSimilarity_DetectsCopiedPassage(t *testing.T) {
+	// esai disalin dengan huruf & tanda baca berbeda lalu diberi pembuka sendiri
+	copied := "Menurut saya, " + strings.ToUpper(strings.ReplaceAll(similarityBaseEssay, ".", ";")) + " Sekian."
+
+	a := services.NewSimilarityText(similarityBaseEssay)
+	b := services.NewSimilarityText(copied)
+	assert.True(t, a.Comparable())
+
+	match := services.CompareSimilarity(a, b)
+	assert.InDelta(t, 1.0, match.ScoreA, 0.001)
+	assert.Greater(t, match.Score, 0.8)
+	if assert.Len(t, match.Passages, 1) {
+		p := match.Passages[0]
+		assert.Equal(t, "Pajak", a.Excerpt(p.AStart, p.AStart+5))
+		assert.True(t, strings.HasPrefix(b.Excerpt(p.BStart, p.BEnd), "PAJAK PENGHASILAN"))
+		assert.True(t, strings.HasSuffix(b.Excerpt(p.BStart, p.BEnd), "APA PUN"))
+	}
+}
+
+func TestCompareSimilarity_UnrelatedTexts(t *testing.T) {
+	match := services.CompareSimilarity(
+		services.NewSimilarityText(similarityBaseEssay),
+		services.NewSimilarityText(similarityOtherEssay),
+	)
+	assert.Less(t, match.Score, services.DefaultSimilarityMinScore)
+	assert.Empty(t, match.Passages)
+}
+
+func TestSimilarityText_ShortTextNotComparable(t *testing.T) {
+	assert.False(t, services.NewSimilarityText("Jawaban singkat saja").Comparable())
+}
+
+func TestExtractDocxText(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("word/document.xml")
+	fmt.Fprint(w, `<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`+
+		`<w:p><w:r><w:t>Pajak </w:t></w:r><w:r><w:t>penghasilan</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:t>Bab</w:t><w:tab/><w:t>dua</w:t></w:r></w:p></w:body></w:document>`)
+	assert.NoError(t, zw.Close())
+
+	text, err := utils.ExtractDocumentText("tugas.DOCX", buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "Pajak penghasilan\nBab\tdua\n", text)
+}
+
+func TestExtractPDFText(t *testing.T) {
+	content := "BT /F1 12 Tf 72 720 Td (Pajak \\(PPh\\) penghasilan) Tj T* [(sub)-20(jek)-300(pajak)] TJ <4F4B> Tj ET"
+	var flate bytes.Buffer
+	zw := zlib.NewWriter(&flate)
+	zw.Write([]byte(content))
+	zw.Close()
+
+	pdf := fmt.Sprintf("%%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"+
+		"2 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n%%%%EOF", flate.Len(), flate.String())
+
+	text, err := utils.ExtractDocumentText("tugas.pdf", []byte(pdf))
+	assert.NoError(t, err)
+	assert.Equal(t, "Pajak (PPh) penghasilan", strings.TrimSpace(strings.Split(text, "\n")[0]))
+	assert.Contains(t, text, "subjek pajakOK")
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitIntoGroups_BalancedAndComplete(t *testing.T) {
+	ids := make([]uuid.UUID, 10)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	groups := services.SplitIntoGroups(ids, 4, rand.New(rand.NewSource(1)))
+	assert.Len(t, groups, 3)
+
+	seen := map[uuid.UUID]bool{}
+	for _, g := range groups {
+		// 10 siswa ukuran 4 -> 4, 3, 3
+		assert.GreaterOrEqual(t, len(g), 3)
+		assert.LessOrEqual(t, len(g), 4)
+		for _, id := range g {
+			assert.False(t, seen[id], "siswa masuk dua kelompok")
+			seen[id] = true
+		}
+	}
+	assert.Len(t, seen, len(ids))
+}
+
+func TestSplitIntoGroups_FewerThanSize(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	groups := services.SplitIntoGroups(ids, 4, rand.New(rand.NewSource(1)))
+	assert.Len(t, groups, 1)
+	assert.ElementsMatch(t, ids, groups[0])
+
+	assert.Nil(t, services.SplitIntoGroups(nil, 4, rand.New(rand.NewSource(1))))
+}
+
+func TestMemberGrade_AppliesAdjustmentClamped(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	penalized := 90
+	grade := models.AssignmentGrade{
+		Grade:          95,
+		PenalizedGrade: &penalized,
+		Adjustments: []models.AssignmentGradeAdjustment{
+			{UserID: a, Points: -15},
+			{UserID: b, Points: 20},
+		},
+	}
+
+	assert.Equal(t, 75, grade.MemberGrade(a))
+	assert.Equal(t, 100, grade.MemberGrade(b))
+	assert.Equal(t, 90, grade.MemberGrade(c))
+}
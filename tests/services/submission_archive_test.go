@@ -0,0 +1,70 @@
+package services
+
+import (
+	"archive/zip"
+	"brevet-api/models"
+	"brevet-api/services"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSubmissionArchive_FoldersAndManifest(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("UPLOAD_DIR", dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "submissions"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "submissions", "tugas.pdf"), []byte("%PDF-isi"), 0o644))
+
+	essay := "Jawaban essay"
+	archive := &services.SubmissionArchive{
+		Filename: "submission.zip",
+		Submissions: []models.AssignmentSubmission{
+			{
+				UserID:    uuid.New(),
+				User:      models.User{Name: "Budi Santoso", Profile: &models.Profile{NIM: sql.NullString{String: "2101", Valid: true}}},
+				EssayText: &essay,
+				IsLate:    true, LateMinutes: 15, Version: 2,
+				SubmissionFiles: []models.SubmissionFile{
+					{FileURL: "/uploads/submissions/tugas.pdf"},
+					{FileURL: "/uploads/submissions/tugas.pdf"},
+					{FileURL: "/uploads/submissions/hilang.pdf"},
+				},
+			},
+			{UserID: uuid.New(), User: models.User{Name: "Budi Santoso"}, Version: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteSubmissionArchive(&buf, archive))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	assert.Equal(t, "Jawaban essay", files["Budi Santoso_2101/essay.txt"])
+	assert.Equal(t, "%PDF-isi", files["Budi Santoso_2101/tugas.pdf"])
+	assert.Equal(t, "%PDF-isi", files["Budi Santoso_2101/tugas (2).pdf"])
+	assert.NotContains(t, files, "Budi Santoso_2101/note.txt")
+
+	rows, err := csv.NewReader(bytes.NewReader([]byte(files["manifest.csv"]))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"Ya", "15", "2", "2", "hilang.pdf"}, rows[1][6:])
+	// nama sama tanpa NIM tetap dapat folder sendiri
+	assert.Equal(t, "Budi Santoso", rows[2][4])
+}
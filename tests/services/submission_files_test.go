@@ -0,0 +1,61 @@
+package services
+
+import (
+	"brevet-api/models"
+	"brevet-api/services"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeUpload(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	return "/uploads/" + name
+}
+
+func TestValidateSubmissionFiles_ContentSniffing(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("UPLOAD_DIR", dir)
+
+	pdf := writeUpload(t, dir, "laporan.pdf", []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n%%EOF\n"))
+	exe := writeUpload(t, dir, "virus.pdf", append([]byte("MZ\x90\x00\x03\x00\x00\x00"), make([]byte, 64)...))
+	txt := writeUpload(t, dir, "catatan.txt", []byte("jawaban soal nomor satu\n"))
+
+	assignment := &models.Assignment{}
+	assert.NoError(t, services.ValidateSubmissionFiles(assignment, []string{pdf, txt}))
+
+	err := services.ValidateSubmissionFiles(assignment, []string{pdf, exe})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file ke-2")
+	assert.Contains(t, err.Error(), "tidak sesuai")
+
+	err = services.ValidateSubmissionFiles(assignment, []string{"/uploads/tidak-ada.pdf"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tidak ditemukan")
+}
+
+func TestValidateSubmissionFiles_AssignmentRules(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("UPLOAD_DIR", dir)
+
+	pdf := writeUpload(t, dir, "a.pdf", []byte("%PDF-1.4\n%%EOF\n"))
+	txt := writeUpload(t, dir, "b.txt", []byte("halo\n"))
+	big := writeUpload(t, dir, "c.pdf", append([]byte("%PDF-1.4\n"), make([]byte, 2<<20)...))
+
+	assignment := &models.Assignment{
+		AllowedFileTypes: []string{".pdf"},
+		MaxFileSizeMB:    1,
+		MinFiles:         1,
+		MaxFiles:         2,
+	}
+
+	assert.NoError(t, services.ValidateSubmissionFiles(assignment, []string{pdf}))
+	assert.ErrorContains(t, services.ValidateSubmissionFiles(assignment, nil), "minimal 1 file")
+	assert.ErrorContains(t, services.ValidateSubmissionFiles(assignment, []string{pdf, pdf, pdf}), "maksimal 2 file")
+	assert.ErrorContains(t, services.ValidateSubmissionFiles(assignment, []string{txt}), "tidak diperbolehkan")
+	assert.ErrorContains(t, services.ValidateSubmissionFiles(assignment, []string{big}), "melebihi batas 1 MB")
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"brevet-api/dto"
+	"brevet-api/services"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rebuild susun ulang teks lama (tanpa insert) atau baru (tanpa delete) dari hasil diff
+func rebuild(ops []dto.TextDiffOp, skip string) string {
+	var sb strings.Builder
+	for _, op := range ops {
+		if op.Op != skip {
+			sb.WriteString(op.Text)
+		}
+	}
+	return sb.String()
+}
+
+func TestDiffText_WordChanges(t *testing.T) {
+	a := "Pajak penghasilan dipotong oleh pemberi kerja setiap bulan."
+	b := "Pajak penghasilan karyawan dipotong pemberi kerja setiap bulan sekali."
+
+	ops := services.DiffText(a, b)
+	assert.Equal(t, a, rebuild(ops, services.TextDiffInsert))
+	assert.Equal(t, b, rebuild(ops, services.TextDiffDelete))
+
+	var inserted, deleted []string
+	for _, op := range ops {
+		switch op.Op {
+		case services.TextDiffInsert:
+			inserted = append(inserted, strings.TrimSpace(op.Text))
+		case services.TextDiffDelete:
+			deleted = append(deleted, strings.TrimSpace(op.Text))
+		}
+	}
+	assert.Contains(t, inserted, "karyawan")
+	assert.Contains(t, deleted, "oleh")
+}
+
+func TestDiffText_EdgeCases(t *testing.T) {
+	assert.Empty(t, services.DiffText("", ""))
+	assert.Equal(t, []dto.TextDiffOp{{Op: services.TextDiffEqual, Text: "sama persis"}}, services.DiffText("sama persis", "sama persis"))
+	assert.Equal(t, []dto.TextDiffOp{{Op: services.TextDiffInsert, Text: "teks baru"}}, services.DiffText("", "teks baru"))
+	assert.Equal(t, []dto.TextDiffOp{{Op: services.TextDiffDelete, Text: "teks lama"}}, services.DiffText("teks lama", ""))
+
+	// teks yang diganti total tetap bisa disusun ulang
+	a := strings.Repeat("alpha beta ", 3000)
+	b := strings.Repeat("gamma delta ", 3000)
+	ops := services.DiffText(a, b)
+	assert.Equal(t, a, rebuild(ops, services.TextDiffInsert))
+	assert.Equal(t, b, rebuild(ops, services.TextDiffDelete))
+}
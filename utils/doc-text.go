@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExtractDocumentText ambil teks polos dari file tugas berdasarkan ekstensinya (.txt, .docx, .pdf)
+func ExtractDocumentText(filename string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".txt":
+		if !utf8.Valid(data) {
+			return strings.ToValidUTF8(string(data), " "), nil
+		}
+		return string(data), nil
+	case ".docx":
+		return ExtractDocxText(data)
+	case ".pdf":
+		return ExtractPDFText(data)
+	}
+	return "", fmt.Errorf("format %s belum didukung", filepath.Ext(filename))
+}
+
+// ExtractDocxText isi teks dokumen Word (word/document.xml), satu paragraf per baris
+func ExtractDocxText(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("file docx tidak valid: %w", err)
+	}
+
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+			break
+		}
+	}
+	if doc == nil {
+		return "", fmt.Errorf("file docx tidak punya word/document.xml")
+	}
+
+	rc, err := doc.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	dec := xml.NewDecoder(rc)
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("file docx tidak valid: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// ExtractPDFText teks dari content stream PDF (operator Tj, TJ, ' dan ").
+// PDF hasil scan atau font tanpa encoding standar tidak menghasilkan teks.
+func ExtractPDFText(data []byte) (string, error) {
+	var sb strings.Builder
+	for _, stream := range pdfStreams(data) {
+		if pdfImageDictPattern.Match(stream.dict) || bytes.Contains(stream.dict, []byte("/Length1")) {
+			continue
+		}
+
+		content := stream.body
+		if filter := pdfFilterPattern.FindSubmatch(stream.dict); filter != nil {
+			names := pdfNamePattern.FindAllSubmatch(filter[1], -1)
+			if len(names) != 1 || string(names[0][1]) != "FlateDecode" {
+				continue
+			}
+			zr, err := zlib.NewReader(bytes.NewReader(stream.body))
+			if err != nil {
+				continue
+			}
+			content, err = io.ReadAll(zr)
+			if err != nil {
+				continue
+			}
+		}
+		if !bytes.Contains(content, []byte("BT")) {
+			continue
+		}
+		writePDFContentText(&sb, content)
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+// writePDFContentText jalankan tokenizer sederhana atas content stream dan tulis string dari operator teks
+func writePDFContentText(sb *strings.Builder, content []byte) {
+	var operands []string // string yang menunggu operator
+	var array []string    // isi [ ... ] untuk TJ
+	inArray := false
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, next := readPDFLiteral(content, i)
+			if inArray {
+				array = append(array, s)
+			} else {
+				operands = append(operands, s)
+			}
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			s, next := readPDFHex(content, i)
+			if inArray {
+				array = append(array, s)
+			} else {
+				operands = append(operands, s)
+			}
+			i = next
+		case c == '[':
+			inArray, array = true, nil
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case inArray && (c == '-' || c == '.' || (c >= '0' && c <= '9')):
+			start := i
+			for i < len(content) && (content[i] == '-' || content[i] == '.' || (content[i] >= '0' && content[i] <= '9')) {
+				i++
+			}
+			// kerning besar di TJ biasanya jarak antar kata
+			if v, err := strconv.ParseFloat(string(content[start:i]), 64); err == nil && v <= -200 {
+				array = append(array, " ")
+			}
+		case isPDFRegular(c):
+			start := i
+			for i < len(content) && isPDFRegular(content[i]) {
+				i++
+			}
+			switch string(content[start:i]) {
+			case "Tj":
+				sb.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				sb.WriteByte('\n')
+				sb.WriteString(strings.Join(operands, ""))
+			case "TJ":
+				sb.WriteString(strings.Join(array, ""))
+				array = nil
+			case "Td", "TD", "Tm":
+				sb.WriteByte(' ')
+			case "T*", "ET":
+				sb.WriteByte('\n')
+			}
+			operands = nil
+		default:
+			i++
+		}
+	}
+}
+
+func isPDFRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
+
+// readPDFLiteral baca string (...) termasuk kurung bersarang & escape, hasil dianggap Latin-1
+func readPDFLiteral(content []byte, start int) (string, int) {
+	var out []rune
+	depth := 0
+	i := start
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// sambungan baris
+			default:
+				if e >= '0' && e <= '7' {
+					v, n := 0, 0
+					for n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7' {
+						v = v*8 + int(content[i]-'0')
+						i++
+						n++
+					}
+					out = append(out, rune(v&0xff))
+					continue
+				}
+				out = append(out, rune(e))
+			}
+			i++
+			continue
+		case c == '(':
+			depth++
+			if depth > 1 {
+				out = append(out, '(')
+			}
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return string(out), i + 1
+			}
+			out = append(out, ')')
+		default:
+			out = append(out, rune(c))
+		}
+		i++
+	}
+	return string(out), i
+}
+
+// readPDFHex baca string <...>. String berisi byte non-cetak (mis. glyph ID font CID) dianggap kosong.
+func readPDFHex(content []byte, start int) (string, int) {
+	end := bytes.IndexByte(content[start:], '>')
+	if end < 0 {
+		return "", len(content)
+	}
+	hex := bytes.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, content[start+1:start+end])
+	if len(hex)%2 == 1 {
+		hex = append(hex, '0')
+	}
+
+	var out []rune
+	for i := 0; i+1 < len(hex); i += 2 {
+		v := hexNibble(hex[i])<<4 | hexNibble(hex[i+1])
+		if v < 0x20 || v > 0x7e {
+			return "", start + end + 1
+		}
+		out = append(out, rune(v))
+	}
+	return string(out), start + end + 1
+}
+
+func hexNibble(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
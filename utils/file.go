@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"brevet-api/config"
+	"io"
+	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
 )
 
 // AllowedImageExtensions is a list of allowed image extensions
@@ -12,8 +17,77 @@ var AllowedImageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", "
 // AllowedDocumentExtensions is a list of allowed document extensions
 var AllowedDocumentExtensions = []string{".pdf", ".doc", ".docx", ".ppt", ".pptx", ".xls", ".xlsx", ".txt"}
 
+// AllowedScanExtensions is a list of allowed scanned answer sheet extensions
+var AllowedScanExtensions = []string{".png", ".jpg", ".jpeg", ".pdf"}
+
+// SubmissionFileExtensions ekstensi yang bisa dipilih guru untuk file submission
+var SubmissionFileExtensions = slices.Concat(AllowedDocumentExtensions, AllowedImageExtensions)
+
+// extensionMIMEs tipe konten (hasil sniffing) yang sah untuk tiap ekstensi, parent MIME ikut dicek
+var extensionMIMEs = map[string][]string{
+	".pdf":  {"application/pdf"},
+	".doc":  {"application/msword", "application/x-ole-storage"},
+	".xls":  {"application/vnd.ms-excel", "application/x-ole-storage"},
+	".ppt":  {"application/vnd.ms-powerpoint", "application/x-ole-storage"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/zip"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/zip"},
+	".txt":  {"text/plain"},
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".png":  {"image/png"},
+	".gif":  {"image/gif"},
+	".bmp":  {"image/bmp"},
+	".webp": {"image/webp"},
+}
+
+// DetectContentType tebak tipe file dari isinya, bukan dari nama
+func DetectContentType(r io.Reader) (*mimetype.MIME, error) {
+	return mimetype.DetectReader(r)
+}
+
+// ContentMatchesExtension isi file cocok dengan ekstensinya. Ekstensi yang tidak dikenal dianggap cocok.
+func ContentMatchesExtension(filename string, detected *mimetype.MIME) bool {
+	expected, ok := extensionMIMEs[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return true
+	}
+	for m := detected; m != nil; m = m.Parent() {
+		for _, e := range expected {
+			if m.Is(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsAllowedExtension checks if the file extension is allowed
 func IsAllowedExtension(filename string, allowedExts []string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
 	return slices.Contains(allowedExts, ext)
 }
+
+// LocalUploadPath terjemahkan URL upload (/uploads/... atau CDN) ke path file lokal, "" kalau bukan file upload
+func LocalUploadPath(fileURL string) string {
+	rel := ""
+	cdnBase := strings.TrimRight(config.GetEnv("CDN_URL", "https://cdn.tcugapps.com"), "/")
+	switch {
+	case strings.HasPrefix(fileURL, "/uploads/"):
+		rel = strings.TrimPrefix(fileURL, "/uploads/")
+	case strings.HasPrefix(fileURL, cdnBase+"/"):
+		rel = strings.TrimPrefix(fileURL, cdnBase+"/")
+	default:
+		return ""
+	}
+
+	baseDir := config.GetEnv("UPLOAD_DIR", "./public/uploads")
+	path := filepath.Join(baseDir, filepath.Clean("/"+rel))
+	if !IsSafePath(baseDir, path) {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
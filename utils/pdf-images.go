@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	pdfObjectPattern    = regexp.MustCompile(`\d+\s+\d+\s+obj\b`)
+	pdfImageDictPattern = regexp.MustCompile(`/Subtype\s*/Image`)
+	pdfFilterPattern    = regexp.MustCompile(`/Filter\s*(\[[^\]]*\]|/\w+)`)
+	pdfNamePattern      = regexp.MustCompile(`/(\w+)`)
+	pdfWidthPattern     = regexp.MustCompile(`/Width\s+(\d+)`)
+	pdfHeightPattern    = regexp.MustCompile(`/Height\s+(\d+)`)
+	pdfBPCPattern       = regexp.MustCompile(`/BitsPerComponent\s+(\d+)`)
+	pdfPredictorPattern = regexp.MustCompile(`/Predictor\s+(\d+)`)
+)
+
+// ExtractPDFImages ambil gambar yang tertanam di PDF hasil scan, urut sesuai posisi di file
+// (umumnya satu gambar per halaman). Yang didukung: DCTDecode (JPEG) dan FlateDecode
+// DeviceGray/DeviceRGB 8 bit atau gray 1 bit, termasuk PNG predictor.
+func ExtractPDFImages(data []byte) ([]image.Image, error) {
+	var images []image.Image
+	for _, stream := range pdfStreams(data) {
+		if !pdfImageDictPattern.Match(stream.dict) {
+			continue
+		}
+		img, err := decodePDFImage(stream.dict, stream.body)
+		if err != nil {
+			return nil, err
+		}
+		if img != nil {
+			images = append(images, img)
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("tidak ada gambar scan di dalam PDF")
+	}
+	return images, nil
+}
+
+// pdfStream dictionary & isi mentah satu stream object
+type pdfStream struct {
+	dict, body []byte
+}
+
+// pdfStreams pecah PDF jadi stream object, urut sesuai posisi di file
+func pdfStreams(data []byte) []pdfStream {
+	locs := pdfObjectPattern.FindAllIndex(data, -1)
+	var streams []pdfStream
+	for i, loc := range locs {
+		end := len(data)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		obj := data[loc[1]:end]
+
+		streamAt := bytes.Index(obj, []byte("stream"))
+		if streamAt < 0 {
+			continue
+		}
+		body := obj[streamAt+len("stream"):]
+		body = bytes.TrimPrefix(body, []byte("\r"))
+		body = bytes.TrimPrefix(body, []byte("\n"))
+		endAt := bytes.LastIndex(body, []byte("endstream"))
+		if endAt < 0 {
+			continue
+		}
+		streams = append(streams, pdfStream{dict: obj[:streamAt], body: bytes.TrimRight(body[:endAt], "\r\n")})
+	}
+	return streams
+}
+
+func decodePDFImage(dict, body []byte) (image.Image, error) {
+	filter := pdfFilterPattern.FindSubmatch(dict)
+	if filter == nil {
+		return nil, nil
+	}
+	names := pdfNamePattern.FindAllSubmatch(filter[1], -1)
+	if len(names) != 1 {
+		return nil, fmt.Errorf("PDF memakai filter gambar berlapis yang tidak didukung")
+	}
+
+	switch name := string(names[0][1]); name {
+	case "DCTDecode":
+		return jpeg.Decode(bytes.NewReader(body))
+	case "FlateDecode":
+		return decodeFlateImage(dict, body)
+	default:
+		return nil, fmt.Errorf("format gambar PDF %s tidak didukung, scan ulang sebagai JPEG/PNG", name)
+	}
+}
+
+func decodeFlateImage(dict, body []byte) (image.Image, error) {
+	width, height := pdfInt(dict, pdfWidthPattern), pdfInt(dict, pdfHeightPattern)
+	bpc := pdfInt(dict, pdfBPCPattern)
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("ukuran gambar PDF tidak valid")
+	}
+
+	colors := 1
+	switch {
+	case bytes.Contains(dict, []byte("/DeviceRGB")):
+		colors = 3
+	case bytes.Contains(dict, []byte("/DeviceGray")):
+	default:
+		return nil, fmt.Errorf("color space gambar PDF tidak didukung")
+	}
+	if bpc != 8 && !(bpc == 1 && colors == 1) {
+		return nil, fmt.Errorf("bits per component %d tidak didukung", bpc)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	rowLen := (width*colors*bpc + 7) / 8
+	if pdfInt(dict, pdfPredictorPattern) >= 10 {
+		if raw, err = unpredictPNG(raw, rowLen, (colors*bpc+7)/8); err != nil {
+			return nil, err
+		}
+	}
+	if len(raw) < rowLen*height {
+		return nil, fmt.Errorf("data gambar PDF terpotong")
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		row := raw[y*rowLen : (y+1)*rowLen]
+		for x := 0; x < width; x++ {
+			var v uint8
+			switch {
+			case bpc == 1:
+				if row[x/8]&(0x80>>uint(x%8)) != 0 {
+					v = 255
+				}
+			case colors == 3:
+				r, g, b := row[x*3], row[x*3+1], row[x*3+2]
+				v = color.GrayModel.Convert(color.RGBA{R: r, G: g, B: b, A: 255}).(color.Gray).Y
+			default:
+				v = row[x]
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img, nil
+}
+
+// unpredictPNG buang byte filter PNG per baris (None/Sub/Up/Average/Paeth)
+func unpredictPNG(raw []byte, rowLen, bpp int) ([]byte, error) {
+	stride := rowLen + 1
+	if len(raw)%stride != 0 {
+		return nil, fmt.Errorf("data PNG predictor tidak valid")
+	}
+	rows := len(raw) / stride
+	out := make([]byte, rows*rowLen)
+	prev := make([]byte, rowLen)
+	for y := 0; y < rows; y++ {
+		filter := raw[y*stride]
+		cur := out[y*rowLen : (y+1)*rowLen]
+		copy(cur, raw[y*stride+1:(y+1)*stride])
+		for x := 0; x < rowLen; x++ {
+			var left, upLeft byte
+			if x >= bpp {
+				left, upLeft = cur[x-bpp], prev[x-bpp]
+			}
+			up := prev[x]
+			switch filter {
+			case 1:
+				cur[x] += left
+			case 2:
+				cur[x] += up
+			case 3:
+				cur[x] += byte((int(left) + int(up)) / 2)
+			case 4:
+				cur[x] += paeth(left, up, upLeft)
+			}
+		}
+		prev = cur
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := absInt(p-int(a)), absInt(p-int(b)), absInt(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func pdfInt(dict []byte, pattern *regexp.Regexp) int {
+	m := pattern.FindSubmatch(dict)
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(string(m[1]))
+	return v
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewLockScript hanya memperpanjang lease kalau masih dipegang owner yang sama
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLockScript hanya menghapus lease kalau masih dipegang owner yang sama
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock mencoba mengambil lease terdistribusi, true kalau berhasil
+func AcquireLock(ctx context.Context, client *redis.Client, key, owner string, ttl time.Duration) (bool, error) {
+	return client.SetNX(ctx, key, owner, ttl).Result()
+}
+
+// RenewLock memperpanjang lease milik owner, false kalau lease sudah diambil instance lain
+func RenewLock(ctx context.Context, client *redis.Client, key, owner string, ttl time.Duration) (bool, error) {
+	res, err := renewLockScript.Run(ctx, client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// ReleaseLock melepas lease milik owner
+func ReleaseLock(ctx context.Context, client *redis.Client, key, owner string) error {
+	return releaseLockScript.Run(ctx, client, []string{key}, owner).Err()
+}
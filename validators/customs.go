@@ -2,7 +2,9 @@ package validators
 
 import (
 	"brevet-api/models"
+	"brevet-api/utils"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -99,6 +101,186 @@ func QuizTypeValidator(fl validator.FieldLevel) bool {
 	}
 }
 
+// QuizScoringPolicyValidator checks if quiz_scoring_policy value is valid
+func QuizScoringPolicyValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.QuizScoringPolicy(val) {
+	case models.ScoringPolicyHighest, models.ScoringPolicyLatest, models.ScoringPolicyAverage, models.ScoringPolicyFirst:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuizModeValidator checks if quiz_mode value is valid
+func QuizModeValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.QuizMode(val) {
+	case models.QuizModeGraded, models.QuizModePractice, models.QuizModeSurvey:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuizReviewLevelValidator checks if quiz_review_level value is valid
+func QuizReviewLevelValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.QuizReviewLevel(val) {
+	case models.ReviewScoreOnly, models.ReviewWrongAnswers, models.ReviewFullSolution:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuizReviewReleaseValidator checks if quiz_review_release value is valid
+func QuizReviewReleaseValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.QuizReviewRelease(val) {
+	case models.ReleaseImmediate, models.ReleaseAfterEnd, models.ReleaseManual:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProctorEventTypeValidator checks if proctor_event_type value is valid
+func ProctorEventTypeValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.ProctorEventType(val) {
+	case models.ProctorTabBlur, models.ProctorFullscreenExit, models.ProctorCopy, models.ProctorPaste, models.ProctorReconnect:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuestionScoringModeValidator checks if question_scoring_mode value is valid
+func QuestionScoringModeValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.QuestionScoringMode(val) {
+	case models.ScoringNormal, models.ScoringVoid, models.ScoringFreeCredit:
+		return true
+	default:
+		return false
+	}
+}
+
 // CourseTypeValidator checks if course_type value is valid
 func CourseTypeValidator(fl validator.FieldLevel) bool {
 	field := fl.Field()
@@ -220,6 +402,36 @@ func AssignmentTypeValidator(fl validator.FieldLevel) bool {
 	}
 }
 
+// LatePenaltyUnitValidator checks if late_penalty_unit value is valid
+func LatePenaltyUnitValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+
+	// Ambil nilai string dari pointer atau value biasa
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.LatePenaltyUnit(val) {
+	case models.LatePenaltyPerHour, models.LatePenaltyPerDay:
+		return true
+	default:
+		return false
+	}
+}
+
 // PaymentStatusValidator checks if payment status value is valid
 func PaymentStatusValidator(fl validator.FieldLevel) bool {
 	field := fl.Field()
@@ -272,3 +484,40 @@ func BirthDateValidator(fl validator.FieldLevel) bool {
 	// Valid jika tanggal lahir tidak setelah hari ini
 	return !birthDate.After(time.Now())
 }
+
+// FileExtensionValidator checks if file extension is one of utils.SubmissionFileExtensions
+func FileExtensionValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.String {
+		return false
+	}
+	return slices.Contains(utils.SubmissionFileExtensions, field.String())
+}
+
+// AnnotationTypeValidator checks if annotation type value is valid
+func AnnotationTypeValidator(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	// Kalau nil, anggap valid (tidak wajib)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+	}
+
+	var val string
+	if field.Kind() == reflect.Ptr {
+		val = field.Elem().String()
+	} else if field.Kind() == reflect.String {
+		val = field.String()
+	} else {
+		return false
+	}
+
+	switch models.AnnotationType(val) {
+	case models.AnnotationHighlight, models.AnnotationBox, models.AnnotationNote:
+		return true
+	default:
+		return false
+	}
+}
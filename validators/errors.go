@@ -1,7 +1,9 @@
 package validators
 
 import (
+	"brevet-api/utils"
 	"fmt"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -38,8 +40,26 @@ func FormatValidationError(errs validator.ValidationErrors) map[string]string {
 			msg = fmt.Sprintf("%s harus salah satu dari: online, offline", field)
 		case "assignment_type":
 			msg = fmt.Sprintf("%s harus salah satu dari: file, essay", field)
+		case "late_penalty_unit":
+			msg = fmt.Sprintf("%s harus salah satu dari: hour, day", field)
+		case "annotation_type":
+			msg = fmt.Sprintf("%s harus salah satu dari: highlight, box, note", field)
+		case "file_extension":
+			msg = fmt.Sprintf("%s harus salah satu dari: %s", field, strings.Join(utils.SubmissionFileExtensions, ", "))
 		case "quiz_type":
 			msg = fmt.Sprintf("%s harus salah satu dari: mc, tf", field)
+		case "quiz_mode":
+			msg = fmt.Sprintf("%s harus salah satu dari: graded, practice, survey", field)
+		case "quiz_scoring_policy":
+			msg = fmt.Sprintf("%s harus salah satu dari: highest, latest, average, first", field)
+		case "quiz_review_level":
+			msg = fmt.Sprintf("%s harus salah satu dari: score_only, wrong_answers, full_solution", field)
+		case "quiz_review_release":
+			msg = fmt.Sprintf("%s harus salah satu dari: immediate, after_end, manual", field)
+		case "proctor_event_type":
+			msg = fmt.Sprintf("%s harus salah satu dari: tab_blur, fullscreen_exit, copy, paste, reconnect", field)
+		case "question_scoring_mode":
+			msg = fmt.Sprintf("%s harus salah satu dari: normal, void, free_credit", field)
 		case "payment_status_type":
 			msg = fmt.Sprintf("%s harus salah satu dari: pending, waiting_confirmation, paid, rejected, expired, cancelled", field)
 		default:
@@ -11,6 +11,15 @@ func RegisterCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("course_type", CourseTypeValidator)
 	v.RegisterValidation("meeting_type", MeetingTypeValidator)
 	v.RegisterValidation("assignment_type", AssignmentTypeValidator)
+	v.RegisterValidation("late_penalty_unit", LatePenaltyUnitValidator)
+	v.RegisterValidation("file_extension", FileExtensionValidator)
+	v.RegisterValidation("annotation_type", AnnotationTypeValidator)
 	v.RegisterValidation("payment_status_type", PaymentStatusValidator)
 	v.RegisterValidation("quiz_type", QuizTypeValidator)
+	v.RegisterValidation("quiz_mode", QuizModeValidator)
+	v.RegisterValidation("question_scoring_mode", QuestionScoringModeValidator)
+	v.RegisterValidation("quiz_scoring_policy", QuizScoringPolicyValidator)
+	v.RegisterValidation("quiz_review_level", QuizReviewLevelValidator)
+	v.RegisterValidation("quiz_review_release", QuizReviewReleaseValidator)
+	v.RegisterValidation("proctor_event_type", ProctorEventTypeValidator)
 }